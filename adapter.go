@@ -82,6 +82,12 @@ type Tx interface {
 	Commit(ctx context.Context) error
 	Rollback(ctx context.Context) error
 
+	// Savepoint 在当前事务内创建保存点；RollbackTo 回滚到该保存点，事务本身保持可用；
+	// ReleaseSavepoint 释放保存点并保留其后的写入（SQL Server 无对应语句，为空操作）。
+	Savepoint(ctx context.Context, name string) error
+	RollbackTo(ctx context.Context, name string) error
+	ReleaseSavepoint(ctx context.Context, name string) error
+
 	// 事务中的查询和执行
 	Query(ctx context.Context, sql string, args ...interface{}) (*sql.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...interface{}) *sql.Row
@@ -349,6 +355,8 @@ type Repository struct {
 	resultCacheBackend      CacheBackend
 	scheduledTaskFallbackOn bool
 	fallbackTaskManager     *inProcessScheduledTaskManager
+	changesetHooks          map[string][]ChangesetHook
+	timestampOptions        TimestampOptions
 	mu                      sync.RWMutex
 }
 
@@ -707,7 +715,7 @@ func (r *Repository) NewQueryConstructor(schema Schema) (QueryConstructor, error
 		return nil, fmt.Errorf("query constructor provider is not available")
 	}
 
-	return provider.NewQueryConstructor(schema), nil
+	return bindTransactionIfNeeded(r.adapter, provider.NewQueryConstructor(schema)), nil
 }
 
 // QueryConstructorExecutionResult 表示 QueryConstructor 统一执行结果。
@@ -759,13 +767,45 @@ func (r *Repository) ExecuteQueryConstructor(ctx context.Context, constructor Qu
 	if constructor == nil {
 		return nil, fmt.Errorf("query constructor cannot be nil")
 	}
+	if err := r.requireTransactionForLock(constructor); err != nil {
+		return nil, err
+	}
 
 	query, args, err := constructor.Build(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return r.executeCompiledQueryStatement(ctx, query, args)
+	result, err := r.executeCompiledQueryStatement(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	result, err = postProcessQueryConstructorResult(constructor, result)
+	if err != nil {
+		return nil, err
+	}
+	return r.preloadQueryConstructorResult(ctx, constructor, result)
+}
+
+// queryRowPostProcessor 由需要在应用层补齐结果的构造器实现（例如窗口函数降级计算）。
+type queryRowPostProcessor interface {
+	postProcessQueryRows(rows []map[string]interface{}) ([]map[string]interface{}, error)
+}
+
+func postProcessQueryConstructorResult(constructor QueryConstructor, result *QueryConstructorExecutionResult) (*QueryConstructorExecutionResult, error) {
+	if result == nil {
+		return result, nil
+	}
+	processor, ok := constructor.GetNativeBuilder().(queryRowPostProcessor)
+	if !ok {
+		return result, nil
+	}
+	rows, err := processor.postProcessQueryRows(result.Rows)
+	if err != nil {
+		return nil, err
+	}
+	result.Rows = rows
+	return result, nil
 }
 
 // ExecuteQueryConstructorWithCache 执行 QueryConstructor，并在编译阶段接入缓存。
@@ -781,6 +821,9 @@ func (r *Repository) ExecuteQueryConstructorWithCache(ctx context.Context, cache
 	if strings.TrimSpace(cacheKey) == "" {
 		return nil, false, fmt.Errorf("cache key cannot be empty")
 	}
+	if err := r.requireTransactionForLock(constructor); err != nil {
+		return nil, false, err
+	}
 
 	query, args, cacheHit, err := r.BuildAndCacheQuery(ctx, cacheKey, constructor)
 	if err != nil {
@@ -791,6 +834,14 @@ func (r *Repository) ExecuteQueryConstructorWithCache(ctx context.Context, cache
 	if execErr != nil {
 		return nil, cacheHit, execErr
 	}
+	result, execErr = postProcessQueryConstructorResult(constructor, result)
+	if execErr != nil {
+		return nil, cacheHit, execErr
+	}
+	result, execErr = r.preloadQueryConstructorResult(ctx, constructor, result)
+	if execErr != nil {
+		return nil, cacheHit, execErr
+	}
 	return result, cacheHit, nil
 }
 
@@ -1312,23 +1363,10 @@ func scanRowsToMapSlice(rows *sql.Rows) ([]map[string]interface{}, error) {
 
 	result := make([]map[string]interface{}, 0)
 	for rows.Next() {
-		vals := make([]interface{}, len(cols))
-		ptrs := make([]interface{}, len(cols))
-		for i := range vals {
-			ptrs[i] = &vals[i]
-		}
-		if scanErr := rows.Scan(ptrs...); scanErr != nil {
+		entry, scanErr := scanCurrentRowToMap(rows, cols)
+		if scanErr != nil {
 			return nil, scanErr
 		}
-
-		entry := make(map[string]interface{}, len(cols))
-		for i, col := range cols {
-			v := vals[i]
-			if b, ok := v.([]byte); ok {
-				v = string(b)
-			}
-			entry[strings.ToLower(col)] = v
-		}
 		result = append(result, entry)
 	}
 
@@ -1339,6 +1377,28 @@ func scanRowsToMapSlice(rows *sql.Rows) ([]map[string]interface{}, error) {
 	return result, nil
 }
 
+// scanCurrentRowToMap 扫描当前行为 map（列名小写，[]byte 转为 string）。
+func scanCurrentRowToMap(rows *sql.Rows, cols []string) (map[string]interface{}, error) {
+	vals := make([]interface{}, len(cols))
+	ptrs := make([]interface{}, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+
+	entry := make(map[string]interface{}, len(cols))
+	for i, col := range cols {
+		v := vals[i]
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		entry[strings.ToLower(col)] = v
+	}
+	return entry, nil
+}
+
 // GetQueryBuilderCapabilities 获取当前适配器的查询构造能力声明
 func (r *Repository) GetQueryBuilderCapabilities() (*QueryBuilderCapabilities, error) {
 	r.mu.RLock()
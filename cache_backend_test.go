@@ -417,6 +417,20 @@ func (s *stubQueryConstructor) WhereAny(_ ...Condition) QueryConstructor
 func (s *stubQueryConstructor) Select(_ ...string) QueryConstructor              { return s }
 func (s *stubQueryConstructor) Count(_ ...string) QueryConstructor               { return s }
 func (s *stubQueryConstructor) CountWith(_ *CountBuilder) QueryConstructor       { return s }
+func (s *stubQueryConstructor) Distinct() QueryConstructor                      { return s }
+func (s *stubQueryConstructor) DistinctOn(_ ...string) QueryConstructor           { return s }
+func (s *stubQueryConstructor) GroupBy(_ ...string) QueryConstructor             { return s }
+func (s *stubQueryConstructor) Having(_ Condition) QueryConstructor              { return s }
+func (s *stubQueryConstructor) Aggregate(_ ...*AggregateBuilder) QueryConstructor { return s }
+func (s *stubQueryConstructor) Window(_ ...*WindowBuilder) QueryConstructor       { return s }
+func (s *stubQueryConstructor) With(_ ...*CTEBuilder) QueryConstructor           { return s }
+func (s *stubQueryConstructor) Lock(_ *RowLock) QueryConstructor                 { return s }
+func (s *stubQueryConstructor) WithDeleted() QueryConstructor                   { return s }
+func (s *stubQueryConstructor) OnlyDeleted() QueryConstructor                   { return s }
+func (s *stubQueryConstructor) Union(_ QueryConstructor) QueryConstructor         { return s }
+func (s *stubQueryConstructor) UnionAll(_ QueryConstructor) QueryConstructor      { return s }
+func (s *stubQueryConstructor) Intersect(_ QueryConstructor) QueryConstructor     { return s }
+func (s *stubQueryConstructor) Except(_ QueryConstructor) QueryConstructor        { return s }
 func (s *stubQueryConstructor) OrderBy(_ string, _ string) QueryConstructor      { return s }
 func (s *stubQueryConstructor) Limit(_ int) QueryConstructor                     { return s }
 func (s *stubQueryConstructor) Offset(_ int) QueryConstructor                    { return s }
@@ -428,6 +442,7 @@ func (s *stubQueryConstructor) LeftJoin(_ string, _ string, _ ...string) QueryCo
 func (s *stubQueryConstructor) RightJoin(_ string, _ string, _ ...string) QueryConstructor { return s }
 func (s *stubQueryConstructor) CrossJoin(_ string, _ ...string) QueryConstructor           { return s }
 func (s *stubQueryConstructor) JoinWith(_ *JoinBuilder) QueryConstructor         { return s }
+func (s *stubQueryConstructor) Preload(_ ...string) QueryConstructor             { return s }
 func (s *stubQueryConstructor) CrossTableStrategy(_ CrossTableStrategy) QueryConstructor { return s }
 func (s *stubQueryConstructor) CustomMode() QueryConstructor                     { return s }
 func (s *stubQueryConstructor) Build(_ context.Context) (string, []interface{}, error) {
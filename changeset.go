@@ -30,6 +30,18 @@ type Changeset struct {
 	// 当前 changeset 的操作语义（insert/update/upsert）
 	action Action
 
+	// CastAssoc 登记的关联变更（按关系名去重，保持调用顺序）
+	assocs []*assocChange
+
+	// 写入时由关联自动填充的字段（外键等），校验时不检查必填
+	autoFilled map[string]bool
+
+	// UniqueConstraint / ForeignKeyConstraint / CheckConstraint 声明，写入失败时映射为字段错误
+	constraints []*changesetConstraint
+
+	// ContextValidator 结果缓存（按字段、验证器、值与主键）
+	validationCache map[string]error
+
 	// 锁
 	mu sync.RWMutex
 }
@@ -111,9 +123,9 @@ func (cs *Changeset) ValidateWithLocale(locale string) *Changeset {
 	return cs.validateLocked(locale, cs.action)
 }
 
-// ValidateWithContext 从上下文读取 locale 执行验证。
+// ValidateWithContext 从上下文读取 locale 执行验证，并运行需要数据库的 ContextValidator。
 func (cs *Changeset) ValidateWithContext(ctx context.Context) *Changeset {
-	return cs.ValidateWithLocale(ValidationLocaleFromContext(ctx))
+	return cs.validateWithContext(ctx, "")
 }
 
 // ValidateForInsert 验证插入场景（全字段 required 语义）。
@@ -132,7 +144,7 @@ func (cs *Changeset) ValidateForInsertWithLocale(locale string) *Changeset {
 
 // ValidateForInsertWithContext 从上下文读取 locale 验证插入场景。
 func (cs *Changeset) ValidateForInsertWithContext(ctx context.Context) *Changeset {
-	return cs.ValidateForInsertWithLocale(ValidationLocaleFromContext(ctx))
+	return cs.validateWithContext(ctx, ActionInsert)
 }
 
 // ValidateForUpdate 验证更新场景（仅校验变更字段）。
@@ -151,7 +163,7 @@ func (cs *Changeset) ValidateForUpdateWithLocale(locale string) *Changeset {
 
 // ValidateForUpdateWithContext 从上下文读取 locale 验证更新场景（仅校验变更字段）。
 func (cs *Changeset) ValidateForUpdateWithContext(ctx context.Context) *Changeset {
-	return cs.ValidateForUpdateWithLocale(ValidationLocaleFromContext(ctx))
+	return cs.validateWithContext(ctx, ActionUpdate)
 }
 
 // ValidateForUpsert 验证 upsert 场景。
@@ -174,10 +186,15 @@ func (cs *Changeset) ValidateForUpsertWithLocale(locale string) *Changeset {
 
 // ValidateForUpsertWithContext 从上下文读取 locale 验证 upsert 场景。
 func (cs *Changeset) ValidateForUpsertWithContext(ctx context.Context) *Changeset {
-	return cs.ValidateForUpsertWithLocale(ValidationLocaleFromContext(ctx))
+	return cs.validateWithContext(ctx, ActionUpsert)
 }
 
 func (cs *Changeset) validateLocked(locale string, action Action) *Changeset {
+	return cs.validateFieldsLocked(locale, action, nil)
+}
+
+// validateFieldsLocked deferred 非空时收集 ContextValidator 留待解锁后执行，否则回退调用 Validate。
+func (cs *Changeset) validateFieldsLocked(locale string, action Action, deferred *[]contextValidation) *Changeset {
 	cs.errors = make(map[string][]string) // 清空之前的错误
 	cs.valid = true
 
@@ -221,7 +238,7 @@ func (cs *Changeset) validateLocked(locale string, action Action) *Changeset {
 		}
 
 		// 检查必填字段
-		if shouldCheckRequired && !field.Null && (!exists || value == nil || value == "") {
+		if shouldCheckRequired && !field.Null && !cs.autoFilled[field.Name] && (!exists || value == nil || value == "") {
 			cs.addError(field.Name, "字段为必填项")
 			cs.valid = false
 			continue
@@ -230,6 +247,10 @@ func (cs *Changeset) validateLocked(locale string, action Action) *Changeset {
 		// 应用验证器
 		if shouldRunValidators {
 			for _, validator := range field.Validators {
+				if contextual, ok := validator.(ContextValidator); ok && deferred != nil {
+					*deferred = append(*deferred, contextValidation{field: field.Name, value: value, validator: contextual})
+					continue
+				}
 				var err error
 				if localeAware, ok := validator.(LocaleAwareValidator); ok {
 					err = localeAware.ValidateWithLocale(value, locale)
@@ -245,6 +266,10 @@ func (cs *Changeset) validateLocked(locale string, action Action) *Changeset {
 		}
 	}
 
+	for _, assoc := range cs.assocs {
+		cs.mergeAssocErrorsLocked(assoc)
+	}
+
 	return cs
 }
 
@@ -282,8 +307,17 @@ func (cs *Changeset) ValidateChangeWithLocale(fieldName string, validator Valida
 	return cs
 }
 
-// ValidateChangeWithContext 从上下文读取 locale 验证指定字段。
+// ValidateChangeWithContext 从上下文读取 locale 验证指定字段；ContextValidator 可访问上下文中的 Repository。
 func (cs *Changeset) ValidateChangeWithContext(ctx context.Context, fieldName string, validator Validator) *Changeset {
+	if contextual, ok := validator.(ContextValidator); ok {
+		value, exists := cs.GetChanged(fieldName)
+		if !exists {
+			return cs
+		}
+		cs.runContextValidations(ctx, cs.Action(), ValidationLocaleFromContext(ctx),
+			[]contextValidation{{field: fieldName, value: value, validator: contextual}})
+		return cs
+	}
 	return cs.ValidateChangeWithLocale(fieldName, validator, ValidationLocaleFromContext(ctx))
 }
 
@@ -391,6 +425,23 @@ func (cs *Changeset) PutChange(fieldName string, value interface{}) *Changeset {
 	return cs
 }
 
+// putData 仅更新原始数据（不记为变更），用于写入成功后同步数据库生成的值。
+func (cs *Changeset) putData(fieldName string, value interface{}) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.data[fieldName] = value
+	delete(cs.changes, fieldName)
+}
+
+// mergeData 将数据库返回的持久化行合并到原始数据（不影响变更集）。
+func (cs *Changeset) mergeData(row map[string]interface{}) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for k, v := range row {
+		cs.data[k] = v
+	}
+}
+
 // ClearError 清除错误
 func (cs *Changeset) ClearError(fieldName string) *Changeset {
 	cs.mu.Lock()
@@ -0,0 +1,535 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// assocChange CastAssoc 登记的一组关联子 Changeset。
+type assocChange struct {
+	name     string
+	relation *SchemaRelation
+	children []*Changeset
+	err      string // 关联级错误（未知关联、单值关联传入多项）
+}
+
+// single HasOne / BelongsTo 为单值关联，错误路径不带下标。
+func (a *assocChange) single() bool {
+	return a.relation != nil && (a.relation.Type == RelationHasOne || a.relation.Type == RelationBelongsTo)
+}
+
+func (a *assocChange) errorPath(index int, field string) string {
+	if a.single() {
+		return a.name + "." + field
+	}
+	return fmt.Sprintf("%s[%d].%s", a.name, index, field)
+}
+
+// findAssocRelation 按关系名查找关系；未命名的关系按目标表名匹配。
+func findAssocRelation(schema Schema, name string) *SchemaRelation {
+	relational, ok := schema.(RelationalSchema)
+	if !ok {
+		return nil
+	}
+	relations := relational.Relations()
+	for i := range relations {
+		if relations[i].TargetSchema != nil && relations[i].Name == name {
+			return &relations[i]
+		}
+	}
+	for i := range relations {
+		if relations[i].TargetSchema != nil && relations[i].Name == "" && relations[i].TargetSchema.TableName() == name {
+			return &relations[i]
+		}
+	}
+	return nil
+}
+
+// CastAssoc 将关联数据转换为子 Changeset（参考 Ecto.Changeset.cast_assoc）。
+// relationName 为 SchemaRelation.Name，未命名的关系使用目标表名。
+// 每项按目标 Schema 校验：带主键的项按更新校验、写入时更新，其余按插入校验、写入时插入。
+// 带主键的项必须已与当前记录关联：HasMany / HasOne 子记录的外键须指向当前记录，
+// BelongsTo / ManyToMany 目标仅引用主键时视为关联已有记录，修改其字段时须已与当前记录关联，否则写入返回错误；
+// 子项错误以 "items[2].qty"（HasOne / BelongsTo 为 "author.name"）的路径合并到当前 Changeset。
+// 关联由 ChangesetExecutor.Insert / UpdateByID 在同一事务内按外键顺序写入。
+func (cs *Changeset) CastAssoc(relationName string, items []map[string]interface{}) *Changeset {
+	relationName = strings.TrimSpace(relationName)
+	assoc := &assocChange{name: relationName, relation: findAssocRelation(cs.schema, relationName)}
+	switch {
+	case assoc.relation == nil:
+		assoc.err = "未知的关联"
+	case assoc.single() && len(items) > 1:
+		assoc.err = "关联最多只能包含一项"
+	default:
+		for _, item := range items {
+			assoc.children = append(assoc.children, newAssocChild(assoc.relation, item))
+		}
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	replaced := false
+	for i, existing := range cs.assocs {
+		if existing.name == relationName {
+			cs.assocs[i] = assoc
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		cs.assocs = append(cs.assocs, assoc)
+	}
+	if assoc.relation != nil && assoc.relation.Type == RelationBelongsTo && assoc.relation.ForeignKey != "" {
+		if cs.autoFilled == nil {
+			cs.autoFilled = make(map[string]bool)
+		}
+		cs.autoFilled[assoc.relation.ForeignKey] = true
+	}
+	cs.mergeAssocErrorsLocked(assoc)
+	return cs
+}
+
+// GetAssoc 返回 CastAssoc 登记的子 Changeset。
+func (cs *Changeset) GetAssoc(relationName string) []*Changeset {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	for _, assoc := range cs.assocs {
+		if assoc.name == strings.TrimSpace(relationName) {
+			return append([]*Changeset(nil), assoc.children...)
+		}
+	}
+	return nil
+}
+
+func (cs *Changeset) assocChanges() []*assocChange {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return append([]*assocChange(nil), cs.assocs...)
+}
+
+// newAssocChild 主键、外键、时间戳与版本字段由写入过程填充，不做必填校验。
+func newAssocChild(relation *SchemaRelation, item map[string]interface{}) *Changeset {
+	target := relation.TargetSchema
+	primaryKey := primaryKeyNameOrDefaultIR(target)
+	child := NewChangeset(target)
+	child.autoFilled = map[string]bool{primaryKey: true, createdAtField: true, updatedAtField: true}
+	if versionField := versionFieldOf(target); versionField != nil {
+		child.autoFilled[versionField.Name] = true
+	}
+	if relation.Type == RelationHasMany || relation.Type == RelationHasOne {
+		child.autoFilled[relation.ForeignKey] = true
+	}
+	child.Cast(item)
+	if child.Get(primaryKey) != nil {
+		return child.ApplyAction(ActionUpdate)
+	}
+	return child.ApplyAction(ActionInsert)
+}
+
+func (cs *Changeset) mergeAssocErrorsLocked(assoc *assocChange) {
+	if assoc.err != "" {
+		cs.addError(assoc.name, assoc.err)
+		cs.valid = false
+	}
+	for i, child := range assoc.children {
+		for field, messages := range child.Errors() {
+			for _, message := range messages {
+				cs.addError(assoc.errorPath(i, field), message)
+			}
+			cs.valid = false
+		}
+	}
+}
+
+// rejectAssocChanges 仅 Insert / UpdateByID 支持写入关联。
+func rejectAssocChanges(cs *Changeset, operation string) error {
+	if cs != nil && len(cs.assocChanges()) > 0 {
+		return fmt.Errorf("%s does not support association changes; use Insert or UpdateByID", operation)
+	}
+	return nil
+}
+
+// inAssocTransaction 关联写入（及 MySQL 条件更新的计数）需在同一事务内完成；非事务执行器开启新事务。
+func (e *ChangesetExecutor) inAssocTransaction(fn func(tx *ChangesetExecutor) error) error {
+	if e.afterCommit != nil {
+		return fn(e)
+	}
+	return e.owner.WithChangeset(e.context(), e.qb.schema, fn)
+}
+
+func (e *ChangesetExecutor) insertWithAssocs(cs *Changeset) (sql.Result, error) {
+	if !cs.IsValid() {
+		return nil, fmt.Errorf("changeset validation failed: %v", cs.Errors())
+	}
+	var res sql.Result
+	err := e.inAssocTransaction(func(tx *ChangesetExecutor) error {
+		var err error
+		res, err = tx.insertKeyed(cs)
+		return err
+	})
+	if isConstraintError(err) {
+		cs.mergeAssocConstraintErrors()
+	}
+	return res, err
+}
+
+func (e *ChangesetExecutor) updateWithAssocs(id interface{}, cs *Changeset) (sql.Result, error) {
+	if !cs.IsValid() {
+		return nil, fmt.Errorf("changeset validation failed: %v", cs.Errors())
+	}
+	var res sql.Result
+	err := e.inAssocTransaction(func(tx *ChangesetExecutor) error {
+		var err error
+		res, err = tx.updateKeyed(id, cs)
+		return err
+	})
+	if isConstraintError(err) {
+		cs.mergeAssocConstraintErrors()
+	}
+	return res, err
+}
+
+// insertKeyed 写入顺序：BelongsTo 目标 -> 本记录 -> HasMany / HasOne 子记录 -> ManyToMany 目标与中间表。
+// 写入后本记录主键同步到 cs.Data()。
+func (e *ChangesetExecutor) insertKeyed(cs *Changeset) (sql.Result, error) {
+	assocs := cs.assocChanges()
+	if err := e.saveBelongsTo(cs, assocs, nil); err != nil {
+		return nil, err
+	}
+
+	primaryKey := primaryKeyNameOrDefaultIR(e.qb.schema)
+	generated := cs.Get(primaryKey) == nil
+	var res sql.Result
+	if dialect, err := e.returningDialect(); generated && err == nil && resolveReturningMode(dialect) != returningReadBack {
+		// RETURNING / OUTPUT 读取生成的主键（PostgreSQL、SQL Server 不支持 LastInsertId）
+		if _, err := e.insertReturningRow(cs); err != nil {
+			return nil, err
+		}
+		res = changesetWriteResult{rowsAffected: 1}
+	} else {
+		if res, err = e.insertRow(cs); err != nil {
+			return nil, err
+		}
+		if generated {
+			id, err := res.LastInsertId()
+			if err != nil {
+				return nil, fmt.Errorf("read generated %s of %s: %w", primaryKey, e.qb.schema.TableName(), err)
+			}
+			cs.putData(primaryKey, id)
+		}
+	}
+
+	if err := e.saveAssocChildren(cs, assocs, false); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// updateKeyed 写入顺序同 insertKeyed；本记录无字段变更时跳过其 UPDATE。
+func (e *ChangesetExecutor) updateKeyed(id interface{}, cs *Changeset) (sql.Result, error) {
+	assocs := cs.assocChanges()
+	if err := e.saveBelongsTo(cs, assocs, id); err != nil {
+		return nil, err
+	}
+
+	var res sql.Result = changesetWriteResult{}
+	if len(cs.Changes()) > 0 {
+		var err error
+		if res, err = e.updateRow(id, cs); err != nil {
+			return nil, err
+		}
+	}
+	if primaryKey := primaryKeyNameOrDefaultIR(e.qb.schema); cs.Get(primaryKey) == nil {
+		cs.putData(primaryKey, id)
+	}
+
+	if err := e.saveAssocChildren(cs, assocs, true); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// saveAssocChild 插入子项，或按主键更新子项（除主键外无变更时跳过）。
+func (e *ChangesetExecutor) saveAssocChild(child *Changeset) error {
+	if child.Action() != ActionUpdate {
+		_, err := e.insertKeyed(child)
+		return err
+	}
+	if !assocChildWrites(child, e.qb.schema) {
+		return nil
+	}
+	_, err := e.updateKeyed(child.Get(primaryKeyNameOrDefaultIR(e.qb.schema)), child)
+	return err
+}
+
+// assocChildWrites 带主键的子项除主键外有变更（或含嵌套关联）时需要更新目标记录。
+func assocChildWrites(child *Changeset, schema Schema) bool {
+	if child.Action() != ActionUpdate {
+		return false
+	}
+	changes := child.Changes()
+	delete(changes, primaryKeyNameOrDefaultIR(schema))
+	return len(changes) > 0 || len(child.assocChanges()) > 0
+}
+
+// existingValues 返回当前执行器 Schema 中满足条件的记录的 key 字段值。
+func (e *ChangesetExecutor) existingValues(key string, condition Condition) ([]interface{}, error) {
+	qc, err := e.NewQueryConstructor()
+	if err != nil {
+		return nil, err
+	}
+	qc.Select(key).Where(condition)
+	result, err := e.ExecuteQueryConstructor(qc)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		values = append(values, row[key])
+	}
+	return values, nil
+}
+
+// existingKeys 同 existingValues，返回按 fmt.Sprint 比较的值集合。
+func (e *ChangesetExecutor) existingKeys(key string, condition Condition) (map[string]bool, error) {
+	values, err := e.existingValues(key, condition)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(values))
+	for _, value := range values {
+		keys[fmt.Sprint(value)] = true
+	}
+	return keys, nil
+}
+
+// assocParentKey HasMany / HasOne / ManyToMany 中本侧被引用的字段（默认主键）。
+func assocParentKey(relation *SchemaRelation, schema Schema) string {
+	if relation.OriginKey != "" && relation.Type != RelationManyToMany {
+		return relation.OriginKey
+	}
+	return primaryKeyNameOrDefaultIR(schema)
+}
+
+// saveBelongsTo 写入 BelongsTo 目标并回填外键；id 为当前记录主键（插入时为 nil）。
+// 修改已有目标的字段时，目标须是当前记录外键正指向的记录。
+func (e *ChangesetExecutor) saveBelongsTo(cs *Changeset, assocs []*assocChange, id interface{}) error {
+	for _, assoc := range assocs {
+		if assoc.relation == nil || assoc.relation.Type != RelationBelongsTo || len(assoc.children) == 0 {
+			continue
+		}
+		relation := assoc.relation
+		if relation.ForeignKey == "" {
+			return fmt.Errorf("association %s: relation has no foreign key (declare it with Over)", assoc.name)
+		}
+		target := e.WithSchema(relation.TargetSchema)
+		child := assoc.children[0]
+		originKey := relation.OriginKey
+		if originKey == "" {
+			originKey = primaryKeyNameOrDefaultIR(relation.TargetSchema)
+		}
+		if assocChildWrites(child, relation.TargetSchema) {
+			if err := e.checkBelongsToTarget(target, relation, originKey, child, id); err != nil {
+				return fmt.Errorf("association %s: %w", assoc.name, err)
+			}
+		}
+		if err := target.saveAssocChild(child); err != nil {
+			return fmt.Errorf("association %s: %w", assoc.name, err)
+		}
+		cs.PutChange(relation.ForeignKey, child.Get(originKey))
+	}
+	return nil
+}
+
+// checkBelongsToTarget 校验待更新的 BelongsTo 目标正被当前记录（主键 id）引用。
+func (e *ChangesetExecutor) checkBelongsToTarget(target *ChangesetExecutor, relation *SchemaRelation, originKey string, child *Changeset, id interface{}) error {
+	targetKey := primaryKeyNameOrDefaultIR(relation.TargetSchema)
+	childID := child.Get(targetKey)
+	notAssociated := fmt.Errorf("%s %v is not associated with this %s", relation.TargetSchema.TableName(), childID, e.qb.schema.TableName())
+	if id == nil {
+		return notAssociated
+	}
+	current, err := e.existingValues(relation.ForeignKey, Eq(primaryKeyNameOrDefaultIR(e.qb.schema), id))
+	if err != nil {
+		return err
+	}
+	if len(current) != 1 || current[0] == nil {
+		return notAssociated
+	}
+	matched, err := target.existingKeys(targetKey, And(Eq(targetKey, childID), Eq(originKey, current[0])))
+	if err != nil {
+		return err
+	}
+	if !matched[fmt.Sprint(childID)] {
+		return notAssociated
+	}
+	return nil
+}
+
+func (e *ChangesetExecutor) saveAssocChildren(cs *Changeset, assocs []*assocChange, replace bool) error {
+	for _, assoc := range assocs {
+		if assoc.relation == nil {
+			return fmt.Errorf("association %s: unknown relation", assoc.name)
+		}
+		if assoc.relation.Type == RelationBelongsTo {
+			continue
+		}
+		parentKey := assocParentKey(assoc.relation, e.qb.schema)
+		parentValue := cs.Get(parentKey)
+		if parentValue == nil {
+			return fmt.Errorf("association %s: parent %s is not set", assoc.name, parentKey)
+		}
+		var err error
+		if assoc.relation.Type == RelationManyToMany {
+			err = e.saveManyToManyAssoc(assoc, parentValue, replace)
+		} else {
+			err = e.saveHasAssoc(assoc, parentValue, replace)
+		}
+		if err != nil {
+			return fmt.Errorf("association %s: %w", assoc.name, err)
+		}
+	}
+	return nil
+}
+
+// saveHasAssoc 写入 FK 在子侧的关联；replace 时删除（支持软删除则软删除）不在新列表中的原有子记录。
+func (e *ChangesetExecutor) saveHasAssoc(assoc *assocChange, parentValue interface{}, replace bool) error {
+	relation := assoc.relation
+	if relation.ForeignKey == "" {
+		return fmt.Errorf("relation has no foreign key (declare it with Over)")
+	}
+	target := e.WithSchema(relation.TargetSchema)
+	targetKey := primaryKeyNameOrDefaultIR(relation.TargetSchema)
+	// 带主键的子项只能是已属于当前记录的子记录，防止经请求参数改写其他记录的子项
+	claimed := make([]interface{}, 0)
+	for _, child := range assoc.children {
+		if child.Action() == ActionUpdate {
+			claimed = append(claimed, child.Get(targetKey))
+		}
+	}
+	if len(claimed) > 0 {
+		owned, err := target.existingKeys(targetKey, And(Eq(relation.ForeignKey, parentValue), In(targetKey, claimed...)))
+		if err != nil {
+			return err
+		}
+		for i, child := range assoc.children {
+			if child.Action() == ActionUpdate && !owned[fmt.Sprint(child.Get(targetKey))] {
+				return fmt.Errorf("%s: %s %v does not belong to this %s", assoc.errorPath(i, targetKey), relation.TargetSchema.TableName(), child.Get(targetKey), e.qb.schema.TableName())
+			}
+		}
+	}
+	kept := make([]interface{}, 0, len(assoc.children))
+	for _, child := range assoc.children {
+		child.PutChange(relation.ForeignKey, parentValue)
+		if err := target.saveAssocChild(child); err != nil {
+			return err
+		}
+		kept = append(kept, child.Get(targetKey))
+	}
+	if !replace {
+		return nil
+	}
+
+	orphans := Eq(relation.ForeignKey, parentValue)
+	if len(kept) > 0 {
+		orphans = And(orphans, NotIn(targetKey, kept...))
+	}
+	var err error
+	if schemaSupportsSoftDelete(relation.TargetSchema) {
+		_, err = target.SoftDeleteWhere(orphans)
+	} else {
+		_, err = target.DeleteWhere(orphans)
+	}
+	return err
+}
+
+// saveManyToManyAssoc 写入目标记录并维护中间表；replace 时删除不在新列表中的中间记录。
+func (e *ChangesetExecutor) saveManyToManyAssoc(assoc *assocChange, parentValue interface{}, replace bool) error {
+	through := assoc.relation.Through
+	if through == nil || strings.TrimSpace(through.Table) == "" || through.SourceKey == "" || through.TargetKey == "" {
+		return fmt.Errorf("many-to-many relation requires Through(schema, sourceKey, targetKey)")
+	}
+	target := e.WithSchema(assoc.relation.TargetSchema)
+	targetKey := primaryKeyNameOrDefaultIR(assoc.relation.TargetSchema)
+	throughSchema := throughSchemaOf(through)
+	link := e.WithSchema(throughSchema)
+	// 仅引用主键的目标直接关联；修改目标字段时须已与当前记录关联
+	var existing map[string]bool
+	for i, child := range assoc.children {
+		if !assocChildWrites(child, assoc.relation.TargetSchema) {
+			continue
+		}
+		if existing == nil {
+			var err error
+			if existing, err = link.linkedKeys(through, parentValue); err != nil {
+				return err
+			}
+		}
+		if !existing[fmt.Sprint(child.Get(targetKey))] {
+			return fmt.Errorf("%s: %s %v is not associated with this %s", assoc.errorPath(i, targetKey), assoc.relation.TargetSchema.TableName(), child.Get(targetKey), e.qb.schema.TableName())
+		}
+	}
+	keys := make([]interface{}, 0, len(assoc.children))
+	for _, child := range assoc.children {
+		if err := target.saveAssocChild(child); err != nil {
+			return err
+		}
+		keys = append(keys, child.Get(targetKey))
+	}
+
+	linked := make(map[string]bool)
+	if replace {
+		stale := Eq(through.SourceKey, parentValue)
+		if len(keys) > 0 {
+			stale = And(stale, NotIn(through.TargetKey, keys...))
+		}
+		if _, err := link.DeleteWhere(stale); err != nil {
+			return err
+		}
+		var err error
+		if linked, err = link.linkedKeys(through, parentValue); err != nil {
+			return err
+		}
+	}
+	for _, key := range keys {
+		if linked[fmt.Sprint(key)] {
+			continue
+		}
+		linked[fmt.Sprint(key)] = true
+		row := NewChangeset(throughSchema).PutChange(through.SourceKey, parentValue).PutChange(through.TargetKey, key)
+		if _, err := link.insertRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkedKeys 读取中间表中 parentValue 已关联的目标键。
+func (e *ChangesetExecutor) linkedKeys(through *RelationThrough, parentValue interface{}) (map[string]bool, error) {
+	qc, err := e.NewQueryConstructor()
+	if err != nil {
+		return nil, err
+	}
+	qc.Select(through.TargetKey).Where(Eq(through.SourceKey, parentValue))
+	result, err := e.ExecuteQueryConstructor(qc)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(result.Rows))
+	for _, row := range result.Rows {
+		keys[fmt.Sprint(row[through.TargetKey])] = true
+	}
+	return keys, nil
+}
+
+// throughSchemaOf 中间表未声明 Schema 时按连接键构造最小 Schema。
+func throughSchemaOf(through *RelationThrough) Schema {
+	if through.Schema != nil {
+		return through.Schema
+	}
+	schema := NewBaseSchema(strings.TrimSpace(through.Table))
+	schema.AddField(NewField(through.SourceKey, TypeInteger).Build())
+	schema.AddField(NewField(through.TargetKey, TypeInteger).Build())
+	return schema
+}
@@ -0,0 +1,261 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type assocTestSchemas struct {
+	customers, orders, items, tags, orderTags *BaseSchema
+}
+
+func newAssocTestSchemas() assocTestSchemas {
+	s := assocTestSchemas{
+		customers: NewBaseSchema("customers"),
+		orders:    NewBaseSchema("orders"),
+		items:     NewBaseSchema("items"),
+		tags:      NewBaseSchema("tags"),
+		orderTags: NewBaseSchema("order_tags"),
+	}
+	s.customers.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	s.customers.AddField(NewField("name", TypeString).Build())
+	s.orders.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	s.orders.AddField(NewField("customer_id", TypeInteger).Build())
+	s.orders.AddField(NewField("note", TypeString).Null(true).Build())
+	s.items.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	s.items.AddField(NewField("order_id", TypeInteger).Build())
+	s.items.AddField(NewField("sku", TypeString).Build())
+	s.items.AddField(NewField("qty", TypeInteger).Validate(NewMinRangeValidator(1)).Build())
+	s.tags.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	s.tags.AddField(NewField("name", TypeString).Build())
+	s.orderTags.AddField(NewField("order_id", TypeInteger).Build())
+	s.orderTags.AddField(NewField("tag_id", TypeInteger).Build())
+
+	s.orders.BelongsTo(s.customers).Over("customer_id", "id").Named("customer")
+	s.orders.HasMany(s.items).Over("order_id", "id")
+	s.orders.ManyToMany(s.tags).Through(s.orderTags, "order_id", "tag_id")
+	return s
+}
+
+func TestChangesetCastAssocErrors(t *testing.T) {
+	s := newAssocTestSchemas()
+	cs := NewChangeset(s.orders).
+		Cast(map[string]interface{}{"note": "gift"}).
+		CastAssoc("customer", []map[string]interface{}{{"name": "alice"}}).
+		CastAssoc("items", []map[string]interface{}{
+			{"sku": "a", "qty": 1},
+			{"sku": "b", "qty": 2},
+			{"sku": "c", "qty": 0},
+		}).
+		Validate()
+
+	if cs.IsValid() {
+		t.Fatalf("expected invalid changeset")
+	}
+	errs := cs.Errors()
+	if len(errs["items[2].qty"]) == 0 {
+		t.Fatalf("expected nested error path items[2].qty, got %v", errs)
+	}
+	// 外键与子项主键由写入过程填充，不参与必填校验。
+	for path := range errs {
+		if path == "customer_id" || strings.HasSuffix(path, ".order_id") || strings.HasSuffix(path, ".id") {
+			t.Fatalf("unexpected required error on auto-filled field %s: %v", path, errs)
+		}
+	}
+	if len(cs.GetAssoc("items")) != 3 {
+		t.Fatalf("expected 3 item changesets")
+	}
+
+	cs = NewChangeset(s.orders).
+		CastAssoc("customer", []map[string]interface{}{{"name": "a"}, {"name": "b"}}).
+		CastAssoc("missing", nil)
+	if len(cs.GetError("customer")) == 0 || len(cs.GetError("missing")) == 0 {
+		t.Fatalf("expected relation-level errors, got %v", cs.Errors())
+	}
+	if cs.CastAssoc("customer", []map[string]interface{}{{}}); len(cs.Errors()["customer.name"]) == 0 {
+		t.Fatalf("expected single association error path customer.name, got %v", cs.Errors())
+	}
+}
+
+func TestChangesetExecutorAssocWritesSQLite(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, customer_id INTEGER NOT NULL REFERENCES customers(id), note TEXT)",
+		"CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT, order_id INTEGER NOT NULL REFERENCES orders(id), sku TEXT NOT NULL, qty INTEGER NOT NULL)",
+		"CREATE TABLE tags (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL)",
+		"CREATE TABLE order_tags (order_id INTEGER NOT NULL, tag_id INTEGER NOT NULL, PRIMARY KEY (order_id, tag_id))",
+		"INSERT INTO tags (id, name) VALUES (1, 'urgent')",
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+	s := newAssocTestSchemas()
+	count := func(query string, args ...interface{}) int {
+		var n int
+		if err := repo.QueryRow(ctx, query, args...).Scan(&n); err != nil {
+			t.Fatalf("count %q failed: %v", query, err)
+		}
+		return n
+	}
+
+	executor, err := repo.NewChangesetExecutor(ctx, s.orders)
+	if err != nil {
+		t.Fatalf("new executor failed: %v", err)
+	}
+	order := NewChangeset(s.orders).
+		Cast(map[string]interface{}{"note": "first"}).
+		CastAssoc("customer", []map[string]interface{}{{"name": "alice"}}).
+		CastAssoc("items", []map[string]interface{}{{"sku": "a", "qty": 1}, {"sku": "b", "qty": 2}}).
+		CastAssoc("tags", []map[string]interface{}{{"id": 1}, {"name": "fragile"}})
+	if _, err := executor.Insert(order); err != nil {
+		t.Fatalf("insert with assocs failed: %v", err)
+	}
+	orderID := order.Get("id")
+	if orderID == nil || order.Get("customer_id") == nil {
+		t.Fatalf("expected generated keys on order, got %v", order.Data())
+	}
+	if n := count("SELECT COUNT(*) FROM items WHERE order_id = ?", orderID); n != 2 {
+		t.Fatalf("expected 2 items, got %d", n)
+	}
+	if n := count("SELECT COUNT(*) FROM order_tags WHERE order_id = ?", orderID); n != 2 {
+		t.Fatalf("expected 2 tag links, got %d", n)
+	}
+	firstItem := order.GetAssoc("items")[0].Get("id")
+
+	// 替换：保留并修改第一项、删除第二项、新增一项；标签只保留新建的 fragile。
+	fragile := order.GetAssoc("tags")[1].Get("id")
+	update := NewChangeset(s.orders).
+		CastAssoc("items", []map[string]interface{}{{"id": firstItem, "qty": 5}, {"sku": "c", "qty": 3}}).
+		CastAssoc("tags", []map[string]interface{}{{"id": fragile}})
+	if _, err := executor.UpdateByID(orderID, update); err != nil {
+		t.Fatalf("update with assocs failed: %v", err)
+	}
+	if n := count("SELECT COUNT(*) FROM items WHERE order_id = ?", orderID); n != 2 {
+		t.Fatalf("expected 2 items after replace, got %d", n)
+	}
+	if n := count("SELECT qty FROM items WHERE id = ?", firstItem); n != 5 {
+		t.Fatalf("expected kept item updated to qty 5, got %d", n)
+	}
+	if n := count("SELECT COUNT(*) FROM order_tags WHERE order_id = ? AND tag_id = ?", orderID, fragile); n != 1 || count("SELECT COUNT(*) FROM order_tags") != 1 {
+		t.Fatalf("expected only fragile tag linked")
+	}
+
+	// 子项校验失败时不写入任何数据。
+	invalid := NewChangeset(s.orders).
+		CastAssoc("customer", []map[string]interface{}{{"name": "bob"}}).
+		CastAssoc("items", []map[string]interface{}{{"sku": "x", "qty": 0}})
+	if _, err := executor.Insert(invalid); err == nil || !strings.Contains(err.Error(), "items[0].qty") {
+		t.Fatalf("expected nested validation error, got: %v", err)
+	}
+	if n := count("SELECT COUNT(*) FROM customers"); n != 1 {
+		t.Fatalf("expected no customer written for invalid changeset, got %d", n)
+	}
+
+	// 客户写入后订单主键冲突，整体回滚。
+	broken := NewChangeset(s.orders).
+		Cast(map[string]interface{}{"id": orderID}).
+		CastAssoc("customer", []map[string]interface{}{{"name": "carol"}})
+	if _, err := executor.Insert(broken); err == nil {
+		t.Fatalf("expected insert to fail on primary key conflict")
+	}
+	if n := count("SELECT COUNT(*) FROM customers"); n != 1 {
+		t.Fatalf("expected rollback of customer insert, got %d customers", n)
+	}
+
+	if _, err := executor.UpdateWhere(update, Eq("id", orderID)); err == nil || !strings.Contains(err.Error(), "association") {
+		t.Fatalf("expected UpdateWhere to reject association changes, got: %v", err)
+	}
+	t.Logf("✓ nested association writes")
+}
+
+func TestChangesetExecutorAssocRejectsForeignChildren(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, customer_id INTEGER NOT NULL REFERENCES customers(id), note TEXT)",
+		"CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT, order_id INTEGER NOT NULL REFERENCES orders(id), sku TEXT NOT NULL, qty INTEGER NOT NULL)",
+		"CREATE TABLE tags (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL)",
+		"CREATE TABLE order_tags (order_id INTEGER NOT NULL, tag_id INTEGER NOT NULL, PRIMARY KEY (order_id, tag_id))",
+		"INSERT INTO customers (id, name) VALUES (1, 'alice'), (2, 'bob')",
+		"INSERT INTO orders (id, customer_id) VALUES (1, 1), (2, 2)",
+		"INSERT INTO items (id, order_id, sku, qty) VALUES (10, 1, 'a', 1), (20, 2, 'b', 2)",
+		"INSERT INTO tags (id, name) VALUES (1, 'urgent'), (2, 'fragile')",
+		"INSERT INTO order_tags (order_id, tag_id) VALUES (2, 2)",
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+	s := newAssocTestSchemas()
+	scalar := func(query string, args ...interface{}) interface{} {
+		var v interface{}
+		if err := repo.QueryRow(ctx, query, args...).Scan(&v); err != nil {
+			t.Fatalf("query %q failed: %v", query, err)
+		}
+		return v
+	}
+	executor, err := repo.NewChangesetExecutor(ctx, s.orders)
+	if err != nil {
+		t.Fatalf("new executor failed: %v", err)
+	}
+
+	// 订单 2 的明细不能经订单 1 的关联改写或挪走
+	steal := NewChangeset(s.orders).CastAssoc("items", []map[string]interface{}{{"id": 20, "qty": 9}})
+	if _, err := executor.UpdateByID(1, steal); err == nil || !strings.Contains(err.Error(), "items[0].id") {
+		t.Fatalf("expected foreign item to be rejected, got: %v", err)
+	}
+	if owner, qty := scalar("SELECT order_id FROM items WHERE id = 20"), scalar("SELECT qty FROM items WHERE id = 20"); owner != int64(2) || qty != int64(2) {
+		t.Fatalf("expected item 20 untouched, got order_id=%v qty=%v", owner, qty)
+	}
+	if n := scalar("SELECT COUNT(*) FROM items WHERE order_id = 1"); n != int64(1) {
+		t.Fatalf("expected replace of order 1 items rolled back, got %v", n)
+	}
+	attach := NewChangeset(s.orders).CastAssoc("items", []map[string]interface{}{{"id": 20}})
+	if _, err := executor.UpdateByID(1, attach); err == nil {
+		t.Fatalf("expected attaching foreign item by id to be rejected")
+	}
+	newOrder := NewChangeset(s.orders).
+		Cast(map[string]interface{}{"customer_id": 1}).
+		CastAssoc("items", []map[string]interface{}{{"id": 10}})
+	if _, err := executor.Insert(newOrder); err == nil {
+		t.Fatalf("expected inserted order to reject existing item")
+	}
+
+	// BelongsTo：只引用主键即改挂客户；修改非当前客户的字段被拒绝
+	rename := NewChangeset(s.orders).CastAssoc("customer", []map[string]interface{}{{"id": 2, "name": "mallory"}})
+	if _, err := executor.UpdateByID(1, rename); err == nil || !strings.Contains(err.Error(), "not associated") {
+		t.Fatalf("expected foreign customer update to be rejected, got: %v", err)
+	}
+	if name := scalar("SELECT name FROM customers WHERE id = 2"); name != "bob" {
+		t.Fatalf("expected customer 2 untouched, got %v", name)
+	}
+	own := NewChangeset(s.orders).CastAssoc("customer", []map[string]interface{}{{"id": 1, "name": "alice2"}})
+	if _, err := executor.UpdateByID(1, own); err != nil {
+		t.Fatalf("expected own customer update to succeed: %v", err)
+	}
+	reassign := NewChangeset(s.orders).CastAssoc("customer", []map[string]interface{}{{"id": 2}})
+	if _, err := executor.UpdateByID(1, reassign); err != nil || scalar("SELECT customer_id FROM orders WHERE id = 1") != int64(2) {
+		t.Fatalf("expected reassigning customer by id to succeed: %v", err)
+	}
+
+	// ManyToMany：只引用主键即关联；修改未关联标签的字段被拒绝
+	retag := NewChangeset(s.orders).CastAssoc("tags", []map[string]interface{}{{"id": 2, "name": "hacked"}})
+	if _, err := executor.UpdateByID(1, retag); err == nil || !strings.Contains(err.Error(), "tags[0].id") {
+		t.Fatalf("expected unlinked tag update to be rejected, got: %v", err)
+	}
+	if name := scalar("SELECT name FROM tags WHERE id = 2"); name != "fragile" {
+		t.Fatalf("expected tag 2 untouched, got %v", name)
+	}
+	link := NewChangeset(s.orders).CastAssoc("tags", []map[string]interface{}{{"id": 2}})
+	if _, err := executor.UpdateByID(1, link); err != nil || scalar("SELECT COUNT(*) FROM order_tags WHERE order_id = 1 AND tag_id = 2") != int64(1) {
+		t.Fatalf("expected linking tag by id to succeed: %v", err)
+	}
+	t.Logf("✓ association children owned by other records rejected")
+}
@@ -0,0 +1,235 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// changesetConstraint Changeset 上声明的数据库约束。
+type changesetConstraint struct {
+	kind    ConstraintKind
+	field   string
+	name    string
+	message string
+}
+
+// ConstraintOption Changeset 约束声明选项（函数选项模式）。
+type ConstraintOption func(*changesetConstraint)
+
+// WithConstraintName 指定约束名；未指定时使用 Schema 中 AddUniqueConstraint / AddForeignKey 声明的名称。
+func WithConstraintName(name string) ConstraintOption {
+	return func(c *changesetConstraint) {
+		c.name = strings.TrimSpace(name)
+	}
+}
+
+// WithConstraintMessage 覆盖默认错误信息。
+func WithConstraintMessage(message string) ConstraintOption {
+	return func(c *changesetConstraint) {
+		c.message = message
+	}
+}
+
+// ConstraintError 写入触发的数据库约束冲突已映射为 Changeset 字段错误。
+type ConstraintError struct {
+	Kind ConstraintKind
+	// Constraint 数据库报告的约束名（SQLite 等驱动不提供时为空）。
+	Constraint string
+	Field      string
+	Err        error
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%s constraint violation on %s: %v", e.Kind, e.Field, e.Err)
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// UniqueConstraint 声明唯一约束：写入触发唯一冲突时在 field 上记录错误而非返回驱动错误。
+// 按约束名匹配（默认取 Schema 中包含该字段的唯一约束名），驱动只报告列名时（SQLite、MongoDB）按列匹配。
+func (cs *Changeset) UniqueConstraint(field string, opts ...ConstraintOption) *Changeset {
+	return cs.addConstraint(ConstraintUnique, field, "", opts)
+}
+
+// ForeignKeyConstraint 声明外键约束：写入触发外键冲突时在 field 上记录错误。
+// 默认取 Schema 中包含该字段的外键约束名；SQLite 不报告外键名，仅在只声明了一个外键约束时映射。
+func (cs *Changeset) ForeignKeyConstraint(field string, opts ...ConstraintOption) *Changeset {
+	return cs.addConstraint(ConstraintForeignKey, field, "", opts)
+}
+
+// CheckConstraint 声明检查约束 name：写入触发该检查约束冲突时在 field 上记录错误。
+func (cs *Changeset) CheckConstraint(field, name string, opts ...ConstraintOption) *Changeset {
+	return cs.addConstraint(ConstraintCheck, field, name, opts)
+}
+
+func (cs *Changeset) addConstraint(kind ConstraintKind, field, name string, opts []ConstraintOption) *Changeset {
+	c := &changesetConstraint{kind: kind, field: strings.TrimSpace(field), name: strings.TrimSpace(name)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.constraints = append(cs.constraints, c)
+	return cs
+}
+
+func (c *changesetConstraint) defaultMessage() string {
+	if c.message != "" {
+		return c.message
+	}
+	switch c.kind {
+	case ConstraintUnique:
+		return "已被占用"
+	case ConstraintForeignKey:
+		return "关联记录不存在"
+	default:
+		return "不满足约束条件"
+	}
+}
+
+// resolve 返回可匹配的约束名与列。
+// 未显式命名时取 Schema 中包含该字段的约束，并追加数据库为列级约束生成的默认名
+// （PostgreSQL <table>_<field>_key / _fkey，MySQL 唯一索引名即列名，MongoDB <field>_1）。
+func (c *changesetConstraint) resolve(schema Schema) ([]string, []string) {
+	names := make([]string, 0)
+	fields := []string{c.field}
+	if c.name != "" {
+		names = append(names, c.name)
+	}
+	if schema == nil || c.kind == ConstraintCheck {
+		return names, fields
+	}
+	if cs, ok := schema.(ConstrainedSchema); ok {
+		for _, tc := range cs.Constraints() {
+			if tc.Kind != c.kind || !containsString(tc.Fields, c.field) {
+				continue
+			}
+			if c.name == "" || strings.EqualFold(tc.Name, c.name) {
+				if c.name == "" && tc.Name != "" {
+					names = append(names, tc.Name)
+				}
+				fields = tc.Fields
+				break
+			}
+		}
+	}
+	if c.name == "" {
+		table := schema.TableName()
+		switch c.kind {
+		case ConstraintUnique:
+			names = append(names, table+"_"+c.field+"_key", c.field, c.field+"_1")
+		case ConstraintForeignKey:
+			names = append(names, table+"_"+c.field+"_fkey")
+		}
+	}
+	return names, fields
+}
+
+// matchConstraintLocked 查找与约束冲突对应的声明。
+func (cs *Changeset) matchConstraintLocked(v *constraintViolation) *changesetConstraint {
+	candidates := make([]*changesetConstraint, 0)
+	for _, c := range cs.constraints {
+		if c.kind == v.kind {
+			candidates = append(candidates, c)
+		}
+	}
+	for _, c := range candidates {
+		names, fields := c.resolve(cs.schema)
+		switch {
+		case v.name != "" && constraintNameMatches(names, v.name):
+			return c
+		case len(v.fields) > 0 && sameFieldSet(fields, v.fields):
+			return c
+		}
+	}
+	if v.name == "" && len(v.fields) == 0 && len(candidates) == 1 {
+		return candidates[0]
+	}
+	return nil
+}
+
+// mapConstraintError 将已声明的约束冲突写入 Changeset 错误并返回 *ConstraintError；未声明时原样返回。
+func (cs *Changeset) mapConstraintError(err error) error {
+	if cs == nil || err == nil {
+		return err
+	}
+	violation, ok := parseConstraintViolation(err)
+	if !ok {
+		return err
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	c := cs.matchConstraintLocked(violation)
+	if c == nil {
+		return err
+	}
+	cs.addError(c.field, c.defaultMessage())
+	cs.valid = false
+	return &ConstraintError{Kind: c.kind, Constraint: violation.name, Field: c.field, Err: err}
+}
+
+func (cs *Changeset) hasConstraints() bool {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return len(cs.constraints) > 0
+}
+
+// mergeAssocConstraintErrors 关联子记录写入触发约束冲突后，将子项错误按路径合并到父 Changeset。
+func (cs *Changeset) mergeAssocConstraintErrors() {
+	for _, assoc := range cs.assocChanges() {
+		for _, child := range assoc.children {
+			child.mergeAssocConstraintErrors()
+		}
+		cs.mu.Lock()
+		cs.mergeAssocErrorsLocked(assoc)
+		cs.mu.Unlock()
+	}
+}
+
+// withConstraintErrors 包裹一次写入，将驱动返回的约束冲突映射到 Changeset。
+func withConstraintErrors(cs *Changeset, write func() (sql.Result, error)) func() (sql.Result, error) {
+	if cs == nil || !cs.hasConstraints() {
+		return write
+	}
+	return func() (sql.Result, error) {
+		res, err := write()
+		if err != nil {
+			return nil, cs.mapConstraintError(err)
+		}
+		return res, nil
+	}
+}
+
+func isConstraintError(err error) bool {
+	var constraintErr *ConstraintError
+	return errors.As(err, &constraintErr)
+}
+
+// constraintNameMatches 忽略大小写比较约束名；MySQL 8 报告的 "table.key" 去除表名前缀。
+func constraintNameMatches(names []string, reported string) bool {
+	if idx := strings.LastIndex(reported, "."); idx >= 0 {
+		reported = reported[idx+1:]
+	}
+	for _, name := range names {
+		if strings.EqualFold(name, reported) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameFieldSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, field := range b {
+		if !containsString(a, field) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	mssql "github.com/microsoft/go-mssqldb"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestParseConstraintViolation(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want constraintViolation
+	}{
+		{"postgres unique", &pq.Error{Code: "23505", Constraint: "uniq_users_email", Detail: "Key (email)=(a@x.io) already exists."},
+			constraintViolation{kind: ConstraintUnique, name: "uniq_users_email", fields: []string{"email"}}},
+		{"postgres foreign key", fmt.Errorf("insert failed: %w", &pq.Error{Code: "23503", Constraint: "fk_orders_users"}),
+			constraintViolation{kind: ConstraintForeignKey, name: "fk_orders_users"}},
+		{"postgres check", &pq.Error{Code: "23514", Constraint: "age_positive"},
+			constraintViolation{kind: ConstraintCheck, name: "age_positive"}},
+		{"mysql unique", &mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry 'a@x.io' for key 'users.uniq_users_email'"},
+			constraintViolation{kind: ConstraintUnique, name: "users.uniq_users_email"}},
+		{"mysql foreign key", &mysqldriver.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`app`.`orders`, CONSTRAINT `fk_orders_users` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"},
+			constraintViolation{kind: ConstraintForeignKey, name: "fk_orders_users"}},
+		{"mysql check", &mysqldriver.MySQLError{Number: 3819, Message: "Check constraint 'age_positive' is violated."},
+			constraintViolation{kind: ConstraintCheck, name: "age_positive"}},
+		{"sqlserver unique", mssql.Error{Number: 2627, Message: "Violation of UNIQUE KEY constraint 'uniq_users_email'. Cannot insert duplicate key in object 'dbo.users'."},
+			constraintViolation{kind: ConstraintUnique, name: "uniq_users_email"}},
+		{"sqlserver foreign key", mssql.Error{Number: 547, Message: `The INSERT statement conflicted with the FOREIGN KEY constraint "fk_orders_users".`},
+			constraintViolation{kind: ConstraintForeignKey, name: "fk_orders_users"}},
+		{"sqlserver check", mssql.Error{Number: 547, Message: `The INSERT statement conflicted with the CHECK constraint "age_positive".`},
+			constraintViolation{kind: ConstraintCheck, name: "age_positive"}},
+		{"mongo duplicate key", mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: `E11000 duplicate key error collection: app.users index: email_1_tenant_1 dup key: { email: "a@x.io", tenant: 1 }`}}},
+			constraintViolation{kind: ConstraintUnique, name: "email_1_tenant_1", fields: []string{"email", "tenant"}}},
+	}
+	for _, tc := range cases {
+		got, ok := parseConstraintViolation(tc.err)
+		if !ok {
+			t.Fatalf("%s: expected constraint violation", tc.name)
+		}
+		if len(got.fields) == 0 {
+			got.fields = nil
+		}
+		if !reflect.DeepEqual(*got, tc.want) {
+			t.Fatalf("%s: got %+v, want %+v", tc.name, *got, tc.want)
+		}
+	}
+	if _, ok := parseConstraintViolation(errors.New("connection refused")); ok {
+		t.Fatalf("expected plain error not to be parsed as constraint violation")
+	}
+	if _, ok := parseConstraintViolation(&pq.Error{Code: "42P01"}); ok {
+		t.Fatalf("expected undefined_table not to be parsed as constraint violation")
+	}
+}
+
+func TestChangesetConstraintMatching(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("email", TypeString).Build())
+	schema.AddField(NewField("tenant_id", TypeInteger).Build())
+	schema.AddUniqueConstraint("uniq_users_email", "email", "tenant_id")
+	schema.AddForeignKey("fk_users_tenants", []string{"tenant_id"}, "tenants", []string{"id"}, "", "")
+
+	driverErr := &mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry 'a-1' for key 'users.uniq_users_email'"}
+	cs := NewChangeset(schema).UniqueConstraint("email").ForeignKeyConstraint("tenant_id")
+	err := cs.mapConstraintError(driverErr)
+	var constraintErr *ConstraintError
+	if !errors.As(err, &constraintErr) || constraintErr.Field != "email" || !errors.Is(err, driverErr) {
+		t.Fatalf("expected ConstraintError on email wrapping driver error, got: %v", err)
+	}
+	if cs.IsValid() || !reflect.DeepEqual(cs.GetError("email"), []string{"已被占用"}) {
+		t.Fatalf("unexpected changeset errors: %v", cs.Errors())
+	}
+
+	// 列级唯一约束按数据库默认名匹配（PostgreSQL <table>_<field>_key）。
+	cs = NewChangeset(schema).UniqueConstraint("id", WithConstraintMessage("has already been taken"))
+	if err := cs.mapConstraintError(&pq.Error{Code: "23505", Constraint: "users_id_key"}); !isConstraintError(err) {
+		t.Fatalf("expected default postgres name to match, got: %v", err)
+	}
+	if got := cs.GetError("id"); !reflect.DeepEqual(got, []string{"has already been taken"}) {
+		t.Fatalf("expected custom message, got %v", got)
+	}
+
+	// 未声明的约束保留原始驱动错误。
+	cs = NewChangeset(schema).UniqueConstraint("email")
+	other := &pq.Error{Code: "23503", Constraint: "fk_users_tenants"}
+	if err := cs.mapConstraintError(other); err != other || !cs.IsValid() {
+		t.Fatalf("expected undeclared constraint to pass through, got: %v", err)
+	}
+	cs = NewChangeset(schema).CheckConstraint("email", "email_lower")
+	if err := cs.mapConstraintError(mssql.Error{Number: 547, Message: `conflicted with the CHECK constraint "email_lower".`}); !isConstraintError(err) {
+		t.Fatalf("expected check constraint mapped, got: %v", err)
+	}
+}
+
+func TestChangesetExecutorConstraintErrorsSQLite(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE members (id INTEGER PRIMARY KEY, email TEXT NOT NULL UNIQUE, age INTEGER NOT NULL, CONSTRAINT age_positive CHECK (age > 0))",
+		"CREATE TABLE member_tags (id INTEGER PRIMARY KEY, member_id INTEGER NOT NULL, label TEXT NOT NULL, UNIQUE (member_id, label))",
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+	members := NewBaseSchema("members")
+	members.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	members.AddField(NewField("email", TypeString).Build())
+	members.AddField(NewField("age", TypeInteger).Build())
+	tags := NewBaseSchema("member_tags")
+	tags.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	tags.AddField(NewField("member_id", TypeInteger).Build())
+	tags.AddField(NewField("label", TypeString).Build())
+	tags.AddUniqueConstraint("uniq_member_tags_label", "member_id", "label")
+	members.HasMany(tags).Over("member_id", "id").Named("tags")
+
+	executor, err := repo.NewChangesetExecutor(ctx, members)
+	if err != nil {
+		t.Fatalf("new executor failed: %v", err)
+	}
+	if _, err := executor.Insert(NewChangeset(members).Cast(map[string]interface{}{"id": 1, "email": "a@x.io", "age": 20})); err != nil {
+		t.Fatalf("seed insert failed: %v", err)
+	}
+
+	dup := NewChangeset(members).Cast(map[string]interface{}{"id": 2, "email": "a@x.io", "age": 20}).UniqueConstraint("email")
+	if _, err := executor.Insert(dup); !isConstraintError(err) {
+		t.Fatalf("expected ConstraintError for duplicate email, got: %v", err)
+	}
+	if len(dup.GetError("email")) == 0 {
+		t.Fatalf("expected email error, got %v", dup.Errors())
+	}
+
+	invalidAge := NewChangeset(members).Cast(map[string]interface{}{"id": 3, "email": "b@x.io", "age": 0}).CheckConstraint("age", "age_positive")
+	if _, err := executor.Insert(invalidAge); !isConstraintError(err) || len(invalidAge.GetError("age")) == 0 {
+		t.Fatalf("expected age check error, got: %v (%v)", err, invalidAge.Errors())
+	}
+
+	undeclared := NewChangeset(members).Cast(map[string]interface{}{"id": 4, "email": "a@x.io", "age": 20})
+	if _, err := executor.Insert(undeclared); err == nil || isConstraintError(err) || !undeclared.IsValid() {
+		t.Fatalf("expected raw driver error without declaration, got: %v", err)
+	}
+
+	nested := NewChangeset(members).Cast(map[string]interface{}{"id": 5, "email": "c@x.io", "age": 30})
+	nested.CastAssoc("tags", []map[string]interface{}{{"label": "vip"}, {"label": "vip"}})
+	nested.GetAssoc("tags")[1].UniqueConstraint("label")
+	if _, err := executor.Insert(nested); !isConstraintError(err) {
+		t.Fatalf("expected nested ConstraintError, got: %v", err)
+	}
+	if len(nested.Errors()["tags[1].label"]) == 0 {
+		t.Fatalf("expected nested error path tags[1].label, got %v", nested.Errors())
+	}
+	var n int
+	if err := repo.QueryRow(ctx, "SELECT COUNT(*) FROM members WHERE id = 5").Scan(&n); err != nil || n != 0 {
+		t.Fatalf("expected nested insert rolled back, got %d (%v)", n, err)
+	}
+	if !strings.Contains(nested.ErrorString(), "tags[1].label") {
+		t.Fatalf("expected error string to include nested path, got %s", nested.ErrorString())
+	}
+	t.Logf("✓ constraint violations mapped onto changeset errors")
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// contextValidation 一次待执行的 ContextValidator 调用。
+type contextValidation struct {
+	field     string
+	value     interface{}
+	validator ContextValidator
+}
+
+// validateWithContext 先在锁内执行同步校验，再在锁外执行 ContextValidator；action 为空时使用当前操作语义。
+func (cs *Changeset) validateWithContext(ctx context.Context, action Action) *Changeset {
+	locale := ValidationLocaleFromContext(ctx)
+	deferred := make([]contextValidation, 0)
+
+	cs.mu.Lock()
+	if action == "" {
+		action = cs.action
+	}
+	action = normalizeAction(action)
+	cs.validateFieldsLocked(locale, action, &deferred)
+	cs.mu.Unlock()
+
+	cs.runContextValidations(ctx, action, locale, deferred)
+	return cs
+}
+
+func (cs *Changeset) runContextValidations(ctx context.Context, action Action, locale string, pending []contextValidation) {
+	if len(pending) == 0 {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	base := ValidationContext{
+		Repository: ValidationRepositoryFromContext(ctx),
+		Schema:     cs.schema,
+		Action:     action,
+		Locale:     locale,
+	}
+	if action != ActionInsert {
+		base.PrimaryKey = cs.Get(primaryKeyNameOrDefaultIR(cs.schema))
+	}
+
+	keys := make([]string, len(pending))
+	results := make([]error, len(pending))
+	fresh := make([]bool, len(pending))
+	validate := func(i int, p contextValidation) {
+		vc := base
+		vc.Field = p.field
+		results[i] = p.validator.ValidateWithContext(ctx, &vc, p.value)
+	}
+	// 事务绑定的 Repository 共用一条连接，不能并发查询（MySQL busy buffer、pq 协议错乱），逐个执行
+	sequential := base.Repository != nil && isTxBoundRepository(base.Repository)
+	var wg sync.WaitGroup
+	for i, p := range pending {
+		keys[i] = contextValidationCacheKey(p, base.Repository, base.PrimaryKey)
+		cs.mu.RLock()
+		cached, hit := cs.validationCache[keys[i]]
+		cs.mu.RUnlock()
+		if hit {
+			results[i] = cached
+			continue
+		}
+		fresh[i] = true
+		if sequential {
+			validate(i, p)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, p contextValidation) {
+			defer wg.Done()
+			validate(i, p)
+		}(i, p)
+	}
+	wg.Wait()
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for i, p := range pending {
+		err := results[i]
+		// 仅缓存确定的校验结论；查询失败（连接错误、超时等）下次重新执行。
+		var validationErr *ValidationError
+		if fresh[i] && (err == nil || errors.As(err, &validationErr)) {
+			if cs.validationCache == nil {
+				cs.validationCache = make(map[string]error)
+			}
+			cs.validationCache[keys[i]] = err
+		}
+		if err != nil {
+			cs.addError(p.field, err.Error())
+			cs.valid = false
+		}
+	}
+}
+
+// isTxBoundRepository 判断 Repository 是否为事务内的包装（WithChangeset 的 e.Repository()）。
+func isTxBoundRepository(repo *Repository) bool {
+	_, ok := repo.GetAdapter().(*txAdapter)
+	return ok
+}
+
+func contextValidationCacheKey(p contextValidation, repo *Repository, primaryKey interface{}) string {
+	return fmt.Sprintf("%s\x00%p\x00%p\x00%#v\x00%#v", p.field, p.validator, repo, p.value, primaryKey)
+}
+
+// countMatching 统计 schema 中满足 condition 的记录数（SQL 使用 COUNT，MongoDB 使用 CountDocuments）。
+// withDeleted 为 true 时包含已软删除的记录。
+func countMatching(ctx context.Context, repo *Repository, schema Schema, condition Condition, withDeleted bool) (int64, error) {
+	qc, err := repo.NewQueryConstructor(schema)
+	if err != nil {
+		return 0, err
+	}
+	if withDeleted {
+		qc.WithDeleted()
+	}
+	return qc.Where(condition).SelectCount(ctx, repo)
+}
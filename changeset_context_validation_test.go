@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type countingContextValidator struct {
+	calls atomic.Int32
+}
+
+func (v *countingContextValidator) Validate(value interface{}) error {
+	return nil
+}
+
+func (v *countingContextValidator) ValidateWithContext(ctx context.Context, vc *ValidationContext, value interface{}) error {
+	v.calls.Add(1)
+	if value == "taken" {
+		return NewValidationError("custom", "taken")
+	}
+	return nil
+}
+
+func TestChangesetContextValidatorsSQLite(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE tenants (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE accounts (id INTEGER PRIMARY KEY, email TEXT NOT NULL, tenant_id INTEGER NOT NULL, deleted_at DATETIME NULL)",
+		"INSERT INTO tenants (id, name) VALUES (1, 'acme')",
+		"INSERT INTO accounts (id, email, tenant_id) VALUES (1, 'a@x.io', 1)",
+		"INSERT INTO accounts (id, email, tenant_id, deleted_at) VALUES (2, 'gone@x.io', 1, CURRENT_TIMESTAMP)",
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+	tenants := NewBaseSchema("tenants")
+	tenants.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	accounts := NewBaseSchema("accounts")
+	accounts.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	accounts.AddField(NewField("email", TypeString).Validate(&UniqueValidator{}).Build())
+	accounts.AddField(NewField("tenant_id", TypeInteger).Validate(&ExistsValidator{Schema: tenants}).Build())
+	accounts.AddSoftDelete()
+
+	validCtx := WithValidationRepository(ctx, repo)
+	cs := NewChangeset(accounts).Cast(map[string]interface{}{"id": 3, "email": "a@x.io", "tenant_id": 9})
+	if !cs.ValidateWithContext(ctx).IsValid() {
+		t.Fatalf("expected lookups skipped without repository, got %v", cs.Errors())
+	}
+	if !cs.Validate().IsValid() {
+		t.Fatalf("expected Validate to skip database lookups, got %v", cs.Errors())
+	}
+	cs.ValidateForInsertWithContext(validCtx)
+	if !reflect.DeepEqual(cs.GetError("email"), []string{"unique: 已被占用"}) ||
+		!reflect.DeepEqual(cs.GetError("tenant_id"), []string{"exists: 关联记录不存在"}) {
+		t.Fatalf("unexpected lookup errors: %v", cs.Errors())
+	}
+
+	// 更新时排除当前主键；软删除记录仍占用唯一值（数据库唯一索引同样计入），但不满足存在性校验。
+	self := NewChangeset(accounts)
+	self.Cast(map[string]interface{}{"id": 1})
+	self.SetAction(ActionUpdate)
+	self.Cast(map[string]interface{}{"email": "a@x.io"})
+	if !self.ValidateForUpdateWithContext(validCtx).IsValid() {
+		t.Fatalf("expected update of own email to pass, got %v", self.Errors())
+	}
+	reuse := NewChangeset(accounts).Cast(map[string]interface{}{"id": 4, "email": "gone@x.io", "tenant_id": 1})
+	if reuse.ValidateForInsertWithContext(WithValidationLocale(validCtx, "en-US")).IsValid() || len(reuse.GetError("email")) != 1 {
+		t.Fatalf("expected soft-deleted duplicate email to be taken, got %v", reuse.Errors())
+	}
+	deletedRef := NewChangeset(accounts).Cast(map[string]interface{}{"tenant_id": 2})
+	deletedRef.ValidateChangeWithContext(validCtx, "tenant_id", &ExistsValidator{Schema: accounts})
+	if len(deletedRef.GetError("tenant_id")) != 1 {
+		t.Fatalf("expected soft-deleted reference to fail exists check, got %v", deletedRef.Errors())
+	}
+
+	enCtx := WithValidationLocale(validCtx, "en-US")
+	changed := NewChangeset(accounts).Cast(map[string]interface{}{"tenant_id": 7})
+	changed.ValidateChangeWithContext(enCtx, "tenant_id", &ExistsValidator{Schema: tenants})
+	if got := changed.GetError("tenant_id"); len(got) != 1 || !strings.Contains(got[0], "does not exist") {
+		t.Fatalf("expected localized exists error, got %v", got)
+	}
+
+	// 同一 Changeset 内按值缓存查询结果。
+	counter := &countingContextValidator{}
+	custom := NewBaseSchema("accounts")
+	custom.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	custom.AddField(NewField("email", TypeString).Validate(counter).Build())
+	custom.AddField(NewField("alias", TypeString).Validate(counter).Build())
+	cached := NewChangeset(custom).Cast(map[string]interface{}{"id": 5, "email": "taken", "alias": "free"})
+	cached.ValidateWithContext(validCtx)
+	cached.ValidateWithContext(validCtx)
+	if calls := counter.calls.Load(); calls != 2 {
+		t.Fatalf("expected cached validator results, got %d calls", calls)
+	}
+	if len(cached.GetError("email")) != 1 || len(cached.GetError("alias")) != 0 {
+		t.Fatalf("expected cached error replayed, got %v", cached.Errors())
+	}
+	cached.Cast(map[string]interface{}{"alias": "taken"})
+	if cached.ValidateWithContext(validCtx); counter.calls.Load() != 3 || len(cached.GetError("alias")) != 1 {
+		t.Fatalf("expected changed value revalidated, calls=%d errors=%v", counter.calls.Load(), cached.Errors())
+	}
+	t.Logf("✓ database-backed validators")
+}
+
+type overlapContextValidator struct {
+	active, overlaps atomic.Int32
+}
+
+func (v *overlapContextValidator) Validate(value interface{}) error {
+	return nil
+}
+
+func (v *overlapContextValidator) ValidateWithContext(ctx context.Context, vc *ValidationContext, value interface{}) error {
+	if v.active.Add(1) > 1 {
+		v.overlaps.Add(1)
+	}
+	defer v.active.Add(-1)
+	var n int
+	return vc.Repository.QueryRow(ctx, "SELECT COUNT(*) FROM users").Scan(&n)
+}
+
+func TestChangesetContextValidatorsSequentialInTransaction(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+	validator := &overlapContextValidator{}
+	schema := NewBaseSchema("users")
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		schema.AddField(NewField(name, TypeString).Validate(validator).Build())
+	}
+	err := repo.WithChangeset(ctx, schema, func(e *ChangesetExecutor) error {
+		cs := NewChangeset(schema).Cast(map[string]interface{}{"a": "1", "b": "2", "c": "3", "d": "4", "e": "5", "f": "6"})
+		if !cs.ValidateWithContext(WithValidationRepository(ctx, e.Repository())).IsValid() {
+			t.Fatalf("expected validators to pass, got %v", cs.Errors())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+	if n := validator.overlaps.Load(); n != 0 {
+		t.Fatalf("expected tx-bound validators to run sequentially, got %d overlaps", n)
+	}
+	t.Logf("✓ context validators run sequentially on tx-bound repository")
+}
+
+func TestMongoSelectCountRequiresMongoAdapter(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	qc := NewMongoQueryConstructor(schema)
+	if _, err := qc.Where(Eq("id", 1)).SelectCount(context.Background(), repo); err == nil || !strings.Contains(err.Error(), "MongoAdapter") {
+		t.Fatalf("expected MongoAdapter requirement error, got: %v", err)
+	}
+}
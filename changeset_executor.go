@@ -4,12 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 )
 
 // ChangesetExecutor 面向业务层的写操作封装。
 // 它仅暴露基于 Changeset 的常见写路径，避免业务层默认直接操作 Tx。
 type ChangesetExecutor struct {
 	qb *QueryBuilder
+	// owner 持有按表注册的钩子（事务执行器的 qb.repo 为事务包装，钩子仍取自原 Repository）。
+	owner *Repository
+	// afterCommit 事务内登记的 after-commit 钩子；nil 表示非事务执行器，写入后立即执行。
+	afterCommit *[]func(context.Context) error
 }
 
 func newChangesetExecutor(schema Schema, repo *Repository, ctx context.Context) *ChangesetExecutor {
@@ -19,63 +24,385 @@ func newChangesetExecutor(schema Schema, repo *Repository, ctx context.Context)
 			repo:    repo,
 			context: ctx,
 		},
+		owner: repo,
 	}
 }
 
 // Insert 插入 Changeset。
+// Schema 含乐观锁版本字段且未赋值时，版本从 1 开始；未赋值的 created_at / updated_at 自动填充。
+// Changeset 含 CastAssoc 关联时，在同一事务内按外键顺序写入关联记录。
 func (e *ChangesetExecutor) Insert(cs *Changeset) (sql.Result, error) {
-	if e == nil || e.qb == nil {
-		return nil, fmt.Errorf("changeset executor is not initialized")
+	if err := e.ready(); err != nil {
+		return nil, err
 	}
-	return e.qb.Insert(cs)
+	if cs != nil && len(cs.assocChanges()) > 0 {
+		return e.insertWithAssocs(cs)
+	}
+	return e.insertRow(cs)
 }
 
-// Update 按条件更新 Changeset。
+func (e *ChangesetExecutor) insertRow(cs *Changeset) (sql.Result, error) {
+	e.touchInsertTimestamps(cs)
+	if versionField := versionFieldOf(e.qb.schema); versionField != nil && cs != nil && cs.Get(versionField.Name) == nil {
+		cs.PutChange(versionField.Name, int64(1))
+	}
+	return e.runHooked(ChangesetHookEvent{Operation: ChangesetOperationInsert, Changeset: cs}, func() (sql.Result, error) {
+		return e.qb.Insert(cs)
+	})
+}
+
+// Update 按条件更新 Changeset（自动刷新 updated_at）。
 func (e *ChangesetExecutor) Update(cs *Changeset, whereClause string, whereArgs ...interface{}) (sql.Result, error) {
-	if e == nil || e.qb == nil {
-		return nil, fmt.Errorf("changeset executor is not initialized")
+	if err := e.ready(); err != nil {
+		return nil, err
+	}
+	if err := rejectAssocChanges(cs, "Update"); err != nil {
+		return nil, err
 	}
-	return e.qb.Update(cs, whereClause, whereArgs...)
+	e.touchUpdateTimestamp(cs)
+	return e.runHooked(ChangesetHookEvent{Operation: ChangesetOperationUpdate, Changeset: cs}, func() (sql.Result, error) {
+		return e.qb.Update(cs, whereClause, whereArgs...)
+	})
 }
 
-// UpdateByID 按 ID 更新 Changeset。
+// UpdateByID 按 ID 更新 Changeset（自动刷新 updated_at）。
+// Schema 含乐观锁版本字段时追加 version = 当前值 条件并递增版本，未命中记录返回 *StaleEntryError。
+// Changeset 含 CastAssoc 关联时，在同一事务内写入关联并替换原有的 HasMany / HasOne / ManyToMany 关联。
 func (e *ChangesetExecutor) UpdateByID(id interface{}, cs *Changeset) (sql.Result, error) {
-	if e == nil || e.qb == nil {
-		return nil, fmt.Errorf("changeset executor is not initialized")
+	if err := e.ready(); err != nil {
+		return nil, err
+	}
+	if cs != nil && len(cs.assocChanges()) > 0 {
+		return e.updateWithAssocs(id, cs)
+	}
+	return e.updateRow(id, cs)
+}
+
+func (e *ChangesetExecutor) updateRow(id interface{}, cs *Changeset) (sql.Result, error) {
+	e.touchUpdateTimestamp(cs)
+	return e.runHooked(ChangesetHookEvent{Operation: ChangesetOperationUpdate, Changeset: cs, ID: id}, func() (sql.Result, error) {
+		if versionField := versionFieldOf(e.qb.schema); versionField != nil {
+			return e.updateByIDWithLock(id, cs, versionField.Name)
+		}
+		return e.qb.UpdateByID(id, cs)
+	})
+}
+
+func (e *ChangesetExecutor) updateByIDWithLock(id interface{}, cs *Changeset, versionField string) (sql.Result, error) {
+	if cs == nil {
+		return nil, fmt.Errorf("changeset is nil")
+	}
+	if !cs.IsValid() {
+		return nil, fmt.Errorf("changeset validation failed: %v", cs.Errors())
+	}
+	current := currentLockVersion(cs, versionField)
+	next, err := nextLockVersion(current)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := cs.Changes()
+	changes[versionField] = next
+	condition := And(Eq(primaryKeyNameOrDefaultIR(e.qb.schema), id), Eq(versionField, current))
+	affected, err := e.writeWhere(condition, changes)
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, &StaleEntryError{Table: e.qb.schema.TableName(), ID: id, VersionField: versionField, Version: current}
+	}
+	cs.putData(versionField, next)
+	return changesetWriteResult{rowsAffected: affected}, nil
+}
+
+// Upsert 按冲突列插入或更新 Changeset（conflictColumns 为空时从 Schema 推断），仅支持 SQL 适配器。
+// 插入时填充 created_at / updated_at，冲突更新时仅刷新 updated_at。
+func (e *ChangesetExecutor) Upsert(cs *Changeset, conflictColumns ...string) (sql.Result, error) {
+	if err := e.ready(); err != nil {
+		return nil, err
 	}
-	return e.qb.UpdateByID(id, cs)
+	if cs == nil {
+		return nil, fmt.Errorf("changeset is nil")
+	}
+	if err := rejectAssocChanges(cs, "Upsert"); err != nil {
+		return nil, err
+	}
+	e.touchInsertTimestamps(cs)
+	return e.runHooked(ChangesetHookEvent{Operation: ChangesetOperationUpsert, Changeset: cs}, func() (sql.Result, error) {
+		constructor, err := e.NewQueryConstructor()
+		if err != nil {
+			return nil, err
+		}
+		return constructor.Upsert(e.context(), e.qb.repo, cs, conflictColumns...)
+	})
 }
 
 // Delete 按条件删除记录。
 func (e *ChangesetExecutor) Delete(whereClause string, whereArgs ...interface{}) (sql.Result, error) {
-	if e == nil || e.qb == nil {
-		return nil, fmt.Errorf("changeset executor is not initialized")
+	if err := e.ready(); err != nil {
+		return nil, err
 	}
-	return e.qb.Delete(whereClause, whereArgs...)
+	return e.runHooked(ChangesetHookEvent{Operation: ChangesetOperationDelete}, func() (sql.Result, error) {
+		return e.qb.Delete(whereClause, whereArgs...)
+	})
 }
 
 // DeleteByID 按 ID 删除记录。
 func (e *ChangesetExecutor) DeleteByID(id interface{}) (sql.Result, error) {
-	if e == nil || e.qb == nil {
-		return nil, fmt.Errorf("changeset executor is not initialized")
+	if err := e.ready(); err != nil {
+		return nil, err
 	}
-	return e.qb.DeleteByID(id)
+	return e.runHooked(ChangesetHookEvent{Operation: ChangesetOperationDelete, ID: id}, func() (sql.Result, error) {
+		return e.qb.DeleteByID(id)
+	})
 }
 
 // SoftDelete 软删除记录。
 func (e *ChangesetExecutor) SoftDelete(whereClause string, whereArgs ...interface{}) (sql.Result, error) {
-	if e == nil || e.qb == nil {
-		return nil, fmt.Errorf("changeset executor is not initialized")
+	if err := e.ready(); err != nil {
+		return nil, err
 	}
-	return e.qb.SoftDelete(whereClause, whereArgs...)
+	return e.runHooked(ChangesetHookEvent{Operation: ChangesetOperationDelete, SoftDelete: true}, func() (sql.Result, error) {
+		return e.qb.SoftDelete(whereClause, whereArgs...)
+	})
 }
 
 // SoftDeleteByID 按 ID 软删除记录。
 func (e *ChangesetExecutor) SoftDeleteByID(id interface{}) (sql.Result, error) {
+	if err := e.ready(); err != nil {
+		return nil, err
+	}
+	return e.runHooked(ChangesetHookEvent{Operation: ChangesetOperationDelete, ID: id, SoftDelete: true}, func() (sql.Result, error) {
+		return e.qb.SoftDeleteByID(id)
+	})
+}
+
+// UpdateWhere 按条件更新 Changeset，返回匹配条件的行数。
+// 条件经适配器的查询构造器编译：SQL 方言使用各自的占位符并校验字段，MongoDB 使用 UpdateMany。
+// MySQL 的 RowsAffected 只计实际变化的行，这里在同一事务内先锁定并统计匹配行，值未变化的匹配行同样计入。
+// 可传入 WhereBuilder.Build() 组合的条件。
+func (e *ChangesetExecutor) UpdateWhere(cs *Changeset, condition Condition) (int64, error) {
+	if err := e.ready(); err != nil {
+		return 0, err
+	}
+	if cs == nil {
+		return 0, fmt.Errorf("changeset is nil")
+	}
+	if err := rejectAssocChanges(cs, "UpdateWhere"); err != nil {
+		return 0, err
+	}
+	e.touchUpdateTimestamp(cs)
+	return e.hookedWhere(ChangesetHookEvent{Operation: ChangesetOperationUpdate, Changeset: cs, Condition: condition}, func() (int64, error) {
+		if !cs.IsValid() {
+			return 0, fmt.Errorf("changeset validation failed: %v", cs.Errors())
+		}
+		changes := cs.Changes()
+		if len(changes) == 0 {
+			return 0, fmt.Errorf("no fields to update")
+		}
+		return e.writeWhere(condition, changes)
+	})
+}
+
+// DeleteWhere 按条件删除记录，返回删除的行数（MongoDB 使用 DeleteMany）。
+func (e *ChangesetExecutor) DeleteWhere(condition Condition) (int64, error) {
+	if err := e.ready(); err != nil {
+		return 0, err
+	}
+	return e.hookedWhere(ChangesetHookEvent{Operation: ChangesetOperationDelete, Condition: condition}, func() (int64, error) {
+		return e.writeWhere(condition, nil)
+	})
+}
+
+// SoftDeleteWhere 按条件软删除记录（设置 deleted_at），已软删除的记录不会被重复更新。
+func (e *ChangesetExecutor) SoftDeleteWhere(condition Condition) (int64, error) {
+	if err := e.ready(); err != nil {
+		return 0, err
+	}
+	if e.qb.schema.GetField("deleted_at") == nil {
+		return 0, fmt.Errorf("schema %s does not support soft delete (missing deleted_at field)", e.qb.schema.TableName())
+	}
+	if condition == nil {
+		return 0, fmt.Errorf("condition cannot be nil")
+	}
+	return e.hookedWhere(ChangesetHookEvent{Operation: ChangesetOperationDelete, Condition: condition, SoftDelete: true}, func() (int64, error) {
+		return e.writeWhere(And(condition, IsNull("deleted_at")), map[string]interface{}{"deleted_at": e.timestampNow()})
+	})
+}
+
+func (e *ChangesetExecutor) hookedWhere(event ChangesetHookEvent, write func() (int64, error)) (int64, error) {
+	res, err := e.runHooked(event, func() (sql.Result, error) {
+		affected, err := write()
+		if err != nil {
+			return nil, err
+		}
+		return changesetWriteResult{rowsAffected: affected}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// writeWhere 条件写入：changes 为 nil 时执行删除。
+func (e *ChangesetExecutor) writeWhere(condition Condition, changes map[string]interface{}) (int64, error) {
+	if err := e.ready(); err != nil {
+		return 0, err
+	}
+	if condition == nil {
+		return 0, fmt.Errorf("condition cannot be nil")
+	}
+	constructor, err := e.NewQueryConstructor()
+	if err != nil {
+		return 0, err
+	}
+	constructor.Where(condition)
+
+	ctx := e.context()
+	switch native := constructor.GetNativeBuilder().(type) {
+	case *SQLQueryConstructor:
+		var (
+			query string
+			args  []interface{}
+		)
+		if changes == nil {
+			query, args, err = native.buildDeleteSQL(ctx)
+		} else {
+			query, args, err = native.buildUpdateSQL(ctx, changes)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if changes != nil && strings.EqualFold(strings.TrimSpace(native.dialect.Name()), "mysql") {
+			return e.updateMatchedMySQL(native, query, args)
+		}
+		res, err := e.qb.repo.Exec(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	case *MongoQueryConstructor:
+		mongoAdapter, ok := e.qb.repo.GetAdapter().(*MongoAdapter)
+		if !ok {
+			return 0, fmt.Errorf("mongo query constructor requires mongodb adapter")
+		}
+		filter, err := native.buildFilter()
+		if err != nil {
+			return 0, err
+		}
+		if len(filter) == 0 {
+			return 0, fmt.Errorf("condition-based write requires at least one condition")
+		}
+		collection := e.qb.schema.TableName()
+		if changes == nil {
+			return mongoAdapter.deleteManyDocuments(ctx, collection, filter)
+		}
+		return mongoAdapter.updateManyDocuments(ctx, collection, filter, changes)
+	default:
+		return 0, fmt.Errorf("condition-based writes are not supported by %T", native)
+	}
+}
+
+// updateMatchedMySQL 在同一事务内以 SELECT COUNT(*) ... FOR UPDATE 锁定并统计匹配行后执行 UPDATE，
+// 返回匹配行数（与其他方言及 MongoDB MatchedCount 一致），不改变连接的 clientFoundRows 设置。
+func (e *ChangesetExecutor) updateMatchedMySQL(native *SQLQueryConstructor, update string, updateArgs []interface{}) (int64, error) {
+	ctx := e.context()
+	argIndex := 1
+	where, whereArgs, err := native.compileMutationWhere(ctx, &argIndex)
+	if err != nil {
+		return 0, err
+	}
+	count := "SELECT COUNT(*) FROM " + native.dialect.QuoteIdentifier(e.qb.schema.TableName()) + " WHERE " + where + " FOR UPDATE"
+	var matched int64
+	err = e.inAssocTransaction(func(tx *ChangesetExecutor) error {
+		if err := tx.qb.repo.QueryRow(ctx, count, whereArgs...).Scan(&matched); err != nil {
+			return err
+		}
+		_, err := tx.qb.repo.Exec(ctx, update, updateArgs...)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return matched, nil
+}
+
+// ExecuteQueryConstructor 在当前事务内执行查询构造器（例如 Lock 行锁查询）。
+func (e *ChangesetExecutor) ExecuteQueryConstructor(constructor QueryConstructor) (*QueryConstructorExecutionResult, error) {
+	if e == nil || e.qb == nil {
+		return nil, fmt.Errorf("changeset executor is not initialized")
+	}
+	return e.qb.repo.ExecuteQueryConstructor(e.context(), constructor)
+}
+
+// ExecuteQueryConstructorStream 在当前事务内流式读取 QueryConstructor 的结果。
+func (e *ChangesetExecutor) ExecuteQueryConstructorStream(constructor QueryConstructor, opts QueryStreamOptions) (*QueryRowIterator, error) {
 	if e == nil || e.qb == nil {
 		return nil, fmt.Errorf("changeset executor is not initialized")
 	}
-	return e.qb.SoftDeleteByID(id)
+	return e.qb.repo.ExecuteQueryConstructorStream(e.context(), constructor, opts)
+}
+
+// WithSchema 返回共享当前事务（及 after-commit 队列）的另一 Schema 执行器，用于在同一事务内写入其他表。
+func (e *ChangesetExecutor) WithSchema(schema Schema) *ChangesetExecutor {
+	if e == nil || e.qb == nil || schema == nil {
+		return e
+	}
+	sibling := newChangesetExecutor(schema, e.qb.repo, e.qb.context)
+	sibling.owner = e.owner
+	sibling.afterCommit = e.afterCommit
+	return sibling
+}
+
+// Repository 返回执行器绑定的 Repository；在 WithChangeset 内即事务 Repository，可用于嵌套 WithChangeset。
+func (e *ChangesetExecutor) Repository() *Repository {
+	if e == nil || e.qb == nil {
+		return nil
+	}
+	return e.qb.repo
+}
+
+// NewQueryConstructor 创建绑定当前事务的查询构造器（默认使用执行器的 Schema）。
+func (e *ChangesetExecutor) NewQueryConstructor(schema ...Schema) (QueryConstructor, error) {
+	if e == nil || e.qb == nil {
+		return nil, fmt.Errorf("changeset executor is not initialized")
+	}
+	target := e.qb.schema
+	if len(schema) > 0 && schema[0] != nil {
+		target = schema[0]
+	}
+	return e.qb.repo.NewQueryConstructor(target)
+}
+
+// changesetWriteResult 非 SQL 直接执行路径（条件写入）的 sql.Result。
+type changesetWriteResult struct {
+	rowsAffected int64
+}
+
+func (r changesetWriteResult) LastInsertId() (int64, error) {
+	return 0, fmt.Errorf("LastInsertId is not supported for condition-based writes")
+}
+
+func (r changesetWriteResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// ready 写入前检查执行器已初始化且绑定了 Schema（仅含 Run 步骤的 Multi 中 tx 未绑定 Schema）。
+func (e *ChangesetExecutor) ready() error {
+	if e == nil || e.qb == nil {
+		return fmt.Errorf("changeset executor is not initialized")
+	}
+	if e.qb.schema == nil {
+		return fmt.Errorf("changeset executor has no schema; bind one with WithSchema")
+	}
+	return nil
+}
+
+func (e *ChangesetExecutor) context() context.Context {
+	if e.qb.context != nil {
+		return e.qb.context
+	}
+	return context.Background()
 }
 
 // NewChangesetExecutor 创建面向业务层的 Changeset 执行器。
@@ -94,6 +421,8 @@ func (r *Repository) NewChangesetExecutor(ctx context.Context, schema Schema) (*
 
 // WithChangeset 在单个事务内执行一组基于 Changeset 的写操作。
 // 这是业务层推荐的多步写入入口。
+// 在事务 Repository（ChangesetExecutor.Repository）上再次调用时，嵌套块使用保存点执行，
+// 失败只回滚嵌套块自身的写入，外层事务继续可用。
 func (r *Repository) WithChangeset(ctx context.Context, schema Schema, fn func(*ChangesetExecutor) error) error {
 	if r == nil {
 		return fmt.Errorf("repository is nil")
@@ -104,6 +433,14 @@ func (r *Repository) WithChangeset(ctx context.Context, schema Schema, fn func(*
 	if fn == nil {
 		return fmt.Errorf("callback cannot be nil")
 	}
+	return r.withChangesetTx(ctx, schema, fn)
+}
+
+// withChangesetTx 开启事务（在事务 Repository 上使用保存点）并以绑定 schema 的执行器运行 fn。
+func (r *Repository) withChangesetTx(ctx context.Context, schema Schema, fn func(*ChangesetExecutor) error) error {
+	if outer, ok := r.GetAdapter().(*txAdapter); ok {
+		return r.withSavepoint(ctx, outer, schema, fn)
+	}
 
 	tx, err := r.Begin(ctx)
 	if err != nil {
@@ -111,8 +448,11 @@ func (r *Repository) WithChangeset(ctx context.Context, schema Schema, fn func(*
 	}
 
 	provider := r.GetAdapter().GetQueryBuilderProvider()
-	txRepo := &Repository{adapter: &txAdapter{tx: tx, provider: provider}}
+	afterCommit := make([]func(context.Context) error, 0)
+	txRepo := &Repository{adapter: &txAdapter{tx: tx, provider: provider, owner: r, afterCommit: &afterCommit}}
 	executor := newChangesetExecutor(schema, txRepo, ctx)
+	executor.owner = r
+	executor.afterCommit = &afterCommit
 
 	if err := fn(executor); err != nil {
 		_ = tx.Rollback(ctx)
@@ -124,5 +464,5 @@ func (r *Repository) WithChangeset(ctx context.Context, schema Schema, fn func(*
 		return err
 	}
 
-	return nil
+	return runAfterCommitHooks(ctx, afterCommit)
 }
\ No newline at end of file
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ChangesetHookStage Changeset 写入生命周期阶段。
+type ChangesetHookStage string
+
+const (
+	HookBeforeInsert ChangesetHookStage = "before_insert"
+	HookAfterInsert  ChangesetHookStage = "after_insert"
+	HookBeforeUpdate ChangesetHookStage = "before_update"
+	HookAfterUpdate  ChangesetHookStage = "after_update"
+	HookBeforeDelete ChangesetHookStage = "before_delete"
+	HookAfterDelete  ChangesetHookStage = "after_delete"
+	// HookAfterCommit 在事务提交后触发（非事务执行器在写入成功后立即触发），此时错误不再回滚写入。
+	HookAfterCommit ChangesetHookStage = "after_commit"
+)
+
+// ChangesetOperation Changeset 写操作类型。
+type ChangesetOperation string
+
+const (
+	ChangesetOperationInsert ChangesetOperation = "insert"
+	ChangesetOperationUpdate ChangesetOperation = "update"
+	ChangesetOperationDelete ChangesetOperation = "delete"
+	// ChangesetOperationUpsert 触发 insert 阶段的钩子。
+	ChangesetOperationUpsert ChangesetOperation = "upsert"
+)
+
+// ChangesetHookEvent 传递给钩子的写入上下文。
+type ChangesetHookEvent struct {
+	Stage     ChangesetHookStage
+	Operation ChangesetOperation
+	Table     string
+	// Changeset 插入 / 更新的变更集，before 阶段可修改；删除操作为 nil。
+	Changeset *Changeset
+	// ID 按 ID 写入时的主键值。
+	ID interface{}
+	// Condition 条件写入（*Where）时的条件。
+	Condition Condition
+	// SoftDelete 软删除（更新 deleted_at）时为 true。
+	SoftDelete bool
+	// RowsAffected after 阶段的影响行数。
+	RowsAffected int64
+	// Executor 当前执行器；after 阶段可用于在同一事务内继续写入。
+	Executor *ChangesetExecutor
+}
+
+// ChangesetHook Changeset 生命周期钩子。
+// Schema 可直接实现该接口，也可通过 Repository.RegisterChangesetHook 按表注册。
+// before 阶段返回错误即否决写入；after 阶段返回错误会使 WithChangeset 事务回滚。
+type ChangesetHook interface {
+	HandleChangesetEvent(ctx context.Context, event *ChangesetHookEvent) error
+}
+
+// ChangesetHookFunc 函数形式的 ChangesetHook。
+type ChangesetHookFunc func(ctx context.Context, event *ChangesetHookEvent) error
+
+func (f ChangesetHookFunc) HandleChangesetEvent(ctx context.Context, event *ChangesetHookEvent) error {
+	return f(ctx, event)
+}
+
+// ChangesetHookError 钩子返回的错误。
+type ChangesetHookError struct {
+	Stage ChangesetHookStage
+	Table string
+	Err   error
+}
+
+func (e *ChangesetHookError) Error() string {
+	return fmt.Sprintf("%s hook on %s: %v", e.Stage, e.Table, e.Err)
+}
+
+func (e *ChangesetHookError) Unwrap() error {
+	return e.Err
+}
+
+// RegisterChangesetHook 为指定表注册 Changeset 生命周期钩子，按注册顺序在 Schema 自身钩子之后执行。
+func (r *Repository) RegisterChangesetHook(table string, hook ChangesetHook) error {
+	if r == nil {
+		return fmt.Errorf("repository is nil")
+	}
+	table = strings.TrimSpace(table)
+	if table == "" {
+		return fmt.Errorf("table name cannot be empty")
+	}
+	if hook == nil {
+		return fmt.Errorf("hook cannot be nil")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.changesetHooks == nil {
+		r.changesetHooks = make(map[string][]ChangesetHook)
+	}
+	r.changesetHooks[table] = append(r.changesetHooks[table], hook)
+	return nil
+}
+
+func (r *Repository) changesetHooksFor(table string) []ChangesetHook {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	hooks := r.changesetHooks[strings.TrimSpace(table)]
+	if len(hooks) == 0 {
+		return nil
+	}
+	return append([]ChangesetHook(nil), hooks...)
+}
+
+// changesetHooks 返回执行器当前 Schema 适用的钩子（Schema 自身优先）。
+func (e *ChangesetExecutor) changesetHooks() []ChangesetHook {
+	hooks := make([]ChangesetHook, 0)
+	if hook, ok := e.qb.schema.(ChangesetHook); ok {
+		hooks = append(hooks, hook)
+	}
+	return append(hooks, e.owner.changesetHooksFor(e.qb.schema.TableName())...)
+}
+
+// runHooked 包裹一次写入：before 钩子 -> 写入 -> after 钩子 -> 登记 / 执行 after-commit 钩子。
+// 写入触发 Changeset 上声明的约束冲突时，错误映射到 Changeset 并返回 *ConstraintError。
+func (e *ChangesetExecutor) runHooked(event ChangesetHookEvent, write func() (sql.Result, error)) (sql.Result, error) {
+	write = withConstraintErrors(event.Changeset, write)
+	hooks := e.changesetHooks()
+	if len(hooks) == 0 {
+		return write()
+	}
+	if err := e.beforeWrite(hooks, event); err != nil {
+		return nil, err
+	}
+	res, err := write()
+	if err != nil {
+		return nil, err
+	}
+	if res != nil {
+		event.RowsAffected, _ = res.RowsAffected()
+	}
+	if err := e.afterWrite(hooks, event); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (e *ChangesetExecutor) beforeWrite(hooks []ChangesetHook, event ChangesetHookEvent) error {
+	event.Table = e.qb.schema.TableName()
+	event.Executor = e
+	before, _ := changesetHookStages(event.Operation)
+	return dispatchChangesetHooks(e.context(), hooks, event, before)
+}
+
+func (e *ChangesetExecutor) afterWrite(hooks []ChangesetHook, event ChangesetHookEvent) error {
+	event.Table = e.qb.schema.TableName()
+	event.Executor = e
+	_, after := changesetHookStages(event.Operation)
+	if err := dispatchChangesetHooks(e.context(), hooks, event, after); err != nil {
+		return err
+	}
+
+	event.Executor = nil
+	afterCommit := func(ctx context.Context) error {
+		return dispatchChangesetHooks(ctx, hooks, event, HookAfterCommit)
+	}
+	if e.afterCommit != nil {
+		*e.afterCommit = append(*e.afterCommit, afterCommit)
+		return nil
+	}
+	return afterCommit(e.context())
+}
+
+func changesetHookStages(operation ChangesetOperation) (ChangesetHookStage, ChangesetHookStage) {
+	switch operation {
+	case ChangesetOperationInsert, ChangesetOperationUpsert:
+		return HookBeforeInsert, HookAfterInsert
+	case ChangesetOperationUpdate:
+		return HookBeforeUpdate, HookAfterUpdate
+	default:
+		return HookBeforeDelete, HookAfterDelete
+	}
+}
+
+func dispatchChangesetHooks(ctx context.Context, hooks []ChangesetHook, event ChangesetHookEvent, stage ChangesetHookStage) error {
+	event.Stage = stage
+	for _, hook := range hooks {
+		if err := hook.HandleChangesetEvent(ctx, &event); err != nil {
+			return &ChangesetHookError{Stage: stage, Table: event.Table, Err: err}
+		}
+	}
+	return nil
+}
+
+// runAfterCommitHooks 事务提交后执行登记的 after-commit 钩子，所有钩子都会执行，错误合并返回。
+func runAfterCommitHooks(ctx context.Context, pending []func(context.Context) error) error {
+	var errs []error
+	for _, fn := range pending {
+		if err := fn(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// hookedUserSchema 通过嵌入 BaseSchema 并实现 ChangesetHook 提供 Schema 自身的钩子。
+type hookedUserSchema struct {
+	*BaseSchema
+	audit  *BaseSchema
+	stages []string
+}
+
+func (s *hookedUserSchema) HandleChangesetEvent(ctx context.Context, event *ChangesetHookEvent) error {
+	s.stages = append(s.stages, string(event.Stage))
+	switch event.Stage {
+	case HookBeforeInsert:
+		// 派生字段：根据 name 生成 email。
+		if name, ok := event.Changeset.Get("name").(string); ok && event.Changeset.Get("email") == nil {
+			event.Changeset.PutChange("email", strings.ToLower(name)+"@example.com")
+		}
+	case HookBeforeUpdate:
+		if event.Changeset.Get("name") == "forbidden" {
+			return fmt.Errorf("name is reserved")
+		}
+	case HookAfterInsert:
+		audit := NewChangeset(s.audit).Cast(map[string]interface{}{"action": "insert:" + event.Changeset.Get("name").(string)})
+		if _, err := event.Executor.WithSchema(s.audit).Insert(audit); err != nil {
+			return err
+		}
+		if event.Changeset.Get("name") == "Broken" {
+			return fmt.Errorf("audit rejected")
+		}
+	}
+	return nil
+}
+
+func createHookTestRepo(t *testing.T) (*Repository, *hookedUserSchema) {
+	t.Helper()
+	repo := createChangesetExecutorTestRepo(t)
+	if _, err := repo.Exec(context.Background(), "CREATE TABLE audit_logs (id INTEGER PRIMARY KEY, action TEXT NOT NULL)"); err != nil {
+		repo.Close()
+		t.Fatalf("failed to create audit table: %v", err)
+	}
+	audit := NewBaseSchema("audit_logs")
+	audit.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	audit.AddField(NewField("action", TypeString).Build())
+	return repo, &hookedUserSchema{BaseSchema: buildUserSchemaForChangesetExecutor(), audit: audit}
+}
+
+func TestChangesetHooksInsideWithChangeset(t *testing.T) {
+	repo, schema := createHookTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+
+	committed := make([]string, 0)
+	err := repo.RegisterChangesetHook("users", ChangesetHookFunc(func(ctx context.Context, event *ChangesetHookEvent) error {
+		if event.Stage == HookAfterCommit {
+			committed = append(committed, fmt.Sprintf("%s:%v", event.Operation, event.Changeset.Get("name")))
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("register hook failed: %v", err)
+	}
+
+	err = repo.WithChangeset(ctx, schema, func(e *ChangesetExecutor) error {
+		_, err := e.Insert(NewChangeset(schema).Cast(map[string]interface{}{"id": 1, "name": "Alice"}))
+		if err != nil {
+			return err
+		}
+		if len(committed) != 0 {
+			t.Fatalf("after-commit hooks must not run before commit")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("with changeset failed: %v", err)
+	}
+	if !reflect.DeepEqual(committed, []string{"insert:Alice"}) {
+		t.Fatalf("unexpected after-commit events: %v", committed)
+	}
+	if !reflect.DeepEqual(schema.stages, []string{"before_insert", "after_insert", "after_commit"}) {
+		t.Fatalf("unexpected hook stages: %v", schema.stages)
+	}
+
+	var email string
+	var audits int
+	if err := repo.QueryRow(ctx, "SELECT email FROM users WHERE id = 1").Scan(&email); err != nil || email != "alice@example.com" {
+		t.Fatalf("expected derived email, got %q (%v)", email, err)
+	}
+	if err := repo.QueryRow(ctx, "SELECT COUNT(*) FROM audit_logs").Scan(&audits); err != nil || audits != 1 {
+		t.Fatalf("expected audit row written in the same transaction, got %d (%v)", audits, err)
+	}
+
+	// after 钩子失败时整个事务回滚（包括钩子内的审计写入），且不触发 after-commit。
+	err = repo.WithChangeset(ctx, schema, func(e *ChangesetExecutor) error {
+		_, err := e.Insert(NewChangeset(schema).Cast(map[string]interface{}{"id": 2, "name": "Broken"}))
+		return err
+	})
+	var hookErr *ChangesetHookError
+	if !errors.As(err, &hookErr) || hookErr.Stage != HookAfterInsert {
+		t.Fatalf("expected after_insert hook error, got: %v", err)
+	}
+	if err := repo.QueryRow(ctx, "SELECT (SELECT COUNT(*) FROM users) + (SELECT COUNT(*) FROM audit_logs)").Scan(&audits); err != nil || audits != 2 {
+		t.Fatalf("failed after hook must roll back the transaction, got %d rows (%v)", audits, err)
+	}
+	if len(committed) != 1 {
+		t.Fatalf("rolled back writes must not fire after-commit hooks: %v", committed)
+	}
+	t.Logf("✓ hook stages: %v", schema.stages)
+}
+
+func TestChangesetHooksVetoAndImmediateAfterCommit(t *testing.T) {
+	repo, schema := createHookTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+
+	deleted := 0
+	if err := repo.RegisterChangesetHook("users", ChangesetHookFunc(func(ctx context.Context, event *ChangesetHookEvent) error {
+		if event.Stage == HookAfterCommit && event.Operation == ChangesetOperationDelete {
+			deleted += int(event.RowsAffected)
+		}
+		return nil
+	})); err != nil {
+		t.Fatalf("register hook failed: %v", err)
+	}
+
+	executor, err := repo.NewChangesetExecutor(ctx, schema)
+	if err != nil {
+		t.Fatalf("failed to create changeset executor: %v", err)
+	}
+	if _, err := executor.Insert(NewChangeset(schema).Cast(map[string]interface{}{"id": 1, "name": "Bob"})); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	cs := NewChangeset(schema).Cast(map[string]interface{}{"name": "forbidden"})
+	if _, err := executor.UpdateWhere(cs, Eq("id", 1)); err == nil || !strings.Contains(err.Error(), "before_update hook on users: name is reserved") {
+		t.Fatalf("expected veto from before_update hook, got: %v", err)
+	}
+	var name string
+	if err := repo.QueryRow(ctx, "SELECT name FROM users WHERE id = 1").Scan(&name); err != nil || name != "Bob" {
+		t.Fatalf("vetoed update must not be applied, got %q (%v)", name, err)
+	}
+
+	if affected, err := executor.DeleteWhere(Eq("id", 1)); err != nil || affected != 1 {
+		t.Fatalf("delete failed: %d (%v)", affected, err)
+	}
+	if deleted != 1 {
+		t.Fatalf("expected after-commit to fire immediately outside transactions, got %d", deleted)
+	}
+
+	if err := repo.RegisterChangesetHook("", ChangesetHookFunc(nil)); err == nil {
+		t.Fatalf("expected empty table name error")
+	}
+}
@@ -0,0 +1,546 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 各驱动单条语句可绑定的参数上限。
+const (
+	postgresMaxBindParams  = 65535
+	mysqlMaxBindParams     = 65535
+	sqliteMaxBindParams    = 32766 // SQLite 3.32+；更早版本为 999
+	sqliteLegacyBindParams = 999
+	sqlServerMaxBindParams = 2100
+	sqlServerMaxValuesRows = 1000 // SQL Server 单个 VALUES 子句最多 1000 行
+	defaultInsertAllRows   = 1000 // MongoDB / Neo4j 默认每批文档数
+)
+
+// InsertAllOptions 批量插入选项。
+type InsertAllOptions struct {
+	// BatchSize 每批最多行数；<= 0 时按驱动参数上限自动计算（SQL），MongoDB / Neo4j 默认 1000。
+	// 超过驱动参数上限时自动收紧。
+	BatchSize int
+	// ReturnIDs 返回生成的主键（按 Changeset 顺序）：
+	// - PostgreSQL / SQLite：RETURNING 的行序不保证，按自增主键升序（即 VALUES 行的分配顺序）对应；
+	//   生成的主键不是整数（如 UUID 默认值）时无法对应，返回错误，需在 Changeset 中显式给出主键
+	// - SQL Server：MERGE ... OUTPUT 同时输出源行序号，按序号对应
+	// - MySQL：LastInsertId 为首行自增值，按 @@auto_increment_increment 步长推算
+	// - MongoDB 返回 InsertedIDs，Neo4j 返回 elementId(n)
+	// Changeset 已包含主键时直接使用该值。
+	ReturnIDs bool
+	// ContinueOnError 某批失败后继续写入后续批次；默认在首个失败批次停止。
+	// 注意：PostgreSQL 事务内语句失败后事务即中止，WithChangeset 中继续写入没有意义。
+	ContinueOnError bool
+}
+
+// InsertAllChunkError 单个批次的写入错误，Start / End 为该批在输入切片中的下标范围 [Start, End)。
+type InsertAllChunkError struct {
+	Chunk int
+	Start int
+	End   int
+	Err   error
+}
+
+func (e *InsertAllChunkError) Error() string {
+	return fmt.Sprintf("insert all chunk %d (rows %d-%d): %v", e.Chunk, e.Start, e.End-1, e.Err)
+}
+
+func (e *InsertAllChunkError) Unwrap() error {
+	return e.Err
+}
+
+// InsertAllResult 批量插入结果。
+type InsertAllResult struct {
+	RowsAffected int64
+	Chunks       int
+	// InsertedIDs 与输入 Changeset 一一对应（仅 ReturnIDs 时填充；失败批次对应位置为 nil）。
+	InsertedIDs []interface{}
+	Errors      []*InsertAllChunkError
+}
+
+// insertAllChunk 同一列集合的连续行。
+type insertAllChunk struct {
+	start   int
+	columns []string
+	rows    []map[string]interface{}
+}
+
+// InsertAll 校验所有 Changeset 后分批写入：
+// - MySQL / SQLite / PostgreSQL：多行 VALUES，按驱动参数上限分批
+// - SQL Server：多行 VALUES，单批不超过 2100 个参数与 1000 行
+// - MongoDB：InsertMany
+// - Neo4j：UNWIND $rows CREATE
+// 任一 Changeset 校验失败时不写入任何数据；批次错误记录在结果的 Errors 中并以 error 返回。
+func (e *ChangesetExecutor) InsertAll(changesets []*Changeset, opts InsertAllOptions) (*InsertAllResult, error) {
+	if err := e.ready(); err != nil {
+		return nil, err
+	}
+	hooks := e.changesetHooks()
+	now := e.timestampNow()
+	rows := make([]map[string]interface{}, 0, len(changesets))
+	for i, cs := range changesets {
+		if cs == nil {
+			return nil, fmt.Errorf("changeset %d is nil", i)
+		}
+		if err := rejectAssocChanges(cs, "InsertAll"); err != nil {
+			return nil, fmt.Errorf("changeset %d: %w", i, err)
+		}
+		e.touchInsertTimestampsAt(cs, now)
+		if len(hooks) > 0 {
+			if err := e.beforeWrite(hooks, ChangesetHookEvent{Operation: ChangesetOperationInsert, Changeset: cs}); err != nil {
+				return nil, fmt.Errorf("changeset %d: %w", i, err)
+			}
+		}
+		if !cs.IsValid() {
+			return nil, fmt.Errorf("changeset %d validation failed: %v", i, cs.Errors())
+		}
+		cs.ForceChanges()
+		changes := cs.Changes()
+		if len(changes) == 0 {
+			return nil, fmt.Errorf("changeset %d has no fields to insert", i)
+		}
+		rows = append(rows, changes)
+	}
+
+	result := &InsertAllResult{}
+	if opts.ReturnIDs {
+		result.InsertedIDs = make([]interface{}, len(rows))
+	}
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	var (
+		write    func(chunk insertAllChunk) (int64, []interface{}, error)
+		rowLimit func(columns int) int
+	)
+	switch adapter := e.qb.repo.GetAdapter().(type) {
+	case *MongoAdapter:
+		rowLimit = func(int) int { return defaultInsertAllRows }
+		write = func(chunk insertAllChunk) (int64, []interface{}, error) {
+			return e.insertAllMongo(adapter, chunk)
+		}
+	case *Neo4jAdapter:
+		rowLimit = func(int) int { return defaultInsertAllRows }
+		write = func(chunk insertAllChunk) (int64, []interface{}, error) {
+			return e.insertAllNeo4j(adapter, chunk, opts.ReturnIDs)
+		}
+	default:
+		dialect := e.qb.dialect()
+		rowLimit = insertAllRowLimit(dialect)
+		write = func(chunk insertAllChunk) (int64, []interface{}, error) {
+			return e.insertAllSQL(dialect, chunk, opts.ReturnIDs)
+		}
+	}
+
+	chunks := splitInsertAllChunks(rows, func(columns int) int {
+		limit := rowLimit(columns)
+		if opts.BatchSize > 0 && opts.BatchSize < limit {
+			return opts.BatchSize
+		}
+		return limit
+	})
+	result, err := e.runInsertAllChunks(result, chunks, write, opts)
+	if len(hooks) == 0 {
+		return result, err
+	}
+	if hookErr := e.afterInsertAll(hooks, changesets, chunks, result); hookErr != nil {
+		return result, hookErr
+	}
+	return result, err
+}
+
+// afterInsertAll 对写入成功批次中的每个 Changeset 触发 after_insert 钩子。
+func (e *ChangesetExecutor) afterInsertAll(hooks []ChangesetHook, changesets []*Changeset, chunks []insertAllChunk, result *InsertAllResult) error {
+	failed := make(map[int]bool, len(result.Errors))
+	for _, chunkErr := range result.Errors {
+		failed[chunkErr.Chunk] = true
+	}
+	for i := 0; i < result.Chunks && i < len(chunks); i++ {
+		if failed[i] {
+			continue
+		}
+		for j := chunks[i].start; j < chunks[i].start+len(chunks[i].rows); j++ {
+			event := ChangesetHookEvent{Operation: ChangesetOperationInsert, Changeset: changesets[j], RowsAffected: 1}
+			if result.InsertedIDs != nil {
+				event.ID = result.InsertedIDs[j]
+			}
+			if err := e.afterWrite(hooks, event); err != nil {
+				return fmt.Errorf("changeset %d: %w", j, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (e *ChangesetExecutor) runInsertAllChunks(result *InsertAllResult, chunks []insertAllChunk, write func(chunk insertAllChunk) (int64, []interface{}, error), opts InsertAllOptions) (*InsertAllResult, error) {
+	for i, chunk := range chunks {
+		if err := e.context().Err(); err != nil {
+			return result, err
+		}
+		result.Chunks++
+		affected, ids, err := write(chunk)
+		if err != nil {
+			result.Errors = append(result.Errors, &InsertAllChunkError{Chunk: i, Start: chunk.start, End: chunk.start + len(chunk.rows), Err: err})
+			if !opts.ContinueOnError {
+				break
+			}
+			continue
+		}
+		result.RowsAffected += affected
+		if opts.ReturnIDs {
+			copy(result.InsertedIDs[chunk.start:chunk.start+len(chunk.rows)], ids)
+		}
+	}
+	if len(result.Errors) > 0 {
+		return result, fmt.Errorf("insert all: %d of %d chunks failed: %w", len(result.Errors), len(chunks), result.Errors[0])
+	}
+	return result, nil
+}
+
+// splitInsertAllChunks 按列集合与批大小切分连续行，保持输入顺序。
+func splitInsertAllChunks(rows []map[string]interface{}, maxRows func(columns int) int) []insertAllChunk {
+	chunks := make([]insertAllChunk, 0)
+	var current *insertAllChunk
+	signature := ""
+	limit := 0
+	for i, row := range rows {
+		columns := make([]string, 0, len(row))
+		for column := range row {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		key := strings.Join(columns, "\x00")
+		if current == nil || key != signature || len(current.rows) >= limit {
+			chunks = append(chunks, insertAllChunk{start: i, columns: columns})
+			current = &chunks[len(chunks)-1]
+			signature = key
+			limit = maxRows(len(columns))
+			if limit < 1 {
+				limit = 1
+			}
+		}
+		current.rows = append(current.rows, row)
+	}
+	return chunks
+}
+
+// insertAllRowLimit 返回方言按列数计算的单批最大行数。
+func insertAllRowLimit(dialect SQLDialect) func(columns int) int {
+	maxParams := mysqlMaxBindParams
+	maxRows := 0
+	switch strings.ToLower(strings.TrimSpace(dialect.Name())) {
+	case "postgres", "postgresql":
+		maxParams = postgresMaxBindParams
+	case "sqlite":
+		maxParams = sqliteMaxBindParams
+		if version := resolveSQLDialectVersionIR(dialect); version != "" && compareVersion(version, "3.32.0") < 0 {
+			maxParams = sqliteLegacyBindParams
+		}
+	case "sqlserver":
+		maxParams = sqlServerMaxBindParams - 1
+		maxRows = sqlServerMaxValuesRows
+	}
+	return func(columns int) int {
+		if columns <= 0 {
+			columns = 1
+		}
+		rows := maxParams / columns
+		if maxRows > 0 && rows > maxRows {
+			rows = maxRows
+		}
+		return rows
+	}
+}
+
+// buildInsertAllSQL 构建多行 INSERT；returning 非空时附加 RETURNING / OUTPUT INSERTED 子句。
+// returning 为 "*" 时返回整行。
+func buildInsertAllSQL(dialect SQLDialect, table string, chunk insertAllChunk, returning string) (string, []interface{}) {
+	quotedColumns := make([]string, 0, len(chunk.columns))
+	for _, column := range chunk.columns {
+		quotedColumns = append(quotedColumns, dialect.QuoteIdentifier(column))
+	}
+	args := make([]interface{}, 0, len(chunk.rows)*len(chunk.columns))
+	tuples := make([]string, 0, len(chunk.rows))
+	argIndex := 1
+	for _, row := range chunk.rows {
+		placeholders := make([]string, 0, len(chunk.columns))
+		for _, column := range chunk.columns {
+			placeholders = append(placeholders, dialect.GetPlaceholder(argIndex))
+			args = append(args, row[column])
+			argIndex++
+		}
+		tuples = append(tuples, "("+strings.Join(placeholders, ", ")+")")
+	}
+
+	var sql strings.Builder
+	sql.WriteString("INSERT INTO ")
+	sql.WriteString(dialect.QuoteIdentifier(table))
+	sql.WriteString(" (")
+	sql.WriteString(strings.Join(quotedColumns, ", "))
+	sql.WriteString(")")
+	isSQLServer := strings.EqualFold(strings.TrimSpace(dialect.Name()), "sqlserver")
+	returningColumn := returning
+	if returning != "" && returning != "*" {
+		returningColumn = dialect.QuoteIdentifier(returning)
+	}
+	if returning != "" && isSQLServer {
+		sql.WriteString(" OUTPUT INSERTED.")
+		sql.WriteString(returningColumn)
+	}
+	sql.WriteString(" VALUES ")
+	sql.WriteString(strings.Join(tuples, ", "))
+	if returning != "" && !isSQLServer {
+		sql.WriteString(" RETURNING ")
+		sql.WriteString(returningColumn)
+	}
+	return sql.String(), args
+}
+
+// providedInsertAllIDs 每行均显式给出主键时直接返回这些值。
+func providedInsertAllIDs(chunk insertAllChunk, primaryKey string) ([]interface{}, bool) {
+	if !containsString(chunk.columns, primaryKey) {
+		return nil, false
+	}
+	ids := make([]interface{}, 0, len(chunk.rows))
+	for _, row := range chunk.rows {
+		if row[primaryKey] == nil {
+			return nil, false
+		}
+		ids = append(ids, row[primaryKey])
+	}
+	return ids, true
+}
+
+func (e *ChangesetExecutor) insertAllSQL(dialect SQLDialect, chunk insertAllChunk, returnIDs bool) (int64, []interface{}, error) {
+	ctx := e.context()
+	repo := e.qb.repo
+	table := e.qb.schema.TableName()
+	primaryKey := primaryKeyNameOrDefaultIR(e.qb.schema)
+
+	if returnIDs {
+		if ids, ok := providedInsertAllIDs(chunk, primaryKey); ok {
+			query, args := buildInsertAllSQL(dialect, table, chunk, "")
+			res, err := repo.Exec(ctx, query, args...)
+			if err != nil {
+				return 0, nil, err
+			}
+			affected, _ := res.RowsAffected()
+			return affected, ids, nil
+		}
+	}
+
+	name := strings.ToLower(strings.TrimSpace(dialect.Name()))
+	if !returnIDs || name == "mysql" {
+		query, args := buildInsertAllSQL(dialect, table, chunk, "")
+		res, err := repo.Exec(ctx, query, args...)
+		if err != nil {
+			return 0, nil, err
+		}
+		affected, _ := res.RowsAffected()
+		if !returnIDs {
+			return affected, nil, nil
+		}
+		// MySQL 多行 INSERT 的 LastInsertId 为本批第一行的自增值，同批按 auto_increment_increment 步长分配
+		// （Galera / 多主复制常将步长设为节点数）。
+		first, err := res.LastInsertId()
+		if err != nil {
+			return affected, nil, fmt.Errorf("read last insert id: %w", err)
+		}
+		var step int64
+		if err := repo.QueryRow(ctx, "SELECT @@auto_increment_increment").Scan(&step); err != nil {
+			return affected, nil, fmt.Errorf("read auto_increment_increment: %w", err)
+		}
+		if step < 1 {
+			step = 1
+		}
+		ids := make([]interface{}, len(chunk.rows))
+		for i := range ids {
+			ids[i] = first + int64(i)*step
+		}
+		return affected, ids, nil
+	}
+	if name == "sqlserver" {
+		return e.insertAllSQLServerIDs(dialect, chunk, primaryKey)
+	}
+
+	if name == "sqlite" {
+		if version := resolveSQLDialectVersionIR(dialect); version != "" && compareVersion(version, "3.35.0") < 0 {
+			return 0, nil, fmt.Errorf("returning generated ids requires SQLite 3.35+ (RETURNING), got %s", version)
+		}
+	}
+	query, args := buildInsertAllSQL(dialect, table, chunk, primaryKey)
+	rows, err := repo.Query(ctx, query, args...)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+	ids := make([]interface{}, 0, len(chunk.rows))
+	for rows.Next() {
+		var id interface{}
+		if err := rows.Scan(&id); err != nil {
+			return 0, nil, err
+		}
+		if b, ok := id.([]byte); ok {
+			id = string(b)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	if err := sortGeneratedInsertAllIDs(ids, primaryKey); err != nil {
+		return int64(len(ids)), nil, err
+	}
+	return int64(len(ids)), ids, nil
+}
+
+// sortGeneratedInsertAllIDs 将 RETURNING 返回的主键按升序排列。
+// RETURNING 的输出顺序没有保证，但自增主键（serial / identity / rowid）按 VALUES 行序递增分配，
+// 升序即对应输入 Changeset 的顺序。
+func sortGeneratedInsertAllIDs(ids []interface{}, primaryKey string) error {
+	keys := make([]int64, len(ids))
+	for i, id := range ids {
+		n, ok := id.(int64)
+		if !ok {
+			return fmt.Errorf("cannot match generated %s values of type %T to changesets; set primary keys explicitly", primaryKey, id)
+		}
+		keys[i] = n
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	for i, n := range keys {
+		ids[i] = n
+	}
+	return nil
+}
+
+// buildSQLServerInsertAllSQL 以 MERGE 写入多行，OUTPUT 同时输出源行序号与生成的主键，
+// 不依赖 OUTPUT 的行序（SQL Server 不保证 OUTPUT INSERTED 的顺序）。
+func buildSQLServerInsertAllSQL(dialect SQLDialect, table string, chunk insertAllChunk, primaryKey string) (string, []interface{}) {
+	const ordinal = "__eit_row"
+	quotedColumns := make([]string, 0, len(chunk.columns))
+	sourceColumns := make([]string, 0, len(chunk.columns))
+	for _, column := range chunk.columns {
+		quotedColumns = append(quotedColumns, dialect.QuoteIdentifier(column))
+		sourceColumns = append(sourceColumns, "[source]."+dialect.QuoteIdentifier(column))
+	}
+	args := make([]interface{}, 0, len(chunk.rows)*len(chunk.columns))
+	tuples := make([]string, 0, len(chunk.rows))
+	argIndex := 1
+	for i, row := range chunk.rows {
+		values := make([]string, 0, len(chunk.columns)+1)
+		values = append(values, fmt.Sprintf("%d", i))
+		for _, column := range chunk.columns {
+			values = append(values, dialect.GetPlaceholder(argIndex))
+			args = append(args, row[column])
+			argIndex++
+		}
+		tuples = append(tuples, "("+strings.Join(values, ", ")+")")
+	}
+
+	var sql strings.Builder
+	sql.WriteString("MERGE INTO ")
+	sql.WriteString(dialect.QuoteIdentifier(table))
+	sql.WriteString(" AS [target] USING (VALUES ")
+	sql.WriteString(strings.Join(tuples, ", "))
+	sql.WriteString(") AS [source] (")
+	sql.WriteString(dialect.QuoteIdentifier(ordinal) + ", " + strings.Join(quotedColumns, ", "))
+	sql.WriteString(") ON 1 = 0 WHEN NOT MATCHED THEN INSERT (")
+	sql.WriteString(strings.Join(quotedColumns, ", "))
+	sql.WriteString(") VALUES (")
+	sql.WriteString(strings.Join(sourceColumns, ", "))
+	sql.WriteString(") OUTPUT [source].")
+	sql.WriteString(dialect.QuoteIdentifier(ordinal))
+	sql.WriteString(", INSERTED.")
+	sql.WriteString(dialect.QuoteIdentifier(primaryKey))
+	sql.WriteString(";")
+	return sql.String(), args
+}
+
+func (e *ChangesetExecutor) insertAllSQLServerIDs(dialect SQLDialect, chunk insertAllChunk, primaryKey string) (int64, []interface{}, error) {
+	query, args := buildSQLServerInsertAllSQL(dialect, e.qb.schema.TableName(), chunk, primaryKey)
+	rows, err := e.qb.repo.Query(e.context(), query, args...)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+	ids := make([]interface{}, len(chunk.rows))
+	var affected int64
+	for rows.Next() {
+		var (
+			ordinal int64
+			id      interface{}
+		)
+		if err := rows.Scan(&ordinal, &id); err != nil {
+			return 0, nil, err
+		}
+		if ordinal < 0 || ordinal >= int64(len(ids)) {
+			return 0, nil, fmt.Errorf("unexpected source row %d in merge output", ordinal)
+		}
+		if b, ok := id.([]byte); ok {
+			id = string(b)
+		}
+		ids[ordinal] = id
+		affected++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	return affected, ids, nil
+}
+
+func (e *ChangesetExecutor) insertAllMongo(adapter *MongoAdapter, chunk insertAllChunk) (int64, []interface{}, error) {
+	documents := make([]interface{}, 0, len(chunk.rows))
+	for _, row := range chunk.rows {
+		documents = append(documents, row)
+	}
+	ids, err := adapter.insertDocuments(e.context(), e.qb.schema.TableName(), documents)
+	if err != nil {
+		return 0, nil, err
+	}
+	return int64(len(ids)), ids, nil
+}
+
+// buildInsertAllCypher 构建 UNWIND 批量创建节点的 Cypher。
+func buildInsertAllCypher(label string, returnIDs bool) string {
+	cypher := "UNWIND $rows AS row CREATE (n:" + strings.TrimSpace(label) + ") SET n = row"
+	if returnIDs {
+		cypher += " RETURN elementId(n) AS id"
+	}
+	return cypher
+}
+
+func (e *ChangesetExecutor) insertAllNeo4j(adapter *Neo4jAdapter, chunk insertAllChunk, returnIDs bool) (int64, []interface{}, error) {
+	ctx := e.context()
+	label := e.qb.schema.TableName()
+	params := make([]interface{}, 0, len(chunk.rows))
+	for _, row := range chunk.rows {
+		params = append(params, row)
+	}
+
+	var providedIDs []interface{}
+	if returnIDs {
+		if ids, ok := providedInsertAllIDs(chunk, primaryKeyNameOrDefaultIR(e.qb.schema)); ok {
+			providedIDs, returnIDs = ids, false
+		}
+	}
+	if !returnIDs {
+		summary, err := adapter.ExecCypher(ctx, buildInsertAllCypher(label, false), map[string]interface{}{"rows": params})
+		if err != nil {
+			return 0, nil, err
+		}
+		return int64(summary.NodesCreated), providedIDs, nil
+	}
+
+	records, err := adapter.execCypherRows(ctx, buildInsertAllCypher(label, true), map[string]interface{}{"rows": params})
+	if err != nil {
+		return 0, nil, err
+	}
+	ids := make([]interface{}, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record["id"])
+	}
+	return int64(len(ids)), ids, nil
+}
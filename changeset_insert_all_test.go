@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func buildInsertAllChangesets(t *testing.T, schema Schema, from, to int) []*Changeset {
+	t.Helper()
+	changesets := make([]*Changeset, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		cs := NewChangeset(schema)
+		cs.Cast(map[string]interface{}{
+			"name":  fmt.Sprintf("user-%d", i),
+			"email": fmt.Sprintf("user-%d@example.com", i),
+		})
+		changesets = append(changesets, cs)
+	}
+	return changesets
+}
+
+func TestChangesetExecutorInsertAllSQLite(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	schema := buildUserSchemaForChangesetExecutor()
+	schema.GetField("id").Primary = true
+
+	executor, err := repo.NewChangesetExecutor(context.Background(), schema)
+	if err != nil {
+		t.Fatalf("failed to create changeset executor: %v", err)
+	}
+	result, err := executor.InsertAll(buildInsertAllChangesets(t, schema, 1, 25), InsertAllOptions{BatchSize: 10, ReturnIDs: true})
+	if err != nil {
+		t.Fatalf("insert all failed: %v", err)
+	}
+	if result.Chunks != 3 || result.RowsAffected != 25 || len(result.InsertedIDs) != 25 {
+		t.Fatalf("unexpected result: chunks=%d rows=%d ids=%d", result.Chunks, result.RowsAffected, len(result.InsertedIDs))
+	}
+	if result.InsertedIDs[0] != int64(1) || result.InsertedIDs[24] != int64(25) {
+		t.Fatalf("unexpected generated ids: %v", result.InsertedIDs)
+	}
+
+	var count int
+	if err := repo.QueryRow(context.Background(), "SELECT COUNT(*) FROM users").Scan(&count); err != nil || count != 25 {
+		t.Fatalf("expected 25 rows, got %d (%v)", count, err)
+	}
+
+	// 校验失败时不写入任何数据。
+	invalid := buildInsertAllChangesets(t, schema, 26, 28)
+	invalid[1] = NewChangeset(schema).Cast(map[string]interface{}{"id": 99}).ValidateRequired([]string{"name"})
+	if _, err := executor.InsertAll(invalid, InsertAllOptions{}); err == nil || !strings.Contains(err.Error(), "changeset 1 validation failed") {
+		t.Fatalf("expected validation error, got: %v", err)
+	}
+	if err := repo.QueryRow(context.Background(), "SELECT COUNT(*) FROM users").Scan(&count); err != nil || count != 25 {
+		t.Fatalf("validation failure must not write rows, got %d (%v)", count, err)
+	}
+	t.Logf("✓ InsertAll wrote %d rows in %d chunks", result.RowsAffected, result.Chunks)
+}
+
+func TestChangesetExecutorInsertAllChunkErrors(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	schema := buildUserSchemaForChangesetExecutor()
+
+	executor, err := repo.NewChangesetExecutor(context.Background(), schema)
+	if err != nil {
+		t.Fatalf("failed to create changeset executor: %v", err)
+	}
+	changesets := make([]*Changeset, 0, 6)
+	for _, id := range []int{1, 2, 2, 4, 5, 6} {
+		cs := NewChangeset(schema)
+		cs.Cast(map[string]interface{}{"id": id, "name": "n", "email": "e"})
+		changesets = append(changesets, cs)
+	}
+
+	result, err := executor.InsertAll(changesets, InsertAllOptions{BatchSize: 2, ContinueOnError: true, ReturnIDs: true})
+	var chunkErr *InsertAllChunkError
+	if err == nil || !errors.As(err, &chunkErr) {
+		t.Fatalf("expected chunk error, got: %v", err)
+	}
+	if len(result.Errors) != 1 || chunkErr.Chunk != 1 || chunkErr.Start != 2 || chunkErr.End != 4 {
+		t.Fatalf("unexpected chunk errors: %+v", result.Errors)
+	}
+	if result.Chunks != 3 || result.RowsAffected != 4 {
+		t.Fatalf("expected remaining chunks to continue: chunks=%d rows=%d", result.Chunks, result.RowsAffected)
+	}
+	if !reflect.DeepEqual(result.InsertedIDs, []interface{}{int64(1), int64(2), nil, nil, int64(5), int64(6)}) {
+		t.Fatalf("unexpected ids: %v", result.InsertedIDs)
+	}
+
+	stopped, err := executor.InsertAll(changesets[:4], InsertAllOptions{BatchSize: 1})
+	if err == nil || stopped.Chunks != 1 || len(stopped.Errors) != 1 {
+		t.Fatalf("expected to stop at first failed chunk, got %+v (%v)", stopped, err)
+	}
+}
+
+func TestInsertAllChunkingAndStatements(t *testing.T) {
+	rows := make([]map[string]interface{}, 0, 1500)
+	for i := 0; i < 1500; i++ {
+		rows = append(rows, map[string]interface{}{"a": i, "b": i, "c": i})
+	}
+	chunks := splitInsertAllChunks(rows, insertAllRowLimit(NewSQLServerDialect()))
+	sizes := make([]int, 0, len(chunks))
+	for _, chunk := range chunks {
+		sizes = append(sizes, len(chunk.rows))
+		if len(chunk.rows)*len(chunk.columns) >= sqlServerMaxBindParams {
+			t.Fatalf("sql server chunk exceeds parameter cap: %d rows", len(chunk.rows))
+		}
+	}
+	if !reflect.DeepEqual(sizes, []int{699, 699, 102}) {
+		t.Fatalf("unexpected sql server chunk sizes: %v", sizes)
+	}
+	if limit := insertAllRowLimit(NewSQLServerDialect())(1); limit != sqlServerMaxValuesRows {
+		t.Fatalf("expected sql server VALUES row cap, got %d", limit)
+	}
+	if limit := insertAllRowLimit(NewSQLiteDialectWithVersion("3.31.0"))(3); limit != 333 {
+		t.Fatalf("expected legacy sqlite limit 333, got %d", limit)
+	}
+
+	// 列集合不同的行拆分为不同批次。
+	mixed := splitInsertAllChunks([]map[string]interface{}{{"a": 1}, {"a": 2}, {"a": 3, "b": 3}}, func(int) int { return 10 })
+	if len(mixed) != 2 || mixed[1].start != 2 {
+		t.Fatalf("unexpected mixed chunks: %+v", mixed)
+	}
+
+	chunk := insertAllChunk{columns: []string{"email", "name"}, rows: []map[string]interface{}{{"email": "a", "name": "A"}, {"email": "b", "name": "B"}}}
+	sql, args := buildInsertAllSQL(NewPostgreSQLDialect(), "users", chunk, "id")
+	if sql != `INSERT INTO "users" ("email", "name") VALUES ($1, $2), ($3, $4) RETURNING "id"` || len(args) != 4 || args[2] != "b" {
+		t.Fatalf("unexpected postgres sql: %s %v", sql, args)
+	}
+	sql, _ = buildInsertAllSQL(NewSQLServerDialect(), "users", chunk, "id")
+	if sql != "INSERT INTO [users] ([email], [name]) OUTPUT INSERTED.[id] VALUES (@p1, @p2), (@p3, @p4)" {
+		t.Fatalf("unexpected sql server sql: %s", sql)
+	}
+	sql, args = buildSQLServerInsertAllSQL(NewSQLServerDialect(), "users", chunk, "id")
+	if sql != "MERGE INTO [users] AS [target] USING (VALUES (0, @p1, @p2), (1, @p3, @p4)) AS [source] ([__eit_row], [email], [name]) ON 1 = 0 WHEN NOT MATCHED THEN INSERT ([email], [name]) VALUES ([source].[email], [source].[name]) OUTPUT [source].[__eit_row], INSERTED.[id];" || len(args) != 4 {
+		t.Fatalf("unexpected sql server merge sql: %s %v", sql, args)
+	}
+
+	// RETURNING 行序不保证：按自增主键升序对应输入顺序，非整数主键无法对应。
+	ids := []interface{}{int64(12), int64(10), int64(11)}
+	if err := sortGeneratedInsertAllIDs(ids, "id"); err != nil || !reflect.DeepEqual(ids, []interface{}{int64(10), int64(11), int64(12)}) {
+		t.Fatalf("unexpected sorted ids: %v (%v)", ids, err)
+	}
+	if err := sortGeneratedInsertAllIDs([]interface{}{"a1b2"}, "id"); err == nil || !strings.Contains(err.Error(), "set primary keys explicitly") {
+		t.Fatalf("expected non-integer id error, got: %v", err)
+	}
+
+	sql, _ = buildInsertAllSQL(NewMySQLDialect(), "users", chunk, "")
+	if sql != "INSERT INTO `users` (`email`, `name`) VALUES (?, ?), (?, ?)" {
+		t.Fatalf("unexpected mysql sql: %s", sql)
+	}
+
+	if cypher := buildInsertAllCypher("User", true); cypher != "UNWIND $rows AS row CREATE (n:User) SET n = row RETURN elementId(n) AS id" {
+		t.Fatalf("unexpected cypher: %s", cypher)
+	}
+}
@@ -0,0 +1,282 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// returningMode 读取写入后整行的方式。
+type returningMode int
+
+const (
+	returningClause   returningMode = iota // RETURNING *（PostgreSQL、SQLite 3.35+）
+	returningOutput                        // OUTPUT INSERTED.*（SQL Server）
+	returningReadBack                      // 写入后按主键 SELECT（MySQL、SQLite < 3.35）
+)
+
+func resolveReturningMode(dialect SQLDialect) returningMode {
+	switch strings.ToLower(strings.TrimSpace(dialect.Name())) {
+	case "sqlserver":
+		return returningOutput
+	case "mysql":
+		return returningReadBack
+	case "sqlite":
+		if version := resolveSQLDialectVersionIR(dialect); version != "" && compareVersion(version, "3.35.0") < 0 {
+			return returningReadBack
+		}
+	}
+	return returningClause
+}
+
+// InsertReturning 插入 Changeset 并返回持久化后的整行（含数据库默认值与生成的主键）。
+// PostgreSQL / SQLite 3.35+ 使用 RETURNING *，SQL Server 使用 OUTPUT INSERTED.*，MySQL 按主键回读。
+// 返回的行同时合并到 cs.Data()。
+func (e *ChangesetExecutor) InsertReturning(cs *Changeset) (map[string]interface{}, error) {
+	if err := e.ready(); err != nil {
+		return nil, err
+	}
+	if cs == nil {
+		return nil, fmt.Errorf("changeset is nil")
+	}
+	if err := rejectAssocChanges(cs, "InsertReturning"); err != nil {
+		return nil, err
+	}
+	return e.insertReturningRow(cs)
+}
+
+func (e *ChangesetExecutor) insertReturningRow(cs *Changeset) (map[string]interface{}, error) {
+	if versionField := versionFieldOf(e.qb.schema); versionField != nil && cs.Get(versionField.Name) == nil {
+		cs.PutChange(versionField.Name, int64(1))
+	}
+	e.touchInsertTimestamps(cs)
+
+	var row map[string]interface{}
+	_, err := e.runHooked(ChangesetHookEvent{Operation: ChangesetOperationInsert, Changeset: cs}, func() (sql.Result, error) {
+		var err error
+		row, err = e.insertReturning(cs)
+		if err != nil {
+			return nil, err
+		}
+		return changesetWriteResult{rowsAffected: 1}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// UpdateReturning 按 ID 更新 Changeset 并返回更新后的整行，语义同 UpdateByID（含乐观锁）。
+// 未匹配记录时返回包装 sql.ErrNoRows 的错误（启用乐观锁时返回 *StaleEntryError）。
+func (e *ChangesetExecutor) UpdateReturning(id interface{}, cs *Changeset) (map[string]interface{}, error) {
+	if err := e.ready(); err != nil {
+		return nil, err
+	}
+	if cs == nil {
+		return nil, fmt.Errorf("changeset is nil")
+	}
+	if err := rejectAssocChanges(cs, "UpdateReturning"); err != nil {
+		return nil, err
+	}
+	e.touchUpdateTimestamp(cs)
+
+	var row map[string]interface{}
+	_, err := e.runHooked(ChangesetHookEvent{Operation: ChangesetOperationUpdate, Changeset: cs, ID: id}, func() (sql.Result, error) {
+		var err error
+		row, err = e.updateReturning(id, cs)
+		if err != nil {
+			return nil, err
+		}
+		return changesetWriteResult{rowsAffected: 1}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// InsertReturningInto 插入 Changeset 并将持久化后的行映射为 T。
+func InsertReturningInto[T any](e *ChangesetExecutor, cs *Changeset) (T, error) {
+	row, err := e.InsertReturning(cs)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return mapReturnedRow[T](row)
+}
+
+// UpdateReturningInto 按 ID 更新 Changeset 并将更新后的行映射为 T。
+func UpdateReturningInto[T any](e *ChangesetExecutor, id interface{}, cs *Changeset) (T, error) {
+	row, err := e.UpdateReturning(id, cs)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return mapReturnedRow[T](row)
+}
+
+func mapReturnedRow[T any](row map[string]interface{}) (T, error) {
+	var zero T
+	mapped, err := MapRows[T]([]map[string]interface{}{row})
+	if err != nil {
+		return zero, err
+	}
+	return mapped[0], nil
+}
+
+func (e *ChangesetExecutor) returningDialect() (SQLDialect, error) {
+	provider, ok := e.qb.repo.GetAdapter().GetQueryBuilderProvider().(*DefaultSQLQueryConstructorProvider)
+	if !ok || provider.dialect == nil {
+		return nil, fmt.Errorf("returning writes are only supported by SQL adapters")
+	}
+	return provider.dialect, nil
+}
+
+func (e *ChangesetExecutor) insertReturning(cs *Changeset) (map[string]interface{}, error) {
+	if !cs.IsValid() {
+		return nil, fmt.Errorf("changeset validation failed: %v", cs.Errors())
+	}
+	dialect, err := e.returningDialect()
+	if err != nil {
+		return nil, err
+	}
+	cs.ForceChanges()
+	changes := cs.Changes()
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("no fields to insert")
+	}
+	columns := make([]string, 0, len(changes))
+	for column := range changes {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	ctx := e.context()
+	table := e.qb.schema.TableName()
+	chunk := insertAllChunk{columns: columns, rows: []map[string]interface{}{changes}}
+	var row map[string]interface{}
+	if resolveReturningMode(dialect) == returningReadBack {
+		query, args := buildInsertAllSQL(dialect, table, chunk, "")
+		res, err := e.qb.repo.Exec(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		primaryKey := primaryKeyNameOrDefaultIR(e.qb.schema)
+		id, ok := changes[primaryKey]
+		if !ok || id == nil {
+			if id, err = res.LastInsertId(); err != nil {
+				return nil, fmt.Errorf("read last insert id: %w", err)
+			}
+		}
+		row, err = e.readBackByPrimaryKey(dialect, id)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		query, args := buildInsertAllSQL(dialect, table, chunk, "*")
+		if row, err = e.queryReturnedRow(query, args); err != nil {
+			return nil, err
+		}
+	}
+	cs.mergeData(row)
+	return row, nil
+}
+
+func (e *ChangesetExecutor) updateReturning(id interface{}, cs *Changeset) (map[string]interface{}, error) {
+	if !cs.IsValid() {
+		return nil, fmt.Errorf("changeset validation failed: %v", cs.Errors())
+	}
+	dialect, err := e.returningDialect()
+	if err != nil {
+		return nil, err
+	}
+	changes := cs.Changes()
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("no fields to update")
+	}
+
+	primaryKey := primaryKeyNameOrDefaultIR(e.qb.schema)
+	condition := Eq(primaryKey, id)
+	var stale error
+	if versionField := versionFieldOf(e.qb.schema); versionField != nil {
+		current := currentLockVersion(cs, versionField.Name)
+		next, err := nextLockVersion(current)
+		if err != nil {
+			return nil, err
+		}
+		changes[versionField.Name] = next
+		condition = And(condition, Eq(versionField.Name, current))
+		stale = &StaleEntryError{Table: e.qb.schema.TableName(), ID: id, VersionField: versionField.Name, Version: current}
+	}
+	notFound := stale
+	if notFound == nil {
+		notFound = fmt.Errorf("update returning: no row matched %s = %v: %w", primaryKey, id, sql.ErrNoRows)
+	}
+
+	constructor := NewSQLQueryConstructor(e.qb.schema, dialect)
+	constructor.Where(condition)
+	var row map[string]interface{}
+	if resolveReturningMode(dialect) == returningReadBack {
+		query, args, err := constructor.buildUpdateSQL(e.context(), changes)
+		if err != nil {
+			return nil, err
+		}
+		res, err := e.qb.repo.Exec(e.context(), query, args...)
+		if err != nil {
+			return nil, err
+		}
+		// MySQL 对未实际修改的行报告 0，仅在版本字段必然变化时据此判定冲突。
+		if affected, err := res.RowsAffected(); err == nil && affected == 0 && stale != nil {
+			return nil, stale
+		}
+		if row, err = e.readBackByPrimaryKey(dialect, id); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, notFound
+			}
+			return nil, err
+		}
+	} else {
+		query, args, err := constructor.buildUpdateReturningSQL(e.context(), changes)
+		if err != nil {
+			return nil, err
+		}
+		if row, err = e.queryReturnedRow(query, args); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, notFound
+			}
+			return nil, err
+		}
+	}
+	cs.mergeData(row)
+	return row, nil
+}
+
+// queryReturnedRow 执行带 RETURNING / OUTPUT 的写入并读取第一行；无返回行时返回 sql.ErrNoRows。
+func (e *ChangesetExecutor) queryReturnedRow(query string, args []interface{}) (map[string]interface{}, error) {
+	rows, err := e.qb.repo.Query(e.context(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, sql.ErrNoRows
+	}
+	return scanCurrentRowToMap(rows, cols)
+}
+
+func (e *ChangesetExecutor) readBackByPrimaryKey(dialect SQLDialect, id interface{}) (map[string]interface{}, error) {
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s = %s",
+		dialect.QuoteIdentifier(e.qb.schema.TableName()),
+		dialect.QuoteIdentifier(primaryKeyNameOrDefaultIR(e.qb.schema)),
+		dialect.GetPlaceholder(1),
+	)
+	return e.queryReturnedRow(query, []interface{}{id})
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+type returnedArticle struct {
+	ID     int64  `eit_db:"id"`
+	Title  string `eit_db:"title"`
+	Status string `eit_db:"status"`
+}
+
+func createReturningTestRepo(t *testing.T) (*Repository, *BaseSchema) {
+	t.Helper()
+	repo := createChangesetExecutorTestRepo(t)
+	_, err := repo.Exec(context.Background(), `
+		CREATE TABLE articles (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			title TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'draft'
+		)
+	`)
+	if err != nil {
+		repo.Close()
+		t.Fatalf("failed to create table: %v", err)
+	}
+	schema := NewBaseSchema("articles")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("title", TypeString).Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	return repo, schema
+}
+
+func TestChangesetExecutorReturningSQLite(t *testing.T) {
+	repo, schema := createReturningTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+
+	err := repo.WithChangeset(ctx, schema, func(e *ChangesetExecutor) error {
+		cs := NewChangeset(schema).Cast(map[string]interface{}{"title": "hello"})
+		row, err := e.InsertReturning(cs)
+		if err != nil {
+			return err
+		}
+		if row["id"] != int64(1) || row["status"] != "draft" {
+			t.Fatalf("expected generated id and default status, got %v", row)
+		}
+		if data := cs.Data(); data["id"] != int64(1) || data["status"] != "draft" {
+			t.Fatalf("expected returned row merged into changeset data, got %v", data)
+		}
+
+		updated, err := e.UpdateReturning(1, NewChangeset(schema).Cast(map[string]interface{}{"status": "published"}))
+		if err != nil {
+			return err
+		}
+		if updated["title"] != "hello" || updated["status"] != "published" {
+			t.Fatalf("unexpected updated row: %v", updated)
+		}
+
+		article, err := InsertReturningInto[returnedArticle](e, NewChangeset(schema).Cast(map[string]interface{}{"title": "second"}))
+		if err != nil {
+			return err
+		}
+		if article.ID != 2 || article.Status != "draft" {
+			t.Fatalf("unexpected typed row: %+v", article)
+		}
+
+		_, err = e.UpdateReturning(99, NewChangeset(schema).Cast(map[string]interface{}{"title": "x"}))
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected sql.ErrNoRows for missing row, got: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("with changeset failed: %v", err)
+	}
+}
+
+func TestReturningSQLByDialect(t *testing.T) {
+	schema := NewBaseSchema("articles")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("title", TypeString).Build())
+	changes := map[string]interface{}{"title": "t"}
+
+	pg := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	pg.Where(Eq("id", 1))
+	if sql, _, err := pg.buildUpdateReturningSQL(context.Background(), changes); err != nil || sql != `UPDATE "articles" SET "title" = $1 WHERE "articles"."id" = $2 RETURNING *` {
+		t.Fatalf("unexpected postgres update returning sql: %s (%v)", sql, err)
+	}
+	mssql := NewSQLQueryConstructor(schema, NewSQLServerDialect())
+	mssql.Where(Eq("id", 1))
+	if sql, _, err := mssql.buildUpdateReturningSQL(context.Background(), changes); err != nil || sql != "UPDATE [articles] SET [title] = @p1 OUTPUT INSERTED.* WHERE [articles].[id] = @p2" {
+		t.Fatalf("unexpected sql server update returning sql: %s (%v)", sql, err)
+	}
+
+	chunk := insertAllChunk{columns: []string{"title"}, rows: []map[string]interface{}{changes}}
+	if sql, _ := buildInsertAllSQL(NewSQLServerDialect(), "articles", chunk, "*"); sql != "INSERT INTO [articles] ([title]) OUTPUT INSERTED.* VALUES (@p1)" {
+		t.Fatalf("unexpected sql server insert returning sql: %s", sql)
+	}
+
+	if resolveReturningMode(NewMySQLDialect()) != returningReadBack ||
+		resolveReturningMode(NewSQLiteDialectWithVersion("3.34.1")) != returningReadBack ||
+		resolveReturningMode(NewSQLiteDialectWithVersion("3.45.0")) != returningClause ||
+		resolveReturningMode(NewSQLServerDialect()) != returningOutput {
+		t.Fatalf("unexpected returning mode resolution")
+	}
+}
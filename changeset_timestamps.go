@@ -0,0 +1,128 @@
+package db
+
+import (
+	"strings"
+	"time"
+)
+
+// 自动时间戳字段名（与 BaseSchema.AddTimestamps 一致）。
+const (
+	createdAtField = "created_at"
+	updatedAtField = "updated_at"
+)
+
+// TimestampOptions 自动时间戳配置。
+type TimestampOptions struct {
+	// Clock 时钟，为空时使用 time.Now；测试中可注入固定时钟。
+	Clock func() time.Time
+	// Location 写入时区，为空时使用 UTC；传入 time.Local 使用本地时区。
+	Location *time.Location
+}
+
+// SetTimestampOptions 设置 ChangesetExecutor 维护 created_at / updated_at 使用的时钟与时区。
+func (r *Repository) SetTimestampOptions(opts TimestampOptions) {
+	r.mu.Lock()
+	r.timestampOptions = opts
+	r.mu.Unlock()
+}
+
+// TimestampOptions 返回当前的自动时间戳配置。
+func (r *Repository) TimestampOptions() TimestampOptions {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.timestampOptions
+}
+
+// timestampNow 按 TimestampOptions 取当前时间；事务包装的 Repository 沿用开启事务的 Repository 的配置。
+func (r *Repository) timestampNow() time.Time {
+	var opts TimestampOptions
+	if r != nil {
+		if ta, ok := r.GetAdapter().(*txAdapter); ok && ta.owner != nil {
+			return ta.owner.timestampNow()
+		}
+		opts = r.TimestampOptions()
+	}
+	clock := opts.Clock
+	if clock == nil {
+		clock = time.Now
+	}
+	location := opts.Location
+	if location == nil {
+		location = time.UTC
+	}
+	return clock().In(location)
+}
+
+// timestampPrecision 返回目标存储的时间精度，写入前按该精度截断，保证读回的值与 Changeset 一致。
+func timestampPrecision(adapter Adapter) time.Duration {
+	if adapter == nil {
+		return time.Nanosecond
+	}
+	switch provider := adapter.GetQueryBuilderProvider().(type) {
+	case *DefaultSQLQueryConstructorProvider:
+		if provider.dialect == nil {
+			break
+		}
+		switch strings.ToLower(strings.TrimSpace(provider.dialect.Name())) {
+		case "sqlserver":
+			return 100 * time.Nanosecond // DATETIME2(7)
+		case "mysql":
+			return time.Second // DATETIME 默认无小数秒，截断以避免服务端四舍五入进位
+		default:
+			return time.Microsecond // PostgreSQL TIMESTAMP / TIMESTAMPTZ、SQLite
+		}
+	case *MongoQueryConstructorProvider:
+		return time.Millisecond // BSON DateTime
+	}
+	return time.Nanosecond
+}
+
+func hasAutoTimestamp(schema Schema, name string) bool {
+	if schema == nil {
+		return false
+	}
+	field := schema.GetField(name)
+	return field != nil && field.Type == TypeTime
+}
+
+func (e *ChangesetExecutor) timestampNow() time.Time {
+	return e.owner.timestampNow().Truncate(timestampPrecision(e.qb.repo.GetAdapter()))
+}
+
+// touchInsertTimestamps 插入前填充未赋值的 created_at / updated_at。
+func (e *ChangesetExecutor) touchInsertTimestamps(cs *Changeset) {
+	if cs == nil {
+		return
+	}
+	e.touchInsertTimestampsAt(cs, e.timestampNow())
+}
+
+func (e *ChangesetExecutor) touchInsertTimestampsAt(cs *Changeset, now time.Time) {
+	for _, name := range []string{createdAtField, updatedAtField} {
+		if hasAutoTimestamp(e.qb.schema, name) && cs.Get(name) == nil {
+			cs.PutChange(name, now)
+		}
+	}
+}
+
+// touchUpdateTimestamp 更新前刷新 updated_at（无其他变更或已显式修改时保持不变）。
+func (e *ChangesetExecutor) touchUpdateTimestamp(cs *Changeset) {
+	if cs == nil || !hasAutoTimestamp(e.qb.schema, updatedAtField) || cs.HasChanged(updatedAtField) || len(cs.Changes()) == 0 {
+		return
+	}
+	cs.PutChange(updatedAtField, e.timestampNow())
+}
+
+// excludeInsertOnlyColumns upsert 冲突更新时保留原 created_at。
+func excludeInsertOnlyColumns(schema Schema, columns []string) []string {
+	if !hasAutoTimestamp(schema, createdAtField) {
+		return columns
+	}
+	result := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if col != createdAtField {
+			result = append(result, col)
+		}
+	}
+	return result
+}
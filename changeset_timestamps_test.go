@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestChangesetExecutorTimestampsSQLite(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+
+	if _, err := repo.Exec(ctx, "CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT NOT NULL, created_at DATETIME NOT NULL, updated_at DATETIME NOT NULL)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	schema := NewBaseSchema("posts")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("title", TypeString).Build())
+	schema.AddTimestamps()
+
+	zone := time.FixedZone("UTC+8", 8*3600)
+	now := time.Date(2026, 3, 1, 10, 0, 0, 123456789, time.UTC)
+	repo.SetTimestampOptions(TimestampOptions{Clock: func() time.Time { return now }, Location: zone})
+	created := time.Date(2026, 3, 1, 18, 0, 0, 123456000, zone)
+
+	readBack := func(id int) (time.Time, time.Time) {
+		var createdAt, updatedAt time.Time
+		if err := repo.QueryRow(ctx, "SELECT created_at, updated_at FROM posts WHERE id = ?", id).Scan(&createdAt, &updatedAt); err != nil {
+			t.Fatalf("read back failed: %v", err)
+		}
+		return createdAt, updatedAt
+	}
+
+	executor, err := repo.NewChangesetExecutor(ctx, schema)
+	if err != nil {
+		t.Fatalf("new executor failed: %v", err)
+	}
+	cs := NewChangeset(schema).Cast(map[string]interface{}{"id": 1, "title": "hello"})
+	if _, err := executor.Insert(cs); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if got := cs.Get("created_at").(time.Time); !got.Equal(created) || got.Location() != zone {
+		t.Fatalf("expected created_at truncated to microseconds in configured zone, got %v", got)
+	}
+	if createdAt, updatedAt := readBack(1); !createdAt.Equal(created) || !updatedAt.Equal(created) {
+		t.Fatalf("unexpected stored timestamps: %v %v", createdAt, updatedAt)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := executor.UpdateByID(1, NewChangeset(schema).Cast(map[string]interface{}{"title": "edited"})); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if createdAt, updatedAt := readBack(1); !createdAt.Equal(created) || !updatedAt.Equal(created.Add(time.Hour)) {
+		t.Fatalf("expected only updated_at bumped, got %v %v", createdAt, updatedAt)
+	}
+
+	// 显式赋值的时间戳不被覆盖。
+	explicit := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := executor.Insert(NewChangeset(schema).Cast(map[string]interface{}{"id": 2, "title": "old", "created_at": explicit})); err != nil {
+		t.Fatalf("insert with explicit created_at failed: %v", err)
+	}
+	if createdAt, _ := readBack(2); !createdAt.Equal(explicit) {
+		t.Fatalf("expected explicit created_at kept, got %v", createdAt)
+	}
+
+	now = now.Add(time.Hour)
+	err = repo.WithChangeset(ctx, schema, func(e *ChangesetExecutor) error {
+		_, err := e.Upsert(NewChangeset(schema).Cast(map[string]interface{}{"id": 1, "title": "upserted"}), "id")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("upsert failed: %v", err)
+	}
+	if createdAt, updatedAt := readBack(1); !createdAt.Equal(created) || !updatedAt.Equal(created.Add(2*time.Hour)) {
+		t.Fatalf("expected upsert to keep created_at and bump updated_at, got %v %v", createdAt, updatedAt)
+	}
+	t.Logf("✓ automatic timestamps maintained")
+}
+
+func TestSoftDeleteWhereUsesTimestampClock(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+
+	if _, err := repo.Exec(ctx, "CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT, deleted_at DATETIME NULL)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	if _, err := repo.Exec(ctx, "INSERT INTO notes (id, body) VALUES (1, 'a'), (2, 'b')"); err != nil {
+		t.Fatalf("seed failed: %v", err)
+	}
+	schema := NewBaseSchema("notes")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("body", TypeString).Build())
+	schema.AddSoftDelete()
+
+	now := time.Date(2026, 5, 1, 8, 30, 0, 987654321, time.FixedZone("UTC-5", -5*3600))
+	repo.SetTimestampOptions(TimestampOptions{Clock: func() time.Time { return now }})
+
+	// 事务内的执行器同样使用 Repository 配置的时钟与 UTC 默认时区
+	err := repo.WithChangeset(ctx, schema, func(e *ChangesetExecutor) error {
+		_, err := e.SoftDeleteWhere(Eq("id", 1))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("soft delete failed: %v", err)
+	}
+	var deletedAt time.Time
+	if err := repo.QueryRow(ctx, "SELECT deleted_at FROM notes WHERE id = 1").Scan(&deletedAt); err != nil {
+		t.Fatalf("read back failed: %v", err)
+	}
+	if want := now.UTC().Truncate(time.Microsecond); !deletedAt.Equal(want) {
+		t.Fatalf("expected deleted_at %v from injected clock, got %v", want, deletedAt)
+	}
+
+	if col := buildPostgresColumn(schema.AddTimestamps().GetField("created_at"), nil); col != `"created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP` {
+		t.Fatalf("expected TIMESTAMPTZ for AddTimestamps field, got %s", col)
+	}
+	if col := buildPostgresColumn(schema.GetField("deleted_at"), nil); col != `"deleted_at" TIMESTAMP` {
+		t.Fatalf("expected plain TIMESTAMP for other time fields, got %s", col)
+	}
+	t.Logf("✓ soft delete timestamps follow repository clock")
+}
+
+func TestTimestampPrecisionByAdapter(t *testing.T) {
+	cases := []struct {
+		provider QueryConstructorProvider
+		want     time.Duration
+	}{
+		{NewDefaultSQLQueryConstructorProvider(NewSQLServerDialect()), 100 * time.Nanosecond},
+		{NewDefaultSQLQueryConstructorProvider(NewPostgreSQLDialect()), time.Microsecond},
+		{NewDefaultSQLQueryConstructorProvider(NewMySQLDialect()), time.Second},
+		{NewMongoQueryConstructorProvider(), time.Millisecond},
+	}
+	for _, tc := range cases {
+		if got := timestampPrecision(&txAdapter{provider: tc.provider}); got != tc.want {
+			t.Fatalf("unexpected precision for %T: got %v, want %v", tc.provider, got, tc.want)
+		}
+	}
+
+	schema := NewBaseSchema("posts")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("title", TypeString).Build())
+	schema.AddTimestamps()
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	sql, _, err := qc.buildNativeUpsertSQL("postgresql", []string{"created_at", "id", "title", "updated_at"}, map[string]interface{}{}, []string{"id"})
+	if err != nil {
+		t.Fatalf("build upsert failed: %v", err)
+	}
+	want := `INSERT INTO "posts" ("created_at", "id", "title", "updated_at") VALUES ($1, $2, $3, $4) ON CONFLICT ("id") DO UPDATE SET "title" = excluded."title", "updated_at" = excluded."updated_at"`
+	if sql != want {
+		t.Fatalf("unexpected upsert sql:\n got: %s\nwant: %s", sql, want)
+	}
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	sqlite3 "github.com/mattn/go-sqlite3"
+	mssql "github.com/microsoft/go-mssqldb"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// constraintViolation 从驱动错误中解析出的约束冲突。
+// name 为数据库报告的约束 / 索引名，fields 为报告的列名；驱动未提供时为空。
+type constraintViolation struct {
+	kind   ConstraintKind
+	name   string
+	fields []string
+}
+
+var (
+	pgConstraintNamePattern     = regexp.MustCompile(`constraint "([^"]+)"`)
+	pgDetailKeyPattern          = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+	mysqlDuplicateKeyPattern    = regexp.MustCompile(`for key '([^']+)'`)
+	mysqlForeignKeyPattern      = regexp.MustCompile("CONSTRAINT `([^`]+)`")
+	mysqlCheckPattern           = regexp.MustCompile(`[Cc]heck constraint '([^']+)'`)
+	sqliteConstraintPattern     = regexp.MustCompile(`constraint failed: (.+)$`)
+	sqlServerUniquePattern      = regexp.MustCompile(`(?:constraint|unique index) '([^']+)'`)
+	sqlServerConflictPattern    = regexp.MustCompile(`constraint "([^"]+)"`)
+	mongoDuplicateIndexPattern  = regexp.MustCompile(`index: (\S+)`)
+	mongoDuplicateKeyPattern    = regexp.MustCompile(`dup key: \{(.*)\}`)
+	mongoDuplicateFieldsPattern = regexp.MustCompile(`(?:^|,)\s*([A-Za-z0-9_.$]+):`)
+)
+
+// parseConstraintViolation 识别 PostgreSQL SQLSTATE、MySQL 错误号、SQLite 扩展错误码、
+// SQL Server 错误号与 MongoDB E11000 重复键错误；非约束冲突返回 false。
+func parseConstraintViolation(err error) (*constraintViolation, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		v := &constraintViolation{kind: pgConstraintKind(string(pqErr.Code)), name: pqErr.Constraint}
+		if pqErr.Column != "" {
+			v.fields = []string{pqErr.Column}
+		} else if m := pgDetailKeyPattern.FindStringSubmatch(pqErr.Detail); m != nil {
+			v.fields = splitConstraintColumns(m[1], ",")
+		}
+		return v, v.kind != ""
+	}
+
+	// pgx（GORM PostgreSQL 驱动）错误仅通过 SQLState 与消息暴露。
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		v := &constraintViolation{kind: pgConstraintKind(stateErr.SQLState())}
+		if m := pgConstraintNamePattern.FindStringSubmatch(err.Error()); m != nil {
+			v.name = m[1]
+		}
+		return v, v.kind != ""
+	}
+
+	var mysqlErr *mysqldriver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1062:
+			return &constraintViolation{kind: ConstraintUnique, name: submatch(mysqlDuplicateKeyPattern, mysqlErr.Message)}, true
+		case 1216, 1217, 1451, 1452:
+			return &constraintViolation{kind: ConstraintForeignKey, name: submatch(mysqlForeignKeyPattern, mysqlErr.Message)}, true
+		case 3819, 4025:
+			name := submatch(mysqlCheckPattern, mysqlErr.Message)
+			if name == "" {
+				name = submatch(mysqlForeignKeyPattern, mysqlErr.Message)
+			}
+			return &constraintViolation{kind: ConstraintCheck, name: name}, true
+		}
+		return nil, false
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		detail := submatch(sqliteConstraintPattern, sqliteErr.Error())
+		switch sqliteErr.ExtendedCode {
+		case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+			return &constraintViolation{kind: ConstraintUnique, fields: splitConstraintColumns(detail, ",")}, true
+		case sqlite3.ErrConstraintForeignKey:
+			return &constraintViolation{kind: ConstraintForeignKey}, true
+		case sqlite3.ErrConstraintCheck:
+			return &constraintViolation{kind: ConstraintCheck, name: detail}, true
+		}
+		return nil, false
+	}
+
+	var mssqlErr mssql.Error
+	if errors.As(err, &mssqlErr) {
+		switch mssqlErr.Number {
+		case 2601, 2627:
+			return &constraintViolation{kind: ConstraintUnique, name: submatch(sqlServerUniquePattern, mssqlErr.Message)}, true
+		case 547:
+			name := submatch(sqlServerConflictPattern, mssqlErr.Message)
+			if strings.Contains(mssqlErr.Message, "CHECK constraint") {
+				return &constraintViolation{kind: ConstraintCheck, name: name}, true
+			}
+			return &constraintViolation{kind: ConstraintForeignKey, name: name}, true
+		}
+		return nil, false
+	}
+
+	if mongo.IsDuplicateKeyError(err) {
+		message := err.Error()
+		v := &constraintViolation{kind: ConstraintUnique, name: submatch(mongoDuplicateIndexPattern, message)}
+		if key := submatch(mongoDuplicateKeyPattern, message); key != "" {
+			for _, m := range mongoDuplicateFieldsPattern.FindAllStringSubmatch(key, -1) {
+				v.fields = append(v.fields, m[1])
+			}
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+func pgConstraintKind(code string) ConstraintKind {
+	switch code {
+	case "23505":
+		return ConstraintUnique
+	case "23503":
+		return ConstraintForeignKey
+	case "23514":
+		return ConstraintCheck
+	}
+	return ""
+}
+
+func submatch(pattern *regexp.Regexp, s string) string {
+	if m := pattern.FindStringSubmatch(s); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return ""
+}
+
+// splitConstraintColumns 拆分驱动报告的列名并去除 "table." 前缀。
+func splitConstraintColumns(raw, sep string) []string {
+	result := make([]string, 0)
+	for _, col := range strings.Split(raw, sep) {
+		col = strings.Trim(strings.TrimSpace(col), `"`+"`")
+		if idx := strings.LastIndex(col, "."); idx >= 0 {
+			col = col[idx+1:]
+		}
+		if col != "" {
+			result = append(result, col)
+		}
+	}
+	return result
+}
@@ -151,6 +151,30 @@ func (t *gormTx) Rollback(ctx context.Context) error {
 	return t.tx.Rollback().Error
 }
 
+func (t *gormTx) Savepoint(ctx context.Context, name string) error {
+	if err := validateSavepointName(name); err != nil {
+		return err
+	}
+	return t.tx.SavePoint(name).Error
+}
+
+func (t *gormTx) RollbackTo(ctx context.Context, name string) error {
+	if err := validateSavepointName(name); err != nil {
+		return err
+	}
+	return t.tx.RollbackTo(name).Error
+}
+
+func (t *gormTx) ReleaseSavepoint(ctx context.Context, name string) error {
+	if err := validateSavepointName(name); err != nil {
+		return err
+	}
+	if t.tx.Dialector.Name() == "sqlserver" {
+		return nil
+	}
+	return t.tx.Exec("RELEASE SAVEPOINT " + name).Error
+}
+
 func (t *gormTx) Exec(ctx context.Context, sql string, args ...interface{}) (sql.Result, error) {
 	result := t.tx.Exec(sql, args...)
 	if result.Error != nil {
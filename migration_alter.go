@@ -0,0 +1,692 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AlterTableOperation 表结构变更操作（SchemaMigration.AlterTable 登记的迁移 IR）。
+// 每个操作都携带变更前后的完整定义，Down 由 Inverse 自动推导。
+type AlterTableOperation struct {
+	Kind  MigrationOperationKind
+	Table string
+	// Column 新增 / 删除的列，或类型变更后的列定义（删除时用于 Down 重建该列）。
+	Column *Field
+	// From 类型变更前的列定义（alter_column_type）。
+	From *Field
+	// OldName / NewName 列重命名（rename_column）。
+	OldName string
+	NewName string
+	// Using 类型变更的数据转换表达式：PostgreSQL 用于 USING，SQLite 重建时用于数据拷贝；DownUsing 供 Down 使用。
+	Using     string
+	DownUsing string
+	// Index 索引定义（add_index / drop_index）。
+	Index *TableIndex
+	// Constraint 表级约束（add_constraint / drop_constraint），支持主键、唯一与外键。
+	Constraint *TableConstraint
+}
+
+// Inverse 返回撤销该操作的逆操作。
+func (op AlterTableOperation) Inverse() AlterTableOperation {
+	inverse := op
+	switch op.Kind {
+	case MigrationOpAddColumn:
+		inverse.Kind = MigrationOpDropColumn
+	case MigrationOpDropColumn:
+		inverse.Kind = MigrationOpAddColumn
+	case MigrationOpRenameColumn:
+		inverse.OldName, inverse.NewName = op.NewName, op.OldName
+	case MigrationOpAlterColumnType:
+		inverse.From, inverse.Column = op.Column, op.From
+		inverse.Using, inverse.DownUsing = op.DownUsing, op.Using
+	case MigrationOpAddIndex:
+		inverse.Kind = MigrationOpDropIndex
+	case MigrationOpDropIndex:
+		inverse.Kind = MigrationOpAddIndex
+	case MigrationOpAddConstraint:
+		inverse.Kind = MigrationOpDropConstraint
+	case MigrationOpDropConstraint:
+		inverse.Kind = MigrationOpAddConstraint
+	}
+	return inverse
+}
+
+func (op AlterTableOperation) validate() error {
+	if strings.TrimSpace(op.Table) == "" {
+		return fmt.Errorf("alter table operation %s: table name is required", op.Kind)
+	}
+	switch op.Kind {
+	case MigrationOpAddColumn, MigrationOpDropColumn:
+		if op.Column == nil || strings.TrimSpace(op.Column.Name) == "" {
+			return fmt.Errorf("alter table %s: %s requires a column definition", op.Table, op.Kind)
+		}
+	case MigrationOpRenameColumn:
+		if strings.TrimSpace(op.OldName) == "" || strings.TrimSpace(op.NewName) == "" {
+			return fmt.Errorf("alter table %s: rename_column requires old and new names", op.Table)
+		}
+	case MigrationOpAlterColumnType:
+		if op.From == nil || op.Column == nil {
+			return fmt.Errorf("alter table %s: alter_column_type requires both old and new column definitions", op.Table)
+		}
+		if op.From.Name != op.Column.Name {
+			return fmt.Errorf("alter table %s: alter_column_type cannot rename column %s to %s, use RenameColumn", op.Table, op.From.Name, op.Column.Name)
+		}
+	case MigrationOpAddIndex, MigrationOpDropIndex:
+		if op.Index == nil || strings.TrimSpace(op.Index.Name) == "" || len(op.Index.Fields) == 0 {
+			return fmt.Errorf("alter table %s: %s requires index name and fields", op.Table, op.Kind)
+		}
+	case MigrationOpAddConstraint, MigrationOpDropConstraint:
+		c := op.Constraint
+		if c == nil || strings.TrimSpace(c.Name) == "" || len(c.Fields) == 0 {
+			return fmt.Errorf("alter table %s: %s requires constraint name and fields", op.Table, op.Kind)
+		}
+		switch c.Kind {
+		case ConstraintPrimaryKey, ConstraintUnique:
+		case ConstraintForeignKey:
+			if c.RefTable == "" || len(c.RefFields) != len(c.Fields) {
+				return fmt.Errorf("alter table %s: foreign key %s requires referenced table and matching fields", op.Table, c.Name)
+			}
+		default:
+			return fmt.Errorf("alter table %s: unsupported constraint kind %s", op.Table, c.Kind)
+		}
+	default:
+		return fmt.Errorf("unsupported alter table operation kind: %s", op.Kind)
+	}
+	return nil
+}
+
+// AlterTableBuilder 单表结构变更构建器，由 SchemaMigration.AlterTable 创建。
+type AlterTableBuilder struct {
+	migration *SchemaMigration
+	table     string
+}
+
+// AlterTable 登记对已有表的结构变更；操作在 Up 中按登记顺序执行，Down 逆序执行各自的逆操作。
+// PostgreSQL / SQL Server 上全部变更在同一事务内执行；MySQL / SQLite 中途失败时已完成的变更不会回滚。
+func (m *SchemaMigration) AlterTable(table string) *AlterTableBuilder {
+	return &AlterTableBuilder{migration: m, table: table}
+}
+
+func (b *AlterTableBuilder) add(op AlterTableOperation) *AlterTableBuilder {
+	op.Table = b.table
+	b.migration.alterOps = append(b.migration.alterOps, op)
+	return b
+}
+
+// AddColumn 新增列。
+func (b *AlterTableBuilder) AddColumn(field *Field) *AlterTableBuilder {
+	return b.add(AlterTableOperation{Kind: MigrationOpAddColumn, Column: field})
+}
+
+// DropColumn 删除列；field 为删除前的完整列定义，Down 据此重建该列。
+func (b *AlterTableBuilder) DropColumn(field *Field) *AlterTableBuilder {
+	return b.add(AlterTableOperation{Kind: MigrationOpDropColumn, Column: field})
+}
+
+// RenameColumn 重命名列。
+func (b *AlterTableBuilder) RenameColumn(oldName, newName string) *AlterTableBuilder {
+	return b.add(AlterTableOperation{Kind: MigrationOpRenameColumn, OldName: oldName, NewName: newName})
+}
+
+// AlterColumnType 变更列类型、可空与默认值；from 为变更前定义，Down 据此还原。
+// using 可选：第一个为 Up 的转换表达式，第二个为 Down 的转换表达式。
+// 唯一与主键不随列定义变更，请使用 AddConstraint / DropConstraint。
+func (b *AlterTableBuilder) AlterColumnType(from, to *Field, using ...string) *AlterTableBuilder {
+	op := AlterTableOperation{Kind: MigrationOpAlterColumnType, From: from, Column: to}
+	if len(using) > 0 {
+		op.Using = using[0]
+	}
+	if len(using) > 1 {
+		op.DownUsing = using[1]
+	}
+	return b.add(op)
+}
+
+// AddIndex 创建索引；name 为空时按 idx_<表>_<列> 生成。
+func (b *AlterTableBuilder) AddIndex(name string, unique bool, fields ...string) *AlterTableBuilder {
+	return b.add(AlterTableOperation{Kind: MigrationOpAddIndex, Index: b.index(name, unique, fields)})
+}
+
+// DropIndex 删除索引；需提供原索引定义，Down 据此重建。
+func (b *AlterTableBuilder) DropIndex(name string, unique bool, fields ...string) *AlterTableBuilder {
+	return b.add(AlterTableOperation{Kind: MigrationOpDropIndex, Index: b.index(name, unique, fields)})
+}
+
+func (b *AlterTableBuilder) index(name string, unique bool, fields []string) *TableIndex {
+	if strings.TrimSpace(name) == "" && len(fields) > 0 {
+		name = "idx_" + strings.ReplaceAll(b.table, ".", "_") + "_" + strings.Join(fields, "_")
+	}
+	return &TableIndex{Name: name, Fields: append([]string(nil), fields...), Unique: unique}
+}
+
+// AddConstraint 添加具名表级约束（主键、唯一或外键）。
+func (b *AlterTableBuilder) AddConstraint(constraint TableConstraint) *AlterTableBuilder {
+	return b.add(AlterTableOperation{Kind: MigrationOpAddConstraint, Constraint: &constraint})
+}
+
+// DropConstraint 删除具名表级约束；需提供原约束定义，Down 据此重建。
+func (b *AlterTableBuilder) DropConstraint(constraint TableConstraint) *AlterTableBuilder {
+	return b.add(AlterTableOperation{Kind: MigrationOpDropConstraint, Constraint: &constraint})
+}
+
+func (m *SchemaMigration) validateAlterOperations(repo *Repository) error {
+	if len(m.alterOps) == 0 {
+		return nil
+	}
+	if !supportsSQLDDL(repo) {
+		return fmt.Errorf("alter table operations require a SQL adapter")
+	}
+	for _, op := range m.alterOps {
+		if err := op.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// executeAlterTableOperations 按顺序执行一组变更操作，action 用于错误信息（"alter" / "revert alter"）。
+// PostgreSQL、SQL Server 的 DDL 可回滚，全部操作在同一事务内执行，任一失败整体回滚；
+// MySQL DDL 隐式提交、SQLite 重建自带事务，逐个执行，中途失败时已完成的变更保留，需手动修复。
+func executeAlterTableOperations(ctx context.Context, repo *Repository, ops []AlterTableOperation, action string) error {
+	if !supportsTransactionalDDL(repo) {
+		for _, op := range ops {
+			if err := executeAlterTableOperation(ctx, repo, op); err != nil {
+				return fmt.Errorf("failed to %s table %s (%s): %w", action, op.Table, op.Kind, err)
+			}
+		}
+		return nil
+	}
+
+	statements := make([][]string, len(ops))
+	for i, op := range ops {
+		compiled, err := compileAlterTableOperation(ctx, repo, op)
+		if err != nil {
+			return fmt.Errorf("failed to %s table %s (%s): %w", action, op.Table, op.Kind, err)
+		}
+		statements[i] = compiled
+	}
+	tx, err := repo.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	for i, op := range ops {
+		for _, statement := range statements[i] {
+			if _, err := tx.Exec(ctx, statement); err != nil {
+				_ = tx.Rollback(ctx)
+				return fmt.Errorf("failed to %s table %s (%s): %w", action, op.Table, op.Kind, err)
+			}
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// supportsTransactionalDDL PostgreSQL 与 SQL Server 的 DDL 可在事务内回滚。
+func supportsTransactionalDDL(repo *Repository) bool {
+	switch repo.GetAdapter().(type) {
+	case *PostgreSQLAdapter, *SQLServerAdapter:
+		return true
+	default:
+		return false
+	}
+}
+
+// executeAlterTableOperation 编译并执行单个变更操作；多条语句（SQLite 重建、SQL Server 默认值约束）在同一事务内执行。
+func executeAlterTableOperation(ctx context.Context, repo *Repository, op AlterTableOperation) error {
+	statements, err := compileAlterTableOperation(ctx, repo, op)
+	if err != nil {
+		return err
+	}
+	if len(statements) == 1 {
+		_, err := repo.Exec(ctx, statements[0])
+		return err
+	}
+
+	tx, err := repo.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(ctx, statement); err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// compileAlterTableOperation 将变更操作编译为方言 DDL。SQLite 不支持的变更通过重建表模拟，需要查询当前表结构。
+func compileAlterTableOperation(ctx context.Context, repo *Repository, op AlterTableOperation) ([]string, error) {
+	if err := op.validate(); err != nil {
+		return nil, err
+	}
+	adapter := repo.GetAdapter()
+	dialect := resolveMigrationDialect(repo)
+	table := dialect.QuoteIdentifier(op.Table)
+
+	if _, ok := adapter.(*SQLiteAdapter); ok && sqliteAlterRequiresRebuild(op) {
+		return compileSQLiteTableRebuild(ctx, repo, dialect, op)
+	}
+	_, isSQLServer := adapter.(*SQLServerAdapter)
+	_, isMySQL := adapter.(*MySQLAdapter)
+
+	switch op.Kind {
+	case MigrationOpAddColumn:
+		column := buildColumnDefinition(adapter, dialect, op.Column, op.Column.Primary)
+		if isSQLServer {
+			return []string{fmt.Sprintf("ALTER TABLE %s ADD %s", table, column)}, nil
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, column)}, nil
+
+	case MigrationOpDropColumn:
+		dropSQL := fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, dialect.QuoteIdentifier(op.Column.Name))
+		if isSQLServer {
+			// SQL Server 的列默认值是独立约束，删除列前必须先删除
+			return []string{buildSQLServerDropDefaultSQL(dialect, op.Table, op.Column.Name), dropSQL}, nil
+		}
+		return []string{dropSQL}, nil
+
+	case MigrationOpRenameColumn:
+		if isSQLServer {
+			return []string{fmt.Sprintf("EXEC sp_rename N'%s.%s', N'%s', N'COLUMN'",
+				escapeSQLString(op.Table), escapeSQLString(op.OldName), escapeSQLString(op.NewName))}, nil
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s",
+			table, dialect.QuoteIdentifier(op.OldName), dialect.QuoteIdentifier(op.NewName))}, nil
+
+	case MigrationOpAlterColumnType:
+		return compileAlterColumnType(adapter, dialect, op)
+
+	case MigrationOpAddIndex:
+		unique := ""
+		if op.Index.Unique {
+			unique = "UNIQUE "
+		}
+		return []string{fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)",
+			unique, dialect.QuoteIdentifier(op.Index.Name), table, joinQuotedIdentifiers(dialect, op.Index.Fields))}, nil
+
+	case MigrationOpDropIndex:
+		if isSQLServer || isMySQL {
+			return []string{fmt.Sprintf("DROP INDEX %s ON %s", dialect.QuoteIdentifier(op.Index.Name), table)}, nil
+		}
+		return []string{fmt.Sprintf("DROP INDEX %s", dialect.QuoteIdentifier(op.Index.Name))}, nil
+
+	case MigrationOpAddConstraint:
+		return []string{fmt.Sprintf("ALTER TABLE %s ADD %s", table, buildTableConstraintSQL(dialect, *op.Constraint))}, nil
+
+	case MigrationOpDropConstraint:
+		name := dialect.QuoteIdentifier(op.Constraint.Name)
+		if isMySQL {
+			switch op.Constraint.Kind {
+			case ConstraintPrimaryKey:
+				return []string{fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY", table)}, nil
+			case ConstraintForeignKey:
+				return []string{fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", table, name)}, nil
+			default:
+				return []string{fmt.Sprintf("ALTER TABLE %s DROP INDEX %s", table, name)}, nil
+			}
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, name)}, nil
+	}
+	return nil, fmt.Errorf("unsupported alter table operation kind: %s", op.Kind)
+}
+
+func compileAlterColumnType(adapter Adapter, dialect SQLDialect, op AlterTableOperation) ([]string, error) {
+	table := dialect.QuoteIdentifier(op.Table)
+	to := *op.Column
+	to.Primary, to.Unique, to.Autoinc = false, false, false
+
+	switch adapter.(type) {
+	case *PostgreSQLAdapter:
+		column := quoteColumnIdentifier("postgres", to.Name)
+		columnType := postgresColumnType(&to, adapter)
+		using := op.Using
+		if using == "" {
+			using = fmt.Sprintf("%s::%s", column, columnType)
+		}
+		// 先移除旧默认值，避免其无法转换为新类型
+		actions := []string{
+			fmt.Sprintf("ALTER COLUMN %s DROP DEFAULT", column),
+			fmt.Sprintf("ALTER COLUMN %s TYPE %s USING %s", column, columnType, using),
+		}
+		if to.Null {
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s DROP NOT NULL", column))
+		} else {
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s SET NOT NULL", column))
+		}
+		if to.Default != nil {
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s SET DEFAULT %s", column, formatDefaultValueForDialect(to.Default, "postgres", to.Type)))
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s %s", table, strings.Join(actions, ", "))}, nil
+
+	case *MySQLAdapter:
+		return []string{fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", table, buildMySQLColumn(&to))}, nil
+
+	case *SQLServerAdapter:
+		column := quoteColumnIdentifier("sqlserver", to.Name)
+		nullability := "NOT NULL"
+		if to.Null {
+			nullability = "NULL"
+		}
+		statements := []string{
+			buildSQLServerDropDefaultSQL(dialect, op.Table, to.Name),
+			fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s %s", table, column, mapSQLServerType(to.Type), nullability),
+		}
+		if to.Default != nil {
+			constraintName := "DF_" + strings.ReplaceAll(op.Table, ".", "_") + "_" + to.Name
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s DEFAULT %s FOR %s",
+				table, dialect.QuoteIdentifier(constraintName), formatDefaultValueForDialect(to.Default, "sqlserver", to.Type), column))
+		}
+		return statements, nil
+
+	default:
+		return nil, fmt.Errorf("alter column type is not supported for adapter %T", adapter)
+	}
+}
+
+// buildSQLServerDropDefaultSQL 查找并删除列上的默认值约束（约束名通常由系统生成，需运行时查询）。
+func buildSQLServerDropDefaultSQL(dialect SQLDialect, table, column string) string {
+	dropPrefix := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT ", dialect.QuoteIdentifier(table))
+	return fmt.Sprintf("DECLARE @df NVARCHAR(256); "+
+		"SELECT @df = dc.name FROM sys.default_constraints dc "+
+		"JOIN sys.columns c ON c.object_id = dc.parent_object_id AND c.column_id = dc.parent_column_id "+
+		"WHERE dc.parent_object_id = OBJECT_ID(N'%s') AND c.name = N'%s'; "+
+		"IF @df IS NOT NULL EXEC(N'%s' + QUOTENAME(@df))",
+		escapeSQLString(table), escapeSQLString(column), escapeSQLString(dropPrefix))
+}
+
+func escapeSQLString(value string) string {
+	return strings.ReplaceAll(value, "'", "''")
+}
+
+// sqliteAlterRequiresRebuild SQLite 原生仅支持新增普通列、重命名列与索引操作，其余变更需重建表。
+func sqliteAlterRequiresRebuild(op AlterTableOperation) bool {
+	switch op.Kind {
+	case MigrationOpAddColumn:
+		return op.Column.Primary || op.Column.Unique
+	case MigrationOpRenameColumn, MigrationOpAddIndex, MigrationOpDropIndex:
+		return false
+	default:
+		return true
+	}
+}
+
+// sqliteTableItem CREATE TABLE 括号内的一项：列定义（name 为列名）或表级约束（name 为约束名，可为空）。
+type sqliteTableItem struct {
+	name string
+	sql  string
+}
+
+type sqliteTableDefinition struct {
+	columns     []sqliteTableItem
+	constraints []sqliteTableItem
+	suffix      string // 如 WITHOUT ROWID / STRICT
+}
+
+// compileSQLiteTableRebuild 按 SQLite 官方流程重建表：以变更后的定义创建新表、拷贝数据、删除旧表、重命名，
+// 并恢复旧表上的索引、触发器与引用该表的视图。启用 foreign_keys 时，被其他表引用的表需由调用方先关闭外键检查。
+func compileSQLiteTableRebuild(ctx context.Context, repo *Repository, dialect SQLDialect, op AlterTableOperation) ([]string, error) {
+	var createSQL string
+	if err := repo.QueryRow(ctx, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", op.Table).Scan(&createSQL); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("alter table %s: table does not exist", op.Table)
+		}
+		return nil, err
+	}
+	def, err := parseSQLiteTableDefinition(createSQL)
+	if err != nil {
+		return nil, fmt.Errorf("alter table %s: %w", op.Table, err)
+	}
+	oldColumns := make([]string, 0, len(def.columns))
+	for _, column := range def.columns {
+		oldColumns = append(oldColumns, column.name)
+	}
+	if err := def.apply(repo.GetAdapter(), dialect, op); err != nil {
+		return nil, fmt.Errorf("alter table %s: %w", op.Table, err)
+	}
+
+	// 新旧表共有的列参与数据拷贝；类型变更的列可使用 Using 表达式转换
+	targets := make([]string, 0, len(def.columns))
+	sources := make([]string, 0, len(def.columns))
+	for _, column := range def.columns {
+		if indexOfSQLiteItem(oldColumns, column.name) < 0 {
+			continue
+		}
+		targets = append(targets, dialect.QuoteIdentifier(column.name))
+		if op.Kind == MigrationOpAlterColumnType && op.Using != "" && strings.EqualFold(column.name, op.Column.Name) {
+			sources = append(sources, op.Using)
+		} else {
+			sources = append(sources, dialect.QuoteIdentifier(column.name))
+		}
+	}
+
+	dependents, err := querySQLiteDependents(ctx, repo, op)
+	if err != nil {
+		return nil, err
+	}
+	views, err := querySQLiteViews(ctx, repo, op.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	table := dialect.QuoteIdentifier(op.Table)
+	tmpTable := dialect.QuoteIdentifier("_eit_rebuild_" + op.Table)
+	items := make([]string, 0, len(def.columns)+len(def.constraints))
+	for _, item := range append(append([]sqliteTableItem(nil), def.columns...), def.constraints...) {
+		items = append(items, item.sql)
+	}
+
+	statements := make([]string, 0, 4+len(views)*2+len(dependents))
+	for _, view := range views {
+		statements = append(statements, fmt.Sprintf("DROP VIEW %s", dialect.QuoteIdentifier(view.name)))
+	}
+	statements = append(statements,
+		fmt.Sprintf("CREATE TABLE %s (%s)%s", tmpTable, strings.Join(items, ", "), def.suffix),
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", tmpTable, strings.Join(targets, ", "), strings.Join(sources, ", "), table),
+		fmt.Sprintf("DROP TABLE %s", table),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tmpTable, table),
+	)
+	statements = append(statements, dependents...)
+	for _, view := range views {
+		statements = append(statements, view.sql)
+	}
+	return statements, nil
+}
+
+func (d *sqliteTableDefinition) apply(adapter Adapter, dialect SQLDialect, op AlterTableOperation) error {
+	switch op.Kind {
+	case MigrationOpAddColumn:
+		if indexOfSQLiteTableItem(d.columns, op.Column.Name) >= 0 {
+			return fmt.Errorf("column %s already exists", op.Column.Name)
+		}
+		d.columns = append(d.columns, sqliteTableItem{name: op.Column.Name, sql: buildColumnDefinition(adapter, dialect, op.Column, op.Column.Primary)})
+	case MigrationOpDropColumn:
+		i := indexOfSQLiteTableItem(d.columns, op.Column.Name)
+		if i < 0 {
+			return fmt.Errorf("column %s does not exist", op.Column.Name)
+		}
+		d.columns = append(d.columns[:i], d.columns[i+1:]...)
+	case MigrationOpAlterColumnType:
+		i := indexOfSQLiteTableItem(d.columns, op.Column.Name)
+		if i < 0 {
+			return fmt.Errorf("column %s does not exist", op.Column.Name)
+		}
+		d.columns[i].sql = buildColumnDefinition(adapter, dialect, op.Column, op.Column.Primary)
+	case MigrationOpAddConstraint:
+		if indexOfSQLiteTableItem(d.constraints, op.Constraint.Name) >= 0 {
+			return fmt.Errorf("constraint %s already exists", op.Constraint.Name)
+		}
+		d.constraints = append(d.constraints, sqliteTableItem{name: op.Constraint.Name, sql: buildTableConstraintSQL(dialect, *op.Constraint)})
+	case MigrationOpDropConstraint:
+		i := indexOfSQLiteTableItem(d.constraints, op.Constraint.Name)
+		if i < 0 {
+			return fmt.Errorf("constraint %s does not exist", op.Constraint.Name)
+		}
+		d.constraints = append(d.constraints[:i], d.constraints[i+1:]...)
+	default:
+		return fmt.Errorf("operation %s does not require table rebuild", op.Kind)
+	}
+	return nil
+}
+
+// querySQLiteDependents 读取旧表上需在重建后恢复的索引与触发器（自动索引没有 sql，随约束重建）。
+// 删除列时跳过包含该列的索引。
+func querySQLiteDependents(ctx context.Context, repo *Repository, op AlterTableOperation) ([]string, error) {
+	rows, err := repo.Query(ctx, "SELECT type, name, sql FROM sqlite_master WHERE tbl_name = ? AND type IN ('index', 'trigger') AND sql IS NOT NULL ORDER BY type, name", op.Table)
+	if err != nil {
+		return nil, err
+	}
+	type dependent struct{ kind, name, sql string }
+	dependents := make([]dependent, 0)
+	for rows.Next() {
+		var d dependent
+		if err := rows.Scan(&d.kind, &d.name, &d.sql); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		dependents = append(dependents, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statements := make([]string, 0, len(dependents))
+	for _, d := range dependents {
+		if d.kind == "index" && op.Kind == MigrationOpDropColumn {
+			var hits int
+			if err := repo.QueryRow(ctx, "SELECT COUNT(*) FROM pragma_index_info(?) WHERE name = ?", d.name, op.Column.Name).Scan(&hits); err != nil {
+				return nil, err
+			}
+			if hits > 0 {
+				continue
+			}
+		}
+		statements = append(statements, d.sql)
+	}
+	return statements, nil
+}
+
+// querySQLiteViews 读取定义中引用该表的视图（重命名新表时 SQLite 会校验视图，需先删除后恢复）。
+func querySQLiteViews(ctx context.Context, repo *Repository, table string) ([]sqliteTableItem, error) {
+	rows, err := repo.Query(ctx, "SELECT name, sql FROM sqlite_master WHERE type = 'view' AND sql IS NOT NULL ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	views := make([]sqliteTableItem, 0)
+	for rows.Next() {
+		var view sqliteTableItem
+		if err := rows.Scan(&view.name, &view.sql); err != nil {
+			return nil, err
+		}
+		if strings.Contains(strings.ToLower(view.sql), strings.ToLower(table)) {
+			views = append(views, view)
+		}
+	}
+	return views, rows.Err()
+}
+
+// parseSQLiteTableDefinition 将 sqlite_master 中的 CREATE TABLE 语句拆分为列定义与表级约束。
+func parseSQLiteTableDefinition(createSQL string) (*sqliteTableDefinition, error) {
+	open := strings.Index(createSQL, "(")
+	if open < 0 {
+		return nil, fmt.Errorf("unrecognized table definition: %s", createSQL)
+	}
+	parts, end := splitSQLiteTopLevel(createSQL, open+1)
+	if end < 0 {
+		return nil, fmt.Errorf("unbalanced table definition: %s", createSQL)
+	}
+
+	def := &sqliteTableDefinition{suffix: createSQL[end+1:]}
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		token, quoted, rest := readSQLiteIdentifier(part)
+		switch keyword := strings.ToUpper(token); {
+		case !quoted && keyword == "CONSTRAINT":
+			name, _, _ := readSQLiteIdentifier(rest)
+			def.constraints = append(def.constraints, sqliteTableItem{name: name, sql: part})
+		case !quoted && (keyword == "PRIMARY" || keyword == "UNIQUE" || keyword == "CHECK" || keyword == "FOREIGN"):
+			def.constraints = append(def.constraints, sqliteTableItem{sql: part})
+		default:
+			def.columns = append(def.columns, sqliteTableItem{name: token, sql: part})
+		}
+	}
+	return def, nil
+}
+
+// splitSQLiteTopLevel 从 start 开始按顶层逗号拆分，直到与之匹配的右括号；返回右括号位置（未闭合时为 -1）。
+func splitSQLiteTopLevel(s string, start int) ([]string, int) {
+	parts := make([]string, 0)
+	depth := 0
+	partStart := start
+	for i := start; i < len(s); i++ {
+		switch ch := s[i]; ch {
+		case '\'', '"', '`', '[':
+			closing := ch
+			if ch == '[' {
+				closing = ']'
+			}
+			j := strings.IndexByte(s[i+1:], closing)
+			if j < 0 {
+				return nil, -1
+			}
+			i += j + 1
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return append(parts, s[partStart:i]), i
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[partStart:i])
+				partStart = i + 1
+			}
+		}
+	}
+	return nil, -1
+}
+
+// readSQLiteIdentifier 读取开头的标识符（支持 "x"、`x`、[x] 引用），返回去引号后的名称与剩余部分。
+func readSQLiteIdentifier(s string) (string, bool, string) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", false, ""
+	}
+	closing := map[byte]byte{'"': '"', '`': '`', '[': ']'}[s[0]]
+	if closing != 0 {
+		if end := strings.IndexByte(s[1:], closing); end >= 0 {
+			return s[1 : end+1], true, s[end+2:]
+		}
+	}
+	end := strings.IndexAny(s, " \t\r\n(")
+	if end < 0 {
+		return s, false, ""
+	}
+	return s[:end], false, s[end:]
+}
+
+func indexOfSQLiteTableItem(items []sqliteTableItem, name string) int {
+	for i, item := range items {
+		if item.name != "" && strings.EqualFold(item.name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexOfSQLiteItem(names []string, name string) int {
+	for i, n := range names {
+		if strings.EqualFold(n, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -13,6 +13,16 @@ type MigrationOperationKind string
 const (
 	MigrationOpRecordApplied MigrationOperationKind = "record_applied"
 	MigrationOpRemoveApplied MigrationOperationKind = "remove_applied"
+
+	// 表结构变更（AlterTableOperation），Down 由 AlterTableOperation.Inverse 推导。
+	MigrationOpAddColumn       MigrationOperationKind = "add_column"
+	MigrationOpDropColumn      MigrationOperationKind = "drop_column"
+	MigrationOpRenameColumn    MigrationOperationKind = "rename_column"
+	MigrationOpAlterColumnType MigrationOperationKind = "alter_column_type"
+	MigrationOpAddIndex        MigrationOperationKind = "add_index"
+	MigrationOpDropIndex       MigrationOperationKind = "drop_index"
+	MigrationOpAddConstraint   MigrationOperationKind = "add_constraint"
+	MigrationOpDropConstraint  MigrationOperationKind = "drop_constraint"
 )
 
 // MigrationOperation 是迁移执行层的统一操作描述。
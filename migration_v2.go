@@ -55,6 +55,7 @@ type SchemaMigration struct {
 	*BaseMigration
 	createSchemas []Schema
 	dropSchemas   []Schema
+	alterOps      []AlterTableOperation
 }
 
 // NewSchemaMigration 创建基于 Schema 的迁移
@@ -63,6 +64,7 @@ func NewSchemaMigration(version, description string) *SchemaMigration {
 		BaseMigration: NewBaseMigration(version, description),
 		createSchemas: make([]Schema, 0),
 		dropSchemas:   make([]Schema, 0),
+		alterOps:      make([]AlterTableOperation, 0),
 	}
 }
 
@@ -80,6 +82,10 @@ func (m *SchemaMigration) DropTable(schema Schema) *SchemaMigration {
 
 // Up 执行迁移
 func (m *SchemaMigration) Up(ctx context.Context, repo *Repository) error {
+	if err := m.validateAlterOperations(repo); err != nil {
+		return err
+	}
+
 	// Phase 1: 创建所有表
 	for _, schema := range m.createSchemas {
 		if err := executeSchemaCreate(ctx, repo, schema); err != nil {
@@ -91,7 +97,12 @@ func (m *SchemaMigration) Up(ctx context.Context, repo *Repository) error {
 		return nil
 	}
 
-	// Phase 2: 创建视图（FK ViewHint 声明的热点查询视图）
+	// Phase 2: 按登记顺序执行表结构变更
+	if err := executeAlterTableOperations(ctx, repo, m.alterOps, "alter"); err != nil {
+		return err
+	}
+
+	// Phase 3: 创建视图（FK ViewHint 声明的热点查询视图）
 	for _, schema := range m.createSchemas {
 		cs, ok := schema.(constraintSchema)
 		if !ok {
@@ -115,6 +126,10 @@ func (m *SchemaMigration) Up(ctx context.Context, repo *Repository) error {
 
 // Down 回滚迁移
 func (m *SchemaMigration) Down(ctx context.Context, repo *Repository) error {
+	if err := m.validateAlterOperations(repo); err != nil {
+		return err
+	}
+
 	if supportsSQLDDL(repo) {
 		// Phase 1: 逆序删除视图（视图引用表，必须先删）
 		for i := len(m.createSchemas) - 1; i >= 0; i-- {
@@ -138,15 +153,23 @@ func (m *SchemaMigration) Down(ctx context.Context, repo *Repository) error {
 				}
 			}
 		}
+		// Phase 2: 逆序执行表结构变更的逆操作
+		inverse := make([]AlterTableOperation, 0, len(m.alterOps))
+		for i := len(m.alterOps) - 1; i >= 0; i-- {
+			inverse = append(inverse, m.alterOps[i].Inverse())
+		}
+		if err := executeAlterTableOperations(ctx, repo, inverse, "revert alter"); err != nil {
+			return err
+		}
 	}
-	// Phase 2: 逆序删除表
+	// Phase 3: 逆序删除表
 	for i := len(m.createSchemas) - 1; i >= 0; i-- {
 		schema := m.createSchemas[i]
 		if err := executeSchemaDrop(ctx, repo, schema); err != nil {
 			return fmt.Errorf("failed to drop table %s: %w", schema.TableName(), err)
 		}
 	}
-	// Phase 3: 恢复 Up 中删除的表
+	// Phase 4: 恢复 Up 中删除的表
 	for _, schema := range m.dropSchemas {
 		tableName := schema.TableName()
 		if err := executeSchemaCreate(ctx, repo, schema); err != nil {
@@ -177,28 +200,11 @@ func buildCreateTableSQL(repo *Repository, schema Schema) string {
 	}
 
 	for _, unique := range uniqueConstraints {
-		uniqueSQL := fmt.Sprintf("UNIQUE (%s)", joinQuotedIdentifiers(dialect, unique.Fields))
-		if unique.Name != "" {
-			uniqueSQL = fmt.Sprintf("CONSTRAINT %s %s", dialect.QuoteIdentifier(unique.Name), uniqueSQL)
-		}
-		columns = append(columns, uniqueSQL)
+		columns = append(columns, buildTableConstraintSQL(dialect, unique))
 	}
 
 	for _, fk := range fkConstraints {
-		localCols := joinQuotedIdentifiers(dialect, fk.Fields)
-		refTable := dialect.QuoteIdentifier(fk.RefTable)
-		refCols := joinQuotedIdentifiers(dialect, fk.RefFields)
-		fkSQL := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)", localCols, refTable, refCols)
-		if fk.OnDelete != "" {
-			fkSQL += " ON DELETE " + fk.OnDelete
-		}
-		if fk.OnUpdate != "" {
-			fkSQL += " ON UPDATE " + fk.OnUpdate
-		}
-		if fk.Name != "" {
-			fkSQL = fmt.Sprintf("CONSTRAINT %s %s", dialect.QuoteIdentifier(fk.Name), fkSQL)
-		}
-		columns = append(columns, fkSQL)
+		columns = append(columns, buildTableConstraintSQL(dialect, fk))
 	}
 
 	columnsSQL := strings.Join(columns, ", ")
@@ -212,6 +218,32 @@ func buildCreateTableSQL(repo *Repository, schema Schema) string {
 	}
 }
 
+// buildTableConstraintSQL 生成表级约束子句（CREATE TABLE 与 ALTER TABLE ADD 共用）。
+func buildTableConstraintSQL(dialect SQLDialect, c TableConstraint) string {
+	var constraintSQL string
+	switch c.Kind {
+	case ConstraintPrimaryKey:
+		constraintSQL = fmt.Sprintf("PRIMARY KEY (%s)", joinQuotedIdentifiers(dialect, c.Fields))
+	case ConstraintForeignKey:
+		localCols := joinQuotedIdentifiers(dialect, c.Fields)
+		refTable := dialect.QuoteIdentifier(c.RefTable)
+		refCols := joinQuotedIdentifiers(dialect, c.RefFields)
+		constraintSQL = fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)", localCols, refTable, refCols)
+		if c.OnDelete != "" {
+			constraintSQL += " ON DELETE " + c.OnDelete
+		}
+		if c.OnUpdate != "" {
+			constraintSQL += " ON UPDATE " + c.OnUpdate
+		}
+	default:
+		constraintSQL = fmt.Sprintf("UNIQUE (%s)", joinQuotedIdentifiers(dialect, c.Fields))
+	}
+	if c.Name != "" {
+		constraintSQL = fmt.Sprintf("CONSTRAINT %s %s", dialect.QuoteIdentifier(c.Name), constraintSQL)
+	}
+	return constraintSQL
+}
+
 func buildDropTableSQL(repo *Repository, tableName string) string {
 	quotedTableName := resolveMigrationDialect(repo).QuoteIdentifier(tableName)
 
@@ -361,10 +393,18 @@ func buildPostgresColumn(field *Field, adapter Adapter) string {
 	if field.Primary && field.Autoinc {
 		return fmt.Sprintf("%s SERIAL PRIMARY KEY", name)
 	}
-	col := fmt.Sprintf("%s %s", name, mapPostgresType(field.Type, adapter))
+	col := fmt.Sprintf("%s %s", name, postgresColumnType(field, adapter))
 	return applyColumnConstraints(col, field, "postgres")
 }
 
+// postgresColumnType 在 mapPostgresType 基础上区分带时区的时间字段。
+func postgresColumnType(field *Field, adapter Adapter) string {
+	if field.Type == TypeTime && field.Timezone {
+		return "TIMESTAMPTZ"
+	}
+	return mapPostgresType(field.Type, adapter)
+}
+
 func buildMySQLColumn(field *Field) string {
 	name := quoteColumnIdentifier("mysql", field.Name)
 	if field.Primary && field.Autoinc {
@@ -0,0 +1,215 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCompileAlterTableOperationDialects(t *testing.T) {
+	ctx := context.Background()
+	from := NewField("age", TypeString).Null(true).Build()
+	to := NewField("age", TypeInteger).Default(0).Build()
+	nickname := NewField("nickname", TypeString).Null(true).Default("anon").Build()
+	uniqueEmail := TableConstraint{Name: "uk_users_email", Kind: ConstraintUnique, Fields: []string{"email"}}
+	fk := TableConstraint{Name: "fk_users_org", Kind: ConstraintForeignKey, Fields: []string{"org_id"}, RefTable: "orgs", RefFields: []string{"id"}, OnDelete: "CASCADE"}
+
+	cases := []struct {
+		name    string
+		adapter Adapter
+		op      AlterTableOperation
+		want    []string
+	}{
+		{"postgres alter type", &PostgreSQLAdapter{}, AlterTableOperation{Kind: MigrationOpAlterColumnType, Table: "users", From: from, Column: to},
+			[]string{`ALTER TABLE "users" ALTER COLUMN "age" DROP DEFAULT, ALTER COLUMN "age" TYPE INTEGER USING "age"::INTEGER, ALTER COLUMN "age" SET NOT NULL, ALTER COLUMN "age" SET DEFAULT 0`}},
+		{"postgres alter type using", &PostgreSQLAdapter{}, AlterTableOperation{Kind: MigrationOpAlterColumnType, Table: "users", From: from, Column: to, Using: "NULLIF(age, '')::INTEGER"},
+			[]string{`TYPE INTEGER USING NULLIF(age, '')::INTEGER`}},
+		{"mysql modify", &MySQLAdapter{}, AlterTableOperation{Kind: MigrationOpAlterColumnType, Table: "users", From: from, Column: to},
+			[]string{"ALTER TABLE `users` MODIFY COLUMN `age` INT NOT NULL DEFAULT 0"}},
+		{"mysql drop foreign key", &MySQLAdapter{}, AlterTableOperation{Kind: MigrationOpDropConstraint, Table: "users", Constraint: &fk},
+			[]string{"ALTER TABLE `users` DROP FOREIGN KEY `fk_users_org`"}},
+		{"mysql drop index", &MySQLAdapter{}, AlterTableOperation{Kind: MigrationOpDropIndex, Table: "users", Index: &TableIndex{Name: "idx_users_age", Fields: []string{"age"}}},
+			[]string{"DROP INDEX `idx_users_age` ON `users`"}},
+		{"sqlserver add column", &SQLServerAdapter{}, AlterTableOperation{Kind: MigrationOpAddColumn, Table: "users", Column: nickname},
+			[]string{"ALTER TABLE [users] ADD [nickname] NVARCHAR(255) DEFAULT 'anon'"}},
+		{"sqlserver drop column", &SQLServerAdapter{}, AlterTableOperation{Kind: MigrationOpDropColumn, Table: "users", Column: nickname},
+			[]string{"OBJECT_ID(N'users') AND c.name = N'nickname'", "EXEC(N'ALTER TABLE [users] DROP CONSTRAINT ' + QUOTENAME(@df))", "ALTER TABLE [users] DROP COLUMN [nickname]"}},
+		{"sqlserver alter type", &SQLServerAdapter{}, AlterTableOperation{Kind: MigrationOpAlterColumnType, Table: "users", From: from, Column: to},
+			[]string{"sys.default_constraints", "ALTER TABLE [users] ALTER COLUMN [age] INT NOT NULL", "ALTER TABLE [users] ADD CONSTRAINT [DF_users_age] DEFAULT 0 FOR [age]"}},
+		{"sqlserver rename", &SQLServerAdapter{}, AlterTableOperation{Kind: MigrationOpRenameColumn, Table: "users", OldName: "name", NewName: "full_name"},
+			[]string{"EXEC sp_rename N'users.name', N'full_name', N'COLUMN'"}},
+		{"postgres add constraint", &PostgreSQLAdapter{}, AlterTableOperation{Kind: MigrationOpAddConstraint, Table: "users", Constraint: &uniqueEmail},
+			[]string{`ALTER TABLE "users" ADD CONSTRAINT "uk_users_email" UNIQUE ("email")`}},
+		{"postgres drop constraint", &PostgreSQLAdapter{}, AlterTableOperation{Kind: MigrationOpDropConstraint, Table: "users", Constraint: &fk},
+			[]string{`ALTER TABLE "users" DROP CONSTRAINT "fk_users_org"`}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			statements, err := compileAlterTableOperation(ctx, &Repository{adapter: tc.adapter}, tc.op)
+			if err != nil {
+				t.Fatalf("compile failed: %v", err)
+			}
+			joined := strings.Join(statements, "\n")
+			for _, fragment := range tc.want {
+				if !strings.Contains(joined, fragment) {
+					t.Fatalf("expected %q in:\n%s", fragment, joined)
+				}
+			}
+		})
+	}
+
+	if _, err := compileAlterTableOperation(ctx, &Repository{adapter: &PostgreSQLAdapter{}}, AlterTableOperation{
+		Kind: MigrationOpAlterColumnType, Table: "users", From: from, Column: NewField("years", TypeInteger).Build(),
+	}); err == nil || !strings.Contains(err.Error(), "use RenameColumn") {
+		t.Fatalf("expected rename via alter type rejected, got: %v", err)
+	}
+	if _, err := compileAlterTableOperation(ctx, &Repository{adapter: &PostgreSQLAdapter{}}, AlterTableOperation{
+		Kind: MigrationOpDropConstraint, Table: "users", Constraint: &TableConstraint{Kind: ConstraintUnique, Fields: []string{"email"}},
+	}); err == nil || !strings.Contains(err.Error(), "requires constraint name") {
+		t.Fatalf("expected unnamed constraint rejected, got: %v", err)
+	}
+}
+
+func TestAlterTableOperationInverse(t *testing.T) {
+	m := NewSchemaMigration("20260101000000", "alter users")
+	from := NewField("age", TypeString).Build()
+	to := NewField("age", TypeInteger).Build()
+	m.AlterTable("users").
+		AddColumn(NewField("nickname", TypeString).Build()).
+		RenameColumn("name", "full_name").
+		AlterColumnType(from, to, "CAST(age AS INTEGER)", "CAST(age AS TEXT)").
+		AddIndex("", false, "age")
+
+	ops := m.alterOps
+	if len(ops) != 4 || ops[3].Index.Name != "idx_users_age" {
+		t.Fatalf("unexpected operations: %+v", ops)
+	}
+	if inv := ops[0].Inverse(); inv.Kind != MigrationOpDropColumn || inv.Column.Name != "nickname" {
+		t.Fatalf("expected add_column inverted to drop_column, got %+v", inv)
+	}
+	if inv := ops[1].Inverse(); inv.OldName != "full_name" || inv.NewName != "name" {
+		t.Fatalf("expected rename swapped, got %+v", inv)
+	}
+	if inv := ops[2].Inverse(); inv.Column != from || inv.From != to || inv.Using != "CAST(age AS TEXT)" {
+		t.Fatalf("expected alter type swapped, got %+v", inv)
+	}
+	if inv := ops[3].Inverse(); inv.Kind != MigrationOpDropIndex || inv.Inverse().Kind != MigrationOpAddIndex {
+		t.Fatalf("expected add_index inverted to drop_index, got %+v", inv)
+	}
+}
+
+func TestSchemaMigrationAlterTableSQLite(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE members (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, age TEXT NULL, legacy TEXT NULL, email TEXT NOT NULL, CONSTRAINT ck_members_name CHECK (length(name) > 0))",
+		"CREATE INDEX idx_members_legacy ON members (legacy)",
+		"CREATE INDEX idx_members_name ON members (name)",
+		"CREATE VIEW adult_members AS SELECT id, name FROM members WHERE age >= 18",
+		"INSERT INTO members (name, age, legacy, email) VALUES ('alice', '30', 'x', 'a@x.io'), ('bob', '17', 'y', 'b@x.io')",
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+	columns := func() string {
+		rows, err := repo.Query(ctx, "SELECT name, type FROM pragma_table_info('members') ORDER BY cid")
+		if err != nil {
+			t.Fatalf("table info failed: %v", err)
+		}
+		defer rows.Close()
+		parts := make([]string, 0)
+		for rows.Next() {
+			var name, typ string
+			if err := rows.Scan(&name, &typ); err != nil {
+				t.Fatalf("scan failed: %v", err)
+			}
+			parts = append(parts, name+" "+typ)
+		}
+		return strings.Join(parts, ",")
+	}
+	count := func(query string) int {
+		var n int
+		if err := repo.QueryRow(ctx, query).Scan(&n); err != nil {
+			t.Fatalf("count %q failed: %v", query, err)
+		}
+		return n
+	}
+
+	m := NewSchemaMigration("20260101000000", "alter members")
+	m.AlterTable("members").
+		AddColumn(NewField("nickname", TypeString).Null(true).Build()).
+		RenameColumn("name", "full_name").
+		AlterColumnType(NewField("age", TypeString).Null(true).Build(), NewField("age", TypeInteger).Null(true).Build(), "CAST(age AS INTEGER)").
+		DropColumn(NewField("legacy", TypeString).Null(true).Build()).
+		AddConstraint(TableConstraint{Name: "uk_members_email", Kind: ConstraintUnique, Fields: []string{"email"}}).
+		AddIndex("", false, "age")
+	if err := m.Up(ctx, repo); err != nil {
+		t.Fatalf("up failed: %v", err)
+	}
+	if got := columns(); got != "id INTEGER,full_name TEXT,age INTEGER,email TEXT,nickname TEXT" {
+		t.Fatalf("unexpected columns after up: %s", got)
+	}
+	if count("SELECT COUNT(*) FROM members WHERE age = 30 AND typeof(age) = 'integer'") != 1 {
+		t.Fatalf("expected age converted to integer")
+	}
+	if count("SELECT COUNT(*) FROM adult_members") != 1 {
+		t.Fatalf("expected dependent view restored")
+	}
+	if count("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name IN ('idx_members_name', 'idx_members_age')") != 2 ||
+		count("SELECT COUNT(*) FROM sqlite_master WHERE name = 'idx_members_legacy'") != 0 {
+		t.Fatalf("expected indexes rebuilt without dropped column")
+	}
+	if _, err := repo.Exec(ctx, "INSERT INTO members (full_name, email) VALUES ('carol', 'a@x.io')"); err == nil {
+		t.Fatalf("expected unique constraint enforced after rebuild")
+	}
+	if _, err := repo.Exec(ctx, "INSERT INTO members (full_name, email) VALUES ('', 'c@x.io')"); err == nil {
+		t.Fatalf("expected check constraint preserved after rebuild")
+	}
+
+	if err := m.Down(ctx, repo); err != nil {
+		t.Fatalf("down failed: %v", err)
+	}
+	if got := columns(); got != "id INTEGER,name TEXT,age TEXT,email TEXT,legacy TEXT" {
+		t.Fatalf("unexpected columns after down: %s", got)
+	}
+	if count("SELECT COUNT(*) FROM members WHERE name = 'alice' AND age = '30'") != 1 || count("SELECT COUNT(*) FROM sqlite_master WHERE name = 'idx_members_age'") != 0 {
+		t.Fatalf("expected data kept and index removed after down")
+	}
+	if _, err := repo.Exec(ctx, "INSERT INTO members (name, email) VALUES ('carol', 'a@x.io')"); err != nil {
+		t.Fatalf("expected unique constraint dropped after down: %v", err)
+	}
+
+	missing := NewSchemaMigration("20260101000001", "missing column")
+	missing.AlterTable("members").DropColumn(NewField("ghost", TypeString).Build())
+	if err := missing.Up(ctx, repo); err == nil || !strings.Contains(err.Error(), "column ghost does not exist") {
+		t.Fatalf("expected missing column error, got: %v", err)
+	}
+	t.Logf("✓ alter table with sqlite table rebuild")
+}
+
+func TestSchemaMigrationAlterTableTransactionalDDL(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+	if _, err := repo.Exec(ctx, "CREATE TABLE members (id INTEGER PRIMARY KEY, name TEXT NOT NULL)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	// 以 PostgreSQL 适配器编译 DDL，借用 SQLite 连接执行，验证多个变更共用一个事务
+	pgRepo := &Repository{adapter: &PostgreSQLAdapter{sqlDB: repo.GetAdapter().(*SQLiteAdapter).sqlDB}}
+	m := NewSchemaMigration("20260101000000", "partial failure")
+	m.AlterTable("members").AddColumn(NewField("nickname", TypeString).Null(true).Build())
+	m.AlterTable("missing").AddColumn(NewField("nickname", TypeString).Null(true).Build())
+	if err := m.Up(ctx, pgRepo); err == nil || !strings.Contains(err.Error(), "failed to alter table missing") {
+		t.Fatalf("expected second alter to fail, got: %v", err)
+	}
+	var n int
+	if err := repo.QueryRow(ctx, "SELECT COUNT(*) FROM pragma_table_info('members') WHERE name = 'nickname'").Scan(&n); err != nil || n != 0 {
+		t.Fatalf("expected first alter rolled back with the failed one, got %d (%v)", n, err)
+	}
+	if supportsTransactionalDDL(repo) || !supportsTransactionalDDL(&Repository{adapter: &SQLServerAdapter{}}) {
+		t.Fatalf("expected transactional DDL only on PostgreSQL / SQL Server")
+	}
+	t.Logf("✓ alter operations share one transaction on transactional-DDL adapters")
+}
@@ -146,6 +146,29 @@ func (a *MongoAdapter) GetQueryBuilderProvider() QueryConstructorProvider {
 
 // ExecuteCompiledFindPlan 执行由 MongoQueryConstructor 生成的 Find 计划。
 func (a *MongoAdapter) ExecuteCompiledFindPlan(ctx context.Context, query string) ([]map[string]interface{}, error) {
+	cursor, err := a.openCompiledFindCursor(ctx, query, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rows := make([]map[string]interface{}, 0)
+	for cursor.Next(ctx) {
+		entry := map[string]interface{}{}
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, err
+		}
+		rows = append(rows, entry)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// openCompiledFindCursor 解析 Find 计划并打开游标；batchSize > 0 时设置每批从服务端拉取的文档数。
+func (a *MongoAdapter) openCompiledFindCursor(ctx context.Context, query string, batchSize int32) (*mongo.Cursor, error) {
 	if a.client == nil {
 		return nil, fmt.Errorf("mongodb client not connected")
 	}
@@ -171,13 +194,16 @@ func (a *MongoAdapter) ExecuteCompiledFindPlan(ctx context.Context, query string
 		filter = plan.Filter
 	}
 
-	// 含 lookup 阶段时改用 aggregate pipeline，以支持跨集合关联。
-	if len(plan.Lookups) > 0 {
+	// 含 lookup / group / 子查询 / 去重 / 预加载阶段时改用 aggregate pipeline，以支持跨集合关联与分组聚合。
+	if len(plan.Lookups) > 0 || plan.Group != nil || len(plan.Subqueries) > 0 || plan.Distinct != nil || len(plan.Preloads) > 0 {
 		strategy := normalizeMongoRelationJoinStrategy(a.relationJoinStrategy)
-		pipeline := make([]bson.M, 0, 2+len(plan.Lookups)+3)
+		pipeline := make([]bson.M, 0, 2+len(plan.Lookups)+3*len(plan.Subqueries)+3)
 		if len(filter) > 0 {
 			pipeline = append(pipeline, bson.M{"$match": filter})
 		}
+		for _, stage := range buildMongoSubqueryPipeline(plan.Subqueries) {
+			pipeline = append(pipeline, bson.M(stage))
+		}
 
 		for _, lk := range plan.Lookups {
 			stage, ok := buildMongoLookupStage(lk, strategy)
@@ -199,22 +225,22 @@ func (a *MongoAdapter) ExecuteCompiledFindPlan(ctx context.Context, query string
 			}
 		}
 
-		if len(plan.Sort) > 0 {
-			sortSpec := bson.D{}
-			for _, item := range plan.Sort {
-				field := strings.TrimSpace(item.Field)
-				if field == "" {
-					continue
-				}
-				direction := 1
-				if item.Direction < 0 {
-					direction = -1
-				}
-				sortSpec = append(sortSpec, bson.E{Key: field, Value: direction})
-			}
+		for _, stage := range buildMongoGroupPipeline(plan.Group) {
+			pipeline = append(pipeline, bson.M(stage))
+		}
+
+		sortSpec := buildMongoSortSpec(plan.Sort)
+		if distinctStages := buildMongoDistinctPipeline(plan.Distinct); len(distinctStages) > 0 {
+			// 先排序再 $group 取 $first，使每组保留排序后的第一个文档。
 			if len(sortSpec) > 0 {
 				pipeline = append(pipeline, bson.M{"$sort": sortSpec})
 			}
+			for _, stage := range distinctStages {
+				pipeline = append(pipeline, bson.M(stage))
+			}
+		}
+		if len(sortSpec) > 0 {
+			pipeline = append(pipeline, bson.M{"$sort": sortSpec})
 		}
 		if plan.Offset != nil {
 			pipeline = append(pipeline, bson.M{"$skip": int64(*plan.Offset)})
@@ -222,6 +248,10 @@ func (a *MongoAdapter) ExecuteCompiledFindPlan(ctx context.Context, query string
 		if plan.Limit != nil {
 			pipeline = append(pipeline, bson.M{"$limit": int64(*plan.Limit)})
 		}
+		// 预加载在分页之后执行，只为当前页的文档关联子集合。
+		for _, stage := range plan.Preloads {
+			pipeline = append(pipeline, bson.M(stage))
+		}
 		if len(plan.Projection) > 0 {
 			projection := bson.M{}
 			for _, field := range plan.Projection {
@@ -236,24 +266,11 @@ func (a *MongoAdapter) ExecuteCompiledFindPlan(ctx context.Context, query string
 			}
 		}
 
-		cursor, err := coll.Aggregate(ctx, pipeline)
-		if err != nil {
-			return nil, err
+		aggregateOpts := options.Aggregate()
+		if batchSize > 0 {
+			aggregateOpts.SetBatchSize(batchSize)
 		}
-		defer cursor.Close(ctx)
-
-		rows := make([]map[string]interface{}, 0)
-		for cursor.Next(ctx) {
-			entry := map[string]interface{}{}
-			if err := cursor.Decode(&entry); err != nil {
-				return nil, err
-			}
-			rows = append(rows, entry)
-		}
-		if err := cursor.Err(); err != nil {
-			return nil, err
-		}
-		return rows, nil
+		return coll.Aggregate(ctx, pipeline, aggregateOpts)
 	}
 
 	findOpts := options.Find()
@@ -270,22 +287,8 @@ func (a *MongoAdapter) ExecuteCompiledFindPlan(ctx context.Context, query string
 			findOpts.SetProjection(projection)
 		}
 	}
-	if len(plan.Sort) > 0 {
-		sortSpec := bson.D{}
-		for _, item := range plan.Sort {
-			field := strings.TrimSpace(item.Field)
-			if field == "" {
-				continue
-			}
-			direction := 1
-			if item.Direction < 0 {
-				direction = -1
-			}
-			sortSpec = append(sortSpec, bson.E{Key: field, Value: direction})
-		}
-		if len(sortSpec) > 0 {
-			findOpts.SetSort(sortSpec)
-		}
+	if sortSpec := buildMongoSortSpec(plan.Sort); len(sortSpec) > 0 {
+		findOpts.SetSort(sortSpec)
 	}
 	if plan.Limit != nil {
 		findOpts.SetLimit(int64(*plan.Limit))
@@ -293,26 +296,28 @@ func (a *MongoAdapter) ExecuteCompiledFindPlan(ctx context.Context, query string
 	if plan.Offset != nil {
 		findOpts.SetSkip(int64(*plan.Offset))
 	}
-
-	cursor, err := coll.Find(ctx, filter, findOpts)
-	if err != nil {
-		return nil, err
+	if batchSize > 0 {
+		findOpts.SetBatchSize(batchSize)
 	}
-	defer cursor.Close(ctx)
 
-	rows := make([]map[string]interface{}, 0)
-	for cursor.Next(ctx) {
-		entry := map[string]interface{}{}
-		if err := cursor.Decode(&entry); err != nil {
-			return nil, err
+	return coll.Find(ctx, filter, findOpts)
+}
+
+// buildMongoSortSpec 将排序字段转换为有序的 $sort 规格。
+func buildMongoSortSpec(fields []MongoSortField) bson.D {
+	sortSpec := bson.D{}
+	for _, item := range fields {
+		field := strings.TrimSpace(item.Field)
+		if field == "" {
+			continue
 		}
-		rows = append(rows, entry)
-	}
-	if err := cursor.Err(); err != nil {
-		return nil, err
+		direction := 1
+		if item.Direction < 0 {
+			direction = -1
+		}
+		sortSpec = append(sortSpec, bson.E{Key: field, Value: direction})
 	}
-
-	return rows, nil
+	return sortSpec
 }
 
 func normalizeMongoRelationJoinStrategy(raw string) string {
@@ -466,6 +471,9 @@ func (a *MongoAdapter) ExecuteCompiledWritePlan(ctx context.Context, query strin
 		if err != nil {
 			return nil, err
 		}
+		if strings.TrimSpace(plan.VersionField) != "" && (res == nil || res.MatchedCount == 0) {
+			return nil, &StaleEntryError{Table: collection, VersionField: plan.VersionField, Version: plan.ExpectedVersion}
+		}
 		if res != nil {
 			summary.RowsAffected = int64(res.ModifiedCount)
 			summary.Counters["matched"] = int(res.MatchedCount)
@@ -501,6 +509,57 @@ func (a *MongoAdapter) ExecuteCompiledWritePlan(ctx context.Context, query strin
 	}
 }
 
+// insertDocuments 按顺序批量插入文档（InsertMany），返回生成的 _id 列表。
+// 与编译写入计划不同，文档不经过 JSON 序列化，保留时间、ObjectID 等原生类型。
+func (a *MongoAdapter) insertDocuments(ctx context.Context, collection string, documents []interface{}) ([]interface{}, error) {
+	if a.client == nil {
+		return nil, fmt.Errorf("mongodb client not connected")
+	}
+	if strings.TrimSpace(collection) == "" {
+		return nil, fmt.Errorf("mongodb insert requires collection")
+	}
+	res, err := a.client.Database(a.database).Collection(collection).InsertMany(ctx, documents, options.InsertMany().SetOrdered(true))
+	if err != nil {
+		return nil, err
+	}
+	return res.InsertedIDs, nil
+}
+
+// updateManyDocuments 按过滤器批量更新（$set），返回匹配的文档数。
+func (a *MongoAdapter) updateManyDocuments(ctx context.Context, collection string, filter map[string]interface{}, set map[string]interface{}) (int64, error) {
+	if a.client == nil {
+		return 0, fmt.Errorf("mongodb client not connected")
+	}
+	res, err := a.client.Database(a.database).Collection(collection).UpdateMany(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return 0, err
+	}
+	return res.MatchedCount, nil
+}
+
+// deleteManyDocuments 按过滤器批量删除，返回删除的文档数。
+func (a *MongoAdapter) deleteManyDocuments(ctx context.Context, collection string, filter map[string]interface{}) (int64, error) {
+	if a.client == nil {
+		return 0, fmt.Errorf("mongodb client not connected")
+	}
+	res, err := a.client.Database(a.database).Collection(collection).DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// countDocuments 按过滤器统计文档数。
+func (a *MongoAdapter) countDocuments(ctx context.Context, collection string, filter map[string]interface{}) (int64, error) {
+	if a.client == nil {
+		return 0, fmt.Errorf("mongodb client not connected")
+	}
+	if filter == nil {
+		filter = bson.M{}
+	}
+	return a.client.Database(a.database).Collection(collection).CountDocuments(ctx, filter)
+}
+
 // GetDatabaseFeatures MongoDB 特性声明（最小实现）
 func (a *MongoAdapter) GetDatabaseFeatures() *DatabaseFeatures {
 	return NewMongoDatabaseFeatures()
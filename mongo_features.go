@@ -71,13 +71,14 @@ func NewMongoDatabaseFeatures() *DatabaseFeatures {
 func NewMongoQueryFeatures() *QueryFeatures {
 	return &QueryFeatures{
 		// MongoDB 不走 SQL，以下为近似映射/最小实现
-		SupportsIN:       true,
-		SupportsNotIN:    true,
-		SupportsBetween:  true,
-		SupportsLike:     false,
-		SupportsDistinct: true,
-		SupportsGroupBy:  true,
-		SupportsHaving:   false,
+		SupportsIN:         true,
+		SupportsNotIN:      true,
+		SupportsBetween:    true,
+		SupportsLike:       false,
+		SupportsDistinct:   true,
+		SupportsDistinctOn: false, // $group + $first 改写
+		SupportsGroupBy:    true,
+		SupportsHaving:     false,
 
 		SupportsInnerJoin:     false,
 		SupportsLeftJoin:      false,
@@ -141,6 +142,7 @@ func NewMongoQueryFeatures() *QueryFeatures {
 		},
 
 		FallbackStrategies: map[string]QueryFallbackStrategy{
+			"distinct_on": QueryFallbackAlternativeSyntax, // $sort + $group($first) + $replaceRoot
 			// MongoDB 不支持 SQL JOIN，通过应用层或 $lookup aggregation 替代
 			"inner_join":      QueryFallbackApplicationLayer,
 			"left_join":       QueryFallbackApplicationLayer,
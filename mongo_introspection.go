@@ -0,0 +1,211 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// IntrospectTables 列出当前数据库中的普通集合（排除 system.* 与视图）。
+func (a *MongoAdapter) IntrospectTables(ctx context.Context) ([]string, error) {
+	if a.client == nil {
+		return nil, fmt.Errorf("mongodb adapter not connected")
+	}
+	names, err := a.client.Database(a.database).ListCollectionNames(ctx, bson.M{"type": "collection"})
+	if err != nil {
+		return nil, err
+	}
+	collections := make([]string, 0, len(names))
+	for _, name := range names {
+		if !strings.HasPrefix(name, "system.") {
+			collections = append(collections, name)
+		}
+	}
+	return collections, nil
+}
+
+// IntrospectSchema 基于集合的 $jsonSchema 校验器与索引反查结构：
+// 校验器声明的属性映射为字段（未列入 required 或允许 null 的字段可空），唯一索引映射为唯一约束。
+func (a *MongoAdapter) IntrospectSchema(ctx context.Context, collection string) (*BaseSchema, error) {
+	if a.client == nil {
+		return nil, fmt.Errorf("mongodb adapter not connected")
+	}
+	db := a.client.Database(a.database)
+	specs, err := db.ListCollectionSpecifications(ctx, bson.M{"name": collection})
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("collection %s does not exist", collection)
+	}
+
+	cursor, err := db.Collection(collection).Indexes().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: list indexes on %s: %w", collection, err)
+	}
+	defer cursor.Close(ctx)
+	indexes := make([]mongoIndexSpecification, 0)
+	for cursor.Next(ctx) {
+		var index mongoIndexSpecification
+		if err := cursor.Decode(&index); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return mongoSchemaFromSpecification(collection, specs[0].Options, indexes)
+}
+
+type mongoIndexSpecification struct {
+	Name    string   `bson:"name"`
+	Key     bson.D   `bson:"key"`
+	Unique  bool     `bson:"unique"`
+	Partial bson.Raw `bson:"partialFilterExpression,omitempty"`
+}
+
+type mongoJSONSchemaProperty struct {
+	BSONType bson.RawValue `bson:"bsonType"`
+	Type     bson.RawValue `bson:"type"`
+}
+
+// mongoSchemaFromSpecification 由集合选项（validator.$jsonSchema）与索引组装 BaseSchema；
+// _id 始终作为主键字段，索引引用但校验器未声明的字段以可空字符串补齐。
+func mongoSchemaFromSpecification(collection string, options bson.Raw, indexes []mongoIndexSpecification) (*BaseSchema, error) {
+	schema := NewBaseSchema(collection)
+	schema.AddField(&Field{Name: "_id", Type: TypeString, Primary: true})
+
+	if len(options) > 0 {
+		jsonSchema, err := options.LookupErr("validator", "$jsonSchema")
+		if err == nil && jsonSchema.Type == bsontype.EmbeddedDocument {
+			if err := addMongoJSONSchemaFields(schema, jsonSchema.Document()); err != nil {
+				return nil, fmt.Errorf("collection %s: %w", collection, err)
+			}
+		}
+	}
+
+	for _, index := range indexes {
+		if index.Name == "_id_" || len(index.Partial) > 0 || len(index.Key) == 0 {
+			continue
+		}
+		fields := make([]string, 0, len(index.Key))
+		for _, key := range index.Key {
+			field := schema.GetField(key.Key)
+			if field == nil {
+				field = &Field{Name: key.Key, Type: TypeString, Null: true}
+				schema.AddField(field)
+			}
+			if kind, ok := key.Value.(string); ok && strings.HasPrefix(kind, "2d") {
+				field.Type = TypeLocation
+			}
+			fields = append(fields, key.Key)
+		}
+		if index.Unique {
+			schema.AddUniqueConstraint(index.Name, fields...)
+			continue
+		}
+		if len(fields) == 1 {
+			schema.GetField(fields[0]).Index = true
+		}
+		schema.AddIndex(index.Name, false, fields...)
+	}
+	return schema, nil
+}
+
+func addMongoJSONSchemaFields(schema *BaseSchema, jsonSchema bson.Raw) error {
+	required := make(map[string]bool)
+	if value, err := jsonSchema.LookupErr("required"); err == nil && value.Type == bsontype.Array {
+		values, err := value.Array().Values()
+		if err != nil {
+			return err
+		}
+		for _, item := range values {
+			if name, ok := item.StringValueOK(); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	value, err := jsonSchema.LookupErr("properties")
+	if err != nil || value.Type != bsontype.EmbeddedDocument {
+		return nil
+	}
+	elements, err := value.Document().Elements()
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		var property mongoJSONSchemaProperty
+		if err := bson.Unmarshal(element.Value().Document(), &property); err != nil {
+			return fmt.Errorf("property %s: %w", element.Key(), err)
+		}
+		types := mongoRawStrings(property.BSONType)
+		if len(types) == 0 {
+			types = mongoRawStrings(property.Type)
+		}
+		fieldType, nullable := fieldTypeFromMongoTypes(types)
+		name := element.Key()
+		if field := schema.GetField(name); field != nil {
+			if name != "_id" {
+				field.Type = fieldType
+			}
+			continue
+		}
+		schema.AddField(&Field{Name: name, Type: fieldType, Null: nullable || !required[name]})
+	}
+	return nil
+}
+
+// mongoRawStrings 读取 bsonType / type：既可为单个字符串，也可为字符串数组。
+func mongoRawStrings(value bson.RawValue) []string {
+	if s, ok := value.StringValueOK(); ok {
+		return []string{s}
+	}
+	if value.Type != bsontype.Array {
+		return nil
+	}
+	values, err := value.Array().Values()
+	if err != nil {
+		return nil
+	}
+	out := make([]string, 0, len(values))
+	for _, item := range values {
+		if s, ok := item.StringValueOK(); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// fieldTypeFromMongoTypes 将 $jsonSchema 的 bsonType / type 映射为 FieldType；包含 null 时字段可空。
+func fieldTypeFromMongoTypes(types []string) (FieldType, bool) {
+	fieldType := TypeString
+	nullable := false
+	for _, t := range types {
+		switch t {
+		case "null":
+			nullable = true
+		case "int", "long", "integer":
+			fieldType = TypeInteger
+		case "double", "number":
+			fieldType = TypeFloat
+		case "decimal":
+			fieldType = TypeDecimal
+		case "bool", "boolean":
+			fieldType = TypeBoolean
+		case "date", "timestamp":
+			fieldType = TypeTime
+		case "binData":
+			fieldType = TypeBinary
+		case "object":
+			fieldType = TypeMap
+		case "array":
+			fieldType = TypeArray
+		}
+	}
+	return fieldType, nullable
+}
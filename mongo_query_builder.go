@@ -32,6 +32,13 @@ type MongoQueryConstructor struct {
 	writePlan    *MongoCompiledWritePlan
 	customMode   bool
 	joins        []mongoJoinClause // 跨集合关联（通过关系注册表解析为 $lookup）
+	groupBys     []string
+	aggregates   []QueryAggregateIR
+	distinct     bool
+	distinctOn   []string
+	softDeleted  softDeleteScope
+	preloads     []QueryPreloadIR
+	buildErr     error
 }
 
 // MongoLookupStage 描述一个 MongoDB $lookup 聚合阶段的参数。
@@ -55,7 +62,42 @@ type MongoCompiledFindPlan struct {
 	Sort       []MongoSortField       `json:"sort,omitempty"`
 	Limit      *int                   `json:"limit,omitempty"`
 	Offset     *int                   `json:"offset,omitempty"`
-	Lookups    []MongoLookupStage     `json:"lookups,omitempty"` // $lookup 聚合阶段参数列表
+	Lookups    []MongoLookupStage     `json:"lookups,omitempty"`    // $lookup 聚合阶段参数列表
+	Group      *MongoGroupStage       `json:"group,omitempty"`      // $group 聚合阶段（GroupBy/Aggregate）
+	Subqueries []MongoSubqueryStage   `json:"subqueries,omitempty"` // 子查询条件（$lookup + $match）
+	Distinct   *MongoDistinctStage    `json:"distinct,omitempty"`   // 去重（$group + $first + $replaceRoot）
+	// Preloads 关联预加载的 $lookup / $addFields 阶段，在分页之后、投影之前追加。
+	Preloads []map[string]interface{} `json:"preloads,omitempty"`
+}
+
+// MongoDistinctStage 描述去重阶段：按 Keys 分组，每组保留排序后的第一个文档。
+type MongoDistinctStage struct {
+	Keys []string `json:"keys"`
+}
+
+// MongoSubqueryStage 描述由子查询条件编译的关联 $lookup 阶段。
+// LocalField/ForeignField 为空时表示非关联子查询（仅判断子集合是否存在匹配文档）。
+type MongoSubqueryStage struct {
+	From         string                 `json:"from"`
+	LocalField   string                 `json:"localField,omitempty"`
+	ForeignField string                 `json:"foreignField,omitempty"`
+	Filter       map[string]interface{} `json:"filter,omitempty"`
+	As           string                 `json:"as"`
+	Negate       bool                   `json:"negate,omitempty"` // true → NOT IN / NOT EXISTS
+}
+
+// MongoGroupStage 描述 $group 聚合阶段：Keys 为分组字段，Accumulators 为聚合输出。
+type MongoGroupStage struct {
+	Keys         []string                `json:"keys,omitempty"`
+	Accumulators []MongoGroupAccumulator `json:"accumulators,omitempty"`
+}
+
+// MongoGroupAccumulator 描述一个 $group 累加器。
+type MongoGroupAccumulator struct {
+	Alias    string `json:"alias"`
+	Function string `json:"function"` // count / sum / avg / min / max
+	Field    string `json:"field,omitempty"`
+	Distinct bool   `json:"distinct,omitempty"`
 }
 
 // MongoSortField 表示 Mongo 排序字段。
@@ -76,6 +118,9 @@ type MongoCompiledWritePlan struct {
 	Upsert            bool                     `json:"upsert,omitempty"`
 	ReturnInsertedID  bool                     `json:"return_inserted_id,omitempty"`
 	ReturnWriteDetail bool                     `json:"return_write_detail,omitempty"`
+	// 乐观锁：update_many 追加 VersionField = ExpectedVersion 过滤并 $inc 版本，未匹配时返回 *StaleEntryError。
+	VersionField    string      `json:"version_field,omitempty"`
+	ExpectedVersion interface{} `json:"expected_version,omitempty"`
 }
 
 func NewMongoQueryConstructor(schema Schema) *MongoQueryConstructor {
@@ -129,6 +174,7 @@ func (qb *MongoQueryConstructor) Count(fieldName ...string) QueryConstructor {
 	}
 	qb.countExpr = &expr
 	qb.selectedCols = nil
+	qb.aggregates = nil
 	qb.limitVal = nil
 	qb.offsetVal = nil
 	qb.orderBys = nil
@@ -156,12 +202,135 @@ func (qb *MongoQueryConstructor) CountWith(builder *CountBuilder) QueryConstruct
 	}
 	qb.countExpr = &expr
 	qb.selectedCols = nil
+	qb.aggregates = nil
 	qb.limitVal = nil
 	qb.offsetVal = nil
 	qb.orderBys = nil
 	return qb
 }
 
+func (qb *MongoQueryConstructor) setBuildErr(err error) {
+	if err != nil && qb.buildErr == nil {
+		qb.buildErr = err
+	}
+}
+
+// GroupBy 设置分组字段，编译为 $group 阶段的 _id。
+func (qb *MongoQueryConstructor) GroupBy(fields ...string) QueryConstructor {
+	qb.setBuildErr(requireQueryFeature(queryFeaturesForBackend("mongodb"), "mongodb", "group_by"))
+	for _, field := range fields {
+		if trimmed := strings.TrimSpace(field); trimmed != "" {
+			qb.groupBys = append(qb.groupBys, trimmed)
+		}
+	}
+	return qb
+}
+
+// Having 按 Mongo 查询特性声明校验；当前特性集未声明 HAVING 支持时在 Build 阶段报错。
+func (qb *MongoQueryConstructor) Having(condition Condition) QueryConstructor {
+	if condition == nil {
+		return qb
+	}
+	qb.setBuildErr(requireQueryFeature(queryFeaturesForBackend("mongodb"), "mongodb", "having"))
+	return qb
+}
+
+// Aggregate 追加聚合投影，编译为 $group 累加器。
+func (qb *MongoQueryConstructor) Aggregate(builders ...*AggregateBuilder) QueryConstructor {
+	for _, builder := range builders {
+		agg, err := builder.toIR()
+		if err != nil {
+			qb.setBuildErr(err)
+			continue
+		}
+		qb.aggregates = append(qb.aggregates, agg)
+	}
+	qb.countExpr = nil
+	return qb
+}
+
+// Union / UnionAll / Intersect / Except 按 Mongo 查询特性声明校验；当前特性集未声明集合运算支持时在 Build 阶段报错。
+func (qb *MongoQueryConstructor) Union(other QueryConstructor) QueryConstructor {
+	return qb.rejectSetOperation(SetOperationUnion)
+}
+
+func (qb *MongoQueryConstructor) UnionAll(other QueryConstructor) QueryConstructor {
+	return qb.rejectSetOperation(SetOperationUnionAll)
+}
+
+func (qb *MongoQueryConstructor) Intersect(other QueryConstructor) QueryConstructor {
+	return qb.rejectSetOperation(SetOperationIntersect)
+}
+
+func (qb *MongoQueryConstructor) Except(other QueryConstructor) QueryConstructor {
+	return qb.rejectSetOperation(SetOperationExcept)
+}
+
+// With MongoDB 查询构造器不支持 CTE（层级遍历可使用 $graphLookup 原生聚合）。
+func (qb *MongoQueryConstructor) With(ctes ...*CTEBuilder) QueryConstructor {
+	for _, cte := range ctes {
+		feature := "cte"
+		if cte.IsRecursive() {
+			feature = "recursive_cte"
+		}
+		err := requireQueryFeature(queryFeaturesForBackend("mongodb"), "mongodb", feature)
+		if err == nil {
+			err = fmt.Errorf("mongo query constructor does not support %s", feature)
+		}
+		qb.setBuildErr(err)
+	}
+	return qb
+}
+
+// Distinct 按投影字段去重；未 Select 字段时文档本身唯一（_id），不追加去重阶段。
+func (qb *MongoQueryConstructor) Distinct() QueryConstructor {
+	qb.distinct = true
+	return qb
+}
+
+// DistinctOn 按指定字段去重，每组保留排序后的第一个文档（$group + $first 改写）。
+func (qb *MongoQueryConstructor) DistinctOn(fields ...string) QueryConstructor {
+	if len(fields) == 0 {
+		qb.setBuildErr(fmt.Errorf("distinct on requires at least one field"))
+		return qb
+	}
+	for _, field := range fields {
+		if trimmed := strings.TrimSpace(field); trimmed != "" {
+			qb.distinctOn = append(qb.distinctOn, trimmed)
+		}
+	}
+	return qb
+}
+
+// Lock MongoDB 无行级锁语义（写冲突由文档级并发控制处理）。
+func (qb *MongoQueryConstructor) Lock(lock *RowLock) QueryConstructor {
+	err := requireQueryFeature(queryFeaturesForBackend("mongodb"), "mongodb", "row_lock")
+	if err == nil {
+		err = fmt.Errorf("mongo query constructor does not support row locks")
+	}
+	qb.setBuildErr(err)
+	return qb
+}
+
+// Window MongoDB 查询构造器暂不支持窗口函数投影（$setWindowFields 未接入）。
+func (qb *MongoQueryConstructor) Window(builders ...*WindowBuilder) QueryConstructor {
+	err := requireQueryFeature(queryFeaturesForBackend("mongodb"), "mongodb", "window_func")
+	if err == nil {
+		err = fmt.Errorf("mongo query constructor does not support window functions")
+	}
+	qb.setBuildErr(err)
+	return qb
+}
+
+func (qb *MongoQueryConstructor) rejectSetOperation(operator QuerySetOperator) QueryConstructor {
+	err := requireQueryFeature(queryFeaturesForBackend("mongodb"), "mongodb", operator.featureName())
+	if err == nil {
+		err = fmt.Errorf("mongo query constructor does not support %s", strings.ToLower(string(operator)))
+	}
+	qb.setBuildErr(err)
+	return qb
+}
+
 func (qb *MongoQueryConstructor) OrderBy(field string, direction string) QueryConstructor {
 	direction = strings.ToUpper(strings.TrimSpace(direction))
 	if direction != "DESC" {
@@ -291,6 +460,11 @@ func (qb *MongoQueryConstructor) Build(ctx context.Context) (string, []interface
 			}
 			plan.Filter = filter
 		}
+		if strings.TrimSpace(plan.VersionField) != "" {
+			if err := applyMongoOptimisticLock(&plan); err != nil {
+				return "", nil, err
+			}
+		}
 		payload, err := json.Marshal(plan)
 		if err != nil {
 			return "", nil, fmt.Errorf("failed to marshal mongo write plan: %w", err)
@@ -298,6 +472,10 @@ func (qb *MongoQueryConstructor) Build(ctx context.Context) (string, []interface
 		return mongoCompiledWritePrefix + string(payload), nil, nil
 	}
 
+	if qb.buildErr != nil {
+		return "", nil, qb.buildErr
+	}
+
 	if qb.countExpr != nil {
 		return "", nil, fmt.Errorf("mongo query constructor does not support SQL-style Count build; use Mongo aggregation pipeline")
 	}
@@ -315,8 +493,23 @@ func (qb *MongoQueryConstructor) Build(ctx context.Context) (string, []interface
 	return mongoCompiledQueryPrefix + string(payload), nil, nil
 }
 
+// SelectCount 以 CountDocuments 统计匹配文档数；含 lookup / group / 子查询 / 去重阶段的查询不支持。
 func (qb *MongoQueryConstructor) SelectCount(ctx context.Context, repo *Repository) (int64, error) {
-	return 0, fmt.Errorf("mongo query constructor does not support SelectCount via SQL-style API")
+	if repo == nil {
+		return 0, fmt.Errorf("repository is nil")
+	}
+	adapter, ok := repo.GetAdapter().(*MongoAdapter)
+	if !ok {
+		return 0, fmt.Errorf("mongo SelectCount requires MongoAdapter")
+	}
+	plan, err := qb.BuildFindPlan()
+	if err != nil {
+		return 0, err
+	}
+	if len(plan.Lookups) > 0 || plan.Group != nil || len(plan.Subqueries) > 0 || plan.Distinct != nil {
+		return 0, fmt.Errorf("mongo SelectCount does not support lookup, group, subquery or distinct stages")
+	}
+	return adapter.countDocuments(ctx, plan.Collection, plan.Filter)
 }
 
 func (qb *MongoQueryConstructor) Upsert(ctx context.Context, repo *Repository, cs *Changeset, conflictColumns ...string) (sql.Result, error) {
@@ -353,6 +546,17 @@ func (qb *MongoQueryConstructor) DeleteMany() *MongoQueryConstructor {
 	return qb
 }
 
+// OptimisticLock 为 UpdateMany 写入计划启用乐观锁：仅更新 versionField 等于 expected 的文档并递增版本。
+// 未匹配任何文档时执行返回 *StaleEntryError。
+func (qb *MongoQueryConstructor) OptimisticLock(versionField string, expected interface{}) *MongoQueryConstructor {
+	if qb.writePlan == nil {
+		qb.writePlan = &MongoCompiledWritePlan{}
+	}
+	qb.writePlan.VersionField = strings.TrimSpace(versionField)
+	qb.writePlan.ExpectedVersion = expected
+	return qb
+}
+
 // ReturnInsertedID 请求在写入结果中附加 inserted_id / inserted_ids（仅插入操作有效）。
 func (qb *MongoQueryConstructor) ReturnInsertedID() *MongoQueryConstructor {
 	if qb.writePlan == nil {
@@ -371,14 +575,56 @@ func (qb *MongoQueryConstructor) ReturnWriteDetail() *MongoQueryConstructor {
 	return qb
 }
 
+func applyMongoOptimisticLock(plan *MongoCompiledWritePlan) error {
+	if plan.Operation != "update_many" {
+		return fmt.Errorf("mongo optimistic lock only supports update_many write plans")
+	}
+	if plan.Upsert {
+		return fmt.Errorf("mongo optimistic lock cannot be combined with upsert")
+	}
+	if plan.ExpectedVersion == nil {
+		return fmt.Errorf("optimistic lock requires the current version value")
+	}
+	if len(plan.Filter) == 0 {
+		return fmt.Errorf("mongo optimistic lock requires a filter")
+	}
+
+	plan.Filter = map[string]interface{}{"$and": []interface{}{
+		plan.Filter,
+		map[string]interface{}{plan.VersionField: plan.ExpectedVersion},
+	}}
+	update := make(map[string]interface{}, len(plan.Update)+1)
+	for key, value := range plan.Update {
+		update[key] = value
+	}
+	if set, ok := update["$set"].(map[string]interface{}); ok {
+		if _, exists := set[plan.VersionField]; exists {
+			trimmed := make(map[string]interface{}, len(set))
+			for key, value := range set {
+				if key != plan.VersionField {
+					trimmed[key] = value
+				}
+			}
+			update["$set"] = trimmed
+		}
+	}
+	update["$inc"] = map[string]interface{}{plan.VersionField: 1}
+	plan.Update = update
+	return nil
+}
+
 func (qb *MongoQueryConstructor) buildFilter() (map[string]interface{}, error) {
+	return buildMongoFilter(qb.conditions)
+}
+
+func buildMongoFilter(conditions []Condition) (map[string]interface{}, error) {
 	filter := map[string]interface{}{}
-	if len(qb.conditions) == 0 {
+	if len(conditions) == 0 {
 		return filter, nil
 	}
 
-	andConditions := make([]map[string]interface{}, 0, len(qb.conditions))
-	for _, cond := range qb.conditions {
+	andConditions := make([]map[string]interface{}, 0, len(conditions))
+	for _, cond := range conditions {
 		translated, err := translateMongoCondition(cond)
 		if err != nil {
 			return nil, err
@@ -400,12 +646,34 @@ func (qb *MongoQueryConstructor) BuildFindPlan() (*MongoCompiledFindPlan, error)
 	if qb == nil || qb.schema == nil {
 		return nil, fmt.Errorf("mongo query constructor schema is required")
 	}
+	if qb.buildErr != nil {
+		return nil, qb.buildErr
+	}
 	collection := strings.TrimSpace(qb.schema.TableName())
 	if collection == "" {
 		return nil, fmt.Errorf("mongo collection name is required")
 	}
 
-	filter, err := qb.buildFilter()
+	// 顶层子查询条件编译为 $lookup + $match 阶段，其余条件进入 $match 过滤器。
+	plainConditions := make([]Condition, 0, len(qb.conditions))
+	subqueries := make([]MongoSubqueryStage, 0)
+	for _, cond := range qb.conditions {
+		sub, ok := cond.(*SubqueryCondition)
+		if !ok {
+			plainConditions = append(plainConditions, cond)
+			continue
+		}
+		stage, err := qb.buildSubqueryStage(sub, len(subqueries)+1)
+		if err != nil {
+			return nil, err
+		}
+		subqueries = append(subqueries, *stage)
+	}
+
+	if condition := softDeleteScopeCondition(qb.schema, qb.softDeleted, plainConditions, ""); condition != nil {
+		plainConditions = append(plainConditions, condition)
+	}
+	filter, err := buildMongoFilter(plainConditions)
 	if err != nil {
 		return nil, err
 	}
@@ -445,6 +713,52 @@ func (qb *MongoQueryConstructor) BuildFindPlan() (*MongoCompiledFindPlan, error)
 		}
 	}
 
+	var group *MongoGroupStage
+	if len(qb.groupBys) > 0 || len(qb.aggregates) > 0 {
+		group = &MongoGroupStage{Keys: append([]string(nil), qb.groupBys...)}
+		for _, agg := range qb.aggregates {
+			field := strings.TrimSpace(agg.Field)
+			if field == "*" {
+				field = ""
+			}
+			group.Accumulators = append(group.Accumulators, MongoGroupAccumulator{
+				Alias:    agg.Alias,
+				Function: string(agg.Func),
+				Field:    field,
+				Distinct: agg.Distinct,
+			})
+		}
+		// 分组输出由 $group 决定，不再应用字段投影。
+		projection = nil
+	}
+
+	var distinct *MongoDistinctStage
+	switch {
+	case len(qb.distinctOn) > 0:
+		if group != nil {
+			return nil, fmt.Errorf("distinct on cannot be combined with GROUP BY or aggregates")
+		}
+		distinct = &MongoDistinctStage{Keys: append([]string(nil), qb.distinctOn...)}
+	case qb.distinct && group == nil && len(projection) > 0:
+		distinct = &MongoDistinctStage{Keys: append([]string(nil), projection...)}
+	}
+
+	var preloads []map[string]interface{}
+	if len(qb.preloads) > 0 {
+		if group != nil {
+			return nil, fmt.Errorf("preload cannot be combined with GROUP BY or aggregates")
+		}
+		preloads, err = buildMongoPreloadPipeline(qb.schema, qb.preloads, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, preload := range qb.preloads {
+			if len(projection) > 0 && !containsString(projection, preload.Name) {
+				projection = append(projection, preload.Name)
+			}
+		}
+	}
+
 	return &MongoCompiledFindPlan{
 		Collection: collection,
 		Filter:     filter,
@@ -453,9 +767,226 @@ func (qb *MongoQueryConstructor) BuildFindPlan() (*MongoCompiledFindPlan, error)
 		Limit:      qb.limitVal,
 		Offset:     qb.offsetVal,
 		Lookups:    lookups,
+		Group:      group,
+		Subqueries: subqueries,
+		Distinct:   distinct,
+		Preloads:   preloads,
 	}, nil
 }
 
+// buildSubqueryStage 将子查询条件编译为关联 $lookup 参数：
+//   - in / not_in：外层字段与子查询首个投影字段相等关联
+//   - exists / not_exists：必须在子查询中以 RefField 等值条件显式引用外层字段，不做关系推断，
+//     以免与 SQL / Cypher 的非关联 EXISTS 语义不一致
+//
+// Mongo 无标量子查询语义，比较操作符返回错误。
+func (qb *MongoQueryConstructor) buildSubqueryStage(cond *SubqueryCondition, index int) (*MongoSubqueryStage, error) {
+	if err := requireQueryFeature(queryFeaturesForBackend("mongodb"), "mongodb", "subquery"); err != nil {
+		return nil, err
+	}
+	if cond.Query == nil {
+		return nil, fmt.Errorf("subquery condition requires a query constructor")
+	}
+	inner, ok := cond.Query.GetNativeBuilder().(*MongoQueryConstructor)
+	if !ok {
+		return nil, fmt.Errorf("mongo subquery requires a mongo query constructor, got %T", cond.Query.GetNativeBuilder())
+	}
+	if inner.schema == nil || strings.TrimSpace(inner.schema.TableName()) == "" {
+		return nil, fmt.Errorf("mongo subquery collection name is required")
+	}
+	if inner.buildErr != nil {
+		return nil, inner.buildErr
+	}
+	innerTable := strings.TrimSpace(inner.schema.TableName())
+	outerTable := strings.TrimSpace(qb.schema.TableName())
+
+	stage := &MongoSubqueryStage{
+		From:   innerTable,
+		As:     fmt.Sprintf("_sq%d", index),
+		Negate: cond.Operator == "not_in" || cond.Operator == "not_exists",
+	}
+
+	innerConditions := make([]Condition, 0, len(inner.conditions))
+	for _, c := range inner.conditions {
+		simple, ok := c.(*SimpleCondition)
+		if ok && simple.Operator == "eq" && stage.LocalField == "" {
+			if ref, isRef := simple.Value.(FieldRef); isRef && !mongoFieldHasPrefix(ref.Name, innerTable) {
+				stage.LocalField = trimMongoFieldPrefix(ref.Name, outerTable)
+				stage.ForeignField = trimMongoFieldPrefix(simple.Field, innerTable)
+				continue
+			}
+		}
+		innerConditions = append(innerConditions, c)
+	}
+	filter, err := buildMongoFilter(innerConditions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build mongo subquery filter: %w", err)
+	}
+	stage.Filter = filter
+
+	switch cond.Operator {
+	case "in", "not_in":
+		if strings.TrimSpace(cond.Field) == "" {
+			return nil, fmt.Errorf("subquery operator %s requires a field", cond.Operator)
+		}
+		if len(inner.selectedCols) != 1 || strings.TrimSpace(inner.selectedCols[0]) == "" || inner.selectedCols[0] == "*" {
+			return nil, fmt.Errorf("mongo subquery operator %s requires exactly one selected field", cond.Operator)
+		}
+		if stage.LocalField != "" {
+			return nil, fmt.Errorf("mongo subquery operator %s does not support field references", cond.Operator)
+		}
+		stage.LocalField = trimMongoFieldPrefix(cond.Field, outerTable)
+		stage.ForeignField = strings.TrimSpace(inner.selectedCols[0])
+	case "exists", "not_exists":
+		if stage.LocalField == "" {
+			return nil, fmt.Errorf("mongo subquery operator %s requires an explicit correlation via RefField", cond.Operator)
+		}
+	default:
+		return nil, fmt.Errorf("mongo does not support scalar subquery operator %s; use in or exists", cond.Operator)
+	}
+	return stage, nil
+}
+
+// buildMongoSubqueryPipeline 将子查询阶段展开为 $lookup（pipeline 形式，limit 1）→ $match 存在性 → $unset 临时字段。
+func buildMongoSubqueryPipeline(stages []MongoSubqueryStage) []map[string]interface{} {
+	pipeline := make([]map[string]interface{}, 0, len(stages)*3)
+	for _, sq := range stages {
+		inner := make([]interface{}, 0, 3)
+		lookup := map[string]interface{}{"from": sq.From, "as": sq.As}
+		if sq.LocalField != "" && sq.ForeignField != "" {
+			lookup["let"] = map[string]interface{}{"sq_local": "$" + sq.LocalField}
+			inner = append(inner, map[string]interface{}{
+				"$match": map[string]interface{}{
+					"$expr": map[string]interface{}{"$eq": []interface{}{"$" + sq.ForeignField, "$$sq_local"}},
+				},
+			})
+		}
+		if len(sq.Filter) > 0 {
+			inner = append(inner, map[string]interface{}{"$match": sq.Filter})
+		}
+		inner = append(inner, map[string]interface{}{"$limit": 1})
+		lookup["pipeline"] = inner
+
+		pipeline = append(pipeline,
+			map[string]interface{}{"$lookup": lookup},
+			map[string]interface{}{"$match": map[string]interface{}{sq.As + ".0": map[string]interface{}{"$exists": !sq.Negate}}},
+			map[string]interface{}{"$unset": sq.As},
+		)
+	}
+	return pipeline
+}
+
+func mongoFieldHasPrefix(field, table string) bool {
+	_, ok := splitMongoFieldPrefix(field, table)
+	return ok
+}
+
+func trimMongoFieldPrefix(field, table string) string {
+	if rest, ok := splitMongoFieldPrefix(field, table); ok {
+		return rest
+	}
+	return strings.TrimSpace(field)
+}
+
+func splitMongoFieldPrefix(field, table string) (string, bool) {
+	trimmed := strings.TrimSpace(field)
+	table = strings.TrimSpace(table)
+	if table == "" || !strings.HasPrefix(strings.ToLower(trimmed), strings.ToLower(table)+".") {
+		return "", false
+	}
+	return trimmed[len(table)+1:], true
+}
+
+// buildMongoGroupPipeline 将 $group 计划展开为聚合管道阶段：
+// $group（DISTINCT 先用 $addToSet 收集）→ $project（展开 _id 为分组字段并计算 DISTINCT 结果）。
+// buildMongoDistinctPipeline 将去重阶段编译为 $group（每组取 $first: $$ROOT）+ $replaceRoot。
+// 调用方需在其之前追加 $sort 以确定每组保留的文档，并在其之后重新排序（$group 不保证输出顺序）。
+func buildMongoDistinctPipeline(distinct *MongoDistinctStage) []map[string]interface{} {
+	if distinct == nil || len(distinct.Keys) == 0 {
+		return nil
+	}
+	keys := map[string]interface{}{}
+	for _, key := range distinct.Keys {
+		keys[mongoGroupKeyName(key)] = "$" + key
+	}
+	return []map[string]interface{}{
+		{"$group": map[string]interface{}{"_id": keys, "_doc": map[string]interface{}{"$first": "$$ROOT"}}},
+		{"$replaceRoot": map[string]interface{}{"newRoot": "$_doc"}},
+	}
+}
+
+func buildMongoGroupPipeline(group *MongoGroupStage) []map[string]interface{} {
+	if group == nil {
+		return nil
+	}
+
+	var id interface{}
+	switch len(group.Keys) {
+	case 0:
+		id = nil
+	case 1:
+		id = "$" + group.Keys[0]
+	default:
+		keys := map[string]interface{}{}
+		for _, key := range group.Keys {
+			keys[mongoGroupKeyName(key)] = "$" + key
+		}
+		id = keys
+	}
+
+	groupSpec := map[string]interface{}{"_id": id}
+	project := map[string]interface{}{"_id": 0}
+	switch len(group.Keys) {
+	case 0:
+	case 1:
+		project[mongoGroupKeyName(group.Keys[0])] = "$_id"
+	default:
+		for _, key := range group.Keys {
+			name := mongoGroupKeyName(key)
+			project[name] = "$_id." + name
+		}
+	}
+
+	for _, acc := range group.Accumulators {
+		fn := strings.ToLower(strings.TrimSpace(acc.Function))
+		field := strings.TrimSpace(acc.Field)
+		if acc.Distinct && field != "" {
+			groupSpec[acc.Alias] = map[string]interface{}{"$addToSet": "$" + field}
+			switch fn {
+			case "count":
+				project[acc.Alias] = map[string]interface{}{"$size": "$" + acc.Alias}
+			default:
+				project[acc.Alias] = map[string]interface{}{"$" + fn: "$" + acc.Alias}
+			}
+			continue
+		}
+
+		switch fn {
+		case "count":
+			if field == "" {
+				groupSpec[acc.Alias] = map[string]interface{}{"$sum": 1}
+			} else {
+				// COUNT(field) 仅统计非空值。
+				groupSpec[acc.Alias] = map[string]interface{}{"$sum": map[string]interface{}{
+					"$cond": []interface{}{map[string]interface{}{"$gt": []interface{}{"$" + field, nil}}, 1, 0},
+				}}
+			}
+		default:
+			groupSpec[acc.Alias] = map[string]interface{}{"$" + fn: "$" + field}
+		}
+		project[acc.Alias] = 1
+	}
+
+	return []map[string]interface{}{
+		{"$group": groupSpec},
+		{"$project": project},
+	}
+}
+
+func mongoGroupKeyName(field string) string {
+	return strings.ReplaceAll(strings.TrimSpace(field), ".", "_")
+}
+
 // resolveMongoLookups 从关系注册表或 FK 约束推断 $lookup 阶段参数。
 // 推断优先级：① source→join 直接关系声明 → ② join→source 反向关系声明 → ③ FK 约束。
 // 若无法确定关联字段则返回 nil（跳过该连接）。
@@ -596,6 +1127,21 @@ func translateMongoCondition(condition Condition) (map[string]interface{}, error
 		if field == "" {
 			return nil, fmt.Errorf("mongo condition field cannot be empty")
 		}
+		if ref, ok := c.Value.(FieldRef); ok {
+			// 字段间比较 → $expr
+			op := strings.ToLower(strings.TrimSpace(c.Operator))
+			switch op {
+			case "eq", "ne", "gt", "lt", "gte", "lte":
+				return map[string]interface{}{
+					"$expr": map[string]interface{}{"$" + op: []interface{}{"$" + field, "$" + strings.TrimSpace(ref.Name)}},
+				}, nil
+			default:
+				return nil, fmt.Errorf("mongo operator %s does not support field reference values", c.Operator)
+			}
+		}
+		if isExtendedConditionOperator(c.Operator) {
+			return translateMongoExtendedCondition(field, c)
+		}
 		switch strings.ToLower(strings.TrimSpace(c.Operator)) {
 		case "eq":
 			return map[string]interface{}{field: c.Value}, nil
@@ -665,6 +1211,8 @@ func translateMongoCondition(condition Condition) (map[string]interface{}, error
 			return nil, err
 		}
 		return map[string]interface{}{"$nor": []map[string]interface{}{translated}}, nil
+	case *SubqueryCondition:
+		return nil, fmt.Errorf("mongo subquery conditions are only supported as top-level Where conditions of a find plan")
 	default:
 		return nil, fmt.Errorf("mongo condition type not supported: %T", condition)
 	}
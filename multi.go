@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Multi 多步事务管道（参考 Ecto.Multi）：按登记顺序在同一事务内执行命名步骤，
+// 后续步骤通过 MultiResults 按名称读取前序步骤的结果。由 Repository.ExecuteMulti 执行。
+type Multi struct {
+	steps []multiStep
+	err   error // 登记阶段的错误（步骤名为空或重复），执行时返回
+}
+
+type multiStep struct {
+	name   string
+	schema Schema
+	run    func(tx *ChangesetExecutor, results MultiResults) (interface{}, error)
+}
+
+// MultiResults 已执行步骤的结果（步骤名 -> 结果）。
+// Insert / Update 步骤为写入后的 *Changeset（插入生成的主键已同步到 Data），Delete 步骤为删除行数，Run 步骤为函数返回值。
+type MultiResults map[string]interface{}
+
+// Changeset 返回 Insert / Update 步骤写入的 Changeset；步骤不存在或结果不是 Changeset 时返回 nil。
+func (r MultiResults) Changeset(name string) *Changeset {
+	cs, _ := r[name].(*Changeset)
+	return cs
+}
+
+func (r MultiResults) clone() MultiResults {
+	out := make(MultiResults, len(r))
+	for name, value := range r {
+		out[name] = value
+	}
+	return out
+}
+
+// MultiError Multi 某一步骤失败：事务已回滚，Results 为失败前已执行步骤的结果。
+type MultiError struct {
+	Step string
+	// Value 失败步骤的值（Insert / Update 步骤为携带错误信息的 Changeset）。
+	Value   interface{}
+	Err     error
+	Results MultiResults
+}
+
+func (e *MultiError) Error() string {
+	return fmt.Sprintf("multi step %s failed: %v", e.Step, e.Err)
+}
+
+func (e *MultiError) Unwrap() error {
+	return e.Err
+}
+
+// NewMulti 创建空的多步事务管道。
+func NewMulti() *Multi {
+	return &Multi{steps: make([]multiStep, 0)}
+}
+
+// Insert 登记插入步骤，行为同 ChangesetExecutor.Insert（含 CastAssoc 关联写入）。
+func (m *Multi) Insert(name string, schema Schema, cs *Changeset) *Multi {
+	return m.InsertFunc(name, schema, func(MultiResults) (*Changeset, error) {
+		return cs, nil
+	})
+}
+
+// InsertFunc 登记插入步骤，执行时由 build 根据前序结果构造 Changeset（例如填充外键）。
+func (m *Multi) InsertFunc(name string, schema Schema, build func(results MultiResults) (*Changeset, error)) *Multi {
+	return m.addStep(name, schema, build != nil, func(tx *ChangesetExecutor, results MultiResults) (interface{}, error) {
+		cs, err := build(results)
+		if err != nil {
+			return nil, err
+		}
+		if cs == nil {
+			return nil, fmt.Errorf("changeset cannot be nil")
+		}
+		// insertWithAssocs 在事务内直接写入，并将生成的主键同步到 cs。
+		if _, err := tx.insertWithAssocs(cs); err != nil {
+			return cs, err
+		}
+		return cs, nil
+	})
+}
+
+// Update 登记按主键更新步骤，行为同 ChangesetExecutor.UpdateByID（含乐观锁与关联替换）。
+func (m *Multi) Update(name string, schema Schema, id interface{}, cs *Changeset) *Multi {
+	return m.UpdateFunc(name, schema, func(MultiResults) (interface{}, *Changeset, error) {
+		return id, cs, nil
+	})
+}
+
+// UpdateFunc 登记更新步骤，执行时由 build 根据前序结果返回主键与 Changeset。
+func (m *Multi) UpdateFunc(name string, schema Schema, build func(results MultiResults) (interface{}, *Changeset, error)) *Multi {
+	return m.addStep(name, schema, build != nil, func(tx *ChangesetExecutor, results MultiResults) (interface{}, error) {
+		id, cs, err := build(results)
+		if err != nil {
+			return nil, err
+		}
+		if cs == nil {
+			return nil, fmt.Errorf("changeset cannot be nil")
+		}
+		if _, err := tx.UpdateByID(id, cs); err != nil {
+			return cs, err
+		}
+		return cs, nil
+	})
+}
+
+// Delete 登记按主键删除步骤，结果为删除行数。
+func (m *Multi) Delete(name string, schema Schema, id interface{}) *Multi {
+	return m.DeleteFunc(name, schema, func(MultiResults) (interface{}, error) {
+		return id, nil
+	})
+}
+
+// DeleteFunc 登记删除步骤，执行时由 build 根据前序结果返回主键。
+func (m *Multi) DeleteFunc(name string, schema Schema, build func(results MultiResults) (interface{}, error)) *Multi {
+	return m.addStep(name, schema, build != nil, func(tx *ChangesetExecutor, results MultiResults) (interface{}, error) {
+		id, err := build(results)
+		if err != nil {
+			return nil, err
+		}
+		res, err := tx.DeleteByID(id)
+		if err != nil {
+			return nil, err
+		}
+		return res.RowsAffected()
+	})
+}
+
+// Run 登记任意函数步骤，返回值作为步骤结果。
+// tx 绑定 Multi 中首个写入步骤的 Schema，写入其他表时使用 tx.WithSchema；
+// Multi 仅含 Run 步骤时 tx 未绑定 Schema，直接写入返回错误，需先调用 tx.WithSchema。
+func (m *Multi) Run(name string, fn func(tx *ChangesetExecutor, results MultiResults) (interface{}, error)) *Multi {
+	return m.addStep(name, nil, fn != nil, fn)
+}
+
+// addStep 登记步骤；hasFunc 为 false（调用方传入的函数为 nil）、步骤名为空或重复时记录错误。
+func (m *Multi) addStep(name string, schema Schema, hasFunc bool, run func(*ChangesetExecutor, MultiResults) (interface{}, error)) *Multi {
+	if m.err != nil {
+		return m
+	}
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		m.err = fmt.Errorf("multi step name cannot be empty")
+	case !hasFunc:
+		m.err = fmt.Errorf("multi step %s: function cannot be nil", name)
+	case m.hasStep(name):
+		m.err = fmt.Errorf("multi step %s is already defined", name)
+	default:
+		m.steps = append(m.steps, multiStep{name: name, schema: schema, run: run})
+	}
+	return m
+}
+
+func (m *Multi) hasStep(name string) bool {
+	for _, step := range m.steps {
+		if step.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Names 返回按执行顺序排列的步骤名。
+func (m *Multi) Names() []string {
+	names := make([]string, 0, len(m.steps))
+	for _, step := range m.steps {
+		names = append(names, step.name)
+	}
+	return names
+}
+
+func (m *Multi) baseSchema() Schema {
+	for _, step := range m.steps {
+		if step.schema != nil {
+			return step.schema
+		}
+	}
+	return nil
+}
+
+// ExecuteMulti 在单个事务内按顺序执行 Multi 的全部步骤；在事务 Repository（ChangesetExecutor.Repository）上调用时使用保存点。
+// 任一步骤失败时回滚并返回 *MultiError（失败步骤名、失败值与此前步骤的结果），同时返回已执行步骤的部分结果。
+func (r *Repository) ExecuteMulti(ctx context.Context, multi *Multi) (MultiResults, error) {
+	if r == nil {
+		return nil, fmt.Errorf("repository is nil")
+	}
+	if multi == nil {
+		return nil, fmt.Errorf("multi cannot be nil")
+	}
+	if multi.err != nil {
+		return nil, multi.err
+	}
+
+	results := make(MultiResults, len(multi.steps))
+	err := r.withChangesetTx(ctx, multi.baseSchema(), func(tx *ChangesetExecutor) error {
+		for _, step := range multi.steps {
+			value, err := step.run(tx.WithSchema(step.schema), results)
+			if err != nil {
+				return &MultiError{Step: step.name, Value: value, Err: err, Results: results.clone()}
+			}
+			results[step.name] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return results, err
+	}
+	return results, nil
+}
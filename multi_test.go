@@ -0,0 +1,167 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMultiSQLite(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, sku TEXT NOT NULL, qty INTEGER NOT NULL)",
+		"CREATE TABLE inventory (id INTEGER PRIMARY KEY, sku TEXT NOT NULL, stock INTEGER NOT NULL)",
+		"CREATE TABLE ledger (id INTEGER PRIMARY KEY AUTOINCREMENT, order_id INTEGER NOT NULL, amount INTEGER NOT NULL)",
+		"INSERT INTO inventory (id, sku, stock) VALUES (1, 'pen', 10)",
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+	orders := NewBaseSchema("orders")
+	orders.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	orders.AddField(NewField("sku", TypeString).Build())
+	orders.AddField(NewField("qty", TypeInteger).Build())
+	inventory := NewBaseSchema("inventory")
+	inventory.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	inventory.AddField(NewField("stock", TypeInteger).Build())
+	ledger := NewBaseSchema("ledger")
+	ledger.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	ledger.AddField(NewField("order_id", TypeInteger).Build())
+	ledger.AddField(NewField("amount", TypeInteger).Build())
+	count := func(query string) int {
+		var n int
+		if err := repo.QueryRow(ctx, query).Scan(&n); err != nil {
+			t.Fatalf("count %q failed: %v", query, err)
+		}
+		return n
+	}
+
+	placeOrder := func(qty int, fail bool) *Multi {
+		return NewMulti().
+			Insert("order", orders, NewChangeset(orders).Cast(map[string]interface{}{"sku": "pen", "qty": qty})).
+			Run("stock", func(tx *ChangesetExecutor, results MultiResults) (interface{}, error) {
+				var stock int
+				if err := tx.Repository().QueryRow(ctx, "SELECT stock FROM inventory WHERE id = 1").Scan(&stock); err != nil {
+					return nil, err
+				}
+				return stock, nil
+			}).
+			UpdateFunc("inventory", inventory, func(results MultiResults) (interface{}, *Changeset, error) {
+				stock := results["stock"].(int)
+				if stock < qty {
+					return nil, nil, fmt.Errorf("insufficient stock: %d", stock)
+				}
+				return 1, NewChangeset(inventory).Cast(map[string]interface{}{"stock": stock - qty}), nil
+			}).
+			InsertFunc("ledger", ledger, func(results MultiResults) (*Changeset, error) {
+				if fail {
+					return nil, errors.New("ledger closed")
+				}
+				orderID := results.Changeset("order").Get("id")
+				return NewChangeset(ledger).Cast(map[string]interface{}{"order_id": orderID, "amount": qty * 3}), nil
+			})
+	}
+
+	results, err := repo.ExecuteMulti(ctx, placeOrder(4, false))
+	if err != nil {
+		t.Fatalf("execute multi failed: %v", err)
+	}
+	if got := strings.Join(placeOrder(1, false).Names(), ","); got != "order,stock,inventory,ledger" {
+		t.Fatalf("unexpected step order: %s", got)
+	}
+	if results.Changeset("order").Get("id") == nil || results["stock"] != 10 {
+		t.Fatalf("unexpected results: %v", results)
+	}
+	if count("SELECT stock FROM inventory WHERE id = 1") != 6 || count("SELECT COUNT(*) FROM ledger WHERE amount = 12") != 1 {
+		t.Fatalf("expected all steps committed")
+	}
+
+	// 失败步骤回滚整个事务，并返回失败步骤名与此前步骤的结果。
+	results, err = repo.ExecuteMulti(ctx, placeOrder(2, true))
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) || multiErr.Step != "ledger" || !strings.Contains(err.Error(), "ledger closed") {
+		t.Fatalf("expected MultiError on ledger step, got: %v", err)
+	}
+	if len(multiErr.Results) != 3 || multiErr.Results.Changeset("order") == nil || len(results) != 3 {
+		t.Fatalf("expected partial results of 3 steps, got %v", multiErr.Results)
+	}
+	if count("SELECT COUNT(*) FROM orders") != 1 || count("SELECT stock FROM inventory WHERE id = 1") != 6 {
+		t.Fatalf("expected failed multi rolled back")
+	}
+
+	invalid := NewChangeset(orders).Cast(map[string]interface{}{"qty": 1})
+	invalid.ValidateRequired([]string{"sku"})
+	_, err = repo.ExecuteMulti(ctx, NewMulti().Insert("order", orders, invalid).Delete("cleanup", inventory, 1))
+	if !errors.As(err, &multiErr) || multiErr.Step != "order" || multiErr.Value != invalid {
+		t.Fatalf("expected invalid changeset as failed value, got: %v", err)
+	}
+	if count("SELECT COUNT(*) FROM inventory") != 1 {
+		t.Fatalf("expected delete step not executed")
+	}
+
+	// 在事务 Repository 上执行时使用保存点，失败只回滚 Multi 自身。
+	err = repo.WithChangeset(ctx, orders, func(tx *ChangesetExecutor) error {
+		if _, err := tx.Insert(NewChangeset(orders).Cast(map[string]interface{}{"sku": "ink", "qty": 1})); err != nil {
+			return err
+		}
+		if _, err := tx.Repository().ExecuteMulti(ctx, placeOrder(100, false)); err == nil {
+			return errors.New("expected insufficient stock")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("outer transaction failed: %v", err)
+	}
+	if count("SELECT COUNT(*) FROM orders") != 2 || count("SELECT stock FROM inventory WHERE id = 1") != 6 {
+		t.Fatalf("expected only nested multi rolled back")
+	}
+	t.Logf("✓ multi-step transactional pipeline")
+}
+
+func TestMultiStepValidation(t *testing.T) {
+	schema := NewBaseSchema("orders")
+	if _, err := (&Repository{}).ExecuteMulti(context.Background(), NewMulti().Delete("a", schema, 1).Delete("a", schema, 2)); err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Fatalf("expected duplicate step error, got: %v", err)
+	}
+	if _, err := (&Repository{}).ExecuteMulti(context.Background(), NewMulti().Run("noop", nil)); err == nil || !strings.Contains(err.Error(), "function cannot be nil") {
+		t.Fatalf("expected nil function error, got: %v", err)
+	}
+	if _, err := (&Repository{}).ExecuteMulti(context.Background(), NewMulti().Insert(" ", schema, nil)); err == nil || !strings.Contains(err.Error(), "name cannot be empty") {
+		t.Fatalf("expected empty name error, got: %v", err)
+	}
+}
+
+func TestMultiRunOnlyRequiresSchema(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+	users := NewBaseSchema("users")
+	users.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	users.AddField(NewField("name", TypeString).Build())
+	users.AddField(NewField("email", TypeString).Build())
+
+	unbound := NewMulti().Run("write", func(tx *ChangesetExecutor, results MultiResults) (interface{}, error) {
+		return tx.Insert(NewChangeset(users).Cast(map[string]interface{}{"name": "a", "email": "a@x.io"}))
+	})
+	var multiErr *MultiError
+	if _, err := repo.ExecuteMulti(ctx, unbound); !errors.As(err, &multiErr) || !strings.Contains(err.Error(), "no schema") {
+		t.Fatalf("expected schema-less Run step write to fail, got: %v", err)
+	}
+
+	bound := NewMulti().Run("write", func(tx *ChangesetExecutor, results MultiResults) (interface{}, error) {
+		return tx.WithSchema(users).Insert(NewChangeset(users).Cast(map[string]interface{}{"name": "b", "email": "b@x.io"}))
+	})
+	if _, err := repo.ExecuteMulti(ctx, bound); err != nil {
+		t.Fatalf("expected Run step with WithSchema to succeed: %v", err)
+	}
+	var n int
+	if err := repo.QueryRow(ctx, "SELECT COUNT(*) FROM users").Scan(&n); err != nil || n != 1 {
+		t.Fatalf("expected only bound write committed, got %d (%v)", n, err)
+	}
+	t.Logf("✓ Run-only multi requires explicit schema for writes")
+}
@@ -15,9 +15,10 @@ import (
 
 // MySQLAdapter MySQL 数据库适配器
 type MySQLAdapter struct {
-	config *Config
-	db     *gorm.DB
-	sqlDB  *sql.DB
+	config        *Config
+	db            *gorm.DB
+	sqlDB         *sql.DB
+	serverVersion string // Connect 时通过 SELECT VERSION() 读取，用于版本化特性判断
 }
 
 type mysqlScheduledTaskRecord struct {
@@ -146,6 +147,10 @@ func (a *MySQLAdapter) Connect(ctx context.Context, config *Config) error {
 		sqlDB.SetConnMaxIdleTime(5 * time.Minute)
 	}
 
+	if err := sqlDB.QueryRowContext(ctx, "SELECT VERSION()").Scan(&a.serverVersion); err != nil {
+		return fmt.Errorf("failed to detect MySQL server version: %w", err)
+	}
+
 	return nil
 }
 
@@ -685,6 +690,33 @@ func (t *MySQLTx) Rollback(ctx context.Context) error {
 	return t.tx.Rollback()
 }
 
+// Savepoint 创建事务保存点
+func (t *MySQLTx) Savepoint(ctx context.Context, name string) error {
+	if err := validateSavepointName(name); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(ctx, "SAVEPOINT "+name)
+	return err
+}
+
+// RollbackTo 回滚到事务保存点
+func (t *MySQLTx) RollbackTo(ctx context.Context, name string) error {
+	if err := validateSavepointName(name); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+	return err
+}
+
+// ReleaseSavepoint 释放事务保存点
+func (t *MySQLTx) ReleaseSavepoint(ctx context.Context, name string) error {
+	if err := validateSavepointName(name); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
 // Exec 在事务中执行
 func (t *MySQLTx) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	return t.tx.ExecContext(ctx, query, args...)
@@ -701,8 +733,9 @@ func (t *MySQLTx) QueryRow(ctx context.Context, query string, args ...interface{
 }
 
 // GetQueryBuilderProvider 返回查询构造器提供者
+// 方言携带 Connect 时检测到的服务器版本，5.7 等旧版本的窗口函数在应用层计算。
 func (a *MySQLAdapter) GetQueryBuilderProvider() QueryConstructorProvider {
-	return NewDefaultSQLQueryConstructorProvider(NewMySQLDialect())
+	return NewDefaultSQLQueryConstructorProvider(NewMySQLDialectWithVersion(a.serverVersion))
 }
 
 // GetDatabaseFeatures 返回 MySQL 数据库特性
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// IntrospectTables 列出当前数据库（DATABASE()）中的基础表。
+func (a *MySQLAdapter) IntrospectTables(ctx context.Context) ([]string, error) {
+	rows, err := a.Query(ctx, `
+		SELECT TABLE_NAME
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE'
+		ORDER BY TABLE_NAME
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanIntrospectionNames(rows)
+}
+
+// IntrospectSchema 基于 information_schema（COLUMNS / STATISTICS / KEY_COLUMN_USAGE）反查表结构。
+// table 可带库名前缀（如 "legacy.users"），否则使用 DATABASE()。
+func (a *MySQLAdapter) IntrospectSchema(ctx context.Context, table string) (*BaseSchema, error) {
+	schemaName, tableName := splitIntrospectionTableName(table)
+	result := &introspectedTable{name: table}
+
+	rows, err := a.Query(ctx, `
+		SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, EXTRA
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION
+	`, schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			name, columnType, nullable, extra string
+			defaultValue                      sql.NullString
+		)
+		if err := rows.Scan(&name, &columnType, &nullable, &defaultValue, &extra); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		field := &Field{
+			Name:    name,
+			Type:    fieldTypeFromColumnType(columnType),
+			Null:    nullable == "YES",
+			Autoinc: strings.Contains(strings.ToLower(extra), "auto_increment"),
+		}
+		if defaultValue.Valid {
+			field.Default = normalizeIntrospectedDefault(defaultValue.String, field.Type)
+		}
+		result.columns = append(result.columns, field)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = a.Query(ctx, `
+		SELECT kcu.CONSTRAINT_NAME, kcu.COLUMN_NAME, kcu.REFERENCED_TABLE_NAME, kcu.REFERENCED_COLUMN_NAME, rc.DELETE_RULE, rc.UPDATE_RULE
+		FROM information_schema.KEY_COLUMN_USAGE kcu
+		JOIN information_schema.REFERENTIAL_CONSTRAINTS rc
+			ON rc.CONSTRAINT_SCHEMA = kcu.CONSTRAINT_SCHEMA AND rc.CONSTRAINT_NAME = kcu.CONSTRAINT_NAME AND rc.TABLE_NAME = kcu.TABLE_NAME
+		WHERE kcu.TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND kcu.TABLE_NAME = ?
+		ORDER BY kcu.CONSTRAINT_NAME, kcu.ORDINAL_POSITION
+	`, schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys := make(map[string]struct{})
+	for rows.Next() {
+		var name, column, refTable, refColumn, onDelete, onUpdate string
+		if err := rows.Scan(&name, &column, &refTable, &refColumn, &onDelete, &onUpdate); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		foreignKeys[name] = struct{}{}
+		result.addConstraintColumn(TableConstraint{
+			Name:     name,
+			Kind:     ConstraintForeignKey,
+			RefTable: refTable,
+			OnDelete: normalizeReferentialAction(onDelete),
+			OnUpdate: normalizeReferentialAction(onUpdate),
+		}, column, refColumn)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// MySQL 的主键与唯一约束均以索引形式存在；外键自动创建的同名索引不重复记录，函数索引（COLUMN_NAME 为空）跳过
+	rows, err = a.Query(ctx, `
+		SELECT INDEX_NAME, NON_UNIQUE, COLUMN_NAME
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ?
+		ORDER BY INDEX_NAME = 'PRIMARY' DESC, INDEX_NAME, SEQ_IN_INDEX
+	`, schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			name      string
+			nonUnique int
+			column    sql.NullString
+		)
+		if err := rows.Scan(&name, &nonUnique, &column); err != nil {
+			return nil, err
+		}
+		if !column.Valid {
+			continue
+		}
+		if _, ok := foreignKeys[name]; ok {
+			continue
+		}
+		switch {
+		case name == "PRIMARY":
+			result.primaryKey.Fields = append(result.primaryKey.Fields, column.String)
+		case nonUnique == 0:
+			result.addConstraintColumn(TableConstraint{Name: name, Kind: ConstraintUnique}, column.String, "")
+		default:
+			result.addIndexColumn(name, false, column.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result.toSchema()
+}
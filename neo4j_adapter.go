@@ -198,6 +198,75 @@ func (a *Neo4jAdapter) QueryCypher(ctx context.Context, cypher string, params ma
 	return rows, nil
 }
 
+// openCypherStream 以自动提交的读会话执行 Cypher，返回可逐条拉取的结果流；调用方负责关闭会话。
+// fetchSize > 0 时设置每批从服务端拉取的记录数。
+func (a *Neo4jAdapter) openCypherStream(ctx context.Context, cypher string, params map[string]interface{}, fetchSize int) (neo4j.SessionWithContext, neo4j.ResultWithContext, error) {
+	if a.driver == nil {
+		return nil, nil, fmt.Errorf("neo4j driver not connected")
+	}
+	if strings.TrimSpace(cypher) == "" {
+		return nil, nil, fmt.Errorf("cypher cannot be empty")
+	}
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	config := neo4j.SessionConfig{
+		DatabaseName: a.database,
+		AccessMode:   neo4j.AccessModeRead,
+	}
+	if fetchSize > 0 {
+		config.FetchSize = fetchSize
+	}
+	session := a.driver.NewSession(ctx, config)
+	result, err := session.Run(ctx, cypher, params)
+	if err != nil {
+		_ = session.Close(ctx)
+		return nil, nil, err
+	}
+	return session, result, nil
+}
+
+// execCypherRows 在写事务中执行 Cypher 并返回记录（例如 CREATE ... RETURN）。
+func (a *Neo4jAdapter) execCypherRows(ctx context.Context, cypher string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	if a.driver == nil {
+		return nil, fmt.Errorf("neo4j driver not connected")
+	}
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	session := a.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: a.database,
+		AccessMode:   neo4j.AccessModeWrite,
+	})
+	defer func() { _ = session.Close(ctx) }()
+
+	out, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		result, runErr := tx.Run(ctx, cypher, params)
+		if runErr != nil {
+			return nil, runErr
+		}
+		records, collectErr := result.Collect(ctx)
+		if collectErr != nil {
+			return nil, collectErr
+		}
+		rows := make([]map[string]interface{}, 0, len(records))
+		for _, record := range records {
+			rows = append(rows, record.AsMap())
+		}
+		return rows, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows, ok := out.([]map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("neo4j exec returned unexpected result type: %T", out)
+	}
+	return rows, nil
+}
+
 // ExecCypher 执行写类型 Cypher，并返回写入摘要。
 func (a *Neo4jAdapter) ExecCypher(ctx context.Context, cypher string, params map[string]interface{}) (*CypherWriteSummary, error) {
 	if a.driver == nil {
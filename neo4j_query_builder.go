@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
@@ -11,17 +12,26 @@ import (
 
 // Neo4jQueryConstructor 面向 Neo4j 的 Cypher 查询构造器。
 type Neo4jQueryConstructor struct {
-	schema       Schema
-	compiler     QueryCompiler
-	selectedCols []string
-	countExpr    *string
-	conditions   []Condition
-	orderBys     []OrderBy
-	limitVal     *int
-	offsetVal    *int
-	fromAlias    string
-	joins        []cypherJoinClause
-	customMode   bool
+	schema        Schema
+	compiler      QueryCompiler
+	selectedCols  []string
+	countExpr     *string
+	aggregates    []QueryAggregateIR
+	conditions    []Condition
+	groupBys      []string
+	having        []Condition
+	orderBys      []OrderBy
+	limitVal      *int
+	offsetVal     *int
+	fromAlias     string
+	joins         []cypherJoinClause
+	customMode    bool
+	distinct      bool
+	setOperations []querySetOperation
+	ctes          []*CTEBuilder
+	softDeleted   softDeleteScope
+	preloads      []QueryPreloadIR
+	buildErr      error
 }
 
 type cypherJoinClause struct {
@@ -170,6 +180,7 @@ func (qb *Neo4jQueryConstructor) Count(fieldName ...string) QueryConstructor {
 	}
 	qb.countExpr = &expr
 	qb.selectedCols = nil
+	qb.aggregates = nil
 	qb.limitVal = nil
 	qb.offsetVal = nil
 	qb.orderBys = nil
@@ -197,12 +208,153 @@ func (qb *Neo4jQueryConstructor) CountWith(builder *CountBuilder) QueryConstruct
 	}
 	qb.countExpr = &expr
 	qb.selectedCols = nil
+	qb.aggregates = nil
 	qb.limitVal = nil
 	qb.offsetVal = nil
 	qb.orderBys = nil
 	return qb
 }
 
+func (qb *Neo4jQueryConstructor) setBuildErr(err error) {
+	if err != nil && qb.buildErr == nil {
+		qb.buildErr = err
+	}
+}
+
+// GroupBy 设置分组键；Cypher 以 RETURN 中的非聚合项作为隐式分组键。
+func (qb *Neo4jQueryConstructor) GroupBy(fields ...string) QueryConstructor {
+	qb.setBuildErr(requireQueryFeature(queryFeaturesForBackend("neo4j"), "neo4j", "group_by"))
+	for _, field := range fields {
+		if trimmed := strings.TrimSpace(field); trimmed != "" {
+			qb.groupBys = append(qb.groupBys, trimmed)
+		}
+	}
+	return qb
+}
+
+// Having 添加分组过滤条件，编译为 WITH ... WHERE。
+func (qb *Neo4jQueryConstructor) Having(condition Condition) QueryConstructor {
+	if condition == nil {
+		return qb
+	}
+	qb.setBuildErr(requireQueryFeature(queryFeaturesForBackend("neo4j"), "neo4j", "having"))
+	qb.having = append(qb.having, condition)
+	return qb
+}
+
+// Aggregate 追加聚合投影（sum/avg/min/max/count）。
+func (qb *Neo4jQueryConstructor) Aggregate(builders ...*AggregateBuilder) QueryConstructor {
+	for _, builder := range builders {
+		agg, err := builder.toIR()
+		if err != nil {
+			qb.setBuildErr(err)
+			continue
+		}
+		qb.aggregates = append(qb.aggregates, agg)
+	}
+	qb.countExpr = nil
+	return qb
+}
+
+// Distinct 编译为 RETURN DISTINCT。
+func (qb *Neo4jQueryConstructor) Distinct() QueryConstructor {
+	qb.distinct = true
+	return qb
+}
+
+// DistinctOn Cypher 无按字段去重语法，按 Neo4j 查询特性声明在 Build 阶段报错。
+func (qb *Neo4jQueryConstructor) DistinctOn(fields ...string) QueryConstructor {
+	err := requireQueryFeature(queryFeaturesForBackend("neo4j"), "neo4j", "distinct_on")
+	if err == nil {
+		err = fmt.Errorf("neo4j query constructor does not support distinct on")
+	}
+	qb.setBuildErr(err)
+	return qb
+}
+
+// Lock Cypher 无 SELECT ... FOR UPDATE 语义（写锁在写入节点时隐式获取）。
+func (qb *Neo4jQueryConstructor) Lock(lock *RowLock) QueryConstructor {
+	err := requireQueryFeature(queryFeaturesForBackend("neo4j"), "neo4j", "row_lock")
+	if err == nil {
+		err = fmt.Errorf("neo4j query constructor does not support row locks")
+	}
+	qb.setBuildErr(err)
+	return qb
+}
+
+// With 追加递归 CTE，编译为以锚定节点为起点的可变长度路径匹配；Cypher 不支持普通 CTE。
+func (qb *Neo4jQueryConstructor) With(ctes ...*CTEBuilder) QueryConstructor {
+	for _, cte := range ctes {
+		if err := cte.validate(); err != nil {
+			qb.setBuildErr(err)
+			continue
+		}
+		if err := requireQueryFeature(queryFeaturesForBackend("neo4j"), "neo4j", cte.featureName()); err != nil {
+			qb.setBuildErr(err)
+			continue
+		}
+		valid := true
+		for _, member := range []QueryConstructor{cte.query, cte.recursive} {
+			if _, ok := member.GetNativeBuilder().(*Neo4jQueryConstructor); !ok {
+				qb.setBuildErr(fmt.Errorf("neo4j cte %q requires Neo4j query constructors, got %T", cte.name, member.GetNativeBuilder()))
+				valid = false
+				break
+			}
+		}
+		if valid {
+			qb.ctes = append(qb.ctes, cte)
+		}
+	}
+	return qb
+}
+
+// Window Cypher 没有 OVER 子句，窗口函数投影在 Build 阶段返回错误。
+func (qb *Neo4jQueryConstructor) Window(builders ...*WindowBuilder) QueryConstructor {
+	err := requireQueryFeature(queryFeaturesForBackend("neo4j"), "neo4j", "window_func")
+	if err == nil {
+		err = fmt.Errorf("neo4j query constructor does not support window functions")
+	}
+	qb.setBuildErr(err)
+	return qb
+}
+
+// Union 追加 UNION 分支。
+func (qb *Neo4jQueryConstructor) Union(other QueryConstructor) QueryConstructor {
+	return qb.addSetOperation(SetOperationUnion, other)
+}
+
+// UnionAll 追加 UNION ALL 分支。
+func (qb *Neo4jQueryConstructor) UnionAll(other QueryConstructor) QueryConstructor {
+	return qb.addSetOperation(SetOperationUnionAll, other)
+}
+
+// Intersect Cypher 无 INTERSECT，按查询特性声明在 Build 阶段报错。
+func (qb *Neo4jQueryConstructor) Intersect(other QueryConstructor) QueryConstructor {
+	return qb.addSetOperation(SetOperationIntersect, other)
+}
+
+// Except Cypher 无 EXCEPT，按查询特性声明在 Build 阶段报错。
+func (qb *Neo4jQueryConstructor) Except(other QueryConstructor) QueryConstructor {
+	return qb.addSetOperation(SetOperationExcept, other)
+}
+
+func (qb *Neo4jQueryConstructor) addSetOperation(operator QuerySetOperator, other QueryConstructor) QueryConstructor {
+	if err := requireQueryFeature(queryFeaturesForBackend("neo4j"), "neo4j", operator.featureName()); err != nil {
+		qb.setBuildErr(err)
+		return qb
+	}
+	if other == nil {
+		qb.setBuildErr(fmt.Errorf("%s requires a query constructor", strings.ToLower(string(operator))))
+		return qb
+	}
+	if _, ok := other.GetNativeBuilder().(*Neo4jQueryConstructor); !ok {
+		qb.setBuildErr(fmt.Errorf("neo4j %s requires a neo4j query constructor, got %T", strings.ToLower(string(operator)), other.GetNativeBuilder()))
+		return qb
+	}
+	qb.setOperations = append(qb.setOperations, querySetOperation{operator: operator, query: other})
+	return qb
+}
+
 func (qb *Neo4jQueryConstructor) OrderBy(field string, direction string) QueryConstructor {
 	direction = strings.ToUpper(strings.TrimSpace(direction))
 	if direction != "DESC" {
@@ -286,6 +438,9 @@ func (qb *Neo4jQueryConstructor) Upsert(ctx context.Context, repo *Repository, c
 }
 
 func (qb *Neo4jQueryConstructor) BuildIR(ctx context.Context) (*QueryIR, error) {
+	if qb.buildErr != nil {
+		return nil, qb.buildErr
+	}
 	projections := append([]string(nil), qb.selectedCols...)
 	if qb.countExpr != nil {
 		projections = []string{*qb.countExpr}
@@ -297,12 +452,20 @@ func (qb *Neo4jQueryConstructor) BuildIR(ctx context.Context) (*QueryIR, error)
 			Alias:  sanitizeSymbol(qb.fromAlias, "n"),
 			Schema: qb.schema,
 		},
+		Distinct:    qb.distinct,
 		Projections: projections,
+		Aggregates:  append([]QueryAggregateIR(nil), qb.aggregates...),
 		Conditions:  append([]Condition(nil), qb.conditions...),
+		GroupBys:    append([]string(nil), qb.groupBys...),
+		Having:      append([]Condition(nil), qb.having...),
 		Limit:       qb.limitVal,
 		Offset:      qb.offsetVal,
 		Joins:       make([]QueryJoinIR, 0, len(qb.joins)),
 		OrderBys:    make([]QueryOrderIR, 0, len(qb.orderBys)),
+		Preloads:    qb.preloads,
+	}
+	if condition := softDeleteScopeCondition(qb.schema, qb.softDeleted, qb.conditions, ""); condition != nil {
+		ir.Conditions = append(ir.Conditions, condition)
 	}
 
 	for _, join := range qb.joins {
@@ -322,6 +485,24 @@ func (qb *Neo4jQueryConstructor) BuildIR(ctx context.Context) (*QueryIR, error)
 		ir.OrderBys = append(ir.OrderBys, QueryOrderIR{Field: order.Field, Direction: order.Direction})
 	}
 
+	for _, op := range qb.setOperations {
+		branchIR, err := op.query.GetNativeBuilder().(*Neo4jQueryConstructor).BuildIR(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build %s branch: %w", strings.ToLower(string(op.operator)), err)
+		}
+		ir.SetOperations = append(ir.SetOperations, QuerySetOperationIR{Operator: op.operator, Query: branchIR})
+	}
+
+	for _, builder := range qb.ctes {
+		cte, err := builder.toIR(func(member QueryConstructor) (*QueryIR, error) {
+			return member.GetNativeBuilder().(*Neo4jQueryConstructor).BuildIR(ctx)
+		})
+		if err != nil {
+			return nil, err
+		}
+		ir.CTEs = append(ir.CTEs, cte)
+	}
+
 	return ir, nil
 }
 
@@ -337,18 +518,26 @@ func (c *CypherCompiler) Compile(ctx context.Context, ir *QueryIR) (string, []in
 	if strings.TrimSpace(ir.Source.Table) == "" {
 		return "", nil, fmt.Errorf("query ir source table is required")
 	}
-
-	var cypher strings.Builder
-	args := make([]interface{}, 0)
-	argIndex := 1
+	if len(ir.Preloads) > 0 && (len(ir.SetOperations) > 0 || len(ir.CTEs) > 0) {
+		return "", nil, fmt.Errorf("neo4j preload cannot be combined with set operations or CTEs")
+	}
+	if len(ir.SetOperations) > 0 {
+		return c.compileSetOperations(ctx, ir)
+	}
+	if len(ir.CTEs) > 0 {
+		return c.compileRecursiveCTE(ctx, ir)
+	}
 
 	sourceAlias := sanitizeSymbol(ir.Source.Alias, "n")
 	sourceLabel := sanitizeLabel(ir.Source.Table, "Node")
-	cypher.WriteString("MATCH (")
-	cypher.WriteString(sourceAlias)
-	cypher.WriteString(":")
-	cypher.WriteString(sourceLabel)
-	cypher.WriteString(")")
+	return c.compileMatch(ir, "MATCH ("+sourceAlias+":"+sourceLabel+")", make([]interface{}, 0), 1)
+}
+
+// compileMatch 在给定的起始 MATCH 子句之后编译 JOIN / WHERE / RETURN / 分页部分。
+func (c *CypherCompiler) compileMatch(ir *QueryIR, match string, args []interface{}, argIndex int) (string, []interface{}, error) {
+	var cypher strings.Builder
+	sourceAlias := sanitizeSymbol(ir.Source.Alias, "n")
+	cypher.WriteString(match)
 
 	// 记录每个 join 解析后的别名，供后续 Filter 条件限定使用。
 	resolvedJoinAliases := make([]string, len(ir.Joins))
@@ -449,12 +638,16 @@ func (c *CypherCompiler) Compile(ctx context.Context, ir *QueryIR) (string, []in
 	}
 
 	if len(allConds) > 0 {
+		subqueryIndex := 0
 		cypher.WriteString(" WHERE ")
 		for i, ce := range allConds {
 			if i > 0 {
 				cypher.WriteString(" AND ")
 			}
-			translator := &CypherConditionTranslator{sourceAlias: ce.alias, argIndex: &argIndex}
+			translator := &CypherConditionTranslator{sourceAlias: ce.alias, argIndex: &argIndex, subqueryIndex: &subqueryIndex}
+			if ce.alias == sourceAlias {
+				translator.sourceSchema = ir.Source.Schema
+			}
 			clause, clauseArgs, err := ce.cond.Translate(translator)
 			if err != nil {
 				return "", nil, fmt.Errorf("failed to translate condition: %w", err)
@@ -464,7 +657,37 @@ func (c *CypherCompiler) Compile(ctx context.Context, ir *QueryIR) (string, []in
 		}
 	}
 
+	if len(ir.Preloads) > 0 && (len(ir.Aggregates) > 0 || len(ir.GroupBys) > 0) {
+		return "", nil, fmt.Errorf("preload cannot be combined with GROUP BY or aggregates")
+	}
+	var preloadColumns []string
+	if len(ir.Preloads) > 0 {
+		carry := append([]string{sourceAlias}, resolvedJoinAliases...)
+		clause, columns := compileCypherPreloads(ir.Source.Schema, sourceAlias, carry, ir.Preloads)
+		cypher.WriteString(clause)
+		preloadColumns = columns
+	}
+
+	if len(ir.Aggregates) > 0 || len(ir.GroupBys) > 0 {
+		clause, clauseArgs, err := compileCypherAggregateReturn(ir, sourceAlias, &argIndex)
+		if err != nil {
+			return "", nil, err
+		}
+		cypher.WriteString(clause)
+		args = append(args, clauseArgs...)
+		if ir.Offset != nil {
+			cypher.WriteString(fmt.Sprintf(" SKIP %d", *ir.Offset))
+		}
+		if ir.Limit != nil {
+			cypher.WriteString(fmt.Sprintf(" LIMIT %d", *ir.Limit))
+		}
+		return cypher.String(), args, nil
+	}
+
 	cypher.WriteString(" RETURN ")
+	if ir.Distinct {
+		cypher.WriteString("DISTINCT ")
+	}
 	if len(ir.Projections) == 0 {
 		cypher.WriteString(sourceAlias)
 	} else {
@@ -475,6 +698,12 @@ func (c *CypherCompiler) Compile(ctx context.Context, ir *QueryIR) (string, []in
 			cypher.WriteString(qualifyCypherField(field, sourceAlias))
 		}
 	}
+	for i, column := range preloadColumns {
+		cypher.WriteString(", ")
+		cypher.WriteString(column)
+		cypher.WriteString(" AS ")
+		cypher.WriteString(sanitizeSymbol(ir.Preloads[i].Name, column))
+	}
 
 	if len(ir.OrderBys) > 0 {
 		cypher.WriteString(" ORDER BY ")
@@ -498,10 +727,397 @@ func (c *CypherCompiler) Compile(ctx context.Context, ir *QueryIR) (string, []in
 	return cypher.String(), args, nil
 }
 
+// compileSetOperations 编译 Cypher UNION / UNION ALL。
+// 各分支投影统一为 "alias.field AS name" 以保证列名一致；存在共享的排序分页时包装为
+// CALL { ... UNION ... } RETURN ... ORDER BY ... SKIP ... LIMIT ...。
+func (c *CypherCompiler) compileSetOperations(ctx context.Context, ir *QueryIR) (string, []interface{}, error) {
+	features := queryFeaturesForBackend("neo4j")
+
+	head := *ir
+	head.SetOperations = nil
+	head.OrderBys = nil
+	head.Limit = nil
+	head.Offset = nil
+	head.Projections = cypherSetOperationProjections(&head)
+
+	compound, args, err := c.Compile(ctx, &head)
+	if err != nil {
+		return "", nil, err
+	}
+	for i, op := range ir.SetOperations {
+		if err := validateSetOperationBranchIR(i, op); err != nil {
+			return "", nil, err
+		}
+		if err := requireQueryFeature(features, "neo4j", op.Operator.featureName()); err != nil {
+			return "", nil, err
+		}
+		branch := *op.Query
+		branch.Projections = cypherSetOperationProjections(&branch)
+		branchCypher, branchArgs, err := c.Compile(ctx, &branch)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to compile set operation branch %d: %w", i+1, err)
+		}
+		compound += " " + string(op.Operator) + " " + renumberCypherPlaceholders(branchCypher, len(args))
+		args = append(args, branchArgs...)
+	}
+
+	if len(ir.OrderBys) == 0 && ir.Limit == nil && ir.Offset == nil {
+		return compound, args, nil
+	}
+
+	sourceAlias := sanitizeSymbol(ir.Source.Alias, "n")
+	columns := make([]string, 0, len(head.Projections)+len(head.Aggregates))
+	if len(head.Aggregates) > 0 {
+		keys := head.Projections
+		if len(keys) == 0 {
+			keys = head.GroupBys
+		}
+		for _, key := range keys {
+			columns = append(columns, cypherProjectionName(key))
+		}
+		for _, agg := range head.Aggregates {
+			columns = append(columns, agg.Alias)
+		}
+	} else {
+		for _, projection := range head.Projections {
+			columns = append(columns, resultColumnNameIR(projection))
+		}
+	}
+
+	var cypher strings.Builder
+	cypher.WriteString("CALL { ")
+	cypher.WriteString(compound)
+	cypher.WriteString(" } RETURN ")
+	if len(columns) == 0 {
+		cypher.WriteString(sourceAlias)
+	} else {
+		cypher.WriteString(strings.Join(columns, ", "))
+	}
+	if len(ir.OrderBys) > 0 {
+		cypher.WriteString(" ORDER BY ")
+		for i, order := range ir.OrderBys {
+			if i > 0 {
+				cypher.WriteString(", ")
+			}
+			if len(columns) == 0 {
+				cypher.WriteString(qualifyCypherField(order.Field, sourceAlias))
+			} else {
+				cypher.WriteString(cypherProjectionName(order.Field))
+			}
+			cypher.WriteString(" ")
+			cypher.WriteString(order.Direction)
+		}
+	}
+	if ir.Offset != nil {
+		cypher.WriteString(fmt.Sprintf(" SKIP %d", *ir.Offset))
+	}
+	if ir.Limit != nil {
+		cypher.WriteString(fmt.Sprintf(" LIMIT %d", *ir.Limit))
+	}
+	return cypher.String(), args, nil
+}
+
+// compileRecursiveCTE 将以递归 CTE 为数据源的查询编译为可变长度路径匹配：
+// MATCH (root:Label) WHERE <锚定条件> MATCH path = (root)-[:REL*0..N-1]->(n:Label) ... RETURN ..., length(path) + 1 AS depth。
+// 递归成员的过滤条件作用于路径上除起点外的所有节点。
+func (c *CypherCompiler) compileRecursiveCTE(ctx context.Context, ir *QueryIR) (string, []interface{}, error) {
+	_ = ctx
+	if len(ir.CTEs) != 1 {
+		return "", nil, fmt.Errorf("neo4j query constructor supports exactly one recursive cte per query")
+	}
+	cte := ir.CTEs[0]
+	if cte.Recursive == nil {
+		return "", nil, requireQueryFeature(queryFeaturesForBackend("neo4j"), "neo4j", "cte")
+	}
+	if !strings.EqualFold(strings.TrimSpace(ir.Source.Table), cte.Name) {
+		return "", nil, fmt.Errorf("neo4j recursive cte %q must be the query source", cte.Name)
+	}
+	if err := validateCTEMemberIR(cte.Name, "anchor", cte.Query); err != nil {
+		return "", nil, err
+	}
+	if err := validateCTEMemberIR(cte.Name, "recursive member", cte.Recursive); err != nil {
+		return "", nil, err
+	}
+	join, qualifier, ok := findCTEJoinIR(cte.Recursive, cte.Name)
+	if !ok {
+		return "", nil, fmt.Errorf("recursive cte %q: recursive member must join %q", cte.Name, cte.Name)
+	}
+	relType, direction := resolveCypherRecursiveRelation(cte, join, qualifier)
+
+	sourceAlias := sanitizeSymbol(ir.Source.Alias, "n")
+	rootAlias := "root"
+	if sourceAlias == rootAlias {
+		rootAlias = "cte_root"
+	}
+	const pathVar = "path"
+	args := make([]interface{}, 0)
+	argIndex := 1
+	subqueryIndex := 0
+
+	var match strings.Builder
+	match.WriteString("MATCH (")
+	match.WriteString(rootAlias)
+	match.WriteString(":")
+	match.WriteString(sanitizeLabel(cte.Query.Source.Table, "Node"))
+	match.WriteString(")")
+	if len(cte.Query.Conditions) > 0 {
+		match.WriteString(" WHERE ")
+		for i, cond := range cte.Query.Conditions {
+			if i > 0 {
+				match.WriteString(" AND ")
+			}
+			translator := &CypherConditionTranslator{sourceAlias: rootAlias, argIndex: &argIndex, subqueryIndex: &subqueryIndex, sourceSchema: cte.Query.Source.Schema, sourceTable: cte.Query.Source.Table}
+			clause, clauseArgs, err := cond.Translate(translator)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to translate anchor condition: %w", err)
+			}
+			match.WriteString(clause)
+			args = append(args, clauseArgs...)
+		}
+	}
+
+	hops := "*0.."
+	if cte.MaxDepth > 0 {
+		hops += strconv.Itoa(cte.MaxDepth - 1)
+	}
+	match.WriteString(" MATCH ")
+	match.WriteString(pathVar)
+	match.WriteString(" = ")
+	match.WriteString(buildRelationshipPattern(rootAlias, sourceAlias, sanitizeLabel(cte.Recursive.Source.Table, "Node"), "", relType+hops, direction))
+
+	if len(cte.Recursive.Conditions) > 0 {
+		match.WriteString(" WHERE ALL(step IN nodes(")
+		match.WriteString(pathVar)
+		match.WriteString(")[1..] WHERE ")
+		for i, cond := range cte.Recursive.Conditions {
+			if i > 0 {
+				match.WriteString(" AND ")
+			}
+			translator := &CypherConditionTranslator{sourceAlias: "step", argIndex: &argIndex, subqueryIndex: &subqueryIndex, sourceSchema: cte.Recursive.Source.Schema, sourceTable: cte.Recursive.Source.Table}
+			clause, clauseArgs, err := cond.Translate(translator)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to translate recursive member condition: %w", err)
+			}
+			match.WriteString(clause)
+			args = append(args, clauseArgs...)
+		}
+		match.WriteString(") WITH ")
+		match.WriteString(sourceAlias)
+		match.WriteString(", ")
+		match.WriteString(pathVar)
+	}
+
+	// 主查询字段：去掉 CTE 名前缀，depth 列映射为路径长度。
+	depthExpr := "length(" + pathVar + ") + 1"
+	mapField := func(field string) string {
+		trimmed := strings.TrimSpace(field)
+		if rest, ok := trimCypherTablePrefix(trimmed, cte.Name); ok {
+			trimmed = rest
+		}
+		if cte.DepthColumn != "" && trimmed == cte.DepthColumn {
+			return "(" + depthExpr + ")"
+		}
+		return trimmed
+	}
+	body := *ir
+	body.CTEs = nil
+	body.Conditions = make([]Condition, len(ir.Conditions))
+	for i, cond := range ir.Conditions {
+		body.Conditions[i] = mapConditionFieldsIR(cond, mapField)
+	}
+	body.OrderBys = make([]QueryOrderIR, len(ir.OrderBys))
+	for i, order := range ir.OrderBys {
+		body.OrderBys[i] = QueryOrderIR{Field: mapField(order.Field), Direction: order.Direction}
+	}
+	body.Projections = make([]string, 0, len(ir.Projections)+1)
+	for _, projection := range ir.Projections {
+		mapped := mapField(projection)
+		if cte.DepthColumn != "" && mapped == "("+depthExpr+")" {
+			mapped = depthExpr + " AS " + cte.DepthColumn
+		}
+		body.Projections = append(body.Projections, mapped)
+	}
+	if len(body.Projections) == 0 && len(body.Aggregates) == 0 && cte.DepthColumn != "" {
+		body.Projections = []string{"*", depthExpr + " AS " + cte.DepthColumn}
+	}
+
+	return c.compileMatch(&body, match.String(), args, argIndex)
+}
+
+// resolveCypherRecursiveRelation 解析递归成员的关系类型与方向。
+// JOIN 子句为关系描述（如 "<-[:PARENT_OF]-"）时直接使用；为 SQL 风格 ON 子句时按自引用外键推断：
+// 新行外键指向上一层（向下遍历子节点）为 out，上一层外键指向新行（向上遍历祖先）为 in。
+func resolveCypherRecursiveRelation(cte QueryCTEIR, join QueryJoinIR, qualifier string) (string, string) {
+	onClause := strings.TrimSpace(join.OnClause)
+	relType, direction := parseRelationshipSpec(onClause)
+	if onClause != "" && relType != "RELATED_TO" {
+		return relType, direction
+	}
+
+	relType = inferNeo4jRelType(cte.Query.Source.Schema, cte.Recursive.Source.Schema)
+	if cs, ok := cte.Recursive.Source.Schema.(ConstrainedSchema); ok {
+		lowerOn := strings.ToLower(onClause)
+		for _, tc := range cs.Constraints() {
+			if tc.Kind != ConstraintForeignKey || !strings.EqualFold(tc.RefTable, cte.Recursive.Source.Table) {
+				continue
+			}
+			for _, field := range tc.Fields {
+				if strings.Contains(lowerOn, strings.ToLower(qualifier+"."+field)) {
+					return relType, "in"
+				}
+			}
+		}
+	}
+	return relType, "out"
+}
+
+// cypherSetOperationProjections 为 UNION 分支生成带别名的投影（聚合查询已自带别名，保持原样）。
+func cypherSetOperationProjections(ir *QueryIR) []string {
+	if len(ir.Aggregates) > 0 || len(ir.GroupBys) > 0 {
+		return ir.Projections
+	}
+	sourceAlias := sanitizeSymbol(ir.Source.Alias, "n")
+	projections := make([]string, 0, len(ir.Projections))
+	for _, field := range ir.Projections {
+		trimmed := strings.TrimSpace(field)
+		if trimmed == "" || trimmed == "*" || strings.ContainsAny(trimmed, "() \t\n") {
+			projections = append(projections, field)
+			continue
+		}
+		projections = append(projections, qualifyCypherField(trimmed, sourceAlias)+" AS "+cypherProjectionName(trimmed))
+	}
+	return projections
+}
+
+// renumberCypherPlaceholders 将 $pN 参数整体偏移 offset。
+func renumberCypherPlaceholders(query string, offset int) string {
+	if offset <= 0 {
+		return query
+	}
+	return cypherPlaceholderPattern.ReplaceAllStringFunc(query, func(match string) string {
+		n, err := strconv.Atoi(match[2:])
+		if err != nil {
+			return match
+		}
+		return "$p" + strconv.Itoa(n+offset)
+	})
+}
+
+var cypherPlaceholderPattern = regexp.MustCompile(`\$p(\d+)`)
+
+// compileCypherAggregateReturn 编译聚合查询的 RETURN（及 HAVING 所需的 WITH ... WHERE）与 ORDER BY。
+// Cypher 没有 GROUP BY：RETURN/WITH 中的非聚合项即为分组键。
+func compileCypherAggregateReturn(ir *QueryIR, sourceAlias string, argIndex *int) (string, []interface{}, error) {
+	keys := ir.Projections
+	if len(keys) == 0 {
+		keys = ir.GroupBys
+	}
+
+	items := make([]string, 0, len(keys)+len(ir.Aggregates))
+	names := make([]string, 0, len(keys)+len(ir.Aggregates))
+	for _, key := range keys {
+		trimmed := strings.TrimSpace(key)
+		if trimmed == "" {
+			continue
+		}
+		name := cypherProjectionName(trimmed)
+		items = append(items, qualifyCypherField(trimmed, sourceAlias)+" AS "+name)
+		names = append(names, name)
+	}
+	for _, agg := range ir.Aggregates {
+		items = append(items, renderCypherAggregate(agg, sourceAlias)+" AS "+agg.Alias)
+		names = append(names, agg.Alias)
+	}
+	if len(items) == 0 {
+		return "", nil, fmt.Errorf("neo4j aggregate query requires group keys or aggregates")
+	}
+
+	var b strings.Builder
+	args := make([]interface{}, 0)
+	if len(ir.Having) > 0 {
+		// HAVING → WITH 投影后在同一作用域内过滤，字段直接引用投影名。
+		b.WriteString(" WITH ")
+		b.WriteString(strings.Join(items, ", "))
+		b.WriteString(" WHERE ")
+		translator := &CypherConditionTranslator{argIndex: argIndex, fieldResolver: cypherProjectionName}
+		for i, cond := range ir.Having {
+			if i > 0 {
+				b.WriteString(" AND ")
+			}
+			clause, clauseArgs, err := cond.Translate(translator)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to translate having condition: %w", err)
+			}
+			b.WriteString(clause)
+			args = append(args, clauseArgs...)
+		}
+		b.WriteString(" RETURN ")
+		b.WriteString(strings.Join(names, ", "))
+	} else {
+		b.WriteString(" RETURN ")
+		b.WriteString(strings.Join(items, ", "))
+	}
+
+	if len(ir.OrderBys) > 0 {
+		b.WriteString(" ORDER BY ")
+		for i, order := range ir.OrderBys {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			field := strings.TrimSpace(order.Field)
+			if containsString(names, cypherProjectionName(field)) {
+				b.WriteString(cypherProjectionName(field))
+			} else {
+				b.WriteString(qualifyCypherField(field, sourceAlias))
+			}
+			b.WriteString(" ")
+			b.WriteString(order.Direction)
+		}
+	}
+	return b.String(), args, nil
+}
+
+// renderCypherAggregate 渲染 Cypher 聚合函数表达式（不含别名）。
+func renderCypherAggregate(agg QueryAggregateIR, sourceAlias string) string {
+	fn := strings.ToLower(string(agg.Func))
+	field := strings.TrimSpace(agg.Field)
+	if field == "" || field == "*" {
+		return fn + "(*)"
+	}
+	expr := qualifyCypherField(field, sourceAlias)
+	if agg.Distinct {
+		return fn + "(DISTINCT " + expr + ")"
+	}
+	return fn + "(" + expr + ")"
+}
+
+// cypherProjectionName 返回字段在 WITH/RETURN 投影中的变量名（取最后一段）。
+func cypherProjectionName(field string) string {
+	trimmed := strings.TrimSpace(field)
+	if strings.ContainsAny(trimmed, "() \t\n") {
+		return trimmed
+	}
+	if idx := strings.LastIndex(trimmed, "."); idx >= 0 {
+		trimmed = trimmed[idx+1:]
+	}
+	return sanitizeSymbol(trimmed, trimmed)
+}
+
 // CypherConditionTranslator 将 Condition 转换为 Cypher 过滤表达式。
 type CypherConditionTranslator struct {
 	sourceAlias string
 	argIndex    *int
+	// fieldResolver 非空时用于解析字段引用（例如 WITH 作用域内的投影名），替代默认的 alias 限定。
+	fieldResolver func(string) string
+	// sourceSchema 当前作用域节点的 Schema，用于子查询推断关系模式。
+	sourceSchema Schema
+	// sourceTable 子查询作用域内的表名，"table.field" 形式的字段会被改写为 alias 限定。
+	sourceTable string
+	// outerAlias / outerTable 子查询外层节点，FieldRef 据此解析关联字段。
+	outerAlias string
+	outerTable string
+	// subqueryIndex 子查询变量计数器（sq1, sq2...），在同一条语句内共享。
+	subqueryIndex *int
 }
 
 func (t *CypherConditionTranslator) TranslateCondition(condition Condition) (string, []interface{}, error) {
@@ -516,11 +1132,160 @@ func (t *CypherConditionTranslator) TranslateCondition(condition Condition) (str
 			return "", nil, err
 		}
 		return "NOT (" + inner + ")", args, nil
+	case *SubqueryCondition:
+		return t.translateSubquery(c)
 	default:
 		return "", nil, fmt.Errorf("unknown condition type: %T", condition)
 	}
 }
 
+// translateSubquery 将嵌套 Neo4jQueryConstructor 编译为 Cypher 子查询：
+//   - exists / not_exists → EXISTS { MATCH ... }，存在关系元数据时使用关系模式关联外层节点
+//   - in / not_in → field IN COLLECT { MATCH ... RETURN sqN.col }
+//   - 标量比较 → field op COLLECT { MATCH ... RETURN expr }[0]
+func (t *CypherConditionTranslator) translateSubquery(cond *SubqueryCondition) (string, []interface{}, error) {
+	if err := requireQueryFeature(queryFeaturesForBackend("neo4j"), "neo4j", "subquery"); err != nil {
+		return "", nil, err
+	}
+	if cond.Query == nil {
+		return "", nil, fmt.Errorf("subquery condition requires a query constructor")
+	}
+	inner, ok := cond.Query.GetNativeBuilder().(*Neo4jQueryConstructor)
+	if !ok {
+		return "", nil, fmt.Errorf("neo4j subquery requires a neo4j query constructor, got %T", cond.Query.GetNativeBuilder())
+	}
+	innerIR, err := inner.BuildIR(context.Background())
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to build subquery: %w", err)
+	}
+
+	if t.subqueryIndex == nil {
+		t.subqueryIndex = new(int)
+	}
+	*t.subqueryIndex++
+	alias := fmt.Sprintf("sq%d", *t.subqueryIndex)
+	label := sanitizeLabel(innerIR.Source.Table, "Node")
+
+	pattern := "(" + alias + ":" + label + ")"
+	if relation := buildQueryJoinRelationIR(t.sourceSchema, innerIR.Source.Schema); relation != nil {
+		relType := inferNeo4jRelType(t.sourceSchema, innerIR.Source.Schema)
+		if strings.TrimSpace(relation.Name) != "" {
+			relType = normalizeCypherRelType(relation.Name)
+		}
+		pattern = buildRelationshipPattern(t.sourceAlias, alias, label, "", relType, "out")
+	}
+
+	var body strings.Builder
+	args := make([]interface{}, 0)
+	body.WriteString("MATCH ")
+	body.WriteString(pattern)
+	if len(innerIR.Conditions) > 0 {
+		nested := &CypherConditionTranslator{
+			sourceAlias:   alias,
+			argIndex:      t.argIndex,
+			sourceSchema:  innerIR.Source.Schema,
+			sourceTable:   innerIR.Source.Table,
+			outerAlias:    t.sourceAlias,
+			outerTable:    t.outerTableName(),
+			subqueryIndex: t.subqueryIndex,
+		}
+		body.WriteString(" WHERE ")
+		for i, c := range innerIR.Conditions {
+			if i > 0 {
+				body.WriteString(" AND ")
+			}
+			clause, clauseArgs, err := c.Translate(nested)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to translate subquery condition: %w", err)
+			}
+			body.WriteString(clause)
+			args = append(args, clauseArgs...)
+		}
+	}
+
+	switch cond.Operator {
+	case "exists":
+		return "EXISTS { " + body.String() + " }", args, nil
+	case "not_exists":
+		return "NOT EXISTS { " + body.String() + " }", args, nil
+	}
+
+	if strings.TrimSpace(cond.Field) == "" {
+		return "", nil, fmt.Errorf("subquery operator %s requires a field", cond.Operator)
+	}
+	var projection string
+	switch {
+	case len(innerIR.Aggregates) == 1 && len(innerIR.Projections) == 0:
+		projection = renderCypherAggregate(innerIR.Aggregates[0], alias)
+	case len(innerIR.Projections) == 1 && len(innerIR.Aggregates) == 0:
+		projection = qualifyCypherField(innerIR.Projections[0], alias)
+	default:
+		return "", nil, fmt.Errorf("subquery operator %s requires exactly one projected field", cond.Operator)
+	}
+	field := t.resolveField(cond.Field)
+	collect := "COLLECT { " + body.String() + " RETURN " + projection + " }"
+
+	switch cond.Operator {
+	case "in":
+		return field + " IN " + collect, args, nil
+	case "not_in":
+		return "NOT " + field + " IN " + collect, args, nil
+	default:
+		op, ok := map[string]string{"eq": "=", "ne": "<>", "gt": ">", "lt": "<", "gte": ">=", "lte": "<="}[cond.Operator]
+		if !ok {
+			return "", nil, fmt.Errorf("unsupported subquery operator: %s", cond.Operator)
+		}
+		return field + " " + op + " " + collect + "[0]", args, nil
+	}
+}
+
+func (t *CypherConditionTranslator) outerTableName() string {
+	if t.sourceSchema != nil {
+		return t.sourceSchema.TableName()
+	}
+	return t.sourceTable
+}
+
+// resolveField 解析字段引用：WITH 作用域使用 fieldResolver；子查询内 "table.field" 改写为节点别名限定。
+func (t *CypherConditionTranslator) resolveField(field string) string {
+	if t.fieldResolver != nil {
+		return t.fieldResolver(field)
+	}
+	if t.sourceTable != "" {
+		if rest, ok := trimCypherTablePrefix(field, t.sourceTable); ok {
+			return t.sourceAlias + "." + rest
+		}
+	}
+	return qualifyCypherField(field, t.sourceAlias)
+}
+
+// resolveFieldRef 解析 FieldRef：无前缀或前缀为外层表名时指向外层节点。
+func (t *CypherConditionTranslator) resolveFieldRef(ref FieldRef) string {
+	name := strings.TrimSpace(ref.Name)
+	if t.outerAlias == "" {
+		return t.resolveField(name)
+	}
+	if !strings.Contains(name, ".") {
+		return t.outerAlias + "." + name
+	}
+	if rest, ok := trimCypherTablePrefix(name, t.outerTable); ok {
+		return t.outerAlias + "." + rest
+	}
+	return t.resolveField(name)
+}
+
+func trimCypherTablePrefix(field, table string) (string, bool) {
+	trimmed := strings.TrimSpace(field)
+	idx := strings.Index(trimmed, ".")
+	if idx <= 0 || strings.TrimSpace(table) == "" {
+		return "", false
+	}
+	if !strings.EqualFold(trimmed[:idx], strings.TrimSpace(table)) {
+		return "", false
+	}
+	return trimmed[idx+1:], true
+}
+
 func (t *CypherConditionTranslator) TranslateComposite(operator string, conditions []Condition) (string, []interface{}, error) {
 	if len(conditions) == 0 {
 		return "", nil, fmt.Errorf("composite condition must have at least one condition")
@@ -551,17 +1316,23 @@ func (t *CypherConditionTranslator) TranslateComposite(operator string, conditio
 }
 
 func (t *CypherConditionTranslator) translateSimple(cond *SimpleCondition) (string, []interface{}, error) {
-	field := qualifyCypherField(cond.Field, t.sourceAlias)
+	field := t.resolveField(cond.Field)
 
 	if cond.Operator == "full_text" {
 		placeholder := nextCypherPlaceholder(t.argIndex)
 		return field + " CONTAINS " + placeholder, []interface{}{cond.Value}, nil
 	}
+	if isExtendedConditionOperator(cond.Operator) {
+		return t.translateExtended(field, cond)
+	}
 
 	switch cond.Operator {
 	case "eq", "ne", "gt", "lt", "gte", "lte":
-		placeholder := nextCypherPlaceholder(t.argIndex)
 		op := map[string]string{"eq": "=", "ne": "<>", "gt": ">", "lt": "<", "gte": ">=", "lte": "<="}[cond.Operator]
+		if ref, ok := cond.Value.(FieldRef); ok {
+			return field + " " + op + " " + t.resolveFieldRef(ref), nil, nil
+		}
+		placeholder := nextCypherPlaceholder(t.argIndex)
 		return field + " " + op + " " + placeholder, []interface{}{cond.Value}, nil
 	case "in":
 		placeholder := nextCypherPlaceholder(t.argIndex)
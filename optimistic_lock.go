@@ -0,0 +1,78 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrStaleEntry 乐观锁冲突：记录已被其他写入修改或删除。
+var ErrStaleEntry = errors.New("stale entry")
+
+// StaleEntryError 乐观锁更新未命中任何记录时返回（参考 Ecto 的 Ecto.StaleEntryError）。
+// 可通过 errors.Is(err, ErrStaleEntry) 判断。
+type StaleEntryError struct {
+	Table        string
+	ID           interface{}
+	VersionField string
+	Version      interface{}
+}
+
+func (e *StaleEntryError) Error() string {
+	if e == nil {
+		return ErrStaleEntry.Error()
+	}
+	parts := make([]string, 0, 3)
+	if strings.TrimSpace(e.Table) != "" {
+		parts = append(parts, "table="+e.Table)
+	}
+	if e.ID != nil {
+		parts = append(parts, fmt.Sprintf("id=%v", e.ID))
+	}
+	if strings.TrimSpace(e.VersionField) != "" {
+		parts = append(parts, fmt.Sprintf("%s=%v", e.VersionField, e.Version))
+	}
+	if len(parts) == 0 {
+		return "stale entry: record was modified or deleted"
+	}
+	return "stale entry: record was modified or deleted (" + strings.Join(parts, "; ") + ")"
+}
+
+func (e *StaleEntryError) Unwrap() error {
+	return ErrStaleEntry
+}
+
+// versionFieldOf 返回 Schema 中标记为乐观锁的版本字段。
+func versionFieldOf(schema Schema) *Field {
+	if schema == nil {
+		return nil
+	}
+	for _, field := range schema.Fields() {
+		if field != nil && field.Version {
+			return field
+		}
+	}
+	return nil
+}
+
+// nextLockVersion 计算版本字段的下一个值。
+func nextLockVersion(current interface{}) (int64, error) {
+	if current == nil {
+		return 0, fmt.Errorf("optimistic lock requires the current version value")
+	}
+	version, err := toMappedInt64(current)
+	if err != nil {
+		return 0, fmt.Errorf("invalid optimistic lock version %v: %w", current, err)
+	}
+	return version + 1, nil
+}
+
+// currentLockVersion 取 Changeset 中版本字段的原始值（字段被 Cast 覆盖时取变更前的值）。
+func currentLockVersion(cs *Changeset, field string) interface{} {
+	if cs.HasChanged(field) {
+		if previous := cs.GetPrevious(field); previous != nil {
+			return previous
+		}
+	}
+	return cs.Get(field)
+}
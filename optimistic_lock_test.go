@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChangesetExecutorOptimisticLockSQLite(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+	if _, err := repo.Exec(ctx, "ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1"); err != nil {
+		t.Fatalf("failed to add version column: %v", err)
+	}
+	schema := buildUserSchemaForChangesetExecutor().AddOptimisticLock("")
+	schema.GetField("id").Primary = true
+
+	err := repo.WithChangeset(ctx, schema, func(e *ChangesetExecutor) error {
+		cs := NewChangeset(schema).Cast(map[string]interface{}{"id": 1, "name": "alice", "email": "a@x"})
+		_, err := e.Insert(cs)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	// 两个编辑者读取同一版本。
+	loaded := map[string]interface{}{"id": int64(1), "name": "alice", "email": "a@x", "version": int64(1)}
+	first := FromMap(schema, loaded).Cast(map[string]interface{}{"name": "alice-1"})
+	second := FromMap(schema, loaded).Cast(map[string]interface{}{"name": "alice-2"})
+
+	err = repo.WithChangeset(ctx, schema, func(e *ChangesetExecutor) error {
+		res, err := e.UpdateByID(1, first)
+		if err != nil {
+			return err
+		}
+		if affected, _ := res.RowsAffected(); affected != 1 {
+			t.Fatalf("expected 1 affected row, got %d", affected)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("first update failed: %v", err)
+	}
+	if first.Get("version") != int64(2) {
+		t.Fatalf("expected changeset version to advance to 2, got %v", first.Get("version"))
+	}
+
+	err = repo.WithChangeset(ctx, schema, func(e *ChangesetExecutor) error {
+		_, err := e.UpdateByID(1, second)
+		return err
+	})
+	var stale *StaleEntryError
+	if !errors.As(err, &stale) || !errors.Is(err, ErrStaleEntry) {
+		t.Fatalf("expected stale entry error, got: %v", err)
+	}
+	if stale.Table != "users" || stale.VersionField != "version" || stale.Version != int64(1) {
+		t.Fatalf("unexpected stale entry error: %+v", stale)
+	}
+
+	var name string
+	var version int64
+	if err := repo.QueryRow(ctx, "SELECT name, version FROM users WHERE id = 1").Scan(&name, &version); err != nil {
+		t.Fatalf("select failed: %v", err)
+	}
+	if name != "alice-1" || version != 2 {
+		t.Fatalf("stale update must not be applied: name=%s version=%d", name, version)
+	}
+
+	// 基于最新版本可继续更新。
+	executor, err := repo.NewChangesetExecutor(ctx, schema)
+	if err != nil {
+		t.Fatalf("failed to create changeset executor: %v", err)
+	}
+	if _, err := executor.UpdateByID(1, first.Cast(map[string]interface{}{"email": "b@x"})); err != nil {
+		t.Fatalf("update with fresh version failed: %v", err)
+	}
+	if _, err := executor.UpdateByID(1, NewChangeset(schema).Cast(map[string]interface{}{"name": "x"})); err == nil || !strings.Contains(err.Error(), "current version") {
+		t.Fatalf("expected missing version error, got: %v", err)
+	}
+	t.Logf("✓ optimistic lock rejected stale update: %v", stale)
+}
+
+func TestOptimisticLockSchemaOptions(t *testing.T) {
+	type lockedDocument struct {
+		ID      int64 `eit_db:"id,primary_key"`
+		Title   string
+		Version int64 `eit_db:"lock_version,version"`
+	}
+	schema, err := InferSchema(lockedDocument{})
+	if err != nil {
+		t.Fatalf("infer schema failed: %v", err)
+	}
+	if field := versionFieldOf(schema); field == nil || field.Name != "lock_version" {
+		t.Fatalf("expected lock_version to be the version field, got %+v", field)
+	}
+
+	base := NewBaseSchema("docs").AddOptimisticLock("rev")
+	if field := base.GetField("rev"); field == nil || !field.Version || field.Type != TypeInteger || field.Default != 1 {
+		t.Fatalf("unexpected version field: %+v", field)
+	}
+	if field := NewField("v", TypeInteger).Version().Build(); !field.Version {
+		t.Fatalf("expected FieldBuilder.Version to mark the field")
+	}
+}
+
+func TestMongoWritePlanOptimisticLock(t *testing.T) {
+	schema := NewBaseSchema("articles")
+	schema.AddField(NewField("_id", TypeString).PrimaryKey().Build())
+	schema.AddField(NewField("title", TypeString).Build())
+	schema.AddOptimisticLock("version")
+
+	qc := NewMongoQueryConstructor(schema)
+	qc.Where(Eq("_id", "a1"))
+	qc.UpdateMany(map[string]interface{}{"title": "new", "version": 9}, false).OptimisticLock("version", 3)
+	query, _, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	var plan MongoCompiledWritePlan
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(query, mongoCompiledWritePrefix)), &plan); err != nil {
+		t.Fatalf("decode plan failed: %v", err)
+	}
+	and, ok := plan.Filter["$and"].([]interface{})
+	if !ok || len(and) != 2 || !strings.Contains(query, `{"version":3}`) {
+		t.Fatalf("expected version filter, got: %s", query)
+	}
+	if !strings.Contains(query, `"$inc":{"version":1}`) || !strings.Contains(query, `"$set":{"title":"new"}`) {
+		t.Fatalf("expected version increment and trimmed $set, got: %s", query)
+	}
+
+	qc = NewMongoQueryConstructor(schema)
+	qc.Where(Eq("_id", "a1"))
+	qc.DeleteMany().OptimisticLock("version", 3)
+	if _, _, err := qc.Build(context.Background()); err == nil {
+		t.Fatalf("expected optimistic lock to reject delete_many")
+	}
+}
@@ -841,6 +841,33 @@ func (t *PostgreSQLTx) Rollback(ctx context.Context) error {
 	return t.tx.Rollback()
 }
 
+// Savepoint 创建事务保存点
+func (t *PostgreSQLTx) Savepoint(ctx context.Context, name string) error {
+	if err := validateSavepointName(name); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(ctx, "SAVEPOINT "+name)
+	return err
+}
+
+// RollbackTo 回滚到事务保存点
+func (t *PostgreSQLTx) RollbackTo(ctx context.Context, name string) error {
+	if err := validateSavepointName(name); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+	return err
+}
+
+// ReleaseSavepoint 释放事务保存点
+func (t *PostgreSQLTx) ReleaseSavepoint(ctx context.Context, name string) error {
+	if err := validateSavepointName(name); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
 // Exec 在事务中执行
 func (t *PostgreSQLTx) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	return t.tx.ExecContext(ctx, query, args...)
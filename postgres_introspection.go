@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// IntrospectTables 列出当前 schema（current_schema()）中的基础表。
+func (a *PostgreSQLAdapter) IntrospectTables(ctx context.Context) ([]string, error) {
+	rows, err := a.Query(ctx, `
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+		ORDER BY table_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanIntrospectionNames(rows)
+}
+
+// IntrospectSchema 基于 information_schema.columns 与 pg_catalog（pg_constraint / pg_index）反查表结构。
+// table 可带 schema 前缀（如 "audit.events"），否则使用 current_schema()。
+func (a *PostgreSQLAdapter) IntrospectSchema(ctx context.Context, table string) (*BaseSchema, error) {
+	schemaName, tableName := splitIntrospectionTableName(table)
+	result := &introspectedTable{name: table}
+
+	rows, err := a.Query(ctx, `
+		SELECT column_name, data_type, udt_name, is_nullable, column_default, is_identity
+		FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_name = $2
+		ORDER BY ordinal_position
+	`, schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			name, dataType, udtName, nullable, identity string
+			defaultValue                                sql.NullString
+		)
+		if err := rows.Scan(&name, &dataType, &udtName, &nullable, &defaultValue, &identity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		columnType := dataType
+		if dataType == "USER-DEFINED" {
+			columnType = udtName
+		}
+		field := &Field{Name: name, Type: fieldTypeFromColumnType(columnType), Null: nullable == "YES", Autoinc: identity == "YES"}
+		field.Timezone = dataType == "timestamp with time zone"
+		if defaultValue.Valid {
+			if strings.HasPrefix(defaultValue.String, "nextval(") {
+				field.Autoinc = true
+			} else {
+				field.Default = normalizeIntrospectedDefault(defaultValue.String, field.Type)
+			}
+		}
+		result.columns = append(result.columns, field)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 列名按约束 / 索引中的列序聚合为逗号分隔串，避免依赖驱动的数组类型
+	rows, err = a.Query(ctx, `
+		SELECT con.conname, con.contype,
+			(SELECT string_agg(att.attname, ',' ORDER BY k.ord)
+				FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute att ON att.attrelid = con.conrelid AND att.attnum = k.attnum),
+			COALESCE(CASE WHEN refns.nspname = ns.nspname THEN ref.relname ELSE refns.nspname || '.' || ref.relname END, ''),
+			COALESCE((SELECT string_agg(att.attname, ',' ORDER BY k.ord)
+				FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute att ON att.attrelid = con.confrelid AND att.attnum = k.attnum), ''),
+			con.confdeltype, con.confupdtype
+		FROM pg_constraint con
+		JOIN pg_class rel ON rel.oid = con.conrelid
+		JOIN pg_namespace ns ON ns.oid = rel.relnamespace
+		LEFT JOIN pg_class ref ON ref.oid = con.confrelid
+		LEFT JOIN pg_namespace refns ON refns.oid = ref.relnamespace
+		WHERE ns.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND rel.relname = $2 AND con.contype IN ('p', 'u', 'f')
+		ORDER BY con.contype, con.conname
+	`, schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name, kind, columns, refTable, refColumns, onDelete, onUpdate string
+		if err := rows.Scan(&name, &kind, &columns, &refTable, &refColumns, &onDelete, &onUpdate); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		fields := strings.Split(columns, ",")
+		switch kind {
+		case "p":
+			result.primaryKey = TableConstraint{Name: name, Fields: fields}
+		case "u":
+			result.constraints = append(result.constraints, TableConstraint{Name: name, Kind: ConstraintUnique, Fields: fields})
+		case "f":
+			result.constraints = append(result.constraints, TableConstraint{
+				Name:      name,
+				Kind:      ConstraintForeignKey,
+				Fields:    fields,
+				RefTable:  refTable,
+				RefFields: strings.Split(refColumns, ","),
+				OnDelete:  normalizeReferentialAction(onDelete),
+				OnUpdate:  normalizeReferentialAction(onUpdate),
+			})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 约束自带的索引已由上面覆盖；部分索引与表达式索引无法表示，跳过
+	rows, err = a.Query(ctx, `
+		SELECT idx.relname, ix.indisunique,
+			(SELECT string_agg(att.attname, ',' ORDER BY k.ord)
+				FROM unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute att ON att.attrelid = ix.indrelid AND att.attnum = k.attnum)
+		FROM pg_index ix
+		JOIN pg_class idx ON idx.oid = ix.indexrelid
+		JOIN pg_class rel ON rel.oid = ix.indrelid
+		JOIN pg_namespace ns ON ns.oid = rel.relnamespace
+		WHERE ns.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND rel.relname = $2
+			AND ix.indpred IS NULL AND ix.indexprs IS NULL
+			AND NOT EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = ix.indexrelid)
+		ORDER BY idx.relname
+	`, schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			name    string
+			unique  bool
+			columns sql.NullString
+		)
+		if err := rows.Scan(&name, &unique, &columns); err != nil {
+			return nil, err
+		}
+		if columns.String == "" {
+			continue
+		}
+		result.indexes = append(result.indexes, TableIndex{Name: name, Fields: strings.Split(columns.String, ","), Unique: unique})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result.toSchema()
+}
@@ -0,0 +1,490 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+)
+
+// preloadBatchSize SQL 预加载单条 IN (...) 查询的最大键数量，超出时分批查询。
+const preloadBatchSize = 500
+
+// QueryPreloadIR 关联预加载的中间表示：Preload 路径按层级合并为树。
+type QueryPreloadIR struct {
+	Name     string // 结果行中的字段名（关系名，未命名的关系为目标表名）
+	Relation *SchemaRelation
+	Children []QueryPreloadIR
+}
+
+// single HasOne / BelongsTo 预加载为单条记录，无匹配时为 nil。
+func (p QueryPreloadIR) single() bool {
+	return p.Relation.Type == RelationHasOne || p.Relation.Type == RelationBelongsTo
+}
+
+// buildPreloadTree 将 "items.product" 形式的路径合并进预加载树；关系按 CastAssoc 相同的规则逐级查找。
+func buildPreloadTree(schema Schema, tree []QueryPreloadIR, paths []string) ([]QueryPreloadIR, error) {
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		var err error
+		tree, err = mergePreloadPath(schema, tree, strings.Split(path, "."), path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tree, nil
+}
+
+func mergePreloadPath(schema Schema, nodes []QueryPreloadIR, segments []string, path string) ([]QueryPreloadIR, error) {
+	name := strings.TrimSpace(segments[0])
+	if name == "" {
+		return nil, fmt.Errorf("invalid preload path %q", path)
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("preload %q: schema is required", path)
+	}
+	index := -1
+	for i := range nodes {
+		if nodes[i].Name == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		relation := findAssocRelation(schema, name)
+		if relation == nil {
+			return nil, fmt.Errorf("preload %q: unknown relation %s on %s", path, name, schema.TableName())
+		}
+		switch {
+		case relation.Type == RelationManyToMany && (relation.Through == nil || strings.TrimSpace(relation.Through.Table) == ""):
+			return nil, fmt.Errorf("preload %q: relation %s has no through table (declare it with Through)", path, name)
+		case relation.Type != RelationManyToMany && relation.ForeignKey == "":
+			return nil, fmt.Errorf("preload %q: relation %s has no foreign key (declare it with Over)", path, name)
+		}
+		nodes = append(nodes, QueryPreloadIR{Name: name, Relation: relation})
+		index = len(nodes) - 1
+	}
+	if len(segments) > 1 {
+		children, err := mergePreloadPath(nodes[index].Relation.TargetSchema, nodes[index].Children, segments[1:], path)
+		if err != nil {
+			return nil, err
+		}
+		nodes[index].Children = children
+	}
+	return nodes, nil
+}
+
+// Preload 预加载关联：主查询之后每层关系执行一次 IN (...) 批量查询，结果按关系名嵌套到父行。
+// HasMany / ManyToMany 为 []map[string]interface{}（无匹配时为空切片），HasOne / BelongsTo 为单条记录或 nil。
+func (qb *SQLQueryConstructor) Preload(paths ...string) QueryConstructor {
+	preloads, err := buildPreloadTree(qb.schema, qb.preloads, paths)
+	if err != nil {
+		qb.buildErr = err
+		return qb
+	}
+	qb.preloads = preloads
+	return qb
+}
+
+// Preload 预加载关联，编译为 $lookup 子管道。
+func (qb *MongoQueryConstructor) Preload(paths ...string) QueryConstructor {
+	preloads, err := buildPreloadTree(qb.schema, qb.preloads, paths)
+	if err != nil {
+		qb.buildErr = err
+		return qb
+	}
+	qb.preloads = preloads
+	return qb
+}
+
+// Preload 预加载关联，编译为 OPTIONAL MATCH + collect()。
+func (qb *Neo4jQueryConstructor) Preload(paths ...string) QueryConstructor {
+	preloads, err := buildPreloadTree(qb.schema, qb.preloads, paths)
+	if err != nil {
+		qb.buildErr = err
+		return qb
+	}
+	qb.preloads = preloads
+	return qb
+}
+
+// queryPreloader 由在主查询之后批量加载关联的构造器实现（SQL 后端）。
+type queryPreloader interface {
+	preloadPlan() (Schema, []QueryPreloadIR)
+}
+
+func (qb *SQLQueryConstructor) preloadPlan() (Schema, []QueryPreloadIR) {
+	return qb.schema, qb.preloads
+}
+
+// preloadQueryConstructorResult 为结果行加载构造器声明的 Preload 关联。
+func (r *Repository) preloadQueryConstructorResult(ctx context.Context, constructor QueryConstructor, result *QueryConstructorExecutionResult) (*QueryConstructorExecutionResult, error) {
+	if result == nil {
+		return result, nil
+	}
+	preloader, ok := constructor.GetNativeBuilder().(queryPreloader)
+	if !ok {
+		return result, nil
+	}
+	schema, preloads := preloader.preloadPlan()
+	if len(preloads) == 0 {
+		return result, nil
+	}
+	if err := r.preloadRows(ctx, schema, result.Rows, preloads); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// preloadRows 逐个关系批量加载子记录并写入父行，再对子记录递归加载下一层。
+func (r *Repository) preloadRows(ctx context.Context, schema Schema, rows []map[string]interface{}, preloads []QueryPreloadIR) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	for _, preload := range preloads {
+		children, err := r.preloadRelation(ctx, schema, rows, preload)
+		if err != nil {
+			return fmt.Errorf("preload %s: %w", preload.Name, err)
+		}
+		if err := r.preloadRows(ctx, preload.Relation.TargetSchema, children, preload.Children); err != nil {
+			return fmt.Errorf("preload %s: %w", preload.Name, err)
+		}
+	}
+	return nil
+}
+
+// preloadRelation 加载单个关系并写入父行，返回加载到的子记录。
+func (r *Repository) preloadRelation(ctx context.Context, schema Schema, rows []map[string]interface{}, preload QueryPreloadIR) ([]map[string]interface{}, error) {
+	relation := preload.Relation
+	target := relation.TargetSchema
+	switch relation.Type {
+	case RelationBelongsTo:
+		targetKey := relation.OriginKey
+		if targetKey == "" {
+			targetKey = primaryKeyNameOrDefaultIR(target)
+		}
+		children, err := r.fetchPreloadRows(ctx, rows, relation.ForeignKey, target, targetKey)
+		if err != nil {
+			return nil, err
+		}
+		byKey := groupPreloadRows(children, targetKey)
+		for _, row := range rows {
+			row[preload.Name] = firstPreloadRow(byKey, row[relation.ForeignKey])
+		}
+		return children, nil
+
+	case RelationManyToMany:
+		through := relation.Through
+		parentKey := primaryKeyNameOrDefaultIR(schema)
+		links, err := r.fetchPreloadRows(ctx, rows, parentKey, throughSchemaOf(through), through.SourceKey)
+		if err != nil {
+			return nil, err
+		}
+		targetKey := primaryKeyNameOrDefaultIR(target)
+		children, err := r.fetchPreloadRows(ctx, links, through.TargetKey, target, targetKey)
+		if err != nil {
+			return nil, err
+		}
+		byKey := groupPreloadRows(children, targetKey)
+		grouped := make(map[string][]map[string]interface{})
+		for _, link := range links {
+			source, ok := preloadKey(link[through.SourceKey])
+			if !ok {
+				continue
+			}
+			if targetKey, ok := preloadKey(link[through.TargetKey]); ok {
+				grouped[source] = append(grouped[source], byKey[targetKey]...)
+			}
+		}
+		for _, row := range rows {
+			row[preload.Name] = manyPreloadRows(grouped, row[parentKey])
+		}
+		return children, nil
+
+	default:
+		parentKey := assocParentKey(relation, schema)
+		children, err := r.fetchPreloadRows(ctx, rows, parentKey, target, relation.ForeignKey)
+		if err != nil {
+			return nil, err
+		}
+		grouped := groupPreloadRows(children, relation.ForeignKey)
+		for _, row := range rows {
+			if preload.single() {
+				row[preload.Name] = firstPreloadRow(grouped, row[parentKey])
+			} else {
+				row[preload.Name] = manyPreloadRows(grouped, row[parentKey])
+			}
+		}
+		return children, nil
+	}
+}
+
+// fetchPreloadRows 收集父行 sourceField 的去重键，按 target.targetField IN (...) 分批查询（沿用目标 Schema 的默认软删除作用域）。
+func (r *Repository) fetchPreloadRows(ctx context.Context, rows []map[string]interface{}, sourceField string, target Schema, targetField string) ([]map[string]interface{}, error) {
+	keys := make([]interface{}, 0, len(rows))
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		value, ok := row[sourceField]
+		if !ok {
+			return nil, fmt.Errorf("result rows have no %s column", sourceField)
+		}
+		key, ok := preloadKey(value)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, preloadValue(value))
+	}
+
+	children := make([]map[string]interface{}, 0)
+	for start := 0; start < len(keys); start += preloadBatchSize {
+		end := start + preloadBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		constructor, err := r.NewQueryConstructor(target)
+		if err != nil {
+			return nil, err
+		}
+		constructor.Where(In(targetField, keys[start:end]...))
+		if pk := target.PrimaryKeyField(); pk != nil {
+			constructor.OrderBy(pk.Name, "ASC")
+		}
+		result, err := r.ExecuteQueryConstructor(ctx, constructor)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, result.Rows...)
+	}
+	return children, nil
+}
+
+// preloadValue 统一驱动返回的键值类型（[]byte 转为 string）。
+func preloadValue(value interface{}) interface{} {
+	value = normalizeMappedValue(value)
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
+
+// preloadKey 返回用于父子行匹配的键；nil 值不参与匹配。
+func preloadKey(value interface{}) (string, bool) {
+	value = preloadValue(value)
+	if value == nil {
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
+
+func groupPreloadRows(rows []map[string]interface{}, field string) map[string][]map[string]interface{} {
+	grouped := make(map[string][]map[string]interface{}, len(rows))
+	for _, row := range rows {
+		if key, ok := preloadKey(row[field]); ok {
+			grouped[key] = append(grouped[key], row)
+		}
+	}
+	return grouped
+}
+
+func firstPreloadRow(grouped map[string][]map[string]interface{}, value interface{}) interface{} {
+	key, ok := preloadKey(value)
+	if !ok || len(grouped[key]) == 0 {
+		return nil
+	}
+	return grouped[key][0]
+}
+
+func manyPreloadRows(grouped map[string][]map[string]interface{}, value interface{}) []map[string]interface{} {
+	items := make([]map[string]interface{}, 0)
+	if key, ok := preloadKey(value); ok {
+		items = append(items, grouped[key]...)
+	}
+	return items
+}
+
+// buildMongoPreloadPipeline 将预加载编译为 $lookup（let + $expr 关联子集合）；嵌套关联在子管道内继续 $lookup，
+// ManyToMany 先关联中间集合再以 $replaceRoot 展开目标文档；单值关联以 $addFields 取首个元素，无匹配时为 null。
+func buildMongoPreloadPipeline(schema Schema, preloads []QueryPreloadIR, depth int) ([]map[string]interface{}, error) {
+	stages := make([]map[string]interface{}, 0, len(preloads))
+	variable := fmt.Sprintf("preload_%d", depth)
+	for _, preload := range preloads {
+		relation := preload.Relation
+		target := relation.TargetSchema
+		targetPipeline := func(variable, field string) ([]interface{}, error) {
+			pipeline := []interface{}{mongoPreloadMatch(field, variable)}
+			if schemaSupportsSoftDelete(target) {
+				filter, err := buildMongoFilter([]Condition{IsNull(softDeleteField)})
+				if err != nil {
+					return nil, err
+				}
+				pipeline = append(pipeline, map[string]interface{}{"$match": filter})
+			}
+			nested, err := buildMongoPreloadPipeline(target, preload.Children, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			for _, stage := range nested {
+				pipeline = append(pipeline, stage)
+			}
+			return pipeline, nil
+		}
+
+		var (
+			from       = target.TableName()
+			localField string
+			pipeline   []interface{}
+			err        error
+		)
+		switch relation.Type {
+		case RelationBelongsTo:
+			targetKey := relation.OriginKey
+			if targetKey == "" {
+				targetKey = primaryKeyNameOrDefaultIR(target)
+			}
+			localField = relation.ForeignKey
+			pipeline, err = targetPipeline(variable, targetKey)
+		case RelationManyToMany:
+			through := relation.Through
+			targetVariable := variable + "_target"
+			inner, innerErr := targetPipeline(targetVariable, primaryKeyNameOrDefaultIR(target))
+			if innerErr != nil {
+				return nil, innerErr
+			}
+			from = through.Table
+			localField = primaryKeyNameOrDefaultIR(schema)
+			pipeline = []interface{}{
+				mongoPreloadMatch(through.SourceKey, variable),
+				map[string]interface{}{"$lookup": map[string]interface{}{
+					"from":     target.TableName(),
+					"let":      map[string]interface{}{targetVariable: "$" + through.TargetKey},
+					"pipeline": inner,
+					"as":       "_preload_target",
+				}},
+				map[string]interface{}{"$unwind": "$_preload_target"},
+				map[string]interface{}{"$replaceRoot": map[string]interface{}{"newRoot": "$_preload_target"}},
+			}
+		default:
+			localField = assocParentKey(relation, schema)
+			pipeline, err = targetPipeline(variable, relation.ForeignKey)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		stages = append(stages, map[string]interface{}{"$lookup": map[string]interface{}{
+			"from":     from,
+			"let":      map[string]interface{}{variable: "$" + localField},
+			"pipeline": pipeline,
+			"as":       preload.Name,
+		}})
+		if preload.single() {
+			stages = append(stages, map[string]interface{}{"$addFields": map[string]interface{}{
+				preload.Name: map[string]interface{}{
+					"$ifNull": []interface{}{map[string]interface{}{"$arrayElemAt": []interface{}{"$" + preload.Name, 0}}, nil},
+				},
+			}})
+		}
+	}
+	return stages, nil
+}
+
+func mongoPreloadMatch(field, variable string) map[string]interface{} {
+	return map[string]interface{}{
+		"$match": map[string]interface{}{
+			"$expr": map[string]interface{}{"$eq": []interface{}{"$" + field, "$$" + variable}},
+		},
+	}
+}
+
+// compileCypherPreloads 将预加载编译为 OPTIONAL MATCH + WITH ... collect()，返回子句与各关联结果变量。
+// carry 为后续 WITH 需要保留的变量（主节点与 JOIN 节点）。
+func compileCypherPreloads(schema Schema, sourceAlias string, carry []string, preloads []QueryPreloadIR) (string, []string) {
+	var cypher strings.Builder
+	counter := 0
+	carry = append([]string(nil), carry...)
+	columns := make([]string, 0, len(preloads))
+	for _, preload := range preloads {
+		column := writeCypherPreload(&cypher, schema, sourceAlias, carry, preload, &counter)
+		carry = append(carry, column)
+		columns = append(columns, column)
+	}
+	return cypher.String(), columns
+}
+
+func writeCypherPreload(cypher *strings.Builder, schema Schema, parent string, carry []string, preload QueryPreloadIR, counter *int) string {
+	*counter++
+	node := fmt.Sprintf("pl%d", *counter)
+	relation := preload.Relation
+	target := relation.TargetSchema
+	label := sanitizeLabel(target.TableName(), "Node")
+
+	cypher.WriteString(" OPTIONAL MATCH ")
+	if relation.Type == RelationManyToMany {
+		relType := "RELATED_TO"
+		if strings.TrimSpace(relation.Name) != "" {
+			relType = normalizeCypherRelType(relation.Name)
+		}
+		cypher.WriteString("(" + parent + ")-[:" + relType + "]->(:" + sanitizeLabel(relation.Through.Table, "Middle") + ")-[:" + relType + "]->(" + node + ":" + label + ")")
+	} else {
+		relType := inferNeo4jRelType(schema, target)
+		if strings.TrimSpace(relation.Name) != "" {
+			relType = normalizeCypherRelType(relation.Name)
+		}
+		cypher.WriteString(buildRelationshipPattern(parent, node, label, "", relType, "out"))
+	}
+	if schemaSupportsSoftDelete(target) {
+		cypher.WriteString(" WHERE " + node + "." + softDeleteField + " IS NULL")
+	}
+
+	inner := append(append([]string(nil), carry...), node)
+	entries := make([]string, 0, len(preload.Children))
+	for _, child := range preload.Children {
+		column := writeCypherPreload(cypher, target, node, inner, child, counter)
+		inner = append(inner, column)
+		entries = append(entries, sanitizeSymbol(child.Name, column)+": "+column)
+	}
+	value := node
+	if len(entries) > 0 {
+		value = node + " {.*, " + strings.Join(entries, ", ") + "}"
+	}
+	aggregate := "collect(" + value + ")"
+	if preload.single() {
+		aggregate = "head(" + aggregate + ")"
+	}
+	column := node + "_rows"
+	cypher.WriteString(" WITH " + strings.Join(carry, ", ") + ", " + aggregate + " AS " + column)
+	return column
+}
+
+// postProcessQueryRows 预加载查询返回主节点与关联列：主节点展开为属性，关联节点转换为属性 map。
+func (qb *Neo4jQueryConstructor) postProcessQueryRows(rows []map[string]interface{}) ([]map[string]interface{}, error) {
+	if len(qb.preloads) == 0 {
+		return rows, nil
+	}
+	alias := sanitizeSymbol(qb.fromAlias, "n")
+	for i, row := range rows {
+		out := make(map[string]interface{}, len(row))
+		if node, ok := row[alias].(dbtype.Node); ok {
+			for key, value := range node.Props {
+				out[key] = value
+			}
+		}
+		for key, value := range row {
+			if key == alias {
+				if _, ok := value.(dbtype.Node); ok {
+					continue
+				}
+			}
+			out[key] = value
+		}
+		for _, preload := range qb.preloads {
+			if value, ok := out[preload.Name]; ok {
+				out[preload.Name] = normalizeMappedValue(value)
+			}
+		}
+		rows[i] = out
+	}
+	return rows, nil
+}
@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type preloadTestSchemas struct {
+	customers, orders, items, products, tags, orderTags *BaseSchema
+}
+
+func newPreloadTestSchemas() preloadTestSchemas {
+	s := preloadTestSchemas{
+		customers: NewBaseSchema("customers"),
+		orders:    NewBaseSchema("orders"),
+		items:     NewBaseSchema("items"),
+		products:  NewBaseSchema("products"),
+		tags:      NewBaseSchema("tags"),
+		orderTags: NewBaseSchema("order_tags"),
+	}
+	s.customers.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	s.customers.AddField(NewField("name", TypeString).Build())
+	s.orders.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	s.orders.AddField(NewField("customer_id", TypeInteger).Build())
+	s.items.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	s.items.AddField(NewField("order_id", TypeInteger).Build())
+	s.items.AddField(NewField("product_id", TypeInteger).Build())
+	s.items.AddField(NewField("qty", TypeInteger).Build())
+	s.items.AddSoftDelete()
+	s.products.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	s.products.AddField(NewField("name", TypeString).Build())
+	s.tags.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	s.tags.AddField(NewField("name", TypeString).Build())
+	s.orderTags.AddField(NewField("order_id", TypeInteger).Build())
+	s.orderTags.AddField(NewField("tag_id", TypeInteger).Build())
+
+	s.orders.BelongsTo(s.customers).Over("customer_id", "id").Named("customer")
+	s.orders.HasMany(s.items).Over("order_id", "id")
+	s.orders.ManyToMany(s.tags).Through(s.orderTags, "order_id", "tag_id")
+	s.items.BelongsTo(s.products).Over("product_id", "id").Named("product")
+	return s
+}
+
+type preloadProduct struct {
+	ID   int64
+	Name string
+}
+
+type preloadItem struct {
+	ID      int64
+	Qty     int
+	Product *preloadProduct
+}
+
+type preloadOrder struct {
+	ID       int64
+	Customer *struct{ Name string }
+	Items    []preloadItem
+	Tags     []*struct{ Name string }
+}
+
+func TestPreloadSQLite(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER NOT NULL)",
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, order_id INTEGER NOT NULL, product_id INTEGER NOT NULL, qty INTEGER NOT NULL, deleted_at DATETIME NULL)",
+		"CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE tags (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE order_tags (order_id INTEGER NOT NULL, tag_id INTEGER NOT NULL)",
+		"INSERT INTO customers (id, name) VALUES (1, 'alice')",
+		"INSERT INTO orders (id, customer_id) VALUES (1, 1), (2, 1), (3, 99)",
+		"INSERT INTO products (id, name) VALUES (1, 'pen'), (2, 'ink')",
+		"INSERT INTO items (id, order_id, product_id, qty) VALUES (1, 1, 1, 2), (2, 1, 2, 1), (3, 2, 1, 5)",
+		"INSERT INTO items (id, order_id, product_id, qty, deleted_at) VALUES (4, 1, 2, 9, CURRENT_TIMESTAMP)",
+		"INSERT INTO tags (id, name) VALUES (1, 'urgent'), (2, 'gift')",
+		"INSERT INTO order_tags (order_id, tag_id) VALUES (1, 1), (1, 2), (2, 2)",
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+	s := newPreloadTestSchemas()
+
+	qc, err := repo.NewQueryConstructor(s.orders)
+	if err != nil {
+		t.Fatalf("new query constructor failed: %v", err)
+	}
+	qc.Preload("items", "items.product").Preload("customer", "tags").OrderBy("id", "ASC")
+	result, err := repo.ExecuteQueryConstructor(ctx, qc)
+	if err != nil {
+		t.Fatalf("execute with preload failed: %v", err)
+	}
+	if strings.Contains(strings.ToUpper(result.Statement), "JOIN") {
+		t.Fatalf("expected parent query without JOIN, got %s", result.Statement)
+	}
+	if len(result.Rows) != 3 {
+		t.Fatalf("expected 3 parent rows without duplication, got %d", len(result.Rows))
+	}
+
+	first := result.Rows[0]
+	items, ok := first["items"].([]map[string]interface{})
+	if !ok || len(items) != 2 {
+		t.Fatalf("expected 2 live items on order 1, got %#v", first["items"])
+	}
+	if product, ok := items[0]["product"].(map[string]interface{}); !ok || fmt.Sprint(product["name"]) != "pen" {
+		t.Fatalf("expected nested product pen, got %#v", items[0]["product"])
+	}
+	if customer, ok := first["customer"].(map[string]interface{}); !ok || fmt.Sprint(customer["name"]) != "alice" {
+		t.Fatalf("expected customer alice, got %#v", first["customer"])
+	}
+	if tags, ok := first["tags"].([]map[string]interface{}); !ok || len(tags) != 2 {
+		t.Fatalf("expected 2 tags on order 1, got %#v", first["tags"])
+	}
+	last := result.Rows[2]
+	if items, ok := last["items"].([]map[string]interface{}); !ok || len(items) != 0 || last["customer"] != nil {
+		t.Fatalf("expected empty items and nil customer on order 3, got %#v", last)
+	}
+
+	orders, err := MapRows[preloadOrder](result.Rows)
+	if err != nil {
+		t.Fatalf("map preloaded rows failed: %v", err)
+	}
+	if len(orders[0].Items) != 2 || orders[0].Items[1].Product == nil || orders[0].Items[1].Product.Name != "ink" {
+		t.Fatalf("unexpected typed items: %+v", orders[0].Items)
+	}
+	if orders[0].Customer == nil || orders[0].Customer.Name != "alice" || len(orders[1].Tags) != 1 || orders[1].Tags[0].Name != "gift" {
+		t.Fatalf("unexpected typed relations: %+v", orders[1])
+	}
+	if orders[2].Customer != nil || len(orders[2].Items) != 0 {
+		t.Fatalf("expected empty relations on order 3, got %+v", orders[2])
+	}
+
+	missingKey, _ := repo.NewQueryConstructor(s.orders)
+	missingKey.Select("customer_id").Preload("items")
+	if _, err := repo.ExecuteQueryConstructor(ctx, missingKey); err == nil || !strings.Contains(err.Error(), "no id column") {
+		t.Fatalf("expected missing key column error, got: %v", err)
+	}
+
+	// 预加载依赖完整结果集，流式读取直接报错而非静默丢弃关联
+	if _, err := repo.ExecuteQueryConstructorStream(ctx, qc, QueryStreamOptions{}); err == nil || !strings.Contains(err.Error(), "preloads") {
+		t.Fatalf("expected streaming with preloads to be rejected, got: %v", err)
+	}
+	// 嵌套行解码失败不再被吞掉
+	type badCustomerOrder struct {
+		Customer *struct {
+			Name int `json:"name"`
+		}
+	}
+	if _, err := MapRows[badCustomerOrder](result.Rows[:1]); err == nil || !strings.Contains(err.Error(), "decode nested row") {
+		t.Fatalf("expected nested decode error, got: %v", err)
+	}
+	t.Logf("✓ batched association preloading")
+}
+
+func TestPreloadUnknownRelation(t *testing.T) {
+	s := newPreloadTestSchemas()
+	for _, qc := range []QueryConstructor{
+		NewSQLQueryConstructor(s.orders, NewSQLiteDialect()),
+		NewMongoQueryConstructor(s.orders),
+		NewNeo4jQueryConstructor(s.orders),
+	} {
+		if _, _, err := qc.Preload("items.missing").Build(context.Background()); err == nil || !strings.Contains(err.Error(), "unknown relation missing") {
+			t.Fatalf("expected unknown relation error, got: %v", err)
+		}
+	}
+}
+
+func TestMongoPreloadPipeline(t *testing.T) {
+	s := newPreloadTestSchemas()
+	plan, err := NewMongoQueryConstructor(s.orders).Select("id").Preload("items.product", "customer", "tags").(*MongoQueryConstructor).BuildFindPlan()
+	if err != nil {
+		t.Fatalf("build find plan failed: %v", err)
+	}
+	if len(plan.Preloads) != 4 {
+		t.Fatalf("expected items, customer (+$addFields) and tags stages, got %d", len(plan.Preloads))
+	}
+	encoded, _ := json.Marshal(plan.Preloads)
+	for _, fragment := range []string{
+		`"$lookup":{"as":"items","from":"items","let":{"preload_0":"$id"}`,
+		`{"$match":{"$expr":{"$eq":["$order_id","$$preload_0"]}}},{"$match":{"deleted_at":null}}`,
+		`"$lookup":{"as":"product","from":"products","let":{"preload_1":"$product_id"}`,
+		`"$addFields":{"customer":{"$ifNull":[{"$arrayElemAt":["$customer",0]},null]}}`,
+		`"$lookup":{"as":"tags","from":"order_tags"`,
+		`{"$replaceRoot":{"newRoot":"$_preload_target"}}`,
+	} {
+		if !strings.Contains(string(encoded), fragment) {
+			t.Fatalf("expected preload stages to contain %s, got %s", fragment, encoded)
+		}
+	}
+	if !containsString(plan.Projection, "items") || !containsString(plan.Projection, "customer") {
+		t.Fatalf("expected preload fields kept in projection, got %v", plan.Projection)
+	}
+}
+
+func TestNeo4jPreloadCypher(t *testing.T) {
+	s := newPreloadTestSchemas()
+	cypher, _, err := NewNeo4jQueryConstructor(s.orders).Preload("items.product", "customer").OrderBy("id", "ASC").Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	want := "MATCH (n:orders)" +
+		" OPTIONAL MATCH (n)-[:RELATED_TO]->(pl1:items) WHERE pl1.deleted_at IS NULL" +
+		" OPTIONAL MATCH (pl1)-[:PRODUCT]->(pl2:products) WITH n, pl1, head(collect(pl2)) AS pl2_rows" +
+		" WITH n, collect(pl1 {.*, product: pl2_rows}) AS pl1_rows" +
+		" OPTIONAL MATCH (n)-[:CUSTOMER]->(pl3:customers) WITH n, pl1_rows, head(collect(pl3)) AS pl3_rows" +
+		" RETURN n, pl1_rows AS items, pl3_rows AS customer ORDER BY n.id ASC"
+	if cypher != want {
+		t.Fatalf("unexpected cypher:\n got: %s\nwant: %s", cypher, want)
+	}
+	if _, _, err := NewNeo4jQueryConstructor(s.orders).Preload("items").GroupBy("customer_id").Build(context.Background()); err == nil || !strings.Contains(err.Error(), "GROUP BY") {
+		t.Fatalf("expected preload with GROUP BY rejected, got: %v", err)
+	}
+}
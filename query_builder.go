@@ -225,7 +225,7 @@ func (qb *QueryBuilder) SoftDelete(whereClause string, whereArgs ...interface{})
 	}
 
 	cs := NewChangeset(qb.schema)
-	cs.PutChange("deleted_at", Timestamp())
+	cs.PutChange("deleted_at", qb.repo.timestampNow().Truncate(timestampPrecision(qb.repo.GetAdapter())))
 
 	return qb.Update(cs, whereClause, whereArgs...)
 }
@@ -325,6 +325,10 @@ func (qb *QueryBuilder) Transaction(fn func(*QueryBuilder) error) error {
 type txAdapter struct {
 	tx       Tx
 	provider QueryConstructorProvider
+	// owner 开启事务的 Repository；afterCommit 外层 WithChangeset 的 after-commit 队列，嵌套块共享。
+	owner       *Repository
+	afterCommit *[]func(context.Context) error
+	savepoints  int
 }
 
 func (ta *txAdapter) Connect(ctx context.Context, config *Config) error {
@@ -375,6 +379,10 @@ func (ta *txAdapter) GetQueryBuilderProvider() QueryConstructorProvider {
 	if ta.provider != nil {
 		return ta.provider
 	}
+	if ta.owner != nil {
+		// 沿用所属 Repository 的 provider，保留 Connect 时检测到的方言与版本
+		return ta.owner.adapter.GetQueryBuilderProvider()
+	}
 	return NewDefaultSQLQueryConstructorProvider(NewMySQLDialect())
 }
 
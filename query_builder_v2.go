@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -49,7 +51,14 @@ type SQLQueryConstructor struct {
 	compiler           QueryCompiler
 	selectedCols       []string
 	countExpr          *string
+	countWrapped       bool // 计数构造器：将去重查询包装为派生表后计数
+	distinct           bool
+	distinctOn         []string
+	aggregates         []QueryAggregateIR
+	windows            []QueryWindowIR
 	conditions         []Condition
+	groupBys           []string
+	having             []Condition
 	orderBys           []OrderBy
 	limitVal           *int
 	offsetVal          *int
@@ -57,11 +66,23 @@ type SQLQueryConstructor struct {
 	joins              []sqlJoinClause
 	crossTableStrategy CrossTableStrategy
 	customQueryMode    bool
+	setOperations      []querySetOperation
+	ctes               []*CTEBuilder
+	lock               *QueryLockIR
+	txBound            bool // 由事务适配器创建时为 true，Lock 行锁仅在事务内可用
+	softDeleteScope    softDeleteScope
+	preloads           []QueryPreloadIR
 	buildErr           error
 	// viewRegistry 指定查询跨表视图注册表；nil 时使用 GlobalCrossTableViewRegistry。
 	viewRegistry *CrossTableViewRegistry
 }
 
+// querySetOperation 构造器上登记的集合运算分支。
+type querySetOperation struct {
+	operator QuerySetOperator
+	query    QueryConstructor
+}
+
 type sqlJoinClause struct {
 	joinType string
 	semantic JoinSemantic // JoinWith: 已解析的语义意图
@@ -97,6 +118,62 @@ func (qb *SQLQueryConstructor) setBuildErr(err error) {
 	}
 }
 
+// requireFeature 按方言查询特性校验构造器能力。
+func (qb *SQLQueryConstructor) requireFeature(feature string) error {
+	name := strings.TrimSpace(qb.dialect.Name())
+	return requireQueryFeature(queryFeaturesForBackend(name), name, feature)
+}
+
+// requireVersionedFeature 在 requireFeature 基础上按方言声明的实例版本校验最低版本要求。
+func (qb *SQLQueryConstructor) requireVersionedFeature(feature string) error {
+	if err := qb.requireFeature(feature); err != nil {
+		return err
+	}
+	name := strings.TrimSpace(qb.dialect.Name())
+	features := queryFeaturesForBackend(name)
+	version := resolveSQLDialectVersionIR(qb.dialect)
+	if features == nil || version == "" || features.SupportsFeatureWithVersion(feature, version) {
+		return nil
+	}
+	return fmt.Errorf("%s %s does not support query feature %q (requires %s)", name, version, feature, features.GetFeatureSupport(feature).MinVersion)
+}
+
+func (qb *SQLQueryConstructor) isWindowAlias(name string) bool {
+	_, ok := findWindowIR(qb.windows, name)
+	return ok
+}
+
+func (qb *SQLQueryConstructor) isAggregateAlias(name string) bool {
+	trimmed := strings.TrimSpace(name)
+	for _, agg := range qb.aggregates {
+		if agg.Alias == trimmed {
+			return true
+		}
+	}
+	return false
+}
+
+func (qb *SQLQueryConstructor) validateHavingFields(condition Condition) error {
+	switch c := condition.(type) {
+	case *SimpleCondition:
+		if qb.isAggregateAlias(c.Field) {
+			return nil
+		}
+		return qb.validateFieldReference(c.Field, false)
+	case *CompositeCondition:
+		for _, inner := range c.Conditions {
+			if err := qb.validateHavingFields(inner); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *NotCondition:
+		return qb.validateHavingFields(c.Condition)
+	default:
+		return nil
+	}
+}
+
 func (qb *SQLQueryConstructor) isKnownSchemaField(name string) bool {
 	if qb.schema == nil {
 		return false
@@ -151,7 +228,10 @@ func (qb *SQLQueryConstructor) validateConditionFields(condition Condition) erro
 
 	switch c := condition.(type) {
 	case *SimpleCondition:
-		return qb.validateFieldReference(c.Field, false)
+		if err := qb.validateFieldReference(c.Field, false); err != nil {
+			return err
+		}
+		return qb.validateExtendedCondition(c)
 	case *CompositeCondition:
 		for _, inner := range c.Conditions {
 			if err := qb.validateConditionFields(inner); err != nil {
@@ -161,6 +241,14 @@ func (qb *SQLQueryConstructor) validateConditionFields(condition Condition) erro
 		return nil
 	case *NotCondition:
 		return qb.validateConditionFields(c.Condition)
+	case *SubqueryCondition:
+		if err := qb.requireFeature("subquery"); err != nil {
+			return err
+		}
+		if strings.TrimSpace(c.Field) == "" {
+			return nil
+		}
+		return qb.validateFieldReference(c.Field, false)
 	default:
 		return nil
 	}
@@ -359,6 +447,12 @@ func (qb *SQLQueryConstructor) qualifyCondition(condition Condition) Condition {
 		copied := *c
 		copied.Condition = qb.qualifyCondition(c.Condition)
 		return &copied
+	case *SubqueryCondition:
+		copied := *c
+		if strings.TrimSpace(c.Field) != "" {
+			copied.Field = qb.qualifyIdentifier(c.Field)
+		}
+		return &copied
 	default:
 		return condition
 	}
@@ -423,12 +517,18 @@ type SQLDialect interface {
 type DefaultSQLDialect struct {
 	name           string
 	parameterStyle string // "?" | "$n" | "@n"
+	serverVersion  string // 数据库实例版本；为空表示按最新版本能力编译
 }
 
 func (d *DefaultSQLDialect) Name() string {
 	return d.name
 }
 
+// ServerVersion 返回方言声明的数据库实例版本（用于按版本判断查询特性）。
+func (d *DefaultSQLDialect) ServerVersion() string {
+	return d.serverVersion
+}
+
 func (d *DefaultSQLDialect) QuoteIdentifier(name string) string {
 	return quoteIdentifierWithDelimiter(name, "`", "`")
 }
@@ -493,10 +593,16 @@ type MySQLDialect struct {
 }
 
 func NewMySQLDialect() *MySQLDialect {
+	return NewMySQLDialectWithVersion("")
+}
+
+// NewMySQLDialectWithVersion 创建指定实例版本的 MySQL 方言（例如 "5.7" 时窗口函数在应用层计算）。
+func NewMySQLDialectWithVersion(version string) *MySQLDialect {
 	return &MySQLDialect{
 		DefaultSQLDialect: DefaultSQLDialect{
 			name:           "mysql",
 			parameterStyle: "?",
+			serverVersion:  strings.TrimSpace(version),
 		},
 	}
 }
@@ -507,10 +613,16 @@ type SQLiteDialect struct {
 }
 
 func NewSQLiteDialect() *SQLiteDialect {
+	return NewSQLiteDialectWithVersion("")
+}
+
+// NewSQLiteDialectWithVersion 创建指定实例版本的 SQLite 方言（3.25.0 以下窗口函数在应用层计算）。
+func NewSQLiteDialectWithVersion(version string) *SQLiteDialect {
 	return &SQLiteDialect{
 		DefaultSQLDialect: DefaultSQLDialect{
 			name:           "sqlite",
 			parameterStyle: "?",
+			serverVersion:  strings.TrimSpace(version),
 		},
 	}
 }
@@ -754,6 +866,13 @@ func (qb *SQLQueryConstructor) qualifyConditionWithPrefix(condition Condition, p
 		copied := *c
 		copied.Condition = qb.qualifyConditionWithPrefix(c.Condition, prefix)
 		return &copied
+	case *SubqueryCondition:
+		copied := *c
+		field := strings.TrimSpace(c.Field)
+		if field != "" && !strings.Contains(field, ".") && prefix != "" {
+			copied.Field = prefix + "." + field
+		}
+		return &copied
 	default:
 		return condition
 	}
@@ -812,6 +931,8 @@ func (qb *SQLQueryConstructor) Count(fieldName ...string) QueryConstructor {
 	}
 	qb.countExpr = &expr
 	qb.selectedCols = nil
+	qb.aggregates = nil
+	qb.windows = nil
 	qb.limitVal = nil
 	qb.offsetVal = nil
 	qb.orderBys = nil
@@ -847,12 +968,290 @@ func (qb *SQLQueryConstructor) CountWith(builder *CountBuilder) QueryConstructor
 
 	qb.countExpr = &base
 	qb.selectedCols = nil
+	qb.aggregates = nil
+	qb.windows = nil
 	qb.limitVal = nil
 	qb.offsetVal = nil
 	qb.orderBys = nil
 	return qb
 }
 
+// GroupBy 设置分组字段。
+func (qb *SQLQueryConstructor) GroupBy(fields ...string) QueryConstructor {
+	qb.setBuildErr(qb.requireFeature("group_by"))
+	for _, field := range fields {
+		if trimmed := strings.TrimSpace(field); trimmed != "" {
+			qb.groupBys = append(qb.groupBys, trimmed)
+		}
+	}
+	return qb
+}
+
+// Having 添加分组过滤条件，字段可引用 Aggregate 设置的别名。
+func (qb *SQLQueryConstructor) Having(condition Condition) QueryConstructor {
+	if condition == nil {
+		return qb
+	}
+	qb.setBuildErr(qb.requireFeature("having"))
+	qb.having = append(qb.having, condition)
+	return qb
+}
+
+// Aggregate 追加聚合投影；与 Select 字段一起输出。
+func (qb *SQLQueryConstructor) Aggregate(builders ...*AggregateBuilder) QueryConstructor {
+	for _, builder := range builders {
+		agg, err := builder.toIR()
+		if err != nil {
+			qb.setBuildErr(err)
+			continue
+		}
+		if agg.Field != "*" {
+			qb.setBuildErr(qb.validateFieldReference(agg.Field, false))
+		}
+		qb.aggregates = append(qb.aggregates, agg)
+	}
+	qb.countExpr = nil
+	return qb
+}
+
+// Window 追加窗口函数投影；数据库版本不支持 OVER 子句时按回退策略在应用层计算。
+func (qb *SQLQueryConstructor) Window(builders ...*WindowBuilder) QueryConstructor {
+	for _, builder := range builders {
+		window, err := builder.toIR()
+		if err != nil {
+			qb.setBuildErr(err)
+			continue
+		}
+		if window.Field != "" && window.Field != "*" {
+			qb.setBuildErr(qb.validateFieldReference(window.Field, false))
+		}
+		for _, field := range window.PartitionBy {
+			qb.setBuildErr(qb.validateFieldReference(field, false))
+		}
+		for _, order := range window.OrderBy {
+			qb.setBuildErr(qb.validateFieldReference(order.Field, false))
+		}
+		qb.windows = append(qb.windows, window)
+	}
+	qb.countExpr = nil
+	return qb
+}
+
+// sqlWindowEmulation 应用层窗口计算计划：SQL 只取基础行，窗口值、排序与分页在结果集上完成。
+type sqlWindowEmulation struct {
+	windows       []QueryWindowIR
+	projections   []string
+	helperColumns []string       // 仅为计算窗口而追加的列，返回前移除
+	orderBys      []QueryOrderIR // 引用窗口别名时整体改为应用层排序
+	limit         *int
+	offset        *int
+}
+
+// nativeWindowSupport 判断当前方言（含声明版本）是否原生支持窗口函数。
+func (qb *SQLQueryConstructor) nativeWindowSupport() (bool, error) {
+	name := strings.TrimSpace(qb.dialect.Name())
+	features := queryFeaturesForBackend(name)
+	if features == nil || features.SupportsFeatureWithVersion("window_func", resolveSQLDialectVersionIR(qb.dialect)) {
+		return true, nil
+	}
+	if features.GetFallbackStrategy("window_func") == QueryFallbackApplicationLayer {
+		return false, nil
+	}
+	if version := resolveSQLDialectVersionIR(qb.dialect); version != "" {
+		name = name + " " + version
+	}
+	return false, fmt.Errorf("%s does not support query feature %q", name, "window_func")
+}
+
+// windowEmulationPlan 返回应用层窗口计算计划；原生支持或未使用窗口时返回 nil。
+func (qb *SQLQueryConstructor) windowEmulationPlan() (*sqlWindowEmulation, error) {
+	if len(qb.windows) == 0 {
+		return nil, nil
+	}
+	native, err := qb.nativeWindowSupport()
+	if err != nil || native {
+		return nil, err
+	}
+	if len(qb.aggregates) > 0 || len(qb.groupBys) > 0 || len(qb.setOperations) > 0 {
+		return nil, fmt.Errorf("%s window function fallback does not support GROUP BY, aggregates or set operations", qb.dialect.Name())
+	}
+
+	plan := &sqlWindowEmulation{
+		windows:     append([]QueryWindowIR(nil), qb.windows...),
+		projections: append([]string(nil), qb.selectedCols...),
+		limit:       qb.limitVal,
+		offset:      qb.offsetVal,
+	}
+	if len(plan.projections) > 0 && !containsString(plan.projections, "*") {
+		present := make(map[string]bool, len(plan.projections))
+		for _, field := range plan.projections {
+			present[resultColumnNameIR(field)] = true
+		}
+		addHelper := func(field string) {
+			if field == "" || field == "*" {
+				return
+			}
+			column := resultColumnNameIR(field)
+			if present[column] {
+				return
+			}
+			present[column] = true
+			plan.projections = append(plan.projections, field)
+			plan.helperColumns = append(plan.helperColumns, column)
+		}
+		for _, window := range qb.windows {
+			addHelper(window.Field)
+			for _, field := range window.PartitionBy {
+				addHelper(field)
+			}
+			for _, order := range window.OrderBy {
+				addHelper(order.Field)
+			}
+		}
+		for _, order := range qb.orderBys {
+			if !qb.isWindowAlias(order.Field) {
+				addHelper(order.Field)
+			}
+		}
+	}
+	for _, order := range qb.orderBys {
+		if qb.isWindowAlias(order.Field) {
+			for _, o := range qb.orderBys {
+				plan.orderBys = append(plan.orderBys, QueryOrderIR{Field: o.Field, Direction: o.Direction})
+			}
+			break
+		}
+	}
+	return plan, nil
+}
+
+// postProcessQueryRows 移除 DISTINCT ON 改写的辅助列，在应用层补齐窗口函数值，并完成被推迟的排序与分页。
+func (qb *SQLQueryConstructor) postProcessQueryRows(rows []map[string]interface{}) ([]map[string]interface{}, error) {
+	if len(qb.distinctOn) > 0 {
+		for _, row := range rows {
+			delete(row, distinctOnRowNumberColumn)
+		}
+	}
+	plan, err := qb.windowEmulationPlan()
+	if err != nil || plan == nil {
+		return rows, err
+	}
+	if err := computeWindowValuesInApp(rows, plan.windows); err != nil {
+		return nil, err
+	}
+	sortRowsInApp(rows, plan.orderBys)
+	if plan.offset != nil {
+		if *plan.offset >= len(rows) {
+			rows = rows[:0]
+		} else if *plan.offset > 0 {
+			rows = rows[*plan.offset:]
+		}
+	}
+	if plan.limit != nil && *plan.limit >= 0 && *plan.limit < len(rows) {
+		rows = rows[:*plan.limit]
+	}
+	for _, row := range rows {
+		for _, column := range plan.helperColumns {
+			delete(row, column)
+		}
+	}
+	return rows, nil
+}
+
+// streamRowProcessor 返回流式读取时的逐行处理函数；应用层窗口函数降级与 Preload 依赖完整结果集，不支持流式读取。
+func (qb *SQLQueryConstructor) streamRowProcessor() (func(row map[string]interface{}), error) {
+	if len(qb.preloads) > 0 {
+		return nil, fmt.Errorf("streaming is not supported with preloads; use ExecuteQueryConstructor")
+	}
+	plan, err := qb.windowEmulationPlan()
+	if err != nil {
+		return nil, err
+	}
+	if plan != nil {
+		return nil, fmt.Errorf("streaming is not supported with application-layer window function fallback; use ExecuteQueryConstructor")
+	}
+	if len(qb.distinctOn) == 0 {
+		return nil, nil
+	}
+	return func(row map[string]interface{}) {
+		delete(row, distinctOnRowNumberColumn)
+	}, nil
+}
+
+// With 追加 CTE；递归 CTE 的成员需与主查询使用相同方言。
+func (qb *SQLQueryConstructor) With(ctes ...*CTEBuilder) QueryConstructor {
+	for _, cte := range ctes {
+		if err := cte.validate(); err != nil {
+			qb.setBuildErr(err)
+			continue
+		}
+		if err := qb.requireVersionedFeature(cte.featureName()); err != nil {
+			qb.setBuildErr(err)
+			continue
+		}
+		members := []QueryConstructor{cte.query}
+		if cte.recursive != nil {
+			members = append(members, cte.recursive)
+		}
+		valid := true
+		for _, member := range members {
+			branch, ok := member.GetNativeBuilder().(*SQLQueryConstructor)
+			if !ok {
+				qb.setBuildErr(fmt.Errorf("sql cte %q requires SQL query constructors, got %T", cte.name, member.GetNativeBuilder()))
+				valid = false
+				break
+			}
+			if !strings.EqualFold(branch.dialect.Name(), qb.dialect.Name()) {
+				qb.setBuildErr(fmt.Errorf("cte %q dialect %q does not match %q", cte.name, branch.dialect.Name(), qb.dialect.Name()))
+				valid = false
+				break
+			}
+		}
+		if valid {
+			qb.ctes = append(qb.ctes, cte)
+		}
+	}
+	return qb
+}
+
+// Union 追加 UNION 分支（去重）。
+func (qb *SQLQueryConstructor) Union(other QueryConstructor) QueryConstructor {
+	return qb.addSetOperation(SetOperationUnion, other)
+}
+
+// UnionAll 追加 UNION ALL 分支（保留重复行）。
+func (qb *SQLQueryConstructor) UnionAll(other QueryConstructor) QueryConstructor {
+	return qb.addSetOperation(SetOperationUnionAll, other)
+}
+
+// Intersect 追加 INTERSECT 分支；MySQL 等不支持时改写为 INNER JOIN。
+func (qb *SQLQueryConstructor) Intersect(other QueryConstructor) QueryConstructor {
+	return qb.addSetOperation(SetOperationIntersect, other)
+}
+
+// Except 追加 EXCEPT 分支；MySQL 等不支持时改写为 NOT EXISTS。
+func (qb *SQLQueryConstructor) Except(other QueryConstructor) QueryConstructor {
+	return qb.addSetOperation(SetOperationExcept, other)
+}
+
+func (qb *SQLQueryConstructor) addSetOperation(operator QuerySetOperator, other QueryConstructor) QueryConstructor {
+	if other == nil {
+		qb.setBuildErr(fmt.Errorf("%s requires a query constructor", strings.ToLower(string(operator))))
+		return qb
+	}
+	branch, ok := other.GetNativeBuilder().(*SQLQueryConstructor)
+	if !ok {
+		qb.setBuildErr(fmt.Errorf("sql %s requires a SQL query constructor, got %T", strings.ToLower(string(operator)), other.GetNativeBuilder()))
+		return qb
+	}
+	if !strings.EqualFold(branch.dialect.Name(), qb.dialect.Name()) {
+		qb.setBuildErr(fmt.Errorf("%s branch dialect %q does not match %q", strings.ToLower(string(operator)), branch.dialect.Name(), qb.dialect.Name()))
+		return qb
+	}
+	qb.setOperations = append(qb.setOperations, querySetOperation{operator: operator, query: other})
+	return qb
+}
+
 // OrderBy 排序
 func (qb *SQLQueryConstructor) OrderBy(field string, direction string) QueryConstructor {
 	direction = normalizeOrderDirection(direction)
@@ -961,15 +1360,29 @@ func (qb *SQLQueryConstructor) buildCountConstructor() QueryConstructor {
 		dialect:            qb.dialect,
 		compiler:           qb.compiler,
 		selectedCols:       append([]string(nil), qb.selectedCols...),
+		aggregates:         append([]QueryAggregateIR(nil), qb.aggregates...),
+		windows:            append([]QueryWindowIR(nil), qb.windows...),
 		conditions:         append([]Condition(nil), qb.conditions...),
+		groupBys:           append([]string(nil), qb.groupBys...),
+		having:             append([]Condition(nil), qb.having...),
 		orderBys:           append([]OrderBy(nil), qb.orderBys...),
 		fromAlias:          qb.fromAlias,
 		joins:              append([]sqlJoinClause(nil), qb.joins...),
 		crossTableStrategy: qb.crossTableStrategy,
 		customQueryMode:    qb.customQueryMode,
+		ctes:               append([]*CTEBuilder(nil), qb.ctes...),
+		softDeleteScope:    qb.softDeleteScope,
 		buildErr:           qb.buildErr,
 		viewRegistry:       qb.viewRegistry,
 	}
+	if qb.distinct || len(qb.distinctOn) > 0 {
+		// 去重查询的总数为去重后的行数：保留投影与去重设置，Build 时包装为派生表计数。
+		clone.distinct = qb.distinct
+		clone.distinctOn = append([]string(nil), qb.distinctOn...)
+		clone.countWrapped = true
+		clone.orderBys = nil
+		return clone
+	}
 	if qb.countExpr != nil {
 		expr := *qb.countExpr
 		clone.countExpr = &expr
@@ -1000,6 +1413,9 @@ func (qb *SQLQueryConstructor) Build(ctx context.Context) (string, []interface{}
 		c.SetDialect(qb.dialect)
 	}
 
+	if qb.countWrapped {
+		return qb.compileWrappedCount(ctx, compiler, ir)
+	}
 	return compiler.Compile(ctx, ir)
 }
 
@@ -1017,14 +1433,10 @@ func (qb *SQLQueryConstructor) SelectCount(ctx context.Context, repo *Repository
 		return 0, err
 	}
 
-	countExpr := "COUNT(*)"
-	if qb.countExpr != nil {
-		countExpr = *qb.countExpr
-	}
-	ir.Projections = []string{countExpr}
 	ir.OrderBys = nil
 	ir.Limit = nil
 	ir.Offset = nil
+	ir.Lock = nil
 
 	compiler := qb.compiler
 	if compiler == nil {
@@ -1034,9 +1446,28 @@ func (qb *SQLQueryConstructor) SelectCount(ctx context.Context, repo *Repository
 		c.SetDialect(qb.dialect)
 	}
 
-	query, args, err := compiler.Compile(ctx, ir)
-	if err != nil {
-		return 0, err
+	var (
+		query string
+		args  []interface{}
+	)
+	if len(ir.GroupBys) > 0 || len(ir.SetOperations) > 0 || ir.Distinct || len(ir.DistinctOn) > 0 {
+		query, args, err = qb.compileWrappedCount(ctx, compiler, ir)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		countExpr := "COUNT(*)"
+		if qb.countExpr != nil {
+			countExpr = *qb.countExpr
+		}
+		ir.Projections = []string{countExpr}
+		ir.Aggregates = nil
+		ir.Windows = nil
+		ir.Having = nil
+		query, args, err = compiler.Compile(ctx, ir)
+		if err != nil {
+			return 0, err
+		}
 	}
 
 	row := repo.QueryRow(ctx, query, args...)
@@ -1047,8 +1478,21 @@ func (qb *SQLQueryConstructor) SelectCount(ctx context.Context, repo *Repository
 	return count, nil
 }
 
+// compileWrappedCount 分组 / 复合 / 去重查询统计结果行数：包装为派生表后计数；WITH 子句保持在最外层。
+func (qb *SQLQueryConstructor) compileWrappedCount(ctx context.Context, compiler QueryCompiler, ir *QueryIR) (string, []interface{}, error) {
+	ctes := ir.CTEs
+	ir.CTEs = nil
+	inner, args, err := compiler.Compile(ctx, ir)
+	if err != nil {
+		return "", nil, err
+	}
+	query := "SELECT COUNT(*) FROM (" + inner + ") AS " + qb.dialect.QuoteIdentifier("grouped_count")
+	return attachSQLCTEsIR(ctx, NewBaseSQLCompiler(qb.dialect), ctes, query, args)
+}
+
 // Upsert 基于 Changeset 执行 upsert。
 // 支持方言原生 upsert；不支持时回退到事务模拟（先 UPDATE，后 INSERT）。
+// 冲突更新时不覆盖 created_at。
 func (qb *SQLQueryConstructor) Upsert(ctx context.Context, repo *Repository, cs *Changeset, conflictColumns ...string) (sql.Result, error) {
 	if repo == nil {
 		return nil, fmt.Errorf("repository is nil")
@@ -1108,6 +1552,7 @@ func (qb *SQLQueryConstructor) buildNativeUpsertSQL(dialectName string, keys []s
 
 	nonConflict := nonConflictColumns(keys, conflictColumns)
 	nonConflict = excludePrimaryColumns(qb.schema, nonConflict)
+	nonConflict = excludeInsertOnlyColumns(qb.schema, nonConflict)
 	if len(nonConflict) == 0 {
 		nonConflict = []string{conflictColumns[0]}
 	}
@@ -1202,6 +1647,7 @@ func (qb *SQLQueryConstructor) upsertWithTransactionFallback(ctx context.Context
 	quotedTable := qb.dialect.QuoteIdentifier(qb.schema.TableName())
 	nonConflict := nonConflictColumns(keys, conflictColumns)
 	nonConflict = excludePrimaryColumns(qb.schema, nonConflict)
+	nonConflict = excludeInsertOnlyColumns(qb.schema, nonConflict)
 	if len(nonConflict) == 0 {
 		nonConflict = []string{conflictColumns[0]}
 	}
@@ -1367,6 +1813,9 @@ func (qb *SQLQueryConstructor) BuildIR(ctx context.Context) (*QueryIR, error) {
 	if qb.buildErr != nil {
 		return nil, qb.buildErr
 	}
+	if qb.lock != nil && !qb.txBound {
+		return nil, fmt.Errorf("row lock requires a transaction-bound repository (create it with ChangesetExecutor.NewQueryConstructor inside WithChangeset)")
+	}
 
 	if !qb.customQueryMode {
 		for _, f := range qb.selectedCols {
@@ -1375,6 +1824,9 @@ func (qb *SQLQueryConstructor) BuildIR(ctx context.Context) (*QueryIR, error) {
 			}
 		}
 		for _, o := range qb.orderBys {
+			if qb.isAggregateAlias(o.Field) || qb.isWindowAlias(o.Field) {
+				continue
+			}
 			if err := qb.validateFieldReference(o.Field, false); err != nil {
 				return nil, err
 			}
@@ -1384,6 +1836,16 @@ func (qb *SQLQueryConstructor) BuildIR(ctx context.Context) (*QueryIR, error) {
 				return nil, err
 			}
 		}
+		for _, f := range qb.groupBys {
+			if err := qb.validateFieldReference(f, false); err != nil {
+				return nil, err
+			}
+		}
+		for _, c := range qb.having {
+			if err := qb.validateHavingFields(c); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	projections := append([]string(nil), qb.selectedCols...)
@@ -1397,19 +1859,44 @@ func (qb *SQLQueryConstructor) BuildIR(ctx context.Context) (*QueryIR, error) {
 			Alias:  strings.TrimSpace(qb.fromAlias),
 			Schema: qb.schema,
 		},
+		Distinct:           qb.distinct,
+		DistinctOn:         append([]string(nil), qb.distinctOn...),
 		Projections:        projections,
+		Aggregates:         append([]QueryAggregateIR(nil), qb.aggregates...),
+		Windows:            append([]QueryWindowIR(nil), qb.windows...),
 		Conditions:         append([]Condition(nil), qb.conditions...),
+		GroupBys:           append([]string(nil), qb.groupBys...),
+		Having:             append([]Condition(nil), qb.having...),
 		Limit:              qb.limitVal,
 		Offset:             qb.offsetVal,
 		CrossTableStrategy: qb.crossTableStrategy,
 		Joins:              make([]QueryJoinIR, 0, len(qb.joins)),
 		OrderBys:           make([]QueryOrderIR, 0, len(qb.orderBys)),
+		Lock:               qb.lock,
+		Preloads:           qb.preloads,
 	}
 
 	for _, order := range qb.orderBys {
 		ir.OrderBys = append(ir.OrderBys, QueryOrderIR{Field: order.Field, Direction: order.Direction})
 	}
 
+	if len(qb.windows) > 0 {
+		plan, err := qb.windowEmulationPlan()
+		if err != nil {
+			return nil, err
+		}
+		if plan != nil {
+			// 应用层计算：SQL 只返回基础行，排序/分页推迟到窗口值计算之后。
+			ir.Windows = nil
+			ir.Projections = plan.projections
+			ir.Limit = nil
+			ir.Offset = nil
+			if len(plan.orderBys) > 0 {
+				ir.OrderBys = ir.OrderBys[:0]
+			}
+		}
+	}
+
 	for _, join := range qb.joins {
 		ir.Joins = append(ir.Joins, QueryJoinIR{
 			JoinType: join.joinType,
@@ -1422,6 +1909,7 @@ func (qb *SQLQueryConstructor) BuildIR(ctx context.Context) (*QueryIR, error) {
 			Filters:  append([]Condition(nil), join.filters...),
 		})
 	}
+	qb.applySoftDeleteScope(ir)
 
 	if len(qb.joins) == 1 {
 		reg := qb.viewRegistry
@@ -1444,6 +1932,25 @@ func (qb *SQLQueryConstructor) BuildIR(ctx context.Context) (*QueryIR, error) {
 		ir.Hints.TempTable = qb.sqlServerTempTableName()
 	}
 
+	for _, op := range qb.setOperations {
+		branch := op.query.GetNativeBuilder().(*SQLQueryConstructor)
+		branchIR, err := branch.BuildIR(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build %s branch: %w", strings.ToLower(string(op.operator)), err)
+		}
+		ir.SetOperations = append(ir.SetOperations, QuerySetOperationIR{Operator: op.operator, Query: branchIR})
+	}
+
+	for _, builder := range qb.ctes {
+		cte, err := builder.toIR(func(member QueryConstructor) (*QueryIR, error) {
+			return member.GetNativeBuilder().(*SQLQueryConstructor).BuildIR(ctx)
+		})
+		if err != nil {
+			return nil, err
+		}
+		ir.CTEs = append(ir.CTEs, cte)
+	}
+
 	return ir, nil
 }
 
@@ -1501,6 +2008,7 @@ func (qb *SQLQueryConstructor) GetNativeBuilder() interface{} {
 
 // DefaultSQLTranslator 默认 SQL 转义器
 type DefaultSQLTranslator struct {
+	ctx      context.Context // 编译嵌套子查询时沿用调用方的 ctx；为空时使用 context.Background()
 	dialect  SQLDialect
 	argIndex *int
 }
@@ -1514,15 +2022,37 @@ func (t *DefaultSQLTranslator) TranslateCondition(condition Condition) (string,
 		return t.translateCompositeCondition(c)
 	case *NotCondition:
 		return t.translateNotCondition(c)
+	case *SubqueryCondition:
+		return t.translateSubqueryCondition(c)
 	default:
 		return "", nil, fmt.Errorf("unknown condition type: %T", condition)
 	}
 }
 
+// sqlComparisonOperators 比较操作符到 SQL 运算符的映射。
+var sqlComparisonOperators = map[string]string{
+	"eq":  "=",
+	"ne":  "!=",
+	"gt":  ">",
+	"lt":  "<",
+	"gte": ">=",
+	"lte": "<=",
+}
+
 func (t *DefaultSQLTranslator) translateSimpleCondition(cond *SimpleCondition) (string, []interface{}, error) {
 	if cond.Operator == "full_text" {
 		return t.translateFullTextCondition(cond)
 	}
+	if isExtendedConditionOperator(cond.Operator) {
+		return t.translateExtendedCondition(cond)
+	}
+	if ref, ok := cond.Value.(FieldRef); ok {
+		op, ok := sqlComparisonOperators[cond.Operator]
+		if !ok {
+			return "", nil, fmt.Errorf("operator %s does not support field reference values", cond.Operator)
+		}
+		return t.dialect.QuoteIdentifier(cond.Field) + " " + op + " " + t.dialect.QuoteIdentifier(ref.Name), nil, nil
+	}
 
 	var sql strings.Builder
 	var args []interface{}
@@ -1608,6 +2138,82 @@ func (t *DefaultSQLTranslator) translateFullTextCondition(cond *SimpleCondition)
 	}
 }
 
+// translateSubqueryCondition 将嵌套 QueryConstructor 编译为子查询，并按外层参数序号重编号占位符。
+func (t *DefaultSQLTranslator) translateSubqueryCondition(cond *SubqueryCondition) (string, []interface{}, error) {
+	if cond.Query == nil {
+		return "", nil, fmt.Errorf("subquery condition requires a query constructor")
+	}
+	if nested, ok := cond.Query.GetNativeBuilder().(*SQLQueryConstructor); ok {
+		if !strings.EqualFold(nested.dialect.Name(), t.dialect.Name()) {
+			return "", nil, fmt.Errorf("subquery dialect %q does not match outer dialect %q", nested.dialect.Name(), t.dialect.Name())
+		}
+	} else {
+		return "", nil, fmt.Errorf("sql subquery requires a SQL query constructor, got %T", cond.Query.GetNativeBuilder())
+	}
+
+	ctx := t.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	subSQL, subArgs, err := cond.Query.Build(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to build subquery: %w", err)
+	}
+	subSQL = renumberSQLPlaceholders(t.dialect, subSQL, *t.argIndex-1)
+	*t.argIndex += len(subArgs)
+
+	switch cond.Operator {
+	case "exists":
+		return "EXISTS (" + subSQL + ")", subArgs, nil
+	case "not_exists":
+		return "NOT EXISTS (" + subSQL + ")", subArgs, nil
+	}
+
+	if strings.TrimSpace(cond.Field) == "" {
+		return "", nil, fmt.Errorf("subquery operator %s requires a field", cond.Operator)
+	}
+	field := t.dialect.QuoteIdentifier(cond.Field)
+	switch cond.Operator {
+	case "in":
+		return field + " IN (" + subSQL + ")", subArgs, nil
+	case "not_in":
+		return field + " NOT IN (" + subSQL + ")", subArgs, nil
+	default:
+		op, ok := sqlComparisonOperators[cond.Operator]
+		if !ok {
+			return "", nil, fmt.Errorf("unsupported subquery operator: %s", cond.Operator)
+		}
+		return field + " " + op + " (" + subSQL + ")", subArgs, nil
+	}
+}
+
+// renumberSQLPlaceholders 将从 1 开始编号的占位符整体偏移 offset。
+// "$n"（PostgreSQL）与 "@pN"（SQL Server）需要重编号；"?" 按位置绑定，保持原样。
+func renumberSQLPlaceholders(dialect SQLDialect, query string, offset int) string {
+	if offset <= 0 {
+		return query
+	}
+	sample := dialect.GetPlaceholder(1)
+	var prefix string
+	switch {
+	case strings.HasPrefix(sample, "@p"):
+		prefix = "@p"
+	case strings.HasPrefix(sample, "$"):
+		prefix = "$"
+	default:
+		return query
+	}
+
+	pattern := regexp.MustCompile(regexp.QuoteMeta(prefix) + `(\d+)`)
+	return pattern.ReplaceAllStringFunc(query, func(match string) string {
+		n, err := strconv.Atoi(match[len(prefix):])
+		if err != nil {
+			return match
+		}
+		return prefix + strconv.Itoa(n+offset)
+	})
+}
+
 func (t *DefaultSQLTranslator) translateCompositeCondition(cond *CompositeCondition) (string, []interface{}, error) {
 	return t.TranslateComposite(cond.Operator, cond.Conditions)
 }
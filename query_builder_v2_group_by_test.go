@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newGroupByOrdersSchema() *BaseSchema {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("customer_id", TypeInteger).Build())
+	schema.AddField(NewField("amount", TypeFloat).Build())
+	return schema
+}
+
+func TestSQLQueryConstructorGroupByHavingPostgres(t *testing.T) {
+	qc := NewSQLQueryConstructor(newGroupByOrdersSchema(), NewPostgreSQLDialect())
+	qc.Where(Eq("customer_id", 7)).
+		GroupBy("status").
+		Aggregate(Sum("amount").As("total"), CountDistinct("customer_id")).
+		Having(Gt("total", 100)).
+		OrderBy("total", "DESC")
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	expected := `SELECT "orders"."status", SUM("orders"."amount") AS "total", COUNT(DISTINCT "orders"."customer_id") AS "count_distinct_customer_id" FROM "orders" WHERE "orders"."customer_id" = $1 GROUP BY "orders"."status" HAVING SUM("orders"."amount") > $2 ORDER BY "total" DESC`
+	if sql != expected {
+		t.Fatalf("unexpected sql:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 2 || args[0] != 7 || args[1] != 100 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+	t.Logf("✓ PostgreSQL GROUP BY/HAVING: %s", sql)
+}
+
+func TestSQLQueryConstructorAggregateWithSelectSQLServer(t *testing.T) {
+	qc := NewSQLQueryConstructor(newGroupByOrdersSchema(), NewSQLServerDialect())
+	qc.Select("status").
+		GroupBy("status").
+		Aggregate(Avg("amount").As("avg_amount"), Min("amount"), Max("amount"), CountOf("")).
+		Having(And(Gte("count_all", 2), Lt("max_amount", 1000)))
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	for _, fragment := range []string{
+		"SELECT [orders].[status], AVG([orders].[amount]) AS [avg_amount], MIN([orders].[amount]) AS [min_amount], MAX([orders].[amount]) AS [max_amount], COUNT(*) AS [count_all]",
+		"GROUP BY [orders].[status]",
+		"HAVING (COUNT(*) >= @p1 AND MAX([orders].[amount]) < @p2)",
+	} {
+		if !strings.Contains(sql, fragment) {
+			t.Fatalf("expected %q in sql: %s", fragment, sql)
+		}
+	}
+	if len(args) != 2 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestSQLQueryConstructorGroupByValidation(t *testing.T) {
+	qc := NewSQLQueryConstructor(newGroupByOrdersSchema(), NewMySQLDialect())
+	qc.GroupBy("missing_field").Aggregate(Sum("amount"))
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "missing_field") {
+		t.Fatalf("expected unknown group field error, got: %v", err)
+	}
+
+	qc = NewSQLQueryConstructor(newGroupByOrdersSchema(), NewMySQLDialect())
+	qc.Aggregate(Sum(""))
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "requires a field") {
+		t.Fatalf("expected aggregate field error, got: %v", err)
+	}
+}
+
+func TestSQLQueryConstructorGroupBySQLiteExecution(t *testing.T) {
+	cfg := &Config{Adapter: "sqlite", SQLite: &SQLiteConnectionConfig{Path: filepath.Join(t.TempDir(), "group-by.db")}}
+	repo, err := NewRepository(cfg)
+	if err != nil {
+		t.Fatalf("create repository failed: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	if _, err := repo.Exec(ctx, "CREATE TABLE orders (id INTEGER PRIMARY KEY, status TEXT, customer_id INTEGER, amount REAL)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	if _, err := repo.Exec(ctx, `INSERT INTO orders (id, status, customer_id, amount) VALUES
+		(1, 'paid', 1, 50), (2, 'paid', 2, 80), (3, 'paid', 2, 20), (4, 'open', 3, 10), (5, 'void', 4, 500)`); err != nil {
+		t.Fatalf("seed failed: %v", err)
+	}
+
+	qc, err := repo.NewQueryConstructor(newGroupByOrdersSchema())
+	if err != nil {
+		t.Fatalf("new query constructor failed: %v", err)
+	}
+	qc.GroupBy("status").
+		Aggregate(Sum("amount").As("total"), CountDistinct("customer_id").As("customers")).
+		Having(Gt("total", 40)).
+		OrderBy("total", "ASC")
+
+	result, err := repo.ExecuteQueryConstructor(ctx, qc)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if len(result.Rows) != 2 {
+		t.Fatalf("expected 2 groups, got %d: %v", len(result.Rows), result.Rows)
+	}
+	if result.Rows[0]["status"] != "paid" || result.Rows[0]["total"] != float64(150) || result.Rows[0]["customers"] != int64(2) {
+		t.Fatalf("unexpected first group: %v", result.Rows[0])
+	}
+
+	count, err := qc.SelectCount(ctx, repo)
+	if err != nil {
+		t.Fatalf("select count failed: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected grouped count 2, got %d", count)
+	}
+}
+
+func TestNeo4jQueryConstructorGroupByHaving(t *testing.T) {
+	qc := NewNeo4jQueryConstructor(newGroupByOrdersSchema())
+	qc.Where(Eq("customer_id", 7)).
+		GroupBy("status").
+		Aggregate(Sum("amount").As("total"), CountOf("")).
+		Having(Gt("total", 100)).
+		OrderBy("total", "DESC").
+		Limit(5)
+
+	cypher, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expected := "MATCH (n:orders) WHERE n.customer_id = $p1 WITH n.status AS status, sum(n.amount) AS total, count(*) AS count_all WHERE total > $p2 RETURN status, total, count_all ORDER BY total DESC LIMIT 5"
+	if cypher != expected {
+		t.Fatalf("unexpected cypher:\n got: %s\nwant: %s", cypher, expected)
+	}
+	if len(args) != 2 || args[1] != 100 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	qc = NewNeo4jQueryConstructor(newGroupByOrdersSchema())
+	qc.GroupBy("status").Aggregate(Max("amount"))
+	cypher, _, err = qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if !strings.HasSuffix(cypher, "RETURN n.status AS status, max(n.amount) AS max_amount") {
+		t.Fatalf("unexpected cypher without having: %s", cypher)
+	}
+}
+
+func TestMongoQueryConstructorGroupByPlan(t *testing.T) {
+	qc := NewMongoQueryConstructor(newGroupByOrdersSchema())
+	qc.Where(Eq("customer_id", 7)).
+		GroupBy("status").
+		Aggregate(Sum("amount").As("total"), CountDistinct("customer_id").As("customers"), CountOf(""))
+
+	plan, err := qc.BuildFindPlan()
+	if err != nil {
+		t.Fatalf("build plan failed: %v", err)
+	}
+	if plan.Group == nil || len(plan.Group.Keys) != 1 || len(plan.Group.Accumulators) != 3 {
+		t.Fatalf("unexpected group stage: %+v", plan.Group)
+	}
+
+	stages := buildMongoGroupPipeline(plan.Group)
+	if len(stages) != 2 {
+		t.Fatalf("expected $group + $project stages, got %v", stages)
+	}
+	group := stages[0]["$group"].(map[string]interface{})
+	if group["_id"] != "$status" {
+		t.Fatalf("unexpected group id: %v", group["_id"])
+	}
+	if sum := group["total"].(map[string]interface{}); sum["$sum"] != "$amount" {
+		t.Fatalf("unexpected sum accumulator: %v", sum)
+	}
+	if set := group["customers"].(map[string]interface{}); set["$addToSet"] != "$customer_id" {
+		t.Fatalf("unexpected distinct accumulator: %v", set)
+	}
+	project := stages[1]["$project"].(map[string]interface{})
+	if project["status"] != "$_id" {
+		t.Fatalf("unexpected group key projection: %v", project)
+	}
+	if size := project["customers"].(map[string]interface{}); size["$size"] != "$customers" {
+		t.Fatalf("unexpected distinct count projection: %v", size)
+	}
+}
+
+func TestMongoQueryConstructorHavingUnsupported(t *testing.T) {
+	qc := NewMongoQueryConstructor(newGroupByOrdersSchema())
+	qc.GroupBy("status").Aggregate(Sum("amount").As("total")).Having(Gt("total", 1))
+
+	_, _, err := qc.Build(context.Background())
+	if err == nil {
+		t.Fatalf("expected having to be rejected on mongodb")
+	}
+	if !strings.Contains(err.Error(), `"having"`) {
+		t.Fatalf("expected error to name the missing feature, got: %v", err)
+	}
+}
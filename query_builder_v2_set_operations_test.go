@@ -0,0 +1,193 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newMonthlyOrdersSchema(table string) *BaseSchema {
+	schema := NewBaseSchema(table)
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("amount", TypeFloat).Build())
+	return schema
+}
+
+func TestSQLQueryConstructorUnionAllPostgres(t *testing.T) {
+	archive := NewSQLQueryConstructor(newMonthlyOrdersSchema("orders_2026_09"), NewPostgreSQLDialect())
+	archive.Select("id", "amount").Where(Eq("status", "paid"))
+
+	qc := NewSQLQueryConstructor(newMonthlyOrdersSchema("orders"), NewPostgreSQLDialect())
+	qc.Select("id", "amount").Where(Eq("status", "paid")).
+		UnionAll(archive).
+		OrderBy("amount", "DESC").
+		Limit(10)
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expected := `SELECT "orders"."id", "orders"."amount" FROM "orders" WHERE "orders"."status" = $1 UNION ALL SELECT "orders_2026_09"."id", "orders_2026_09"."amount" FROM "orders_2026_09" WHERE "orders_2026_09"."status" = $2 ORDER BY "amount" DESC LIMIT 10`
+	if sql != expected {
+		t.Fatalf("unexpected sql:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 2 || args[0] != "paid" || args[1] != "paid" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+	t.Logf("✓ PostgreSQL UNION ALL: %s", sql)
+}
+
+func TestSQLQueryConstructorSetOperationsMySQLEmulation(t *testing.T) {
+	newBranch := func(table, status string) *SQLQueryConstructor {
+		qc := NewSQLQueryConstructor(newMonthlyOrdersSchema(table), NewMySQLDialect())
+		qc.Select("id", "status").Where(Eq("status", status))
+		return qc
+	}
+
+	qc := newBranch("orders", "paid")
+	qc.Except(newBranch("orders_2026_09", "paid"))
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build except failed: %v", err)
+	}
+	for _, fragment := range []string{
+		"SELECT DISTINCT `set_l1`.* FROM (SELECT `orders`.`id`, `orders`.`status` FROM `orders` WHERE `orders`.`status` = ?) `set_l1`",
+		"WHERE NOT EXISTS (SELECT 1 FROM (SELECT `orders_2026_09`.`id`, `orders_2026_09`.`status` FROM `orders_2026_09` WHERE `orders_2026_09`.`status` = ?) `set_r1`",
+		"`set_l1`.`id` <=> `set_r1`.`id` AND `set_l1`.`status` <=> `set_r1`.`status`",
+	} {
+		if !strings.Contains(sql, fragment) {
+			t.Fatalf("expected %q in sql: %s", fragment, sql)
+		}
+	}
+	if strings.Contains(sql, "EXCEPT") || len(args) != 2 {
+		t.Fatalf("unexpected except emulation: %s %v", sql, args)
+	}
+
+	qc = newBranch("orders", "paid")
+	qc.Intersect(newBranch("orders_2026_09", "open")).OrderBy("id", "ASC")
+	sql, _, err = qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build intersect failed: %v", err)
+	}
+	if !strings.Contains(sql, "INNER JOIN (SELECT") || strings.Contains(sql, "INTERSECT") || !strings.HasSuffix(sql, "ORDER BY `id` ASC") {
+		t.Fatalf("unexpected intersect emulation: %s", sql)
+	}
+}
+
+func TestSQLQueryConstructorSetOperationValidation(t *testing.T) {
+	branch := NewSQLQueryConstructor(newMonthlyOrdersSchema("orders_2026_09"), NewPostgreSQLDialect())
+	branch.Limit(5)
+	qc := NewSQLQueryConstructor(newMonthlyOrdersSchema("orders"), NewPostgreSQLDialect())
+	qc.Union(branch)
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "ORDER BY/LIMIT/OFFSET") {
+		t.Fatalf("expected branch limit error, got: %v", err)
+	}
+
+	qc = NewSQLQueryConstructor(newMonthlyOrdersSchema("orders"), NewPostgreSQLDialect())
+	qc.Union(NewSQLQueryConstructor(newMonthlyOrdersSchema("orders_2026_09"), NewMySQLDialect()))
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "dialect") {
+		t.Fatalf("expected dialect mismatch error, got: %v", err)
+	}
+}
+
+func TestSQLQueryConstructorSetOperationsSQLiteExecution(t *testing.T) {
+	cfg := &Config{Adapter: "sqlite", SQLite: &SQLiteConnectionConfig{Path: filepath.Join(t.TempDir(), "set-ops.db")}}
+	repo, err := NewRepository(cfg)
+	if err != nil {
+		t.Fatalf("create repository failed: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, status TEXT, amount REAL)",
+		"CREATE TABLE orders_2026_09 (id INTEGER PRIMARY KEY, status TEXT, amount REAL)",
+		"INSERT INTO orders (id, status, amount) VALUES (1, 'paid', 10), (2, 'paid', 20), (3, 'open', 30)",
+		"INSERT INTO orders_2026_09 (id, status, amount) VALUES (2, 'paid', 20), (4, 'paid', 40)",
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+
+	newBranch := func(table string) QueryConstructor {
+		qc, err := repo.NewQueryConstructor(newMonthlyOrdersSchema(table))
+		if err != nil {
+			t.Fatalf("new query constructor failed: %v", err)
+		}
+		return qc.Select("id", "amount").Where(Eq("status", "paid"))
+	}
+
+	cases := []struct {
+		name  string
+		build func(QueryConstructor) QueryConstructor
+		ids   []int64
+	}{
+		{"union", func(qc QueryConstructor) QueryConstructor { return qc.Union(newBranch("orders_2026_09")) }, []int64{1, 2, 4}},
+		{"intersect", func(qc QueryConstructor) QueryConstructor { return qc.Intersect(newBranch("orders_2026_09")) }, []int64{2}},
+		{"except", func(qc QueryConstructor) QueryConstructor { return qc.Except(newBranch("orders_2026_09")) }, []int64{1}},
+	}
+	for _, tc := range cases {
+		qc := tc.build(newBranch("orders")).OrderBy("id", "ASC")
+		result, err := repo.ExecuteQueryConstructor(ctx, qc)
+		if err != nil {
+			t.Fatalf("%s execute failed: %v", tc.name, err)
+		}
+		if len(result.Rows) != len(tc.ids) {
+			t.Fatalf("%s: expected %d rows, got %v", tc.name, len(tc.ids), result.Rows)
+		}
+		for i, id := range tc.ids {
+			if result.Rows[i]["id"] != id {
+				t.Fatalf("%s: unexpected row %d: %v", tc.name, i, result.Rows[i])
+			}
+		}
+	}
+
+	qc := newBranch("orders").UnionAll(newBranch("orders_2026_09"))
+	count, err := qc.SelectCount(ctx, repo)
+	if err != nil {
+		t.Fatalf("select count failed: %v", err)
+	}
+	if count != 4 {
+		t.Fatalf("expected compound count 4, got %d", count)
+	}
+}
+
+func TestNeo4jQueryConstructorUnion(t *testing.T) {
+	archive := NewNeo4jQueryConstructor(newMonthlyOrdersSchema("orders_2026_09"))
+	archive.Select("id", "amount").Where(Eq("status", "paid"))
+
+	qc := NewNeo4jQueryConstructor(newMonthlyOrdersSchema("orders"))
+	qc.Select("id", "amount").Where(Eq("status", "paid")).
+		Union(archive).
+		OrderBy("amount", "DESC").
+		Limit(3)
+
+	cypher, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expected := "CALL { MATCH (n:orders) WHERE n.status = $p1 RETURN n.id AS id, n.amount AS amount UNION MATCH (n:orders_2026_09) WHERE n.status = $p2 RETURN n.id AS id, n.amount AS amount } RETURN id, amount ORDER BY amount DESC LIMIT 3"
+	if cypher != expected {
+		t.Fatalf("unexpected cypher:\n got: %s\nwant: %s", cypher, expected)
+	}
+	if len(args) != 2 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	qc = NewNeo4jQueryConstructor(newMonthlyOrdersSchema("orders"))
+	qc.Except(NewNeo4jQueryConstructor(newMonthlyOrdersSchema("orders_2026_09")))
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), `"except"`) {
+		t.Fatalf("expected except to be rejected on neo4j, got: %v", err)
+	}
+}
+
+func TestMongoQueryConstructorSetOperationsUnsupported(t *testing.T) {
+	qc := NewMongoQueryConstructor(newMonthlyOrdersSchema("orders"))
+	qc.Union(NewMongoQueryConstructor(newMonthlyOrdersSchema("orders_2026_09")))
+	if _, err := qc.BuildFindPlan(); err == nil || !strings.Contains(err.Error(), `"union"`) {
+		t.Fatalf("expected union to be rejected on mongodb, got: %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newSubqueryCustomersSchema() *BaseSchema {
+	schema := NewBaseSchema("customers")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("name", TypeString).Build())
+	schema.AddField(NewField("tier", TypeString).Build())
+	return schema
+}
+
+func newSubqueryOrdersSchema() *BaseSchema {
+	schema := newGroupByOrdersSchema()
+	schema.AddForeignKey("fk_orders_customers", []string{"customer_id"}, "customers", []string{"id"}, "", "")
+	return schema
+}
+
+func TestSQLQueryConstructorInSubqueryPostgresRenumbersPlaceholders(t *testing.T) {
+	sub := NewSQLQueryConstructor(newSubqueryOrdersSchema(), NewPostgreSQLDialect())
+	sub.Select("customer_id").Where(Eq("status", "paid")).Where(Gt("amount", 100))
+
+	qc := NewSQLQueryConstructor(newSubqueryCustomersSchema(), NewPostgreSQLDialect())
+	qc.Where(Eq("tier", "gold")).Where(InSubquery("id", sub)).Where(Ne("name", "test"))
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expected := `SELECT * FROM "customers" WHERE "customers"."tier" = $1 AND "customers"."id" IN (SELECT "orders"."customer_id" FROM "orders" WHERE "orders"."status" = $2 AND "orders"."amount" > $3) AND "customers"."name" != $4`
+	if sql != expected {
+		t.Fatalf("unexpected sql:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 4 || args[0] != "gold" || args[1] != "paid" || args[2] != 100 || args[3] != "test" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+	t.Logf("✓ PostgreSQL IN subquery: %s", sql)
+}
+
+func TestSQLQueryConstructorSubquerySQLServerAndMySQLPlaceholders(t *testing.T) {
+	sub := NewSQLQueryConstructor(newSubqueryOrdersSchema(), NewSQLServerDialect())
+	sub.Aggregate(Max("amount")).Where(Eq("status", "paid"))
+
+	qc := NewSQLQueryConstructor(newSubqueryOrdersSchema(), NewSQLServerDialect())
+	qc.Where(Eq("customer_id", 3)).Where(CompareSubquery("amount", "GTE", sub))
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if !strings.Contains(sql, "[orders].[amount] >= (SELECT MAX([orders].[amount]) AS [max_amount] FROM [orders] WHERE [orders].[status] = @p2)") {
+		t.Fatalf("unexpected sql server subquery: %s", sql)
+	}
+	if len(args) != 2 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	mysqlSub := NewSQLQueryConstructor(newSubqueryOrdersSchema(), NewMySQLDialect())
+	mysqlSub.Select("customer_id").Where(Eq("status", "void"))
+	mysqlQC := NewSQLQueryConstructor(newSubqueryCustomersSchema(), NewMySQLDialect())
+	mysqlQC.Where(Eq("tier", "gold")).Where(NotInSubquery("id", mysqlSub))
+
+	sql, args, err = mysqlQC.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if !strings.Contains(sql, "`customers`.`id` NOT IN (SELECT `orders`.`customer_id` FROM `orders` WHERE `orders`.`status` = ?)") {
+		t.Fatalf("unexpected mysql subquery: %s", sql)
+	}
+	if len(args) != 2 || args[1] != "void" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestSQLQueryConstructorCorrelatedExists(t *testing.T) {
+	sub := NewSQLQueryConstructor(newSubqueryOrdersSchema(), NewPostgreSQLDialect())
+	sub.Select("id").Where(Eq("customer_id", RefField("customers.id"))).Where(Eq("status", "paid"))
+
+	qc := NewSQLQueryConstructor(newSubqueryCustomersSchema(), NewPostgreSQLDialect())
+	qc.Where(Exists(sub))
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expected := `SELECT * FROM "customers" WHERE EXISTS (SELECT "orders"."id" FROM "orders" WHERE "orders"."customer_id" = "customers"."id" AND "orders"."status" = $1)`
+	if sql != expected {
+		t.Fatalf("unexpected sql:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 1 || args[0] != "paid" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	mixed := NewSQLQueryConstructor(newSubqueryCustomersSchema(), NewPostgreSQLDialect())
+	mixed.Where(Exists(NewSQLQueryConstructor(newSubqueryOrdersSchema(), NewMySQLDialect())))
+	if _, _, err := mixed.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "dialect") {
+		t.Fatalf("expected dialect mismatch error, got: %v", err)
+	}
+}
+
+type subqueryContextKey struct{}
+
+// ctxRecordingCompiler 记录编译时收到的 ctx，用于验证子查询沿用外层 ctx。
+type ctxRecordingCompiler struct {
+	*BaseSQLCompiler
+	seen interface{}
+}
+
+func (c *ctxRecordingCompiler) Compile(ctx context.Context, ir *QueryIR) (string, []interface{}, error) {
+	c.seen = ctx.Value(subqueryContextKey{})
+	return c.BaseSQLCompiler.Compile(ctx, ir)
+}
+
+func TestSQLQueryConstructorSubqueryUsesCallerContext(t *testing.T) {
+	recorder := &ctxRecordingCompiler{BaseSQLCompiler: NewBaseSQLCompiler(NewPostgreSQLDialect())}
+	sub := NewSQLQueryConstructorWithCompiler(newSubqueryOrdersSchema(), NewPostgreSQLDialect(), recorder)
+	sub.Select("customer_id").Where(Eq("status", "paid"))
+
+	qc := NewSQLQueryConstructor(newSubqueryCustomersSchema(), NewPostgreSQLDialect())
+	qc.Where(InSubquery("id", sub))
+
+	ctx := context.WithValue(context.Background(), subqueryContextKey{}, "tenant-a")
+	if _, _, err := qc.Build(ctx); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if recorder.seen != "tenant-a" {
+		t.Fatalf("expected subquery to be compiled with caller ctx, got %v", recorder.seen)
+	}
+}
+
+func TestSQLQueryConstructorSubquerySQLiteExecution(t *testing.T) {
+	cfg := &Config{Adapter: "sqlite", SQLite: &SQLiteConnectionConfig{Path: filepath.Join(t.TempDir(), "subquery.db")}}
+	repo, err := NewRepository(cfg)
+	if err != nil {
+		t.Fatalf("create repository failed: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY, name TEXT, tier TEXT)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, status TEXT, customer_id INTEGER, amount REAL)",
+		"INSERT INTO customers (id, name, tier) VALUES (1, 'alice', 'gold'), (2, 'bob', 'gold'), (3, 'carol', 'basic')",
+		"INSERT INTO orders (id, status, customer_id, amount) VALUES (1, 'paid', 1, 50), (2, 'open', 2, 80), (3, 'paid', 3, 20)",
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+
+	sub, err := repo.NewQueryConstructor(newSubqueryOrdersSchema())
+	if err != nil {
+		t.Fatalf("new query constructor failed: %v", err)
+	}
+	sub.Select("id").Where(Eq("customer_id", RefField("customers.id"))).Where(Eq("status", "paid"))
+
+	qc, err := repo.NewQueryConstructor(newSubqueryCustomersSchema())
+	if err != nil {
+		t.Fatalf("new query constructor failed: %v", err)
+	}
+	qc.Where(Eq("tier", "gold")).Where(Exists(sub))
+
+	result, err := repo.ExecuteQueryConstructor(ctx, qc)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0]["name"] != "alice" {
+		t.Fatalf("unexpected rows: %v", result.Rows)
+	}
+}
+
+func TestNeo4jQueryConstructorSubqueries(t *testing.T) {
+	sub := NewNeo4jQueryConstructor(newSubqueryOrdersSchema())
+	sub.Where(Eq("status", "paid"))
+
+	qc := NewNeo4jQueryConstructor(newSubqueryCustomersSchema())
+	qc.Where(Eq("tier", "gold")).Where(Exists(sub))
+
+	cypher, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expected := "MATCH (n:customers) WHERE n.tier = $p1 AND EXISTS { MATCH (n)-[:HAS]->(sq1:orders) WHERE sq1.status = $p2 } RETURN n"
+	if cypher != expected {
+		t.Fatalf("unexpected cypher:\n got: %s\nwant: %s", cypher, expected)
+	}
+	if len(args) != 2 || args[1] != "paid" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	tiers := NewNeo4jQueryConstructor(newSubqueryCustomersSchema())
+	tiers.Select("id").Where(Eq("tier", "gold"))
+	orders := NewNeo4jQueryConstructor(newGroupByOrdersSchema())
+	orders.Where(NotInSubquery("customer_id", tiers))
+
+	cypher, _, err = orders.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if !strings.Contains(cypher, "NOT n.customer_id IN COLLECT { MATCH (sq1:customers) WHERE sq1.tier = $p1 RETURN sq1.id }") {
+		t.Fatalf("unexpected collect subquery: %s", cypher)
+	}
+}
+
+func TestMongoQueryConstructorSubqueryPipeline(t *testing.T) {
+	sub := NewMongoQueryConstructor(newSubqueryOrdersSchema())
+	sub.Where(Eq("customer_id", RefField("customers.id"))).Where(Eq("status", "paid"))
+
+	qc := NewMongoQueryConstructor(newSubqueryCustomersSchema())
+	qc.Where(Eq("tier", "gold")).Where(NotExists(sub))
+
+	plan, err := qc.BuildFindPlan()
+	if err != nil {
+		t.Fatalf("build plan failed: %v", err)
+	}
+	if plan.Filter["tier"] != "gold" {
+		t.Fatalf("unexpected filter: %v", plan.Filter)
+	}
+	if len(plan.Subqueries) != 1 {
+		t.Fatalf("expected one subquery stage, got %+v", plan.Subqueries)
+	}
+	stage := plan.Subqueries[0]
+	if stage.From != "orders" || stage.LocalField != "id" || stage.ForeignField != "customer_id" || !stage.Negate {
+		t.Fatalf("unexpected subquery stage: %+v", stage)
+	}
+	if stage.Filter["status"] != "paid" {
+		t.Fatalf("unexpected subquery filter: %v", stage.Filter)
+	}
+
+	pipeline := buildMongoSubqueryPipeline(plan.Subqueries)
+	if len(pipeline) != 3 {
+		t.Fatalf("expected $lookup/$match/$unset stages, got %v", pipeline)
+	}
+	lookup := pipeline[0]["$lookup"].(map[string]interface{})
+	if lookup["from"] != "orders" || lookup["let"].(map[string]interface{})["sq_local"] != "$id" {
+		t.Fatalf("unexpected lookup: %v", lookup)
+	}
+	match := pipeline[1]["$match"].(map[string]interface{})
+	if exists := match[stage.As+".0"].(map[string]interface{}); exists["$exists"] != false {
+		t.Fatalf("unexpected existence match: %v", match)
+	}
+
+	uncorrelated := NewMongoQueryConstructor(newSubqueryCustomersSchema())
+	uncorrelated.Where(Exists(NewMongoQueryConstructor(newSubqueryOrdersSchema())))
+	if _, err := uncorrelated.BuildFindPlan(); err == nil || !strings.Contains(err.Error(), "explicit correlation") {
+		t.Fatalf("expected explicit correlation error, got: %v", err)
+	}
+
+	scalar := NewMongoQueryConstructor(newSubqueryCustomersSchema())
+	scalar.Where(CompareSubquery("id", "gt", NewMongoQueryConstructor(newSubqueryOrdersSchema())))
+	if _, err := scalar.BuildFindPlan(); err == nil || !strings.Contains(err.Error(), "scalar") {
+		t.Fatalf("expected scalar subquery error, got: %v", err)
+	}
+}
@@ -714,6 +714,26 @@ func (s *staticQueryConstructor) Count(fieldName ...string) QueryConstructor { r
 func (s *staticQueryConstructor) CountWith(builder *CountBuilder) QueryConstructor {
 	return s
 }
+func (s *staticQueryConstructor) Distinct() QueryConstructor { return s }
+func (s *staticQueryConstructor) DistinctOn(fields ...string) QueryConstructor {
+	return s
+}
+func (s *staticQueryConstructor) GroupBy(fields ...string) QueryConstructor { return s }
+func (s *staticQueryConstructor) Having(condition Condition) QueryConstructor {
+	return s
+}
+func (s *staticQueryConstructor) Aggregate(builders ...*AggregateBuilder) QueryConstructor {
+	return s
+}
+func (s *staticQueryConstructor) Window(builders ...*WindowBuilder) QueryConstructor { return s }
+func (s *staticQueryConstructor) With(ctes ...*CTEBuilder) QueryConstructor          { return s }
+func (s *staticQueryConstructor) Lock(lock *RowLock) QueryConstructor                { return s }
+func (s *staticQueryConstructor) WithDeleted() QueryConstructor                      { return s }
+func (s *staticQueryConstructor) OnlyDeleted() QueryConstructor                      { return s }
+func (s *staticQueryConstructor) Union(other QueryConstructor) QueryConstructor      { return s }
+func (s *staticQueryConstructor) UnionAll(other QueryConstructor) QueryConstructor   { return s }
+func (s *staticQueryConstructor) Intersect(other QueryConstructor) QueryConstructor  { return s }
+func (s *staticQueryConstructor) Except(other QueryConstructor) QueryConstructor     { return s }
 func (s *staticQueryConstructor) OrderBy(field string, direction string) QueryConstructor {
 	return s
 }
@@ -744,6 +764,7 @@ func (s *staticQueryConstructor) CrossTableStrategy(strategy CrossTableStrategy)
 	return s
 }
 func (s *staticQueryConstructor) JoinWith(builder *JoinBuilder) QueryConstructor { return s }
+func (s *staticQueryConstructor) Preload(paths ...string) QueryConstructor       { return s }
 func (s *staticQueryConstructor) CustomMode() QueryConstructor                   { return s }
 func (s *staticQueryConstructor) Build(ctx context.Context) (string, []interface{}, error) {
 	return s.query, copyQueryArgs(s.args), nil
@@ -0,0 +1,286 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultRecursiveCTEMaxDepth 递归 CTE 默认最大层数（与 SQL Server MAXRECURSION 默认值一致）。
+const defaultRecursiveCTEMaxDepth = 100
+
+// CTEBuilder 公用表表达式（WITH / WITH RECURSIVE）构造器。
+// 主查询通过以 CTE 名称为表名的 Schema（或 Join CTE 名称）引用其结果。
+type CTEBuilder struct {
+	name        string
+	columns     []string
+	query       QueryConstructor
+	recursive   QueryConstructor
+	maxDepth    int
+	depthColumn string
+}
+
+// NewCTE 创建普通 CTE：WITH name AS (query)。
+func NewCTE(name string, query QueryConstructor) *CTEBuilder {
+	return &CTEBuilder{name: strings.TrimSpace(name), query: query}
+}
+
+// NewRecursiveCTE 创建递归 CTE：WITH RECURSIVE name AS (anchor UNION ALL recursive)。
+// recursive 需 Join CTE 名称以引用上一层结果；默认追加 depth 列并限制最多 100 层。
+func NewRecursiveCTE(name string, anchor, recursive QueryConstructor) *CTEBuilder {
+	return &CTEBuilder{
+		name:        strings.TrimSpace(name),
+		query:       anchor,
+		recursive:   recursive,
+		maxDepth:    defaultRecursiveCTEMaxDepth,
+		depthColumn: "depth",
+	}
+}
+
+// Columns 显式声明 CTE 列名（WITH name (col1, col2) AS ...）。
+func (b *CTEBuilder) Columns(cols ...string) *CTEBuilder {
+	if b == nil {
+		return b
+	}
+	for _, col := range cols {
+		if trimmed := strings.TrimSpace(col); trimmed != "" {
+			b.columns = append(b.columns, trimmed)
+		}
+	}
+	return b
+}
+
+// MaxDepth 设置递归最大层数（锚定成员为第 1 层）；n <= 0 时不追加 depth 列与层数保护。
+func (b *CTEBuilder) MaxDepth(n int) *CTEBuilder {
+	if b != nil {
+		if n < 0 {
+			n = 0
+		}
+		b.maxDepth = n
+	}
+	return b
+}
+
+// DepthColumn 设置递归层数列名，默认 "depth"。
+func (b *CTEBuilder) DepthColumn(name string) *CTEBuilder {
+	if b != nil && strings.TrimSpace(name) != "" {
+		b.depthColumn = strings.TrimSpace(name)
+	}
+	return b
+}
+
+// IsRecursive 是否为递归 CTE。
+func (b *CTEBuilder) IsRecursive() bool {
+	return b != nil && b.recursive != nil
+}
+
+func (b *CTEBuilder) featureName() string {
+	if b.IsRecursive() {
+		return "recursive_cte"
+	}
+	return "cte"
+}
+
+func (b *CTEBuilder) validate() error {
+	if b == nil {
+		return fmt.Errorf("cte builder is nil")
+	}
+	if b.name == "" {
+		return fmt.Errorf("cte name cannot be empty")
+	}
+	if b.query == nil {
+		return fmt.Errorf("cte %q requires a query constructor", b.name)
+	}
+	return nil
+}
+
+// toIR 使用 buildIR 将 CTE 各成员转换为 IR。
+func (b *CTEBuilder) toIR(buildIR func(QueryConstructor) (*QueryIR, error)) (QueryCTEIR, error) {
+	if err := b.validate(); err != nil {
+		return QueryCTEIR{}, err
+	}
+	query, err := buildIR(b.query)
+	if err != nil {
+		return QueryCTEIR{}, fmt.Errorf("failed to build cte %q: %w", b.name, err)
+	}
+	cte := QueryCTEIR{
+		Name:    b.name,
+		Columns: append([]string(nil), b.columns...),
+		Query:   query,
+	}
+	if b.recursive != nil {
+		recursive, err := buildIR(b.recursive)
+		if err != nil {
+			return QueryCTEIR{}, fmt.Errorf("failed to build recursive member of cte %q: %w", b.name, err)
+		}
+		cte.Recursive = recursive
+		if b.maxDepth > 0 {
+			cte.MaxDepth = b.maxDepth
+			cte.DepthColumn = b.depthColumn
+		}
+	}
+	return cte, nil
+}
+
+// findCTEJoinIR 返回递归成员中引用 CTE 自身的 JOIN 限定名（别名优先）。
+func findCTEJoinIR(ir *QueryIR, name string) (QueryJoinIR, string, bool) {
+	for _, join := range ir.Joins {
+		if strings.EqualFold(strings.TrimSpace(join.Table), name) {
+			qualifier := strings.TrimSpace(join.Alias)
+			if qualifier == "" {
+				qualifier = strings.TrimSpace(join.Table)
+			}
+			return join, qualifier, true
+		}
+	}
+	return QueryJoinIR{}, "", false
+}
+
+func validateCTEMemberIR(name string, member string, ir *QueryIR) error {
+	if ir == nil {
+		return fmt.Errorf("cte %q %s is nil", name, member)
+	}
+	if len(ir.OrderBys) > 0 || ir.Limit != nil || ir.Offset != nil {
+		return fmt.Errorf("cte %q %s cannot use ORDER BY/LIMIT/OFFSET", name, member)
+	}
+	return nil
+}
+
+// compileSQLCTEPrefix 编译 WITH 子句（不含主查询），返回是否包含递归 CTE 及 SQL Server MAXRECURSION 取值。
+func (c *BaseSQLCompiler) compileSQLCTEPrefix(ctx context.Context, ctes []QueryCTEIR) (string, []interface{}, int, error) {
+	sqlServer := strings.EqualFold(strings.TrimSpace(c.dialect.Name()), "sqlserver")
+	var (
+		sql          strings.Builder
+		args         []interface{}
+		recursive    bool
+		maxRecursion int
+	)
+	parts := make([]string, 0, len(ctes))
+	for _, cte := range ctes {
+		part, partArgs, err := c.compileSQLCTE(ctx, cte, len(args))
+		if err != nil {
+			return "", nil, 0, err
+		}
+		parts = append(parts, part)
+		args = append(args, partArgs...)
+		if cte.Recursive != nil {
+			recursive = true
+			if cte.MaxDepth > maxRecursion {
+				maxRecursion = cte.MaxDepth
+			}
+		}
+	}
+
+	sql.WriteString("WITH ")
+	if recursive && !sqlServer {
+		sql.WriteString("RECURSIVE ")
+	}
+	sql.WriteString(strings.Join(parts, ", "))
+	if !recursive || !sqlServer {
+		return sql.String(), args, 0, nil
+	}
+	if maxRecursion == 0 {
+		maxRecursion = resolveSQLServerRecursiveCTEMaxRecursionIR(c.dialect)
+	}
+	return sql.String(), args, maxRecursion, nil
+}
+
+func (c *BaseSQLCompiler) compileSQLCTE(ctx context.Context, cte QueryCTEIR, offset int) (string, []interface{}, error) {
+	dialect := c.dialect
+	var sql strings.Builder
+	sql.WriteString(dialect.QuoteIdentifier(cte.Name))
+	columns := append([]string(nil), cte.Columns...)
+	if len(columns) > 0 && cte.DepthColumn != "" {
+		columns = append(columns, cte.DepthColumn)
+	}
+	if len(columns) > 0 {
+		quoted := make([]string, len(columns))
+		for i, col := range columns {
+			quoted[i] = dialect.QuoteIdentifier(col)
+		}
+		sql.WriteString(" (")
+		sql.WriteString(strings.Join(quoted, ", "))
+		sql.WriteString(")")
+	}
+	sql.WriteString(" AS (")
+
+	if cte.Recursive == nil {
+		body, args, err := c.Compile(ctx, cte.Query)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to compile cte %q: %w", cte.Name, err)
+		}
+		sql.WriteString(renumberSQLPlaceholders(dialect, body, offset))
+		sql.WriteString(")")
+		return sql.String(), args, nil
+	}
+
+	if err := validateCTEMemberIR(cte.Name, "anchor", cte.Query); err != nil {
+		return "", nil, err
+	}
+	if err := validateCTEMemberIR(cte.Name, "recursive member", cte.Recursive); err != nil {
+		return "", nil, err
+	}
+	if len(cte.Recursive.SetOperations) > 0 || len(cte.Query.SetOperations) > 0 {
+		return "", nil, fmt.Errorf("cte %q members cannot contain set operations", cte.Name)
+	}
+	_, qualifier, ok := findCTEJoinIR(cte.Recursive, cte.Name)
+	if !ok {
+		return "", nil, fmt.Errorf("recursive cte %q: recursive member must join %q", cte.Name, cte.Name)
+	}
+
+	anchor := *cte.Query
+	member := *cte.Recursive
+	if cte.DepthColumn != "" {
+		depth := dialect.QuoteIdentifier(cte.DepthColumn)
+		anchor.Projections = withAllColumnsProjectionIR(&anchor)
+		anchor.Projections = append(anchor.Projections, "1 AS "+depth)
+		member.Projections = withAllColumnsProjectionIR(&member)
+		member.Projections = append(member.Projections, quoteQualifiedIdentifierIR(dialect, qualifier, cte.DepthColumn)+" + 1 AS "+depth)
+		member.Conditions = append(append([]Condition(nil), member.Conditions...), Lt(qualifier+"."+cte.DepthColumn, cte.MaxDepth))
+	}
+
+	anchorSQL, args, err := c.Compile(ctx, &anchor)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to compile anchor of cte %q: %w", cte.Name, err)
+	}
+	memberSQL, memberArgs, err := c.Compile(ctx, &member)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to compile recursive member of cte %q: %w", cte.Name, err)
+	}
+	sql.WriteString(renumberSQLPlaceholders(dialect, anchorSQL, offset))
+	sql.WriteString(" UNION ALL ")
+	sql.WriteString(renumberSQLPlaceholders(dialect, memberSQL, offset+len(args)))
+	sql.WriteString(")")
+	return sql.String(), append(args, memberArgs...), nil
+}
+
+// withAllColumnsProjectionIR 未显式 Select 时以 "基表.*" 代替裸 *，便于追加计算列。
+func withAllColumnsProjectionIR(ir *QueryIR) []string {
+	if len(ir.Projections) > 0 || len(ir.Aggregates) > 0 {
+		return append([]string(nil), ir.Projections...)
+	}
+	if base := baseTableNameIR(ir); base != "" {
+		return []string{base + ".*"}
+	}
+	return []string{"*"}
+}
+
+// attachSQLCTEsIR 将 WITH 子句拼接到已编译的主查询前；SQL Server 递归 CTE 追加 OPTION (MAXRECURSION n)。
+func attachSQLCTEsIR(ctx context.Context, compiler *BaseSQLCompiler, ctes []QueryCTEIR, body string, bodyArgs []interface{}) (string, []interface{}, error) {
+	if len(ctes) == 0 {
+		return body, bodyArgs, nil
+	}
+	if strings.HasPrefix(strings.TrimSpace(body), "WITH ") {
+		return "", nil, fmt.Errorf("cte cannot be combined with a query that already compiles to a WITH clause")
+	}
+	prefix, args, maxRecursion, err := compiler.compileSQLCTEPrefix(ctx, ctes)
+	if err != nil {
+		return "", nil, err
+	}
+	out := prefix + " " + renumberSQLPlaceholders(compiler.dialect, body, len(args))
+	if maxRecursion > 0 {
+		out += " OPTION (MAXRECURSION " + strconv.Itoa(maxRecursion) + ")"
+	}
+	return out, append(args, bodyArgs...), nil
+}
@@ -0,0 +1,223 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newCTECategoriesSchema() *BaseSchema {
+	schema := NewBaseSchema("categories")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("parent_id", TypeInteger).Null(true).Build())
+	schema.AddField(NewField("name", TypeString).Build())
+	schema.AddForeignKey("fk_categories_parent", []string{"parent_id"}, "categories", []string{"id"}, "", "")
+	return schema
+}
+
+func newCTETreeSchema() *BaseSchema {
+	schema := NewBaseSchema("category_tree")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("parent_id", TypeInteger).Null(true).Build())
+	schema.AddField(NewField("name", TypeString).Build())
+	schema.AddField(NewField("depth", TypeInteger).Build())
+	return schema
+}
+
+func newCategoryTreeCTE(dialect SQLDialect, rootID int) *CTEBuilder {
+	anchor := NewSQLQueryConstructor(newCTECategoriesSchema(), dialect)
+	anchor.Select("id", "parent_id", "name").Where(Eq("id", rootID))
+	member := NewSQLQueryConstructor(newCTECategoriesSchema(), dialect)
+	member.Select("id", "parent_id", "name").Join("category_tree", "categories.parent_id = category_tree.id")
+	return NewRecursiveCTE("category_tree", anchor, member)
+}
+
+func TestSQLQueryConstructorRecursiveCTEPostgres(t *testing.T) {
+	qc := NewSQLQueryConstructor(newCTETreeSchema(), NewPostgreSQLDialect())
+	qc.With(newCategoryTreeCTE(NewPostgreSQLDialect(), 1).MaxDepth(5)).
+		Where(Ne("name", "archived")).
+		OrderBy("depth", "ASC")
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expected := `WITH RECURSIVE "category_tree" AS (SELECT "categories"."id", "categories"."parent_id", "categories"."name", 1 AS "depth" FROM "categories" WHERE "categories"."id" = $1 UNION ALL SELECT "categories"."id", "categories"."parent_id", "categories"."name", "category_tree"."depth" + 1 AS "depth" FROM "categories" INNER JOIN "category_tree" AS "category_tree" ON categories.parent_id = category_tree.id WHERE "category_tree"."depth" < $2) SELECT * FROM "category_tree" WHERE "category_tree"."name" != $3 ORDER BY "category_tree"."depth" ASC`
+	if sql != expected {
+		t.Fatalf("unexpected sql:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 3 || args[0] != 1 || args[1] != 5 || args[2] != "archived" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+	t.Logf("✓ PostgreSQL recursive CTE: %s", sql)
+}
+
+func TestSQLQueryConstructorRecursiveCTEDialects(t *testing.T) {
+	qc := NewSQLQueryConstructor(newCTETreeSchema(), NewSQLServerDialect())
+	qc.With(newCategoryTreeCTE(NewSQLServerDialect(), 1).MaxDepth(10)).Select("id", "depth")
+	sql, _, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if !strings.HasPrefix(sql, "WITH [category_tree] AS (SELECT [categories].[id]") || !strings.HasSuffix(sql, "OPTION (MAXRECURSION 10)") {
+		t.Fatalf("unexpected sql server sql: %s", sql)
+	}
+	if !strings.Contains(sql, "WHERE [category_tree].[depth] < @p2)") {
+		t.Fatalf("expected depth guard in sql server sql: %s", sql)
+	}
+
+	unguarded := NewSQLQueryConstructor(newCTETreeSchema(), NewSQLServerDialectWithOptions("", 0, 250))
+	unguarded.With(newCategoryTreeCTE(NewSQLServerDialect(), 1).MaxDepth(0))
+	sql, _, err = unguarded.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if strings.Contains(sql, "depth") || !strings.HasSuffix(sql, "OPTION (MAXRECURSION 250)") {
+		t.Fatalf("unexpected unguarded sql server sql: %s", sql)
+	}
+
+	mysql := NewSQLQueryConstructor(newCTETreeSchema(), NewMySQLDialectWithVersion("8.0.36"))
+	mysql.With(newCategoryTreeCTE(NewMySQLDialect(), 1))
+	sql, _, err = mysql.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if !strings.HasPrefix(sql, "WITH RECURSIVE `category_tree` AS (SELECT `categories`.`id`") {
+		t.Fatalf("unexpected mysql sql: %s", sql)
+	}
+
+	legacy := NewSQLQueryConstructor(newCTETreeSchema(), NewMySQLDialectWithVersion("5.7.44"))
+	legacy.With(newCategoryTreeCTE(NewMySQLDialect(), 1))
+	if _, _, err := legacy.Build(context.Background()); err == nil || !strings.Contains(err.Error(), `"recursive_cte"`) {
+		t.Fatalf("expected mysql 5.7 recursive cte error, got: %v", err)
+	}
+}
+
+func TestSQLQueryConstructorPlainCTE(t *testing.T) {
+	paid := NewSQLQueryConstructor(newGroupByOrdersSchema(), NewPostgreSQLDialect())
+	paid.Select("id", "customer_id", "amount").Where(Eq("status", "paid"))
+
+	qc := NewSQLQueryConstructor(NewBaseSchema("paid_orders"), NewPostgreSQLDialect())
+	qc.With(NewCTE("paid_orders", paid).Columns("id", "customer_id", "amount")).
+		Where(Gt("amount", 100)).
+		Limit(10)
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expected := `WITH "paid_orders" ("id", "customer_id", "amount") AS (SELECT "orders"."id", "orders"."customer_id", "orders"."amount" FROM "orders" WHERE "orders"."status" = $1) SELECT * FROM "paid_orders" WHERE "paid_orders"."amount" > $2 LIMIT 10`
+	if sql != expected {
+		t.Fatalf("unexpected sql:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 2 || args[0] != "paid" || args[1] != 100 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	missing := NewSQLQueryConstructor(newCTETreeSchema(), NewPostgreSQLDialect())
+	missing.With(NewRecursiveCTE("category_tree",
+		NewSQLQueryConstructor(newCTECategoriesSchema(), NewPostgreSQLDialect()),
+		NewSQLQueryConstructor(newCTECategoriesSchema(), NewPostgreSQLDialect())))
+	if _, _, err := missing.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "must join") {
+		t.Fatalf("expected missing self join error, got: %v", err)
+	}
+}
+
+func TestSQLQueryConstructorRecursiveCTESQLiteExecution(t *testing.T) {
+	cfg := &Config{Adapter: "sqlite", SQLite: &SQLiteConnectionConfig{Path: filepath.Join(t.TempDir(), "cte.db")}}
+	repo, err := NewRepository(cfg)
+	if err != nil {
+		t.Fatalf("create repository failed: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE categories (id INTEGER PRIMARY KEY, parent_id INTEGER NULL, name TEXT)",
+		"INSERT INTO categories (id, parent_id, name) VALUES (1, NULL, 'root'), (2, 1, 'books'), (3, 1, 'music'), (4, 2, 'fiction'), (5, 4, 'fantasy'), (6, NULL, 'other')",
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+
+	qc := NewSQLQueryConstructor(newCTETreeSchema(), NewSQLiteDialect())
+	qc.With(newCategoryTreeCTE(NewSQLiteDialect(), 1).MaxDepth(3)).
+		Select("id", "depth").
+		OrderBy("depth", "ASC").
+		OrderBy("id", "ASC")
+
+	result, err := repo.ExecuteQueryConstructor(ctx, qc)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	expected := [][2]int64{{1, 1}, {2, 2}, {3, 2}, {4, 3}}
+	if len(result.Rows) != len(expected) {
+		t.Fatalf("expected %d rows (fantasy cut by depth guard), got %v", len(expected), result.Rows)
+	}
+	for i, want := range expected {
+		if result.Rows[i]["id"] != want[0] || result.Rows[i]["depth"] != want[1] {
+			t.Fatalf("row %d: unexpected %v", i, result.Rows[i])
+		}
+	}
+
+	count, err := qc.SelectCount(ctx, repo)
+	if err != nil {
+		t.Fatalf("select count failed: %v", err)
+	}
+	if count != 4 {
+		t.Fatalf("expected count 4, got %d", count)
+	}
+}
+
+func TestNeo4jQueryConstructorRecursiveCTE(t *testing.T) {
+	anchor := NewNeo4jQueryConstructor(newCTECategoriesSchema())
+	anchor.Where(Eq("id", 1))
+	member := NewNeo4jQueryConstructor(newCTECategoriesSchema())
+	member.Join("category_tree", "categories.parent_id = category_tree.id").Where(Ne("name", "archived"))
+
+	qc := NewNeo4jQueryConstructor(newCTETreeSchema())
+	qc.With(NewRecursiveCTE("category_tree", anchor, member).MaxDepth(4)).
+		Select("id", "name", "depth").
+		Where(Lte("depth", 3)).
+		OrderBy("depth", "ASC")
+
+	cypher, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expected := "MATCH (root:categories) WHERE root.id = $p1 MATCH path = (root)-[:HAS*0..3]->(n:categories) WHERE ALL(step IN nodes(path)[1..] WHERE step.name <> $p2) WITH n, path WHERE (length(path) + 1) <= $p3 RETURN n.id, n.name, length(path) + 1 AS depth ORDER BY (length(path) + 1) ASC"
+	if cypher != expected {
+		t.Fatalf("unexpected cypher:\n got: %s\nwant: %s", cypher, expected)
+	}
+	if len(args) != 3 || args[0] != 1 || args[1] != "archived" || args[2] != 3 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	ancestors := NewNeo4jQueryConstructor(newCTECategoriesSchema())
+	ancestors.Join("category_tree", "categories.id = category_tree.parent_id")
+	up := NewNeo4jQueryConstructor(newCTETreeSchema())
+	up.With(NewRecursiveCTE("category_tree", anchor, ancestors).MaxDepth(0))
+	cypher, _, err = up.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if !strings.Contains(cypher, "MATCH path = (root)<-[:HAS*0..]-(n:categories) RETURN n") {
+		t.Fatalf("unexpected ancestor cypher: %s", cypher)
+	}
+
+	plain := NewNeo4jQueryConstructor(newCTETreeSchema())
+	plain.With(NewCTE("category_tree", anchor))
+	if _, _, err := plain.Build(context.Background()); err == nil || !strings.Contains(err.Error(), `"cte"`) {
+		t.Fatalf("expected plain cte rejection on neo4j, got: %v", err)
+	}
+}
+
+func TestMongoQueryConstructorCTEUnsupported(t *testing.T) {
+	qc := NewMongoQueryConstructor(newCTETreeSchema())
+	qc.With(NewCTE("category_tree", NewMongoQueryConstructor(newCTECategoriesSchema())))
+	if _, err := qc.BuildFindPlan(); err == nil || !strings.Contains(err.Error(), "cte") {
+		t.Fatalf("expected cte to be rejected on mongodb, got: %v", err)
+	}
+}
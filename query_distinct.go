@@ -0,0 +1,200 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// distinctOnRowNumberColumn ROW_NUMBER() 改写 DISTINCT ON 时的辅助列名，外层只保留编号为 1 的行。
+const distinctOnRowNumberColumn = "__distinct_rn"
+
+// selectKeywordIR 返回 SELECT 关键字（含 DISTINCT）。
+func selectKeywordIR(ir *QueryIR) string {
+	if ir.Distinct {
+		return "SELECT DISTINCT "
+	}
+	return "SELECT "
+}
+
+// nativeDistinctOnIR 方言是否原生支持 DISTINCT ON（目前仅 PostgreSQL）。
+func nativeDistinctOnIR(dialect SQLDialect) bool {
+	features := queryFeaturesForBackend(strings.TrimSpace(dialect.Name()))
+	return features != nil && features.HasQueryFeature("distinct_on")
+}
+
+// validateDistinctOnIR 校验 DISTINCT ON 与查询形态的兼容性。
+func validateDistinctOnIR(ir *QueryIR) error {
+	switch {
+	case len(ir.SetOperations) > 0:
+		return fmt.Errorf("distinct on cannot be combined with set operations")
+	case len(ir.Aggregates) > 0 || len(ir.GroupBys) > 0:
+		return fmt.Errorf("distinct on cannot be combined with GROUP BY or aggregates")
+	case len(ir.Windows) > 0:
+		return fmt.Errorf("distinct on cannot be combined with window functions")
+	case ir.Hints.UseTempTable:
+		return fmt.Errorf("distinct on cannot be combined with the temp table strategy")
+	}
+	return nil
+}
+
+// distinctOnOrderLeadsIR 排序是否以 DISTINCT ON 字段开头（PostgreSQL 要求的形态）。
+func distinctOnOrderLeadsIR(ir *QueryIR) bool {
+	if len(ir.OrderBys) == 0 {
+		return true
+	}
+	if len(ir.OrderBys) < len(ir.DistinctOn) {
+		return false
+	}
+	keys := make(map[string]bool, len(ir.DistinctOn))
+	for _, key := range ir.DistinctOn {
+		keys[resultColumnNameIR(key)] = true
+	}
+	for _, order := range ir.OrderBys[:len(ir.DistinctOn)] {
+		if !keys[resultColumnNameIR(order.Field)] {
+			return false
+		}
+	}
+	return true
+}
+
+// compileDistinctOn 编译 DISTINCT ON 查询：
+//   - PostgreSQL：原生 SELECT DISTINCT ON (...)；排序不以去重字段开头时，内层先按去重字段排序选出每组首行，外层恢复排序与分页
+//   - 其他方言：SELECT ... FROM (SELECT ..., ROW_NUMBER() OVER (PARTITION BY 去重字段 ORDER BY 排序字段) ...) WHERE 编号 = 1
+func (c *BaseSQLCompiler) compileDistinctOn(ctx context.Context, ir *QueryIR) (string, []interface{}, error) {
+	if err := validateDistinctOnIR(ir); err != nil {
+		return "", nil, err
+	}
+	columns := setOperationColumnsIR(ir)
+	wrapped := !nativeDistinctOnIR(c.dialect) || !distinctOnOrderLeadsIR(ir)
+	if wrapped && len(columns) > 0 {
+		// 外层按结果列名排序，排序字段需出现在投影中。
+		for _, order := range ir.OrderBys {
+			if !containsString(columns, resultColumnNameIR(order.Field)) {
+				return "", nil, fmt.Errorf("distinct on order field %q must be selected", order.Field)
+			}
+		}
+	}
+
+	inner := *ir
+	inner.Distinct = false
+	inner.DistinctOn = nil
+
+	if nativeDistinctOnIR(c.dialect) {
+		keys := make([]string, 0, len(ir.DistinctOn))
+		for _, key := range ir.DistinctOn {
+			keys = append(keys, c.dialect.QuoteIdentifier(qualifyIdentifierIR(ir, key)))
+		}
+		prefix := "SELECT DISTINCT ON (" + strings.Join(keys, ", ") + ") "
+		if !wrapped {
+			body, args, err := c.Compile(ctx, &inner)
+			if err != nil {
+				return "", nil, err
+			}
+			return prefix + strings.TrimPrefix(body, "SELECT "), args, nil
+		}
+		inner.OrderBys = make([]QueryOrderIR, 0, len(ir.DistinctOn)+len(ir.OrderBys))
+		for _, key := range ir.DistinctOn {
+			inner.OrderBys = append(inner.OrderBys, QueryOrderIR{Field: key, Direction: "ASC"})
+		}
+		inner.OrderBys = append(inner.OrderBys, ir.OrderBys...)
+		inner.Limit = nil
+		inner.Offset = nil
+		body, args, err := c.Compile(ctx, &inner)
+		if err != nil {
+			return "", nil, err
+		}
+		return c.wrapDistinctOnIR(ir, nil, prefix+strings.TrimPrefix(body, "SELECT "), ""), args, nil
+	}
+
+	inner.OrderBys = nil
+	inner.Limit = nil
+	inner.Offset = nil
+	inner.Windows = []QueryWindowIR{{
+		Func:        WindowRowNumber,
+		PartitionBy: append([]string(nil), ir.DistinctOn...),
+		OrderBy:     append([]QueryOrderIR(nil), ir.OrderBys...),
+		Alias:       distinctOnRowNumberColumn,
+	}}
+	body, args, err := c.Compile(ctx, &inner)
+	if err != nil {
+		return "", nil, err
+	}
+	filter := c.dialect.QuoteIdentifier(distinctOnRowNumberColumn) + " = 1"
+	return c.wrapDistinctOnIR(ir, columns, body, filter), args, nil
+}
+
+// wrapDistinctOnIR 将去重查询包装为派生表，并在外层应用过滤、排序与分页。
+func (c *BaseSQLCompiler) wrapDistinctOnIR(ir *QueryIR, columns []string, inner string, filter string) string {
+	var sql strings.Builder
+	sql.WriteString("SELECT ")
+	if len(columns) == 0 {
+		sql.WriteString("*")
+	}
+	for i, column := range columns {
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString(c.dialect.QuoteIdentifier(column))
+	}
+	sql.WriteString(" FROM (")
+	sql.WriteString(inner)
+	sql.WriteString(")")
+	sql.WriteString(joinKeywordWithAliasIR(c.dialect, "distinct_on"))
+	if filter != "" {
+		sql.WriteString(" WHERE ")
+		sql.WriteString(filter)
+	}
+	if len(ir.OrderBys) > 0 {
+		sql.WriteString(" ORDER BY ")
+		for i, order := range ir.OrderBys {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			sql.WriteString(c.dialect.QuoteIdentifier(resultColumnNameIR(order.Field)))
+			sql.WriteString(" ")
+			sql.WriteString(order.Direction)
+		}
+	}
+	if limitOffset := c.dialect.GenerateLimitOffset(ir.Limit, ir.Offset); limitOffset != "" {
+		sql.WriteString(" ")
+		sql.WriteString(limitOffset)
+	}
+	return sql.String()
+}
+
+// Distinct 对结果行去重（SELECT DISTINCT）。
+func (qb *SQLQueryConstructor) Distinct() QueryConstructor {
+	qb.setBuildErr(qb.requireFeature("distinct"))
+	qb.distinct = true
+	return qb
+}
+
+// DistinctOn 按指定字段去重，每组保留 OrderBy 排序后的第一行。
+// PostgreSQL 使用原生 DISTINCT ON；其他方言按 distinct_on 降级策略改写为 ROW_NUMBER() 子查询，需要窗口函数支持。
+func (qb *SQLQueryConstructor) DistinctOn(fields ...string) QueryConstructor {
+	if len(fields) == 0 {
+		qb.setBuildErr(fmt.Errorf("distinct on requires at least one field"))
+		return qb
+	}
+	if err := qb.requireFeature("distinct_on"); err != nil {
+		features := queryFeaturesForBackend(qb.dialect.Name())
+		if features.GetFallbackStrategy("distinct_on") != QueryFallbackAlternativeSyntax {
+			qb.setBuildErr(err)
+			return qb
+		}
+		if err := qb.requireVersionedFeature("window_func"); err != nil {
+			qb.setBuildErr(fmt.Errorf("distinct on emulation requires window functions: %w", err))
+			return qb
+		}
+	}
+	for _, field := range fields {
+		trimmed := strings.TrimSpace(field)
+		if err := qb.validateFieldReference(trimmed, false); err != nil {
+			qb.setBuildErr(err)
+			continue
+		}
+		qb.distinctOn = append(qb.distinctOn, trimmed)
+	}
+	return qb
+}
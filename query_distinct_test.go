@@ -0,0 +1,201 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newDistinctOrdersSchema() *BaseSchema {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("customer_id", TypeInteger).Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("amount", TypeInteger).Build())
+	return schema
+}
+
+func TestSQLQueryConstructorDistinctOnPostgres(t *testing.T) {
+	qc := NewSQLQueryConstructor(newDistinctOrdersSchema(), NewPostgreSQLDialect())
+	qc.Select("id", "customer_id", "amount").
+		Where(Eq("status", "paid")).
+		DistinctOn("customer_id").
+		OrderBy("customer_id", "ASC").
+		OrderBy("amount", "DESC")
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expected := `SELECT DISTINCT ON ("orders"."customer_id") "orders"."id", "orders"."customer_id", "orders"."amount" FROM "orders" WHERE "orders"."status" = $1 ORDER BY "orders"."customer_id" ASC, "orders"."amount" DESC`
+	if sql != expected {
+		t.Fatalf("unexpected sql:\n got: %s\nwant: %s", sql, expected)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"paid"}) {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	// 排序不以去重字段开头：内层补齐 DISTINCT ON 排序，外层恢复排序与分页。
+	qc = NewSQLQueryConstructor(newDistinctOrdersSchema(), NewPostgreSQLDialect())
+	qc.Select("id", "customer_id", "amount").DistinctOn("customer_id").OrderBy("amount", "DESC").Limit(5)
+	sql, _, err = qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	wrapped := `SELECT * FROM (SELECT DISTINCT ON ("orders"."customer_id") "orders"."id", "orders"."customer_id", "orders"."amount" FROM "orders" ORDER BY "orders"."customer_id" ASC, "orders"."amount" DESC) AS "distinct_on" ORDER BY "amount" DESC LIMIT 5`
+	if sql != wrapped {
+		t.Fatalf("unexpected wrapped sql:\n got: %s\nwant: %s", sql, wrapped)
+	}
+
+	qc = NewSQLQueryConstructor(newDistinctOrdersSchema(), NewPostgreSQLDialect())
+	qc.Select("status").Distinct()
+	if sql, _, err = qc.Build(context.Background()); err != nil || sql != `SELECT DISTINCT "orders"."status" FROM "orders"` {
+		t.Fatalf("unexpected distinct sql: %s (%v)", sql, err)
+	}
+	t.Logf("✓ PostgreSQL DISTINCT ON: %s", expected)
+}
+
+func TestSQLQueryConstructorDistinctOnEmulation(t *testing.T) {
+	qc := NewSQLQueryConstructor(newDistinctOrdersSchema(), NewMySQLDialectWithVersion("8.0.36"))
+	qc.Select("id", "customer_id", "amount").Where(Eq("status", "paid")).DistinctOn("customer_id").OrderBy("amount", "DESC").Limit(10)
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("mysql build failed: %v", err)
+	}
+	expected := "SELECT `id`, `customer_id`, `amount` FROM (SELECT `orders`.`id`, `orders`.`customer_id`, `orders`.`amount`, ROW_NUMBER() OVER (PARTITION BY `orders`.`customer_id` ORDER BY `orders`.`amount` DESC) AS `__distinct_rn` FROM `orders` WHERE `orders`.`status` = ?) `distinct_on` WHERE `__distinct_rn` = 1 ORDER BY `amount` DESC LIMIT 10"
+	if sql != expected {
+		t.Fatalf("unexpected mysql sql:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 1 || args[0] != "paid" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	qc = NewSQLQueryConstructor(newDistinctOrdersSchema(), NewSQLServerDialect())
+	qc.DistinctOn("customer_id")
+	if sql, _, err = qc.Build(context.Background()); err != nil || !strings.Contains(sql, "ROW_NUMBER() OVER (PARTITION BY [orders].[customer_id] ORDER BY (SELECT NULL)) AS [__distinct_rn]") || !strings.HasPrefix(sql, "SELECT [id], [customer_id], [status], [amount] FROM (") {
+		t.Fatalf("unexpected sql server sql: %s (%v)", sql, err)
+	}
+
+	qc = NewSQLQueryConstructor(newDistinctOrdersSchema(), NewMySQLDialectWithVersion("5.7.44"))
+	qc.DistinctOn("customer_id")
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), `"window_func"`) {
+		t.Fatalf("expected mysql 5.7 window_func error, got: %v", err)
+	}
+
+	qc = NewSQLQueryConstructor(newDistinctOrdersSchema(), NewSQLiteDialect())
+	qc.Select("id").DistinctOn("customer_id").OrderBy("amount", "DESC")
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "must be selected") {
+		t.Fatalf("expected unselected order field error, got: %v", err)
+	}
+
+	qc = NewSQLQueryConstructor(newDistinctOrdersSchema(), NewPostgreSQLDialect())
+	qc.DistinctOn("customer_id").GroupBy("customer_id").Aggregate(CountOf("id"))
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "GROUP BY") {
+		t.Fatalf("expected grouped distinct on error, got: %v", err)
+	}
+}
+
+func TestSQLQueryConstructorDistinctSQLiteExecution(t *testing.T) {
+	cfg := &Config{Adapter: "sqlite", SQLite: &SQLiteConnectionConfig{Path: filepath.Join(t.TempDir(), "distinct.db")}}
+	repo, err := NewRepository(cfg)
+	if err != nil {
+		t.Fatalf("create repository failed: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER, status TEXT, amount INTEGER)",
+		`INSERT INTO orders (id, customer_id, status, amount) VALUES
+			(1, 10, 'paid', 50), (2, 10, 'paid', 80), (3, 20, 'paid', 30),
+			(4, 20, 'open', 90), (5, 30, 'paid', 10), (6, 30, 'paid', 70)`,
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+
+	qc := NewSQLQueryConstructor(newDistinctOrdersSchema(), NewSQLiteDialect())
+	qc.Where(Eq("status", "paid")).DistinctOn("customer_id").OrderBy("amount", "DESC")
+	result, err := repo.ExecuteQueryConstructor(ctx, qc)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	ids := make([]interface{}, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		if _, ok := row[distinctOnRowNumberColumn]; ok {
+			t.Fatalf("helper column must not be returned: %v", row)
+		}
+		ids = append(ids, row["id"])
+	}
+	if !reflect.DeepEqual(ids, []interface{}{int64(2), int64(6), int64(3)}) {
+		t.Fatalf("unexpected distinct on rows: %v", result.Rows)
+	}
+
+	count, err := qc.SelectCount(ctx, repo)
+	if err != nil || count != 3 {
+		t.Fatalf("expected distinct on count 3, got %d (%v)", count, err)
+	}
+
+	distinct := NewSQLQueryConstructor(newDistinctOrdersSchema(), NewSQLiteDialect())
+	distinct.Select("status").Distinct().OrderBy("status", "ASC").Limit(1)
+	result, err = repo.ExecuteQueryConstructor(ctx, distinct)
+	if err != nil || len(result.Rows) != 1 || result.Rows[0]["status"] != "open" {
+		t.Fatalf("unexpected distinct rows: %v (%v)", result.Rows, err)
+	}
+	countSQL, countArgs, err := distinct.buildCountConstructor().Build(ctx)
+	if err != nil {
+		t.Fatalf("build count constructor failed: %v", err)
+	}
+	if count, err := repo.executeCompiledCountStatement(ctx, countSQL, countArgs); err != nil || count != 2 {
+		t.Fatalf("expected cached distinct count 2, got %d (%v): %s", count, err, countSQL)
+	}
+}
+
+func TestMongoDistinctPlan(t *testing.T) {
+	qc := NewMongoQueryConstructor(newDistinctOrdersSchema())
+	qc.Where(Eq("status", "paid")).DistinctOn("customer_id").OrderBy("amount", "DESC")
+	plan, err := qc.BuildFindPlan()
+	if err != nil {
+		t.Fatalf("build plan failed: %v", err)
+	}
+	if plan.Distinct == nil || !reflect.DeepEqual(plan.Distinct.Keys, []string{"customer_id"}) {
+		t.Fatalf("unexpected distinct stage: %+v", plan.Distinct)
+	}
+	want := []map[string]interface{}{
+		{"$group": map[string]interface{}{"_id": map[string]interface{}{"customer_id": "$customer_id"}, "_doc": map[string]interface{}{"$first": "$$ROOT"}}},
+		{"$replaceRoot": map[string]interface{}{"newRoot": "$_doc"}},
+	}
+	if got := buildMongoDistinctPipeline(plan.Distinct); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected distinct pipeline:\n got: %v\nwant: %v", got, want)
+	}
+
+	qc = NewMongoQueryConstructor(newDistinctOrdersSchema())
+	qc.Select("status").Distinct()
+	if plan, err = qc.BuildFindPlan(); err != nil || plan.Distinct == nil || !reflect.DeepEqual(plan.Distinct.Keys, []string{"status"}) {
+		t.Fatalf("unexpected distinct plan: %+v (%v)", plan, err)
+	}
+
+	qc = NewMongoQueryConstructor(newDistinctOrdersSchema())
+	qc.Distinct()
+	if plan, err = qc.BuildFindPlan(); err != nil || plan.Distinct != nil {
+		t.Fatalf("distinct without projection should not add a stage: %+v (%v)", plan, err)
+	}
+}
+
+func TestNeo4jDistinct(t *testing.T) {
+	qc := NewNeo4jQueryConstructor(newDistinctOrdersSchema())
+	qc.Select("status").Distinct()
+	cypher, _, err := qc.Build(context.Background())
+	if err != nil || cypher != "MATCH (n:orders) RETURN DISTINCT n.status" {
+		t.Fatalf("unexpected cypher: %s (%v)", cypher, err)
+	}
+
+	qc = NewNeo4jQueryConstructor(newDistinctOrdersSchema())
+	qc.DistinctOn("customer_id")
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), `"distinct_on"`) {
+		t.Fatalf("expected neo4j distinct_on error, got: %v", err)
+	}
+}
@@ -68,13 +68,14 @@ type FeatureSupport struct {
 // QueryFeatures 数据库查询特性集合
 type QueryFeatures struct {
 	// 基础查询特性
-	SupportsIN       bool // IN (value1, value2, ...) 范围查询
-	SupportsNotIN    bool // NOT IN 查询
-	SupportsBetween  bool // BETWEEN 查询
-	SupportsLike     bool // LIKE 模式匹配
-	SupportsDistinct bool // DISTINCT 去重
-	SupportsGroupBy  bool // GROUP BY 分组
-	SupportsHaving   bool // HAVING 条件过滤
+	SupportsIN         bool // IN (value1, value2, ...) 范围查询
+	SupportsNotIN      bool // NOT IN 查询
+	SupportsBetween    bool // BETWEEN 查询
+	SupportsLike       bool // LIKE 模式匹配
+	SupportsDistinct   bool // DISTINCT 去重
+	SupportsDistinctOn bool // DISTINCT ON (字段) 按字段去重（PostgreSQL）
+	SupportsGroupBy    bool // GROUP BY 分组
+	SupportsHaving     bool // HAVING 条件过滤
 
 	// JOIN 操作
 	SupportsInnerJoin     bool // INNER JOIN
@@ -126,6 +127,8 @@ type QueryFeatures struct {
 	SupportsIfExists     bool // IF EXISTS 子句
 	SupportsInsertIgnore bool // INSERT IGNORE (MySQL) 或 ON CONFLICT (PostgreSQL)
 	SupportsUpsert       bool // INSERT ... ON DUPLICATE KEY UPDATE 或 ON CONFLICT
+	SupportsRowLock      bool // 行级锁 (FOR UPDATE / FOR SHARE / WITH (UPDLOCK))
+	SupportsSkipLocked   bool // 锁等待策略 (SKIP LOCKED / NOWAIT / READPAST)
 
 	// ==================== VIEW 支持 ====================
 	SupportsView             bool // 是否支持 VIEW
@@ -167,13 +170,14 @@ type QueryFeatures struct {
 func NewPostgreSQLQueryFeatures() *QueryFeatures {
 	return &QueryFeatures{
 		// 基础特性 - 全部支持
-		SupportsIN:       true,
-		SupportsNotIN:    true,
-		SupportsBetween:  true,
-		SupportsLike:     true,
-		SupportsDistinct: true,
-		SupportsGroupBy:  true,
-		SupportsHaving:   true,
+		SupportsIN:         true,
+		SupportsNotIN:      true,
+		SupportsBetween:    true,
+		SupportsLike:       true,
+		SupportsDistinct:   true,
+		SupportsDistinctOn: true,
+		SupportsGroupBy:    true,
+		SupportsHaving:     true,
 
 		// JOIN 操作 - 全部支持
 		SupportsInnerJoin:     true,
@@ -221,6 +225,8 @@ func NewPostgreSQLQueryFeatures() *QueryFeatures {
 		SupportsIfExists:     true,
 		SupportsInsertIgnore: true,
 		SupportsUpsert:       true,
+		SupportsRowLock:      true,
+		SupportsSkipLocked:   true,
 
 		// VIEW 支持
 		SupportsView:             true,
@@ -257,13 +263,14 @@ func NewPostgreSQLQueryFeatures() *QueryFeatures {
 func NewMySQLQueryFeatures() *QueryFeatures {
 	return &QueryFeatures{
 		// 基础特性 - 全部支持
-		SupportsIN:       true,
-		SupportsNotIN:    true,
-		SupportsBetween:  true,
-		SupportsLike:     true,
-		SupportsDistinct: true,
-		SupportsGroupBy:  true,
-		SupportsHaving:   true,
+		SupportsIN:         true,
+		SupportsNotIN:      true,
+		SupportsBetween:    true,
+		SupportsLike:       true,
+		SupportsDistinct:   true,
+		SupportsDistinctOn: false, // ❌ 以 ROW_NUMBER() 子查询改写
+		SupportsGroupBy:    true,
+		SupportsHaving:     true,
 
 		// JOIN 操作 - 全部支持
 		SupportsInnerJoin:     true,
@@ -311,6 +318,8 @@ func NewMySQLQueryFeatures() *QueryFeatures {
 		SupportsIfExists:     true,
 		SupportsInsertIgnore: true, // ✅ INSERT IGNORE
 		SupportsUpsert:       true, // ✅ INSERT ... ON DUPLICATE KEY UPDATE
+		SupportsRowLock:      true, // ✅ FOR UPDATE / FOR SHARE（8.0 前为 LOCK IN SHARE MODE）
+		SupportsSkipLocked:   true, // ✅ MySQL 8.0+ SKIP LOCKED / NOWAIT
 
 		// VIEW 支持
 		SupportsView:             true,
@@ -337,6 +346,8 @@ func NewMySQLQueryFeatures() *QueryFeatures {
 			"except":                QueryFallbackMultiQuery,        // 用 NOT IN 或 NOT EXISTS
 			"intersect":             QueryFallbackAlternativeSyntax, // 用 INNER JOIN
 			"order_by_in_aggregate": QueryFallbackApplicationLayer,
+			"window_func":           QueryFallbackApplicationLayer,  // 8.0 以下在应用层计算
+			"distinct_on":           QueryFallbackAlternativeSyntax, // 用 ROW_NUMBER() OVER (PARTITION BY ...) 子查询
 		},
 		FeatureNotes: map[string]string{
 			"full_outer_join":       "MySQL 不支持，可用 LEFT JOIN ... UNION ... RIGHT JOIN 模拟",
@@ -353,6 +364,7 @@ func NewMySQLQueryFeatures() *QueryFeatures {
 			"cte":           {Supported: true, MinVersion: "8.0"},
 			"recursive_cte": {Supported: true, MinVersion: "8.0"},
 			"window_func":   {Supported: true, MinVersion: "8.0"},
+			"skip_locked":   {Supported: true, MinVersion: "8.0"},
 			"json_path":     {Supported: true, MinVersion: "5.7"},
 			"json_type":     {Supported: true, MinVersion: "5.7"},
 			"json_agg":      {Supported: true, MinVersion: "5.7"},
@@ -364,13 +376,14 @@ func NewMySQLQueryFeatures() *QueryFeatures {
 func NewSQLiteQueryFeatures() *QueryFeatures {
 	return &QueryFeatures{
 		// 基础特性 - 全部支持
-		SupportsIN:       true,
-		SupportsNotIN:    true,
-		SupportsBetween:  true,
-		SupportsLike:     true,
-		SupportsDistinct: true,
-		SupportsGroupBy:  true,
-		SupportsHaving:   true,
+		SupportsIN:         true,
+		SupportsNotIN:      true,
+		SupportsBetween:    true,
+		SupportsLike:       true,
+		SupportsDistinct:   true,
+		SupportsDistinctOn: false, // ❌ 以 ROW_NUMBER() 子查询改写
+		SupportsGroupBy:    true,
+		SupportsHaving:     true,
 
 		// JOIN 操作 - 全部支持
 		SupportsInnerJoin:     true,
@@ -418,6 +431,8 @@ func NewSQLiteQueryFeatures() *QueryFeatures {
 		SupportsIfExists:     true,
 		SupportsInsertIgnore: false, // ❌ 用 INSERT OR IGNORE
 		SupportsUpsert:       true,  // ✅ INSERT ... ON CONFLICT
+		SupportsRowLock:      false, // ❌ 仅支持数据库级锁
+		SupportsSkipLocked:   false,
 
 		// VIEW 支持
 		SupportsView:             true,
@@ -444,6 +459,8 @@ func NewSQLiteQueryFeatures() *QueryFeatures {
 			"full_text_search": QueryFallbackAlternativeSyntax,
 			"regex_match":      QueryFallbackApplicationLayer,
 			"json_path":        QueryFallbackCustomFunction,
+			"window_func":      QueryFallbackApplicationLayer,  // 3.25.0 以下在应用层计算
+			"distinct_on":      QueryFallbackAlternativeSyntax, // 用 ROW_NUMBER() OVER (PARTITION BY ...) 子查询
 		},
 		FeatureNotes: map[string]string{
 			"full_outer_join":  "SQLite 不支持，可用 LEFT JOIN UNION RIGHT JOIN",
@@ -470,13 +487,14 @@ func NewSQLiteQueryFeatures() *QueryFeatures {
 func NewSQLServerQueryFeatures() *QueryFeatures {
 	return &QueryFeatures{
 		// 基础特性 - 全部支持
-		SupportsIN:       true,
-		SupportsNotIN:    true,
-		SupportsBetween:  true,
-		SupportsLike:     true,
-		SupportsDistinct: true,
-		SupportsGroupBy:  true,
-		SupportsHaving:   true,
+		SupportsIN:         true,
+		SupportsNotIN:      true,
+		SupportsBetween:    true,
+		SupportsLike:       true,
+		SupportsDistinct:   true,
+		SupportsDistinctOn: false, // ❌ 以 ROW_NUMBER() 子查询改写
+		SupportsGroupBy:    true,
+		SupportsHaving:     true,
 
 		// JOIN 操作 - 全部支持
 		SupportsInnerJoin:     true,
@@ -524,6 +542,8 @@ func NewSQLServerQueryFeatures() *QueryFeatures {
 		SupportsIfExists:     true,
 		SupportsInsertIgnore: false, // ❌ 用 MERGE
 		SupportsUpsert:       true,  // ✅ MERGE ... WHEN MATCHED THEN
+		SupportsRowLock:      true,  // ✅ WITH (UPDLOCK, ROWLOCK)
+		SupportsSkipLocked:   true,  // ✅ READPAST / NOWAIT 表提示
 
 		// VIEW 支持
 		SupportsView:             true,
@@ -550,6 +570,7 @@ func NewSQLServerQueryFeatures() *QueryFeatures {
 			"limit":           QueryFallbackAlternativeSyntax, // 用 OFFSET...FETCH NEXT
 			"array_aggregate": QueryFallbackAlternativeSyntax, // 用 STRING_AGG
 			"regex_match":     QueryFallbackApplicationLayer,
+			"distinct_on":     QueryFallbackAlternativeSyntax, // 用 ROW_NUMBER() OVER (PARTITION BY ...) 子查询
 		},
 		FeatureNotes: map[string]string{
 			"limit":            "SQL Server 用 OFFSET ... ROWS FETCH NEXT ... ROWS ONLY",
@@ -567,13 +588,14 @@ func NewSQLServerQueryFeatures() *QueryFeatures {
 // NewNeo4jQueryFeatures Neo4j 查询特性（Cypher 能力映射）。
 func NewNeo4jQueryFeatures() *QueryFeatures {
 	return &QueryFeatures{
-		SupportsIN:       true,
-		SupportsNotIN:    true,
-		SupportsBetween:  true,
-		SupportsLike:     false,
-		SupportsDistinct: true,
-		SupportsGroupBy:  true,
-		SupportsHaving:   true,
+		SupportsIN:         true,
+		SupportsNotIN:      true,
+		SupportsBetween:    true,
+		SupportsLike:       false,
+		SupportsDistinct:   true,
+		SupportsDistinctOn: false,
+		SupportsGroupBy:    true,
+		SupportsHaving:     true,
 
 		SupportsInnerJoin:     false,
 		SupportsLeftJoin:      false,
@@ -667,6 +689,33 @@ func GetQueryFeatures(dbType string) *QueryFeatures {
 	}
 }
 
+// queryFeaturesForBackend 返回已知后端的查询特性；未知后端返回 nil（构造器不做特性门控）。
+func queryFeaturesForBackend(name string) *QueryFeatures {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+	switch normalized {
+	case "postgres", "postgresql", "mysql", "sqlite", "sqlserver", "neo4j", "mongodb":
+		return GetQueryFeatures(normalized)
+	default:
+		return nil
+	}
+}
+
+// requireQueryFeature 校验查询特性支持情况。
+// 不支持时返回的错误会标明缺失的特性名（HasQueryFeature 使用的键）及声明的降级策略。
+func requireQueryFeature(features *QueryFeatures, backend string, feature string) error {
+	if features == nil || features.HasQueryFeature(feature) {
+		return nil
+	}
+	backend = strings.TrimSpace(backend)
+	if backend == "" {
+		backend = "query constructor"
+	}
+	if strategy := features.GetFallbackStrategy(feature); strategy != QueryFallbackNone {
+		return fmt.Errorf("%s does not support query feature %q (fallback strategy: %s)", backend, feature, strategy)
+	}
+	return fmt.Errorf("%s does not support query feature %q", backend, feature)
+}
+
 // ==================== Query Feature Helpers ====================
 
 // HasQueryFeature 检查是否支持某个查询特性
@@ -682,6 +731,8 @@ func (qf *QueryFeatures) HasQueryFeature(feature string) bool {
 		return qf.SupportsLike
 	case "distinct":
 		return qf.SupportsDistinct
+	case "distinct_on":
+		return qf.SupportsDistinctOn
 	case "group_by":
 		return qf.SupportsGroupBy
 	case "having":
@@ -754,6 +805,10 @@ func (qf *QueryFeatures) HasQueryFeature(feature string) bool {
 		return qf.SupportsInsertIgnore
 	case "upsert":
 		return qf.SupportsUpsert
+	case "row_lock":
+		return qf.SupportsRowLock
+	case "skip_locked":
+		return qf.SupportsSkipLocked
 	default:
 		return false
 	}
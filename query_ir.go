@@ -8,15 +8,121 @@ import (
 
 // QueryIR 表示查询的中间表示（IR），用于解耦构建阶段与目标语言编译阶段。
 type QueryIR struct {
+	CTEs               []QueryCTEIR // WITH 子句（普通 / 递归 CTE）
 	Source             QuerySourceIR
+	Distinct           bool     // SELECT DISTINCT
+	DistinctOn         []string // DISTINCT ON 字段；非 PostgreSQL 方言改写为 ROW_NUMBER() 子查询
 	Projections        []string
+	Aggregates         []QueryAggregateIR
+	Windows            []QueryWindowIR // 窗口函数投影（OVER 子句）
 	Conditions         []Condition
+	GroupBys           []string
+	Having             []Condition // HAVING 条件；字段可引用聚合别名
 	OrderBys           []QueryOrderIR
 	Limit              *int
 	Offset             *int
 	Joins              []QueryJoinIR
 	CrossTableStrategy CrossTableStrategy
 	Hints              QueryCompileHints
+	// SetOperations 复合查询的后续分支；存在时 OrderBys / Limit / Offset 作用于整个复合结果。
+	SetOperations []QuerySetOperationIR
+	// Lock 行级锁（FOR UPDATE / WITH (UPDLOCK) 等）；nil 表示不加锁。
+	Lock *QueryLockIR
+	// Preloads 关联预加载树；SQL 由执行层批量查询，Neo4j 编译为 OPTIONAL MATCH + collect()。
+	Preloads []QueryPreloadIR
+}
+
+// QueryLockIR 行级锁 IR。
+type QueryLockIR struct {
+	Strength RowLockStrength
+	Wait     RowLockWait
+}
+
+// QuerySetOperator 集合运算类型。
+type QuerySetOperator string
+
+const (
+	SetOperationUnion     QuerySetOperator = "UNION"
+	SetOperationUnionAll  QuerySetOperator = "UNION ALL"
+	SetOperationIntersect QuerySetOperator = "INTERSECT"
+	SetOperationExcept    QuerySetOperator = "EXCEPT"
+)
+
+// featureName 返回集合运算对应的 QueryFeatures 特性名。
+func (op QuerySetOperator) featureName() string {
+	switch op {
+	case SetOperationIntersect:
+		return "intersect"
+	case SetOperationExcept:
+		return "except"
+	default:
+		return "union"
+	}
+}
+
+// QuerySetOperationIR 复合查询中的一个后续分支。
+type QuerySetOperationIR struct {
+	Operator QuerySetOperator
+	Query    *QueryIR
+}
+
+// validateSetOperationBranchIR 校验集合运算分支：分支不能再携带嵌套集合运算或独立的排序分页。
+func validateSetOperationBranchIR(index int, op QuerySetOperationIR) error {
+	switch op.Operator {
+	case SetOperationUnion, SetOperationUnionAll, SetOperationIntersect, SetOperationExcept:
+	default:
+		return fmt.Errorf("unsupported set operation: %s", op.Operator)
+	}
+	if op.Query == nil {
+		return fmt.Errorf("set operation branch %d is nil", index+1)
+	}
+	if len(op.Query.SetOperations) > 0 {
+		return fmt.Errorf("set operation branch %d cannot contain nested set operations; chain them on the first query", index+1)
+	}
+	if len(op.Query.OrderBys) > 0 || op.Query.Limit != nil || op.Query.Offset != nil {
+		return fmt.Errorf("set operation branch %d cannot have its own ORDER BY/LIMIT/OFFSET; apply them to the compound query", index+1)
+	}
+	return nil
+}
+
+// resultColumnNameIR 返回投影在结果行中的列名（别名或最后一段字段名）。
+func resultColumnNameIR(field string) string {
+	trimmed := strings.TrimSpace(field)
+	lower := strings.ToLower(trimmed)
+	if idx := strings.LastIndex(lower, " as "); idx >= 0 {
+		return strings.Trim(strings.TrimSpace(trimmed[idx+4:]), "`\"[]")
+	}
+	if idx := strings.LastIndex(trimmed, "."); idx >= 0 {
+		trimmed = trimmed[idx+1:]
+	}
+	return strings.Trim(trimmed, "`\"[]")
+}
+
+// setOperationColumnsIR 推断分支的输出列名；SELECT * 时回退到 Schema 字段。
+func setOperationColumnsIR(ir *QueryIR) []string {
+	columns := make([]string, 0, len(ir.Projections)+len(ir.Aggregates))
+	projections := ir.Projections
+	if len(projections) == 0 && len(ir.Aggregates) > 0 {
+		projections = ir.GroupBys
+	}
+	for _, field := range projections {
+		if strings.TrimSpace(field) == "*" {
+			columns = columns[:0]
+			break
+		}
+		if name := resultColumnNameIR(field); name != "" {
+			columns = append(columns, name)
+		}
+	}
+	for _, agg := range ir.Aggregates {
+		columns = append(columns, agg.Alias)
+	}
+	if len(columns) == 0 && len(ir.Joins) == 0 && ir.Source.Schema != nil {
+		for _, field := range ir.Source.Schema.Fields() {
+			columns = append(columns, field.Name)
+		}
+	}
+	return columns
 }
 
 // QuerySourceIR 查询数据源。
@@ -26,6 +132,36 @@ type QuerySourceIR struct {
 	Schema Schema // Schema 引用，nil 表示非 Schema 感知模式
 }
 
+// QueryAggregateIR 聚合投影信息。
+type QueryAggregateIR struct {
+	Func     AggregateFunc
+	Field    string // "*" 仅用于 COUNT(*)
+	Distinct bool
+	Alias    string
+}
+
+// QueryWindowIR 窗口函数投影信息。
+type QueryWindowIR struct {
+	Func        WindowFunc
+	Field       string // LAG/LEAD/聚合的取值字段；COUNT 可为 "*"
+	Offset      int    // LAG/LEAD 偏移量
+	Default     interface{}
+	HasDefault  bool
+	PartitionBy []string
+	OrderBy     []QueryOrderIR
+	Alias       string
+}
+
+// QueryCTEIR 公用表表达式信息。
+type QueryCTEIR struct {
+	Name        string
+	Columns     []string
+	Query       *QueryIR // 普通 CTE 查询，或递归 CTE 的锚定成员
+	Recursive   *QueryIR // 递归成员；nil 表示普通 CTE
+	MaxDepth    int      // 递归最大层数；0 表示不追加层数保护
+	DepthColumn string   // 递归层数列名；为空表示不追加
+}
+
 // QueryOrderIR 排序信息。
 type QueryOrderIR struct {
 	Field     string
@@ -198,17 +334,48 @@ func (c *BaseSQLCompiler) Compile(ctx context.Context, ir *QueryIR) (string, []i
 		usedAliases[strings.TrimSpace(ir.Source.Alias)] = true
 	}
 
-	if sqlText, args, ok, err := tryCompileSQLServerRecursiveManyToMany(c.dialect, ir); ok || err != nil {
+	if len(ir.CTEs) > 0 {
+		head := *ir
+		head.CTEs = nil
+		body, bodyArgs, err := c.Compile(ctx, &head)
+		if err != nil {
+			return "", nil, err
+		}
+		return attachSQLCTEsIR(ctx, c, ir.CTEs, body, bodyArgs)
+	}
+
+	if err := validateRowLockIR(ir); err != nil {
+		return "", nil, err
+	}
+	lockSuffix, err := renderSQLLockSuffixIR(c.dialect, ir.Lock)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(ir.DistinctOn) > 0 {
+		return c.compileDistinctOn(ctx, ir)
+	}
+
+	if len(ir.SetOperations) > 0 {
+		return c.compileSetOperations(ctx, ir)
+	}
+
+	if sqlText, args, ok, err := tryCompileSQLServerRecursiveManyToMany(ctx, c.dialect, ir); ok || err != nil {
 		return sqlText, args, err
 	}
 
 	if ir.Hints.ViewRoute != nil {
-		sql.WriteString("SELECT ")
-		renderSelectColumnsIR(c.dialect, ir, &sql)
+		sql.WriteString(selectKeywordIR(ir))
+		renderSelectColumnsIR(c.dialect, ir, &sql, &args, &argIndex)
 		sql.WriteString(" FROM ")
 		sql.WriteString(c.dialect.QuoteIdentifier(ir.Hints.ViewRoute.ViewName))
 		sql.WriteString(joinKeywordWithAliasIR(c.dialect, strings.TrimSpace(ir.Hints.ViewRoute.Alias)))
-		return appendWhereOrderLimitIR(c.dialect, ir, &sql, args, &argIndex)
+		sql.WriteString(renderSQLServerLockHintIR(c.dialect, ir.Lock))
+		compiled, compiledArgs, err := appendWhereOrderLimitIR(ctx, c.dialect, ir, &sql, args, &argIndex)
+		if err != nil {
+			return "", nil, err
+		}
+		return compiled + lockSuffix, compiledArgs, nil
 	}
 
 	if ir.Hints.UseTempTable {
@@ -239,8 +406,9 @@ func (c *BaseSQLCompiler) Compile(ctx context.Context, ir *QueryIR) (string, []i
 		}
 		sql.WriteString(";")
 
-		sql.WriteString(" SELECT ")
-		renderSelectColumnsIR(c.dialect, ir, &sql)
+		sql.WriteString(" ")
+		sql.WriteString(selectKeywordIR(ir))
+		renderSelectColumnsIR(c.dialect, ir, &sql, &args, &argIndex)
 		sql.WriteString(" FROM ")
 		sql.WriteString(tmpName)
 		if baseAlias != "" {
@@ -250,22 +418,153 @@ func (c *BaseSQLCompiler) Compile(ctx context.Context, ir *QueryIR) (string, []i
 			return "", nil, err
 		}
 	} else {
-		sql.WriteString("SELECT ")
-		renderSelectColumnsIR(c.dialect, ir, &sql)
+		sql.WriteString(selectKeywordIR(ir))
+		renderSelectColumnsIR(c.dialect, ir, &sql, &args, &argIndex)
 		sql.WriteString(" FROM ")
 		sql.WriteString(c.dialect.QuoteIdentifier(ir.Source.Table))
 		if strings.TrimSpace(ir.Source.Alias) != "" {
 			sql.WriteString(joinKeywordWithAliasIR(c.dialect, strings.TrimSpace(ir.Source.Alias)))
 		}
+		sql.WriteString(renderSQLServerLockHintIR(c.dialect, ir.Lock))
 		if err := renderJoinSQLIR(c.dialect, ir, &sql, usedAliases); err != nil {
 			return "", nil, err
 		}
 	}
 
-	return appendWhereOrderLimitIR(c.dialect, ir, &sql, args, &argIndex)
+	compiled, compiledArgs, err := appendWhereOrderLimitIR(ctx, c.dialect, ir, &sql, args, &argIndex)
+	if err != nil {
+		return "", nil, err
+	}
+	return compiled + lockSuffix, compiledArgs, nil
+}
+
+// compileSetOperations 编译复合查询：各分支独立编译后按顺序重编号占位符并拼接，
+// 共享的 ORDER BY / LIMIT / OFFSET 追加在末尾。
+// 方言缺少 INTERSECT / EXCEPT 且 QueryFeatures 声明了降级策略时，改写为 INNER JOIN / NOT EXISTS 等价形式。
+func (c *BaseSQLCompiler) compileSetOperations(ctx context.Context, ir *QueryIR) (string, []interface{}, error) {
+	backend := strings.TrimSpace(c.dialect.Name())
+	features := queryFeaturesForBackend(backend)
+
+	head := *ir
+	head.SetOperations = nil
+	head.OrderBys = nil
+	head.Limit = nil
+	head.Offset = nil
+	columns := setOperationColumnsIR(&head)
+
+	compound, args, err := c.Compile(ctx, &head)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var previous QuerySetOperator
+	for i, op := range ir.SetOperations {
+		if err := validateSetOperationBranchIR(i, op); err != nil {
+			return "", nil, err
+		}
+		branchSQL, branchArgs, err := c.Compile(ctx, op.Query)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to compile set operation branch %d: %w", i+1, err)
+		}
+		branchSQL = renumberSQLPlaceholders(c.dialect, branchSQL, len(args))
+
+		feature := op.Operator.featureName()
+		if features != nil && !features.HasQueryFeature(feature) {
+			if op.Operator == SetOperationUnion || op.Operator == SetOperationUnionAll ||
+				features.GetFallbackStrategy(feature) == QueryFallbackNone {
+				return "", nil, requireQueryFeature(features, backend, feature)
+			}
+			compound, err = emulateSQLSetOperationIR(c.dialect, op.Operator, compound, branchSQL, columns, i+1)
+			if err != nil {
+				return "", nil, err
+			}
+			args = append(args, branchArgs...)
+			previous = ""
+			continue
+		}
+
+		// 运算符变化时先将已有复合结果包装为派生表，保证从左到右的求值顺序（INTERSECT 优先级高于 UNION）。
+		if previous != "" && previous != op.Operator {
+			compound = "SELECT * FROM (" + compound + ")" + joinKeywordWithAliasIR(c.dialect, fmt.Sprintf("set_%d", i))
+		}
+		compound += " " + string(op.Operator) + " " + branchSQL
+		args = append(args, branchArgs...)
+		previous = op.Operator
+	}
+
+	var sql strings.Builder
+	sql.WriteString(compound)
+	if len(ir.OrderBys) > 0 {
+		sql.WriteString(" ORDER BY ")
+		for i, order := range ir.OrderBys {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			sql.WriteString(c.dialect.QuoteIdentifier(resultColumnNameIR(order.Field)))
+			sql.WriteString(" ")
+			sql.WriteString(order.Direction)
+		}
+	}
+	if limitOffset := c.dialect.GenerateLimitOffset(ir.Limit, ir.Offset); limitOffset != "" {
+		sql.WriteString(" ")
+		sql.WriteString(limitOffset)
+	}
+	return sql.String(), args, nil
+}
+
+// emulateSQLSetOperationIR 按 QueryFeatures 降级策略改写 INTERSECT / EXCEPT：
+//   - INTERSECT → SELECT DISTINCT l.* FROM (left) l INNER JOIN (right) r ON 各列 NULL 安全相等
+//   - EXCEPT → SELECT DISTINCT l.* FROM (left) l WHERE NOT EXISTS (SELECT 1 FROM (right) r WHERE 各列 NULL 安全相等)
+func emulateSQLSetOperationIR(dialect SQLDialect, operator QuerySetOperator, left, right string, columns []string, index int) (string, error) {
+	if len(columns) == 0 {
+		return "", fmt.Errorf("cannot emulate %s without known projection columns; use Select to list them", operator)
+	}
+	leftAlias := fmt.Sprintf("set_l%d", index)
+	rightAlias := fmt.Sprintf("set_r%d", index)
+
+	matches := make([]string, 0, len(columns))
+	nullSafe := strings.EqualFold(strings.TrimSpace(dialect.Name()), "mysql")
+	for _, column := range columns {
+		l := quoteQualifiedIdentifierIR(dialect, leftAlias, column)
+		r := quoteQualifiedIdentifierIR(dialect, rightAlias, column)
+		if nullSafe {
+			matches = append(matches, l+" <=> "+r)
+			continue
+		}
+		matches = append(matches, "("+l+" = "+r+" OR ("+l+" IS NULL AND "+r+" IS NULL))")
+	}
+	predicate := strings.Join(matches, " AND ")
+
+	var sql strings.Builder
+	sql.WriteString("SELECT DISTINCT ")
+	sql.WriteString(dialect.QuoteIdentifier(leftAlias))
+	sql.WriteString(".* FROM (")
+	sql.WriteString(left)
+	sql.WriteString(")")
+	sql.WriteString(joinKeywordWithAliasIR(dialect, leftAlias))
+	switch operator {
+	case SetOperationIntersect:
+		sql.WriteString(" INNER JOIN (")
+		sql.WriteString(right)
+		sql.WriteString(")")
+		sql.WriteString(joinKeywordWithAliasIR(dialect, rightAlias))
+		sql.WriteString(" ON ")
+		sql.WriteString(predicate)
+	case SetOperationExcept:
+		sql.WriteString(" WHERE NOT EXISTS (SELECT 1 FROM (")
+		sql.WriteString(right)
+		sql.WriteString(")")
+		sql.WriteString(joinKeywordWithAliasIR(dialect, rightAlias))
+		sql.WriteString(" WHERE ")
+		sql.WriteString(predicate)
+		sql.WriteString(")")
+	default:
+		return "", fmt.Errorf("set operation %s cannot be emulated", operator)
+	}
+	return sql.String(), nil
 }
 
-func tryCompileSQLServerRecursiveManyToMany(dialect SQLDialect, ir *QueryIR) (string, []interface{}, bool, error) {
+func tryCompileSQLServerRecursiveManyToMany(ctx context.Context, dialect SQLDialect, ir *QueryIR) (string, []interface{}, bool, error) {
 	if !strings.EqualFold(strings.TrimSpace(dialect.Name()), "sqlserver") {
 		return "", nil, false, nil
 	}
@@ -344,8 +643,10 @@ func tryCompileSQLServerRecursiveManyToMany(dialect SQLDialect, ir *QueryIR) (st
 	sql.WriteString(fmt.Sprintf("%d", recursiveDepth))
 	sql.WriteString(") ")
 
+	args := make([]interface{}, 0)
+	argIndex := 1
 	sql.WriteString("SELECT ")
-	renderSelectColumnsIR(dialect, ir, &sql)
+	renderSelectColumnsIR(dialect, ir, &sql, &args, &argIndex)
 	sql.WriteString(" FROM ")
 	sql.WriteString(dialect.QuoteIdentifier(ir.Source.Table))
 	sql.WriteString(joinKeywordWithAliasIR(dialect, sourceAlias))
@@ -370,9 +671,7 @@ func tryCompileSQLServerRecursiveManyToMany(dialect SQLDialect, ir *QueryIR) (st
 	sql.WriteString(" = ")
 	sql.WriteString(quoteQualifiedIdentifierIR(dialect, joinAlias, targetPK))
 
-	args := make([]interface{}, 0)
-	argIndex := 1
-	out, outArgs, err := appendWhereOrderLimitIR(dialect, ir, &sql, args, &argIndex)
+	out, outArgs, err := appendWhereOrderLimitIR(ctx, dialect, ir, &sql, args, &argIndex)
 	if err != nil {
 		return "", nil, true, err
 	}
@@ -380,10 +679,10 @@ func tryCompileSQLServerRecursiveManyToMany(dialect SQLDialect, ir *QueryIR) (st
 	return out, outArgs, true, nil
 }
 
-func appendWhereOrderLimitIR(dialect SQLDialect, ir *QueryIR, sql *strings.Builder, args []interface{}, argIndex *int) (string, []interface{}, error) {
+func appendWhereOrderLimitIR(ctx context.Context, dialect SQLDialect, ir *QueryIR, sql *strings.Builder, args []interface{}, argIndex *int) (string, []interface{}, error) {
 	if len(ir.Conditions) > 0 {
 		sql.WriteString(" WHERE ")
-		translator := &DefaultSQLTranslator{dialect: dialect, argIndex: argIndex}
+		translator := &DefaultSQLTranslator{ctx: ctx, dialect: dialect, argIndex: argIndex}
 		for i, condition := range ir.Conditions {
 			if i > 0 {
 				sql.WriteString(" AND ")
@@ -397,13 +696,52 @@ func appendWhereOrderLimitIR(dialect SQLDialect, ir *QueryIR, sql *strings.Build
 		}
 	}
 
+	if len(ir.GroupBys) > 0 {
+		sql.WriteString(" GROUP BY ")
+		for i, field := range ir.GroupBys {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			sql.WriteString(dialect.QuoteIdentifier(qualifyIdentifierIR(ir, field)))
+		}
+	}
+
+	if len(ir.Having) > 0 {
+		sql.WriteString(" HAVING ")
+		translator := &DefaultSQLTranslator{ctx: ctx, dialect: dialect, argIndex: argIndex}
+		resolveField := func(field string) string {
+			// HAVING 中引用聚合别名时展开为聚合表达式，兼容不支持别名引用的方言（PG / SQL Server）。
+			if agg, ok := findAggregateIR(ir, field); ok {
+				return renderAggregateSQLIR(dialect, ir, agg)
+			}
+			return qualifyIdentifierIR(ir, field)
+		}
+		for i, condition := range ir.Having {
+			if i > 0 {
+				sql.WriteString(" AND ")
+			}
+			condSQL, condArgs, err := mapConditionFieldsIR(condition, resolveField).Translate(translator)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to translate having condition: %w", err)
+			}
+			sql.WriteString(condSQL)
+			args = append(args, condArgs...)
+		}
+	}
+
 	if len(ir.OrderBys) > 0 {
 		sql.WriteString(" ORDER BY ")
 		for i, order := range ir.OrderBys {
 			if i > 0 {
 				sql.WriteString(", ")
 			}
-			sql.WriteString(dialect.QuoteIdentifier(qualifyIdentifierIR(ir, order.Field)))
+			if agg, ok := findAggregateIR(ir, order.Field); ok {
+				sql.WriteString(dialect.QuoteIdentifier(agg.Alias))
+			} else if window, ok := findWindowIR(ir.Windows, order.Field); ok {
+				sql.WriteString(dialect.QuoteIdentifier(window.Alias))
+			} else {
+				sql.WriteString(dialect.QuoteIdentifier(qualifyIdentifierIR(ir, order.Field)))
+			}
 			sql.WriteString(" ")
 			sql.WriteString(order.Direction)
 		}
@@ -418,17 +756,109 @@ func appendWhereOrderLimitIR(dialect SQLDialect, ir *QueryIR, sql *strings.Build
 	return sql.String(), args, nil
 }
 
-func renderSelectColumnsIR(dialect SQLDialect, ir *QueryIR, sql *strings.Builder) {
-	if len(ir.Projections) > 0 {
-		for i, col := range ir.Projections {
-			if i > 0 {
-				sql.WriteString(", ")
-			}
-			sql.WriteString(dialect.QuoteIdentifier(qualifyIdentifierIR(ir, col)))
+// renderSelectColumnsIR 渲染投影列；窗口函数的参数值（LAG / LEAD 默认值）追加到 args 并占用占位符序号。
+func renderSelectColumnsIR(dialect SQLDialect, ir *QueryIR, sql *strings.Builder, args *[]interface{}, argIndex *int) {
+	columns := ir.Projections
+	if len(columns) == 0 && len(ir.Aggregates) > 0 {
+		// 聚合查询未显式 Select 时，默认输出分组字段。
+		columns = ir.GroupBys
+	}
+	written := 0
+	for _, col := range columns {
+		if written > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString(dialect.QuoteIdentifier(qualifyIdentifierIR(ir, col)))
+		written++
+	}
+	for _, agg := range ir.Aggregates {
+		if written > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString(renderAggregateSQLIR(dialect, ir, agg))
+		sql.WriteString(" AS ")
+		sql.WriteString(dialect.QuoteIdentifier(agg.Alias))
+		written++
+	}
+	if written == 0 && len(ir.Windows) > 0 {
+		// 未显式 Select 时输出基表全部列（MySQL 不允许裸 * 与其他表达式并列）。
+		if base := baseTableNameIR(ir); base != "" {
+			sql.WriteString(dialect.QuoteIdentifier(base))
+			sql.WriteString(".*")
+		} else {
+			sql.WriteString("*")
+		}
+		written++
+	}
+	for _, window := range ir.Windows {
+		if written > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString(renderWindowSQLIR(dialect, ir, window, args, argIndex))
+		sql.WriteString(" AS ")
+		sql.WriteString(dialect.QuoteIdentifier(window.Alias))
+		written++
+	}
+	if written == 0 {
+		sql.WriteString("*")
+	}
+}
+
+// renderAggregateSQLIR 渲染聚合表达式（不含别名），例如 SUM(DISTINCT "orders"."amount")。
+func renderAggregateSQLIR(dialect SQLDialect, ir *QueryIR, agg QueryAggregateIR) string {
+	fn := strings.ToUpper(string(agg.Func))
+	field := strings.TrimSpace(agg.Field)
+	if field == "" || field == "*" {
+		return fn + "(*)"
+	}
+	expr := dialect.QuoteIdentifier(qualifyIdentifierIR(ir, field))
+	if agg.Distinct {
+		return fn + "(DISTINCT " + expr + ")"
+	}
+	return fn + "(" + expr + ")"
+}
+
+// findAggregateIR 按别名查找聚合投影。
+func findAggregateIR(ir *QueryIR, name string) (QueryAggregateIR, bool) {
+	trimmed := strings.TrimSpace(name)
+	if ir == nil || trimmed == "" {
+		return QueryAggregateIR{}, false
+	}
+	for _, agg := range ir.Aggregates {
+		if agg.Alias == trimmed {
+			return agg, true
 		}
-		return
 	}
-	sql.WriteString("*")
+	return QueryAggregateIR{}, false
+}
+
+// mapConditionFieldsIR 复制条件树并按 mapper 改写字段名。
+func mapConditionFieldsIR(condition Condition, mapper func(string) string) Condition {
+	switch c := condition.(type) {
+	case *SimpleCondition:
+		copied := *c
+		copied.Field = mapper(c.Field)
+		return &copied
+	case *CompositeCondition:
+		copied := *c
+		copied.Conditions = make([]Condition, len(c.Conditions))
+		for i, inner := range c.Conditions {
+			copied.Conditions[i] = mapConditionFieldsIR(inner, mapper)
+		}
+		return &copied
+	case *NotCondition:
+		copied := *c
+		copied.Condition = mapConditionFieldsIR(c.Condition, mapper)
+		return &copied
+	case *SubqueryCondition:
+		copied := *c
+		if strings.TrimSpace(c.Field) != "" {
+			copied.Field = mapper(c.Field)
+		}
+		return &copied
+	default:
+		return condition
+	}
 }
 
 func renderJoinSQLIR(dialect SQLDialect, ir *QueryIR, sql *strings.Builder, usedAliases map[string]bool) error {
@@ -563,6 +993,18 @@ func quoteQualifiedIdentifierIR(dialect SQLDialect, qualifier, field string) str
 	return dialect.QuoteIdentifier(q) + "." + dialect.QuoteIdentifier(f)
 }
 
+type sqlVersionedDialect interface {
+	ServerVersion() string
+}
+
+// resolveSQLDialectVersionIR 返回方言声明的实例版本；未声明时为空。
+func resolveSQLDialectVersionIR(dialect SQLDialect) string {
+	if v, ok := dialect.(sqlVersionedDialect); ok {
+		return strings.TrimSpace(v.ServerVersion())
+	}
+	return ""
+}
+
 type sqlManyToManyStrategyDialect interface {
 	SQLManyToManyStrategy() string
 }
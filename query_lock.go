@@ -0,0 +1,221 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RowLockStrength 行级锁强度。
+type RowLockStrength string
+
+const (
+	RowLockForUpdate RowLockStrength = "update" // 排他锁：FOR UPDATE / UPDLOCK
+	RowLockForShare  RowLockStrength = "share"  // 共享锁：FOR SHARE / LOCK IN SHARE MODE
+)
+
+// RowLockWait 行锁冲突时的等待策略。
+type RowLockWait string
+
+const (
+	RowLockWaitBlock  RowLockWait = ""            // 默认阻塞等待
+	RowLockSkipLocked RowLockWait = "skip_locked" // 跳过已加锁的行：SKIP LOCKED / READPAST
+	RowLockNoWait     RowLockWait = "nowait"      // 立即失败：NOWAIT
+)
+
+// RowLock 行级锁选项（悲观锁），仅能在事务绑定的 Repository 中使用。
+//
+//	qc.Where(Eq("status", "queued")).Limit(10).Lock(ForUpdate().SkipLocked())
+type RowLock struct {
+	Strength RowLockStrength
+	Wait     RowLockWait
+}
+
+// ForUpdate 排他行锁。
+func ForUpdate() *RowLock {
+	return &RowLock{Strength: RowLockForUpdate}
+}
+
+// ForShare 共享行锁。
+func ForShare() *RowLock {
+	return &RowLock{Strength: RowLockForShare}
+}
+
+// SkipLocked 跳过已被其他事务锁定的行（常用于任务队列）。
+func (l *RowLock) SkipLocked() *RowLock {
+	if l != nil {
+		l.Wait = RowLockSkipLocked
+	}
+	return l
+}
+
+// NoWait 遇到已锁定的行时立即返回错误。
+func (l *RowLock) NoWait() *RowLock {
+	if l != nil {
+		l.Wait = RowLockNoWait
+	}
+	return l
+}
+
+func (l *RowLock) validate() error {
+	if l == nil {
+		return fmt.Errorf("row lock is nil")
+	}
+	switch l.Strength {
+	case RowLockForUpdate, RowLockForShare:
+	default:
+		return fmt.Errorf("unsupported row lock strength: %q", l.Strength)
+	}
+	switch l.Wait {
+	case RowLockWaitBlock, RowLockSkipLocked, RowLockNoWait:
+	default:
+		return fmt.Errorf("unsupported row lock wait policy: %q", l.Wait)
+	}
+	return nil
+}
+
+// transactionBoundConstructor 由支持行锁的构造器实现；事务适配器创建构造器时会将其标记为事务绑定。
+type transactionBoundConstructor interface {
+	bindTransaction()
+	requiresTransaction() bool
+}
+
+// bindTransactionIfNeeded 事务内 Repository 创建的构造器标记为事务绑定。
+func bindTransactionIfNeeded(adapter Adapter, qc QueryConstructor) QueryConstructor {
+	if qc == nil {
+		return qc
+	}
+	if _, inTx := adapter.(*txAdapter); !inTx {
+		return qc
+	}
+	if bound, ok := qc.GetNativeBuilder().(transactionBoundConstructor); ok {
+		bound.bindTransaction()
+	}
+	return qc
+}
+
+// requireTransactionForLock 执行前校验：带行锁的构造器只能在事务绑定的 Repository 上执行。
+func (r *Repository) requireTransactionForLock(constructor QueryConstructor) error {
+	bound, ok := constructor.GetNativeBuilder().(transactionBoundConstructor)
+	if !ok || !bound.requiresTransaction() {
+		return nil
+	}
+	if _, inTx := r.GetAdapter().(*txAdapter); inTx {
+		return nil
+	}
+	return fmt.Errorf("row lock requires a transaction-bound repository (create it with ChangesetExecutor.NewQueryConstructor inside WithChangeset)")
+}
+
+// validateRowLockIR 校验行锁与查询形态的兼容性。
+func validateRowLockIR(ir *QueryIR) error {
+	if ir.Lock == nil {
+		return nil
+	}
+	switch {
+	case len(ir.SetOperations) > 0:
+		return fmt.Errorf("row lock cannot be combined with set operations")
+	case len(ir.Aggregates) > 0 || len(ir.GroupBys) > 0:
+		return fmt.Errorf("row lock cannot be combined with GROUP BY or aggregates")
+	case len(ir.Windows) > 0:
+		return fmt.Errorf("row lock cannot be combined with window functions")
+	case ir.Distinct || len(ir.DistinctOn) > 0:
+		return fmt.Errorf("row lock cannot be combined with DISTINCT")
+	case ir.Hints.UseTempTable:
+		return fmt.Errorf("row lock cannot be combined with the temp table strategy")
+	}
+	return nil
+}
+
+// renderSQLServerLockHintIR SQL Server 以表提示实现行锁，紧随 FROM 表名（及别名）。
+func renderSQLServerLockHintIR(dialect SQLDialect, lock *QueryLockIR) string {
+	if lock == nil || !strings.EqualFold(strings.TrimSpace(dialect.Name()), "sqlserver") {
+		return ""
+	}
+	hints := []string{"UPDLOCK", "ROWLOCK"}
+	if lock.Strength == RowLockForShare {
+		hints = []string{"REPEATABLEREAD", "ROWLOCK"}
+	}
+	switch lock.Wait {
+	case RowLockSkipLocked:
+		hints = append(hints, "READPAST")
+	case RowLockNoWait:
+		hints = append(hints, "NOWAIT")
+	}
+	return " WITH (" + strings.Join(hints, ", ") + ")"
+}
+
+// renderSQLLockSuffixIR 返回追加在语句末尾的锁定子句（PostgreSQL / MySQL）。
+func renderSQLLockSuffixIR(dialect SQLDialect, lock *QueryLockIR) (string, error) {
+	if lock == nil {
+		return "", nil
+	}
+	name := strings.ToLower(strings.TrimSpace(dialect.Name()))
+	features := queryFeaturesForBackend(name)
+	if err := requireQueryFeature(features, name, "row_lock"); err != nil {
+		return "", err
+	}
+	if lock.Wait != RowLockWaitBlock {
+		if err := requireQueryFeature(features, name, "skip_locked"); err != nil {
+			return "", err
+		}
+	}
+
+	var clause string
+	switch name {
+	case "sqlserver":
+		return "", nil
+	case "mysql":
+		version := resolveSQLDialectVersionIR(dialect)
+		legacy := version != "" && compareVersion(version, "8.0") < 0
+		if legacy && lock.Wait != RowLockWaitBlock {
+			return "", fmt.Errorf("%s %s does not support query feature %q (requires %s)", name, version, "skip_locked", features.GetFeatureSupport("skip_locked").MinVersion)
+		}
+		clause = " FOR UPDATE"
+		if lock.Strength == RowLockForShare {
+			clause = " FOR SHARE"
+			if legacy {
+				clause = " LOCK IN SHARE MODE"
+			}
+		}
+	default:
+		clause = " FOR UPDATE"
+		if lock.Strength == RowLockForShare {
+			clause = " FOR SHARE"
+		}
+	}
+	switch lock.Wait {
+	case RowLockSkipLocked:
+		clause += " SKIP LOCKED"
+	case RowLockNoWait:
+		clause += " NOWAIT"
+	}
+	return clause, nil
+}
+
+// Lock 设置行级锁（SELECT ... FOR UPDATE / WITH (UPDLOCK) 等）。
+// 构造器需由事务内的 Repository 创建（WithChangeset 内的 ChangesetExecutor.NewQueryConstructor），否则 Build 返回错误。
+func (qb *SQLQueryConstructor) Lock(lock *RowLock) QueryConstructor {
+	if err := lock.validate(); err != nil {
+		qb.setBuildErr(err)
+		return qb
+	}
+	if err := qb.requireFeature("row_lock"); err != nil {
+		qb.setBuildErr(err)
+		return qb
+	}
+	if lock.Wait != RowLockWaitBlock {
+		if err := qb.requireVersionedFeature("skip_locked"); err != nil {
+			qb.setBuildErr(err)
+			return qb
+		}
+	}
+	qb.lock = &QueryLockIR{Strength: lock.Strength, Wait: lock.Wait}
+	return qb
+}
+
+func (qb *SQLQueryConstructor) bindTransaction() {
+	qb.txBound = true
+}
+
+func (qb *SQLQueryConstructor) requiresTransaction() bool {
+	return qb.lock != nil
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newLockJobsSchema() *BaseSchema {
+	schema := NewBaseSchema("jobs")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("priority", TypeInteger).Build())
+	return schema
+}
+
+func newTxBoundLockQuery(dialect SQLDialect, lock *RowLock) *SQLQueryConstructor {
+	qc := NewSQLQueryConstructor(newLockJobsSchema(), dialect)
+	qc.bindTransaction()
+	qc.Select("id").Where(Eq("status", "queued")).OrderBy("priority", "DESC").Limit(10).Lock(lock)
+	return qc
+}
+
+func TestSQLQueryConstructorLockPostgres(t *testing.T) {
+	sql, args, err := newTxBoundLockQuery(NewPostgreSQLDialect(), ForUpdate().SkipLocked()).Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expected := `SELECT "jobs"."id" FROM "jobs" WHERE "jobs"."status" = $1 ORDER BY "jobs"."priority" DESC LIMIT 10 FOR UPDATE SKIP LOCKED`
+	if sql != expected {
+		t.Fatalf("unexpected sql:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 1 || args[0] != "queued" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	sql, _, err = newTxBoundLockQuery(NewPostgreSQLDialect(), ForShare().NoWait()).Build(context.Background())
+	if err != nil || !strings.HasSuffix(sql, "LIMIT 10 FOR SHARE NOWAIT") {
+		t.Fatalf("unexpected share lock sql: %s (%v)", sql, err)
+	}
+	t.Logf("✓ PostgreSQL row lock: %s", expected)
+}
+
+func TestSQLQueryConstructorLockDialects(t *testing.T) {
+	sql, _, err := newTxBoundLockQuery(NewSQLServerDialect(), ForUpdate().SkipLocked()).Build(context.Background())
+	if err != nil {
+		t.Fatalf("sql server build failed: %v", err)
+	}
+	if !strings.Contains(sql, "FROM [jobs] WITH (UPDLOCK, ROWLOCK, READPAST) WHERE [jobs].[status] = @p1") || strings.Contains(sql, "FOR UPDATE") {
+		t.Fatalf("unexpected sql server lock sql: %s", sql)
+	}
+
+	sql, _, err = newTxBoundLockQuery(NewMySQLDialectWithVersion("8.0.36"), ForUpdate().NoWait()).Build(context.Background())
+	if err != nil || !strings.HasSuffix(sql, "LIMIT 10 FOR UPDATE NOWAIT") {
+		t.Fatalf("unexpected mysql 8 lock sql: %s (%v)", sql, err)
+	}
+
+	sql, _, err = newTxBoundLockQuery(NewMySQLDialectWithVersion("5.7.44"), ForShare()).Build(context.Background())
+	if err != nil || !strings.HasSuffix(sql, "LIMIT 10 LOCK IN SHARE MODE") {
+		t.Fatalf("unexpected mysql 5.7 share lock sql: %s (%v)", sql, err)
+	}
+
+	if _, _, err := newTxBoundLockQuery(NewMySQLDialectWithVersion("5.7.44"), ForUpdate().SkipLocked()).Build(context.Background()); err == nil || !strings.Contains(err.Error(), `"skip_locked"`) {
+		t.Fatalf("expected mysql 5.7 skip locked error, got: %v", err)
+	}
+	if _, _, err := newTxBoundLockQuery(NewSQLiteDialect(), ForUpdate()).Build(context.Background()); err == nil || !strings.Contains(err.Error(), `"row_lock"`) {
+		t.Fatalf("expected sqlite row lock error, got: %v", err)
+	}
+
+	grouped := newTxBoundLockQuery(NewPostgreSQLDialect(), ForUpdate())
+	grouped.GroupBy("status").Aggregate(CountOf("id"))
+	if _, _, err := grouped.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "GROUP BY") {
+		t.Fatalf("expected grouped lock error, got: %v", err)
+	}
+}
+
+func TestSQLQueryConstructorLockRequiresTransaction(t *testing.T) {
+	qc := NewSQLQueryConstructor(newLockJobsSchema(), NewPostgreSQLDialect())
+	qc.Lock(ForUpdate())
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "transaction-bound") {
+		t.Fatalf("expected transaction-bound error, got: %v", err)
+	}
+
+	cfg := &Config{Adapter: "sqlite", SQLite: &SQLiteConnectionConfig{Path: filepath.Join(t.TempDir(), "lock.db")}}
+	repo, err := NewRepository(cfg)
+	if err != nil {
+		t.Fatalf("create repository failed: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE jobs (id INTEGER PRIMARY KEY, status TEXT, priority INTEGER)",
+		"INSERT INTO jobs (id, status, priority) VALUES (1, 'queued', 5), (2, 'done', 1), (3, 'queued', 9)",
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+
+	outside, err := repo.NewQueryConstructor(newLockJobsSchema())
+	if err != nil {
+		t.Fatalf("new query constructor failed: %v", err)
+	}
+	if outside.GetNativeBuilder().(*SQLQueryConstructor).txBound {
+		t.Fatalf("constructor created outside a transaction must not be transaction-bound")
+	}
+	bound := newTxBoundLockQuery(NewPostgreSQLDialect(), ForUpdate())
+	if _, err := repo.ExecuteQueryConstructor(ctx, bound); err == nil || !strings.Contains(err.Error(), "transaction-bound") {
+		t.Fatalf("expected execution outside transaction to fail, got: %v", err)
+	}
+
+	err = repo.WithChangeset(ctx, newLockJobsSchema(), func(e *ChangesetExecutor) error {
+		qc, err := e.NewQueryConstructor()
+		if err != nil {
+			return err
+		}
+		if !qc.GetNativeBuilder().(*SQLQueryConstructor).txBound {
+			t.Fatalf("constructor created inside WithChangeset must be transaction-bound")
+		}
+		qc.Where(Eq("status", "queued")).OrderBy("priority", "DESC")
+		result, err := e.ExecuteQueryConstructor(qc)
+		if err != nil {
+			return err
+		}
+		if len(result.Rows) != 2 || result.Rows[0]["id"] != int64(3) {
+			t.Fatalf("unexpected rows: %v", result.Rows)
+		}
+
+		locked, _ := e.NewQueryConstructor()
+		locked.Lock(ForUpdate().SkipLocked())
+		if _, err := e.ExecuteQueryConstructor(locked); err == nil || !strings.Contains(err.Error(), `"row_lock"`) {
+			t.Fatalf("expected sqlite row lock error inside transaction, got: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("with changeset failed: %v", err)
+	}
+}
+
+func TestNonSQLQueryConstructorsRejectLock(t *testing.T) {
+	neo := NewNeo4jQueryConstructor(newLockJobsSchema())
+	neo.Lock(ForUpdate())
+	if _, _, err := neo.Build(context.Background()); err == nil || !strings.Contains(err.Error(), `"row_lock"`) {
+		t.Fatalf("expected neo4j row lock rejection, got: %v", err)
+	}
+
+	mongo := NewMongoQueryConstructor(newLockJobsSchema())
+	mongo.Lock(ForUpdate())
+	if _, err := mongo.BuildFindPlan(); err == nil || !strings.Contains(err.Error(), `"row_lock"`) {
+		t.Fatalf("expected mongodb row lock rejection, got: %v", err)
+	}
+}
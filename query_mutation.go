@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// buildUpdateSQL 编译条件更新：UPDATE 表 SET 字段 = 值 WHERE 条件。
+// 字段与条件经 Schema 校验，占位符按方言顺序编号（SET 在前，WHERE 在后）。
+func (qb *SQLQueryConstructor) buildUpdateSQL(ctx context.Context, changes map[string]interface{}) (string, []interface{}, error) {
+	return qb.compileUpdateSQL(ctx, changes, false)
+}
+
+// buildUpdateReturningSQL 编译返回更新后整行的条件更新：
+// SQL Server 使用 OUTPUT INSERTED.*（位于 WHERE 之前），其他方言追加 RETURNING *。
+func (qb *SQLQueryConstructor) buildUpdateReturningSQL(ctx context.Context, changes map[string]interface{}) (string, []interface{}, error) {
+	return qb.compileUpdateSQL(ctx, changes, true)
+}
+
+func (qb *SQLQueryConstructor) compileUpdateSQL(ctx context.Context, changes map[string]interface{}, returning bool) (string, []interface{}, error) {
+	if len(changes) == 0 {
+		return "", nil, fmt.Errorf("no fields to update")
+	}
+	if err := qb.validateMutation(); err != nil {
+		return "", nil, err
+	}
+
+	argIndex := 1
+	args := make([]interface{}, 0, len(changes))
+	sets := make([]string, 0, len(changes))
+	for _, field := range sortedMapKeys(changes) {
+		if err := qb.validateFieldReference(field, false); err != nil {
+			return "", nil, err
+		}
+		sets = append(sets, qb.dialect.QuoteIdentifier(field)+" = "+qb.dialect.GetPlaceholder(argIndex))
+		args = append(args, changes[field])
+		argIndex++
+	}
+
+	where, whereArgs, err := qb.compileMutationWhere(ctx, &argIndex)
+	if err != nil {
+		return "", nil, err
+	}
+	query := "UPDATE " + qb.dialect.QuoteIdentifier(qb.schema.TableName()) + " SET " + strings.Join(sets, ", ")
+	isSQLServer := strings.EqualFold(strings.TrimSpace(qb.dialect.Name()), "sqlserver")
+	if returning && isSQLServer {
+		query += " OUTPUT INSERTED.*"
+	}
+	query += " WHERE " + where
+	if returning && !isSQLServer {
+		query += " RETURNING *"
+	}
+	return query, append(args, whereArgs...), nil
+}
+
+// buildDeleteSQL 编译条件删除：DELETE FROM 表 WHERE 条件。
+func (qb *SQLQueryConstructor) buildDeleteSQL(ctx context.Context) (string, []interface{}, error) {
+	if err := qb.validateMutation(); err != nil {
+		return "", nil, err
+	}
+	argIndex := 1
+	where, args, err := qb.compileMutationWhere(ctx, &argIndex)
+	if err != nil {
+		return "", nil, err
+	}
+	return "DELETE FROM " + qb.dialect.QuoteIdentifier(qb.schema.TableName()) + " WHERE " + where, args, nil
+}
+
+// validateMutation 条件写入仅支持单表 + WHERE，且必须带条件（避免误更新 / 删除整表）。
+func (qb *SQLQueryConstructor) validateMutation() error {
+	if qb.buildErr != nil {
+		return qb.buildErr
+	}
+	if len(qb.conditions) == 0 {
+		return fmt.Errorf("condition-based write requires at least one condition")
+	}
+	if len(qb.joins) > 0 || len(qb.groupBys) > 0 || len(qb.setOperations) > 0 || strings.TrimSpace(qb.fromAlias) != "" {
+		return fmt.Errorf("condition-based write only supports single-table WHERE conditions")
+	}
+	for _, condition := range qb.conditions {
+		if err := qb.validateConditionFields(condition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileMutationWhere 编译 WHERE 条件；ctx 传给翻译器，用于编译条件中的子查询。
+func (qb *SQLQueryConstructor) compileMutationWhere(ctx context.Context, argIndex *int) (string, []interface{}, error) {
+	translator := &DefaultSQLTranslator{ctx: ctx, dialect: qb.dialect, argIndex: argIndex}
+	parts := make([]string, 0, len(qb.conditions))
+	args := make([]interface{}, 0)
+	for _, condition := range qb.conditions {
+		condSQL, condArgs, err := condition.Translate(translator)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to translate condition: %w", err)
+		}
+		parts = append(parts, condSQL)
+		args = append(args, condArgs...)
+	}
+	return strings.Join(parts, " AND "), args, nil
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newMutationUsersSchema() *BaseSchema {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("name", TypeString).Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("deleted_at", TypeTime).Null(true).Build())
+	return schema
+}
+
+func TestSQLQueryConstructorMutationSQL(t *testing.T) {
+	ctx := context.Background()
+	qc := NewSQLQueryConstructor(newMutationUsersSchema(), NewPostgreSQLDialect())
+	qc.Where(And(Eq("status", "pending"), In("id", 1, 2)))
+	sql, args, err := qc.buildUpdateSQL(ctx, map[string]interface{}{"status": "active", "name": "x"})
+	if err != nil {
+		t.Fatalf("build update failed: %v", err)
+	}
+	expected := `UPDATE "users" SET "name" = $1, "status" = $2 WHERE ("users"."status" = $3 AND "users"."id" IN ($4, $5))`
+	if sql != expected {
+		t.Fatalf("unexpected update sql:\n got: %s\nwant: %s", sql, expected)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"x", "active", "pending", 1, 2}) {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	qc = NewSQLQueryConstructor(newMutationUsersSchema(), NewSQLServerDialect())
+	qc.Where(Eq("status", "pending"))
+	if sql, _, err = qc.buildDeleteSQL(ctx); err != nil || sql != "DELETE FROM [users] WHERE [users].[status] = @p1" {
+		t.Fatalf("unexpected delete sql: %s (%v)", sql, err)
+	}
+
+	qc = NewSQLQueryConstructor(newMutationUsersSchema(), NewMySQLDialect())
+	qc.Where(Eq("missing", 1))
+	if _, _, err := qc.buildDeleteSQL(ctx); err == nil || !strings.Contains(err.Error(), `"missing" does not exist`) {
+		t.Fatalf("expected unknown field error, got: %v", err)
+	}
+	qc = NewSQLQueryConstructor(newMutationUsersSchema(), NewMySQLDialect())
+	if _, _, err := qc.buildUpdateSQL(ctx, map[string]interface{}{"bogus": 1}); err == nil {
+		t.Fatalf("expected missing condition error")
+	}
+	qc.Where(Eq("id", 1))
+	if _, _, err := qc.buildUpdateSQL(ctx, map[string]interface{}{"bogus": 1}); err == nil || !strings.Contains(err.Error(), `"bogus"`) {
+		t.Fatalf("expected unknown set field error, got: %v", err)
+	}
+	t.Logf("✓ condition update: %s", expected)
+}
+
+func TestChangesetExecutorConditionWritesSQLite(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"ALTER TABLE users ADD COLUMN status TEXT",
+		"ALTER TABLE users ADD COLUMN deleted_at DATETIME",
+		`INSERT INTO users (id, name, email, status) VALUES
+			(1, 'a', 'a@x', 'pending'), (2, 'b', 'b@x', 'pending'), (3, 'c', 'c@x', 'active'), (4, 'd', 'd@x', 'banned')`,
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+	schema := newMutationUsersSchema()
+	schema.AddField(NewField("email", TypeString).Build())
+
+	err := repo.WithChangeset(ctx, schema, func(e *ChangesetExecutor) error {
+		cs := NewChangeset(schema)
+		cs.PutChange("status", "active")
+		updated, err := e.UpdateWhere(cs, NewWhereBuilder(Eq("status", "pending")).Build())
+		if err != nil || updated != 2 {
+			t.Fatalf("expected 2 updated rows, got %d (%v)", updated, err)
+		}
+
+		softDeleted, err := e.SoftDeleteWhere(Eq("status", "active"))
+		if err != nil || softDeleted != 3 {
+			t.Fatalf("expected 3 soft deleted rows, got %d (%v)", softDeleted, err)
+		}
+		if again, err := e.SoftDeleteWhere(Eq("status", "active")); err != nil || again != 0 {
+			t.Fatalf("already soft deleted rows must not be updated again, got %d (%v)", again, err)
+		}
+
+		deleted, err := e.DeleteWhere(Eq("status", "banned"))
+		if err != nil || deleted != 1 {
+			t.Fatalf("expected 1 deleted row, got %d (%v)", deleted, err)
+		}
+		if _, err := e.DeleteWhere(nil); err == nil {
+			t.Fatalf("expected nil condition error")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("with changeset failed: %v", err)
+	}
+
+	var remaining, softDeleted int
+	if err := repo.QueryRow(ctx, "SELECT COUNT(*), COUNT(deleted_at) FROM users").Scan(&remaining, &softDeleted); err != nil {
+		t.Fatalf("count failed: %v", err)
+	}
+	if remaining != 3 || softDeleted != 3 {
+		t.Fatalf("unexpected table state: remaining=%d soft_deleted=%d", remaining, softDeleted)
+	}
+
+	executor, err := repo.NewChangesetExecutor(ctx, NewBaseSchema("users"))
+	if err != nil {
+		t.Fatalf("failed to create changeset executor: %v", err)
+	}
+	if _, err := executor.SoftDeleteWhere(Eq("id", 1)); err == nil || !strings.Contains(err.Error(), "deleted_at") {
+		t.Fatalf("expected soft delete schema error, got: %v", err)
+	}
+}
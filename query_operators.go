@@ -0,0 +1,427 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// extendedConditionFeatures 扩展条件操作符到 QueryFeatures 特性键的映射。
+var extendedConditionFeatures = map[string]string{
+	"is_null":        "nulls",
+	"is_not_null":    "nulls",
+	"not_in":         "not_in",
+	"ilike":          "like",
+	"starts_with":    "like",
+	"ends_with":      "like",
+	"regex":          "regex_match",
+	"json_path":      "json_path",
+	"array_contains": "json_operators",
+	"array_overlap":  "json_operators",
+}
+
+// isExtendedConditionOperator 是否为扩展条件操作符。
+func isExtendedConditionOperator(operator string) bool {
+	_, ok := extendedConditionFeatures[operator]
+	return ok
+}
+
+// validateExtendedCondition 校验扩展操作符的特性支持（含版本）及字段类型。
+func (qb *SQLQueryConstructor) validateExtendedCondition(cond *SimpleCondition) error {
+	feature, ok := extendedConditionFeatures[cond.Operator]
+	if !ok {
+		return nil
+	}
+	if err := qb.requireVersionedFeature(feature); err != nil {
+		return err
+	}
+
+	var want FieldType
+	switch cond.Operator {
+	case "json_path":
+		want = TypeJSON
+	case "array_contains", "array_overlap":
+		want = TypeArray
+	default:
+		return nil
+	}
+	if qb.schema == nil {
+		return nil
+	}
+	name := strings.TrimSpace(cond.Field)
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	field := qb.schema.GetField(strings.Trim(name, "`\"[]"))
+	if field != nil && field.Type != want {
+		return fmt.Errorf("%s condition requires a %s field, %q is %s", cond.Operator, want, field.Name, field.Type)
+	}
+	return nil
+}
+
+// ==================== SQL ====================
+
+func (t *DefaultSQLTranslator) translateExtendedCondition(cond *SimpleCondition) (string, []interface{}, error) {
+	if _, ok := cond.Value.(FieldRef); ok {
+		return "", nil, fmt.Errorf("operator %s does not support field reference values", cond.Operator)
+	}
+	field := t.dialect.QuoteIdentifier(cond.Field)
+	dialect := strings.ToLower(strings.TrimSpace(t.dialect.Name()))
+
+	switch cond.Operator {
+	case "is_null":
+		return field + " IS NULL", nil, nil
+	case "is_not_null":
+		return field + " IS NOT NULL", nil, nil
+	case "not_in":
+		values, ok := cond.Value.([]interface{})
+		if !ok {
+			return "", nil, fmt.Errorf("not_in condition value must be []interface{}")
+		}
+		placeholders := make([]string, len(values))
+		for i := range values {
+			placeholders[i] = t.nextPlaceholder()
+		}
+		return field + " NOT IN (" + strings.Join(placeholders, ", ") + ")", values, nil
+	case "ilike":
+		pattern, ok := cond.Value.(string)
+		if !ok {
+			return "", nil, fmt.Errorf("ilike condition value must be string")
+		}
+		if dialect == "postgres" || dialect == "postgresql" {
+			return field + " ILIKE " + t.nextPlaceholder(), []interface{}{pattern}, nil
+		}
+		return "LOWER(" + field + ") LIKE LOWER(" + t.nextPlaceholder() + ")", []interface{}{pattern}, nil
+	case "starts_with", "ends_with":
+		value, ok := cond.Value.(string)
+		if !ok {
+			return "", nil, fmt.Errorf("%s condition value must be string", cond.Operator)
+		}
+		pattern := escapeSQLLikePattern(value, dialect == "sqlserver")
+		if cond.Operator == "starts_with" {
+			pattern += "%"
+		} else {
+			pattern = "%" + pattern
+		}
+		return field + " LIKE " + t.nextPlaceholder() + " ESCAPE '!'", []interface{}{pattern}, nil
+	case "regex":
+		pattern, ok := cond.Value.(string)
+		if !ok {
+			return "", nil, fmt.Errorf("regex condition value must be string")
+		}
+		switch dialect {
+		case "postgres", "postgresql":
+			return field + " ~ " + t.nextPlaceholder(), []interface{}{pattern}, nil
+		case "mysql":
+			return field + " REGEXP " + t.nextPlaceholder(), []interface{}{pattern}, nil
+		default:
+			if err := requireQueryFeature(queryFeaturesForBackend(dialect), dialect, "regex_match"); err != nil {
+				return "", nil, err
+			}
+			return field + " REGEXP " + t.nextPlaceholder(), []interface{}{pattern}, nil
+		}
+	case "json_path":
+		return t.translateJSONPathCondition(field, dialect, cond)
+	case "array_contains", "array_overlap":
+		return t.translateArrayCondition(field, dialect, cond)
+	default:
+		return "", nil, fmt.Errorf("unsupported operator: %s", cond.Operator)
+	}
+}
+
+func (t *DefaultSQLTranslator) nextPlaceholder() string {
+	placeholder := t.dialect.GetPlaceholder(*t.argIndex)
+	*t.argIndex++
+	return placeholder
+}
+
+// translateJSONPathCondition 提取 JSON 路径的标量值后按内层操作符比较。
+func (t *DefaultSQLTranslator) translateJSONPathCondition(field, dialect string, cond *SimpleCondition) (string, []interface{}, error) {
+	cmp, ok := cond.Value.(JSONPathComparison)
+	if !ok {
+		return "", nil, fmt.Errorf("json_path condition value must be JSONPathComparison")
+	}
+	if err := validateJSONPathSegments(cmp.Path); err != nil {
+		return "", nil, err
+	}
+	if isExtendedConditionOperator(cmp.Operator) && cmp.Operator != "is_null" && cmp.Operator != "is_not_null" {
+		return "", nil, fmt.Errorf("json_path does not support nested operator %s", cmp.Operator)
+	}
+
+	var (
+		expr    string
+		pathArg interface{}
+	)
+	numeric := isNumericConditionValue(cmp.Value)
+	switch dialect {
+	case "postgres", "postgresql":
+		pathArg = "{" + strings.Join(cmp.Path, ",") + "}"
+		expr = "(" + field + " #>> CAST(" + t.nextPlaceholder() + " AS TEXT[]))"
+		if numeric {
+			expr = "CAST(" + expr + " AS NUMERIC)"
+		}
+	case "mysql":
+		pathArg = jsonPathExpression(cmp.Path)
+		expr = "JSON_UNQUOTE(JSON_EXTRACT(" + field + ", " + t.nextPlaceholder() + "))"
+	case "sqlite":
+		pathArg = jsonPathExpression(cmp.Path)
+		expr = "json_extract(" + field + ", " + t.nextPlaceholder() + ")"
+	case "sqlserver":
+		pathArg = jsonPathExpression(cmp.Path)
+		expr = "JSON_VALUE(" + field + ", " + t.nextPlaceholder() + ")"
+		if numeric {
+			expr = "CAST(" + expr + " AS FLOAT)"
+		}
+	default:
+		return "", nil, fmt.Errorf("%s does not support query feature %q", dialect, "json_path")
+	}
+
+	clause, args, err := t.translateSimpleCondition(&SimpleCondition{Field: expr, Operator: cmp.Operator, Value: cmp.Value})
+	if err != nil {
+		return "", nil, fmt.Errorf("json_path: %w", err)
+	}
+	return clause, append([]interface{}{pathArg}, args...), nil
+}
+
+// translateArrayCondition 数组字段按 JSON 数组存储：逐元素检查成员关系，contains 以 AND 连接，overlap 以 OR 连接。
+func (t *DefaultSQLTranslator) translateArrayCondition(field, dialect string, cond *SimpleCondition) (string, []interface{}, error) {
+	values, ok := cond.Value.([]interface{})
+	if !ok {
+		return "", nil, fmt.Errorf("%s condition value must be []interface{}", cond.Operator)
+	}
+	joiner := " AND "
+	if cond.Operator == "array_overlap" {
+		joiner = " OR "
+	}
+	if len(values) == 0 {
+		if cond.Operator == "array_overlap" {
+			return "1 = 0", nil, nil
+		}
+		return "1 = 1", nil, nil
+	}
+	if err := requireQueryFeature(queryFeaturesForBackend(dialect), dialect, "json_operators"); err != nil {
+		return "", nil, err
+	}
+
+	parts := make([]string, 0, len(values))
+	args := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		switch dialect {
+		case "postgres", "postgresql", "mysql":
+			encoded, err := json.Marshal([]interface{}{value})
+			if err != nil {
+				return "", nil, fmt.Errorf("%s: failed to encode value: %w", cond.Operator, err)
+			}
+			args = append(args, string(encoded))
+			if dialect == "mysql" {
+				parts = append(parts, "JSON_CONTAINS("+field+", "+t.nextPlaceholder()+")")
+			} else {
+				parts = append(parts, "CAST("+field+" AS JSONB) @> CAST("+t.nextPlaceholder()+" AS JSONB)")
+			}
+		case "sqlite":
+			args = append(args, value)
+			parts = append(parts, "EXISTS (SELECT 1 FROM json_each("+field+") WHERE json_each.value = "+t.nextPlaceholder()+")")
+		case "sqlserver":
+			args = append(args, value)
+			parts = append(parts, "EXISTS (SELECT 1 FROM OPENJSON("+field+") WHERE [value] = "+t.nextPlaceholder()+")")
+		default:
+			return "", nil, fmt.Errorf("%s does not support query feature %q", dialect, "json_operators")
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0], args, nil
+	}
+	return "(" + strings.Join(parts, joiner) + ")", args, nil
+}
+
+// escapeSQLLikePattern 以 '!' 为转义符转义 LIKE 通配符；SQL Server 额外转义 '['。
+func escapeSQLLikePattern(value string, escapeBracket bool) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r == '!' || r == '%' || r == '_' || (escapeBracket && r == '[') {
+			b.WriteRune('!')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func validateJSONPathSegments(path []string) error {
+	if len(path) == 0 {
+		return fmt.Errorf("json_path condition requires a non-empty path")
+	}
+	for _, segment := range path {
+		if strings.TrimSpace(segment) == "" || strings.ContainsAny(segment, "\"'{},[]$ \t\n") {
+			return fmt.Errorf("invalid json path segment %q", segment)
+		}
+	}
+	return nil
+}
+
+// jsonPathExpression 生成 MySQL/SQLite/SQL Server 通用的 JSON 路径（键名加引号，纯数字段作为数组下标）。
+func jsonPathExpression(path []string) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, segment := range path {
+		if _, err := strconv.Atoi(segment); err == nil {
+			b.WriteString("[" + segment + "]")
+			continue
+		}
+		b.WriteString(`."` + segment + `"`)
+	}
+	return b.String()
+}
+
+func isNumericConditionValue(value interface{}) bool {
+	switch v := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	case []interface{}:
+		return len(v) > 0 && isNumericConditionValue(v[0])
+	default:
+		return false
+	}
+}
+
+// ==================== MongoDB ====================
+
+// translateMongoExtendedCondition 将扩展操作符转换为 MongoDB 过滤器。
+func translateMongoExtendedCondition(field string, cond *SimpleCondition) (map[string]interface{}, error) {
+	switch cond.Operator {
+	case "is_null":
+		return map[string]interface{}{field: nil}, nil
+	case "is_not_null":
+		return map[string]interface{}{field: map[string]interface{}{"$ne": nil}}, nil
+	case "not_in":
+		values, ok := cond.Value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("mongo not_in condition requires []interface{} value")
+		}
+		return map[string]interface{}{field: map[string]interface{}{"$nin": values}}, nil
+	case "ilike":
+		pattern, ok := cond.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("mongo ilike condition requires string value")
+		}
+		return map[string]interface{}{field: map[string]interface{}{"$regex": likePatternToRegex(pattern), "$options": "i"}}, nil
+	case "starts_with":
+		value, ok := cond.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("mongo starts_with condition requires string value")
+		}
+		return map[string]interface{}{field: map[string]interface{}{"$regex": "^" + regexp.QuoteMeta(value)}}, nil
+	case "ends_with":
+		value, ok := cond.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("mongo ends_with condition requires string value")
+		}
+		return map[string]interface{}{field: map[string]interface{}{"$regex": regexp.QuoteMeta(value) + "$"}}, nil
+	case "regex":
+		pattern, ok := cond.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("mongo regex condition requires string value")
+		}
+		return map[string]interface{}{field: map[string]interface{}{"$regex": pattern}}, nil
+	case "json_path":
+		cmp, ok := cond.Value.(JSONPathComparison)
+		if !ok {
+			return nil, fmt.Errorf("mongo json_path condition requires JSONPathComparison value")
+		}
+		if err := validateJSONPathSegments(cmp.Path); err != nil {
+			return nil, err
+		}
+		return translateMongoCondition(&SimpleCondition{Field: field + "." + strings.Join(cmp.Path, "."), Operator: cmp.Operator, Value: cmp.Value})
+	case "array_contains", "array_overlap":
+		values, ok := cond.Value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("mongo %s condition requires []interface{} value", cond.Operator)
+		}
+		if cond.Operator == "array_contains" {
+			return map[string]interface{}{field: map[string]interface{}{"$all": values}}, nil
+		}
+		return map[string]interface{}{field: map[string]interface{}{"$in": values}}, nil
+	default:
+		return nil, fmt.Errorf("mongo condition operator not supported: %s", cond.Operator)
+	}
+}
+
+// likePatternToRegex 将 SQL LIKE 模式（% / _）转换为锚定的正则表达式。
+func likePatternToRegex(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// ==================== Cypher ====================
+
+func (t *CypherConditionTranslator) translateExtended(field string, cond *SimpleCondition) (string, []interface{}, error) {
+	if _, ok := cond.Value.(FieldRef); ok {
+		return "", nil, fmt.Errorf("operator %s does not support field reference values", cond.Operator)
+	}
+	switch cond.Operator {
+	case "is_null":
+		return field + " IS NULL", nil, nil
+	case "is_not_null":
+		return field + " IS NOT NULL", nil, nil
+	case "not_in":
+		values, ok := cond.Value.([]interface{})
+		if !ok {
+			return "", nil, fmt.Errorf("not_in condition value must be []interface{}")
+		}
+		return "NOT " + field + " IN " + nextCypherPlaceholder(t.argIndex), []interface{}{values}, nil
+	case "ilike":
+		pattern, ok := cond.Value.(string)
+		if !ok {
+			return "", nil, fmt.Errorf("ilike condition value must be string")
+		}
+		op, value := translateLikeToCypher(pattern)
+		return "toLower(" + field + ") " + op + " toLower(" + nextCypherPlaceholder(t.argIndex) + ")", []interface{}{value}, nil
+	case "starts_with", "ends_with":
+		value, ok := cond.Value.(string)
+		if !ok {
+			return "", nil, fmt.Errorf("%s condition value must be string", cond.Operator)
+		}
+		op := "STARTS WITH"
+		if cond.Operator == "ends_with" {
+			op = "ENDS WITH"
+		}
+		return field + " " + op + " " + nextCypherPlaceholder(t.argIndex), []interface{}{value}, nil
+	case "regex":
+		pattern, ok := cond.Value.(string)
+		if !ok {
+			return "", nil, fmt.Errorf("regex condition value must be string")
+		}
+		return field + " =~ " + nextCypherPlaceholder(t.argIndex), []interface{}{pattern}, nil
+	case "json_path":
+		// Neo4j 属性不能保存嵌套文档，JSON 路径比较依赖 JSON 运算符。
+		if err := requireQueryFeature(queryFeaturesForBackend("neo4j"), "neo4j", "json_operators"); err != nil {
+			return "", nil, err
+		}
+		return "", nil, fmt.Errorf("unsupported operator: %s", cond.Operator)
+	case "array_contains", "array_overlap":
+		values, ok := cond.Value.([]interface{})
+		if !ok {
+			return "", nil, fmt.Errorf("%s condition value must be []interface{}", cond.Operator)
+		}
+		quantifier := "ALL"
+		if cond.Operator == "array_overlap" {
+			quantifier = "ANY"
+		}
+		return quantifier + "(item IN " + nextCypherPlaceholder(t.argIndex) + " WHERE item IN " + field + ")", []interface{}{values}, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported operator: %s", cond.Operator)
+	}
+}
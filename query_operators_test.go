@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newOperatorProductsSchema() *BaseSchema {
+	schema := NewBaseSchema("products")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("name", TypeString).Build())
+	schema.AddField(NewField("deleted_at", TypeTime).Null(true).Build())
+	schema.AddField(NewField("attrs", TypeJSON).Null(true).Build())
+	schema.AddField(NewField("tags", TypeArray).Null(true).Build())
+	return schema
+}
+
+func TestSQLQueryConstructorExtendedOperatorsPostgres(t *testing.T) {
+	qc := NewSQLQueryConstructor(newOperatorProductsSchema(), NewPostgreSQLDialect())
+	qc.Select("id").
+		Where(IsNull("deleted_at")).
+		Where(NotIn("id", 1, 2)).
+		Where(ILike("name", "%pro%")).
+		Where(StartsWith("name", "50%_")).
+		Where(Regex("name", "^[A-Z]")).
+		Where(JSONPath("attrs", "$.dimensions.width", "gte", 10)).
+		Where(ArrayOverlap("tags", "sale", "new"))
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expected := `SELECT "products"."id" FROM "products" WHERE "products"."deleted_at" IS NULL AND "products"."id" NOT IN ($1, $2) AND "products"."name" ILIKE $3 AND "products"."name" LIKE $4 ESCAPE '!' AND "products"."name" ~ $5 AND CAST(("products"."attrs" #>> CAST($6 AS TEXT[])) AS NUMERIC) >= $7 AND (CAST("products"."tags" AS JSONB) @> CAST($8 AS JSONB) OR CAST("products"."tags" AS JSONB) @> CAST($9 AS JSONB))`
+	if sql != expected {
+		t.Fatalf("unexpected sql:\n got: %s\nwant: %s", sql, expected)
+	}
+	want := []interface{}{1, 2, "%pro%", "50!%!_%", "^[A-Z]", "{dimensions,width}", 10, `["sale"]`, `["new"]`}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("unexpected args:\n got: %v\nwant: %v", args, want)
+	}
+	t.Logf("✓ PostgreSQL extended operators: %s", sql)
+}
+
+func TestSQLQueryConstructorExtendedOperatorsDialects(t *testing.T) {
+	build := func(dialect SQLDialect, cond Condition) (string, []interface{}, error) {
+		qc := NewSQLQueryConstructor(newOperatorProductsSchema(), dialect)
+		qc.Select("id").Where(cond)
+		return qc.Build(context.Background())
+	}
+
+	sql, args, err := build(NewMySQLDialect(), JSONPath("attrs", "sizes.0", "eq", "XL"))
+	if err != nil {
+		t.Fatalf("mysql build failed: %v", err)
+	}
+	if !strings.Contains(sql, "JSON_UNQUOTE(JSON_EXTRACT(`products`.`attrs`, ?)) = ?") || args[0] != `$."sizes"[0]` {
+		t.Fatalf("unexpected mysql json path: %s %v", sql, args)
+	}
+
+	sql, _, err = build(NewSQLServerDialect(), EndsWith("name", "[x]"))
+	if err != nil {
+		t.Fatalf("sql server build failed: %v", err)
+	}
+	if !strings.Contains(sql, "[products].[name] LIKE @p1 ESCAPE '!'") {
+		t.Fatalf("unexpected sql server ends_with: %s", sql)
+	}
+	if _, args, _ = build(NewSQLServerDialect(), EndsWith("name", "[x]")); args[0] != "%![x]" {
+		t.Fatalf("unexpected sql server ends_with args: %v", args)
+	}
+
+	sql, _, err = build(NewMySQLDialect(), ILike("name", "Pro%"))
+	if err != nil || !strings.Contains(sql, "LOWER(`products`.`name`) LIKE LOWER(?)") {
+		t.Fatalf("unexpected mysql ilike: %s %v", sql, err)
+	}
+
+	for _, dialect := range []SQLDialect{NewSQLiteDialect(), NewSQLServerDialect()} {
+		if _, _, err := build(dialect, Regex("name", "^a")); err == nil || !strings.Contains(err.Error(), `"regex_match"`) {
+			t.Fatalf("expected %s regex error naming regex_match, got: %v", dialect.Name(), err)
+		}
+	}
+
+	if _, _, err := build(NewMySQLDialectWithVersion("5.6.51"), JSONPath("attrs", "color", "eq", "red")); err == nil || !strings.Contains(err.Error(), `"json_path"`) {
+		t.Fatalf("expected mysql 5.6 json_path error, got: %v", err)
+	}
+	if _, _, err := build(NewPostgreSQLDialect(), JSONPath("name", "color", "eq", "red")); err == nil || !strings.Contains(err.Error(), "json field") {
+		t.Fatalf("expected json field type error, got: %v", err)
+	}
+	if _, _, err := build(NewPostgreSQLDialect(), ArrayContains("attrs", "red")); err == nil || !strings.Contains(err.Error(), "array field") {
+		t.Fatalf("expected array field type error, got: %v", err)
+	}
+}
+
+func TestSQLQueryConstructorExtendedOperatorsSQLiteExecution(t *testing.T) {
+	cfg := &Config{Adapter: "sqlite", SQLite: &SQLiteConnectionConfig{Path: filepath.Join(t.TempDir(), "operators.db")}}
+	repo, err := NewRepository(cfg)
+	if err != nil {
+		t.Fatalf("create repository failed: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT, deleted_at DATETIME NULL, attrs TEXT NULL, tags TEXT NULL)",
+		`INSERT INTO products (id, name, deleted_at, attrs, tags) VALUES
+			(1, 'Laptop Pro', NULL, '{"color":"silver","size":{"width":30}}', '["sale","new"]'),
+			(2, 'laptop_basic', NULL, '{"color":"black","size":{"width":25}}', '["new"]'),
+			(3, 'Phone', '2026-01-01', '{"color":"silver","size":{"width":7}}', '["sale"]'),
+			(4, 'Tablet', NULL, NULL, '[]')`,
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+
+	cases := []struct {
+		name string
+		cond Condition
+		ids  []int64
+	}{
+		{"is_null", IsNull("deleted_at"), []int64{1, 2, 4}},
+		{"is_not_null", IsNotNull("deleted_at"), []int64{3}},
+		{"not_in", NotIn("id", 1, 4), []int64{2, 3}},
+		{"ilike", ILike("name", "LAPTOP%"), []int64{1, 2}},
+		{"starts_with literal underscore", StartsWith("name", "laptop_"), []int64{2}},
+		{"ends_with", EndsWith("name", "Pro"), []int64{1}},
+		{"json_path eq", JSONPath("attrs", "color", "eq", "silver"), []int64{1, 3}},
+		{"json_path nested gt", JSONPath("attrs", "size.width", "gt", 20), []int64{1, 2}},
+		{"array_contains", ArrayContains("tags", "sale", "new"), []int64{1}},
+		{"array_overlap", ArrayOverlap("tags", "sale", "new"), []int64{1, 2, 3}},
+	}
+	for _, tc := range cases {
+		qc := NewSQLQueryConstructor(newOperatorProductsSchema(), NewSQLiteDialect())
+		qc.WithDeleted().Select("id").Where(tc.cond).OrderBy("id", "ASC")
+		result, err := repo.ExecuteQueryConstructor(ctx, qc)
+		if err != nil {
+			t.Fatalf("%s: execute failed: %v", tc.name, err)
+		}
+		if len(result.Rows) != len(tc.ids) {
+			t.Fatalf("%s: expected ids %v, got %v", tc.name, tc.ids, result.Rows)
+		}
+		for i, id := range tc.ids {
+			if result.Rows[i]["id"] != id {
+				t.Fatalf("%s: expected ids %v, got %v", tc.name, tc.ids, result.Rows)
+			}
+		}
+	}
+}
+
+func TestMongoExtendedOperators(t *testing.T) {
+	cases := []struct {
+		cond Condition
+		want map[string]interface{}
+	}{
+		{IsNull("deleted_at"), map[string]interface{}{"deleted_at": nil}},
+		{NotIn("id", 1, 2), map[string]interface{}{"id": map[string]interface{}{"$nin": []interface{}{1, 2}}}},
+		{ILike("name", "lap_op%"), map[string]interface{}{"name": map[string]interface{}{"$regex": "^lap.op.*$", "$options": "i"}}},
+		{StartsWith("name", "a.b"), map[string]interface{}{"name": map[string]interface{}{"$regex": `^a\.b`}}},
+		{Regex("name", "^x+$"), map[string]interface{}{"name": map[string]interface{}{"$regex": "^x+$"}}},
+		{JSONPath("attrs", "size.width", "gt", 20), map[string]interface{}{"attrs.size.width": map[string]interface{}{"$gt": 20}}},
+		{ArrayContains("tags", "a", "b"), map[string]interface{}{"tags": map[string]interface{}{"$all": []interface{}{"a", "b"}}}},
+		{ArrayOverlap("tags", "a"), map[string]interface{}{"tags": map[string]interface{}{"$in": []interface{}{"a"}}}},
+	}
+	for _, tc := range cases {
+		got, err := translateMongoCondition(tc.cond)
+		if err != nil {
+			t.Fatalf("translate %v failed: %v", tc.cond, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("unexpected mongo filter:\n got: %v\nwant: %v", got, tc.want)
+		}
+	}
+}
+
+func TestNeo4jExtendedOperators(t *testing.T) {
+	qc := NewNeo4jQueryConstructor(newOperatorProductsSchema())
+	qc.Select("id").
+		Where(IsNotNull("name")).
+		Where(NotIn("id", 1, 2)).
+		Where(ILike("name", "pro%")).
+		Where(EndsWith("name", "x")).
+		Where(Regex("name", "(?i)^lap.*")).
+		Where(ArrayContains("tags", "sale"))
+
+	cypher, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expected := "MATCH (n:products) WHERE n.name IS NOT NULL AND NOT n.id IN $p1 AND toLower(n.name) STARTS WITH toLower($p2) AND n.name ENDS WITH $p3 AND n.name =~ $p4 AND ALL(item IN $p5 WHERE item IN n.tags) AND n.deleted_at IS NULL RETURN n.id"
+	if cypher != expected {
+		t.Fatalf("unexpected cypher:\n got: %s\nwant: %s", cypher, expected)
+	}
+	if len(args) != 5 || args[1] != "pro" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	qc = NewNeo4jQueryConstructor(newOperatorProductsSchema())
+	qc.Where(JSONPath("attrs", "color", "eq", "red"))
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), `"json_operators"`) {
+		t.Fatalf("expected neo4j json path error naming json_operators, got: %v", err)
+	}
+}
@@ -0,0 +1,354 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// defaultQueryStreamBatchSize 未指定 BatchSize 时的默认批大小。
+const defaultQueryStreamBatchSize = 500
+
+// QueryStreamOptions 流式查询选项。
+type QueryStreamOptions struct {
+	// BatchSize 每批从服务端拉取的行数（MongoDB 游标 batchSize / Neo4j fetch size），也是 NextBatch 单次返回的最大行数。
+	// <= 0 使用默认值；SQL 驱动按协议逐行读取结果集，仅 NextBatch 使用该值。
+	BatchSize int
+}
+
+// QueryRowIterator 逐行读取 QueryConstructor 的执行结果，避免将大结果集一次性载入内存。
+// 用法与 *sql.Rows 一致：
+//
+//	it, err := repo.ExecuteQueryConstructorStream(ctx, qc, QueryStreamOptions{BatchSize: 1000})
+//	defer it.Close()
+//	for it.Next() { it.Scan(&dest) }
+//	if err := it.Err(); err != nil { ... }
+type QueryRowIterator struct {
+	Statement string
+	Args      []interface{}
+
+	ctx       context.Context
+	source    queryRowSource
+	process   func(row map[string]interface{})
+	batchSize int
+
+	row    map[string]interface{}
+	err    error
+	closed bool
+
+	scanType   reflect.Type
+	scanFields []mappedStructField
+}
+
+// queryRowSource 各后端的逐行结果来源。
+type queryRowSource interface {
+	next(ctx context.Context) (map[string]interface{}, bool, error)
+	close(ctx context.Context) error
+}
+
+// ExecuteQueryConstructorStream 执行 QueryConstructor 并返回逐行迭代器。
+// - SQL 适配器：直接读取 *sql.Rows
+// - MongoDB 适配器：读取 Find / Aggregate 游标
+// - Neo4j 适配器：读取自动提交读会话的结果流
+// 其他适配器（Redis、自定义执行钩子）退化为执行后逐行遍历。上下文取消后 Next 返回 false，Err 返回 ctx.Err()。
+func (r *Repository) ExecuteQueryConstructorStream(ctx context.Context, constructor QueryConstructor, opts QueryStreamOptions) (*QueryRowIterator, error) {
+	if constructor == nil {
+		return nil, fmt.Errorf("query constructor cannot be nil")
+	}
+	if err := r.requireTransactionForLock(constructor); err != nil {
+		return nil, err
+	}
+	process, err := streamRowProcessorFor(constructor)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := constructor.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultQueryStreamBatchSize
+	}
+	source, err := r.openQueryRowSource(ctx, query, args, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	return &QueryRowIterator{
+		Statement: query,
+		Args:      copyQueryArgs(args),
+		ctx:       ctx,
+		source:    source,
+		process:   process,
+		batchSize: batchSize,
+	}, nil
+}
+
+// queryRowStreamer 由支持流式读取的构造器实现，返回逐行后处理函数（可为 nil）。
+type queryRowStreamer interface {
+	streamRowProcessor() (func(row map[string]interface{}), error)
+}
+
+func streamRowProcessorFor(constructor QueryConstructor) (func(row map[string]interface{}), error) {
+	native := constructor.GetNativeBuilder()
+	if streamer, ok := native.(queryRowStreamer); ok {
+		return streamer.streamRowProcessor()
+	}
+	if _, ok := native.(queryRowPostProcessor); ok {
+		return nil, fmt.Errorf("query constructor requires full result post-processing; use ExecuteQueryConstructor")
+	}
+	return nil, nil
+}
+
+func (r *Repository) openQueryRowSource(ctx context.Context, query string, args []interface{}, batchSize int) (queryRowSource, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query statement cannot be empty")
+	}
+
+	r.mu.RLock()
+	adapter := r.adapter
+	r.mu.RUnlock()
+	if adapter == nil {
+		return nil, fmt.Errorf("adapter is not initialized")
+	}
+
+	switch native := adapter.(type) {
+	case *Neo4jAdapter:
+		session, result, err := native.openCypherStream(ctx, query, buildCypherParamsFromArgs(args), batchSize)
+		if err != nil {
+			return nil, err
+		}
+		return &neo4jRowSource{session: session, result: result}, nil
+	case *MongoAdapter:
+		trimmed := strings.TrimSpace(query)
+		if strings.HasPrefix(trimmed, mongoCompiledWritePrefix) {
+			return nil, fmt.Errorf("ExecuteQueryConstructorStream is query-only for mongodb write plans; use ExecuteQueryConstructorAuto")
+		}
+		if !strings.HasPrefix(trimmed, mongoCompiledQueryPrefix) {
+			return nil, fmt.Errorf("mongodb query constructor requires compiled plan prefix %q", mongoCompiledQueryPrefix)
+		}
+		cursor, err := native.openCompiledFindCursor(ctx, trimmed, int32(batchSize))
+		if err != nil {
+			return nil, err
+		}
+		return &mongoRowSource{cursor: cursor}, nil
+	}
+
+	descriptor, hasDescriptor := r.resolveExecutionDescriptor(adapter)
+	_, isRedis := adapter.(*RedisAdapter)
+	if isRedis || (hasDescriptor && descriptor.ExecuteQueryConstructor != nil) {
+		// 无原生游标的后端：执行后逐行遍历。
+		result, err := r.executeCompiledQueryStatement(ctx, query, args)
+		if err != nil {
+			return nil, err
+		}
+		return &sliceRowSource{rows: result.Rows}, nil
+	}
+
+	rows, err := r.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := rows.Columns()
+	if err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+	return &sqlRowSource{rows: rows, cols: cols}, nil
+}
+
+// Next 读取下一行；结果读完、出错或上下文取消时返回 false 并自动关闭底层游标。
+func (it *QueryRowIterator) Next() bool {
+	if it == nil || it.closed || it.err != nil {
+		return false
+	}
+	it.row = nil
+	if err := it.ctx.Err(); err != nil {
+		it.fail(err)
+		return false
+	}
+	row, ok, err := it.source.next(it.ctx)
+	if err != nil {
+		it.fail(err)
+		return false
+	}
+	if !ok {
+		if err := it.Close(); err != nil && it.err == nil {
+			it.err = err
+		}
+		return false
+	}
+	if it.process != nil {
+		it.process(row)
+	}
+	it.row = row
+	return true
+}
+
+// NextBatch 读取至多 BatchSize 行；结果读完时返回空切片。
+func (it *QueryRowIterator) NextBatch() ([]map[string]interface{}, error) {
+	if it == nil {
+		return nil, fmt.Errorf("query row iterator is nil")
+	}
+	batch := make([]map[string]interface{}, 0, it.batchSize)
+	for len(batch) < it.batchSize && it.Next() {
+		batch = append(batch, it.row)
+	}
+	return batch, it.err
+}
+
+// Row 返回当前行（需先调用 Next）。
+func (it *QueryRowIterator) Row() map[string]interface{} {
+	if it == nil {
+		return nil
+	}
+	return it.row
+}
+
+// Scan 将当前行写入 dest：*map[string]interface{} 或结构体指针（按 MapRows 的规则映射）。
+func (it *QueryRowIterator) Scan(dest interface{}) error {
+	if it == nil || it.row == nil {
+		return fmt.Errorf("Scan called without a successful Next")
+	}
+	if target, ok := dest.(*map[string]interface{}); ok {
+		if target == nil {
+			return fmt.Errorf("scan destination cannot be nil")
+		}
+		copied := make(map[string]interface{}, len(it.row))
+		for key, value := range it.row {
+			copied[key] = value
+		}
+		*target = copied
+		return nil
+	}
+
+	val := reflect.ValueOf(dest)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("scan destination must be *map[string]interface{} or pointer to struct, got %T", dest)
+	}
+	elem := val.Elem()
+	if it.scanType != elem.Type() {
+		it.scanType = elem.Type()
+		it.scanFields = mappedStructFields(elem.Type())
+	}
+	elem.Set(reflect.Zero(elem.Type()))
+	return mapRowIntoStruct(elem, it.scanFields, it.row)
+}
+
+// Err 返回迭代过程中的错误（包括上下文取消）。
+func (it *QueryRowIterator) Err() error {
+	if it == nil {
+		return nil
+	}
+	return it.err
+}
+
+// BatchSize 返回生效的批大小。
+func (it *QueryRowIterator) BatchSize() int {
+	if it == nil {
+		return 0
+	}
+	return it.batchSize
+}
+
+// Close 关闭底层游标；可重复调用。
+func (it *QueryRowIterator) Close() error {
+	if it == nil || it.closed {
+		return nil
+	}
+	it.closed = true
+	it.row = nil
+	// 上下文已取消时仍需释放游标与会话。
+	return it.source.close(context.WithoutCancel(it.ctx))
+}
+
+func (it *QueryRowIterator) fail(err error) {
+	it.err = err
+	_ = it.Close()
+}
+
+// sqlRowSource 基于 *sql.Rows 的结果来源。
+type sqlRowSource struct {
+	rows *sql.Rows
+	cols []string
+}
+
+func (s *sqlRowSource) next(ctx context.Context) (map[string]interface{}, bool, error) {
+	if !s.rows.Next() {
+		return nil, false, s.rows.Err()
+	}
+	row, err := scanCurrentRowToMap(s.rows, s.cols)
+	if err != nil {
+		return nil, false, err
+	}
+	return row, true, nil
+}
+
+func (s *sqlRowSource) close(ctx context.Context) error {
+	return s.rows.Close()
+}
+
+// mongoRowSource 基于 MongoDB 游标的结果来源。
+type mongoRowSource struct {
+	cursor *mongo.Cursor
+}
+
+func (s *mongoRowSource) next(ctx context.Context) (map[string]interface{}, bool, error) {
+	if !s.cursor.Next(ctx) {
+		return nil, false, s.cursor.Err()
+	}
+	entry := map[string]interface{}{}
+	if err := s.cursor.Decode(&entry); err != nil {
+		return nil, false, err
+	}
+	return entry, true, nil
+}
+
+func (s *mongoRowSource) close(ctx context.Context) error {
+	return s.cursor.Close(ctx)
+}
+
+// neo4jRowSource 基于 Neo4j 结果流的来源，关闭时释放会话。
+type neo4jRowSource struct {
+	session neo4j.SessionWithContext
+	result  neo4j.ResultWithContext
+}
+
+func (s *neo4jRowSource) next(ctx context.Context) (map[string]interface{}, bool, error) {
+	if !s.result.Next(ctx) {
+		return nil, false, s.result.Err()
+	}
+	return s.result.Record().AsMap(), true, nil
+}
+
+func (s *neo4jRowSource) close(ctx context.Context) error {
+	return s.session.Close(ctx)
+}
+
+// sliceRowSource 已物化结果的来源（无原生游标的后端）。
+type sliceRowSource struct {
+	rows []map[string]interface{}
+	pos  int
+}
+
+func (s *sliceRowSource) next(ctx context.Context) (map[string]interface{}, bool, error) {
+	if s.pos >= len(s.rows) {
+		return nil, false, nil
+	}
+	row := s.rows[s.pos]
+	s.rows[s.pos] = nil
+	s.pos++
+	return row, true, nil
+}
+
+func (s *sliceRowSource) close(ctx context.Context) error {
+	s.rows = nil
+	return nil
+}
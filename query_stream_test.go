@@ -0,0 +1,176 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func newStreamSQLiteRepo(t *testing.T, rows int) *Repository {
+	cfg := &Config{Adapter: "sqlite", SQLite: &SQLiteConnectionConfig{Path: filepath.Join(t.TempDir(), "stream.db")}}
+	repo, err := NewRepository(cfg)
+	if err != nil {
+		t.Fatalf("create repository failed: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := repo.Exec(ctx, "CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER, status TEXT, amount INTEGER)"); err != nil {
+		repo.Close()
+		t.Fatalf("create table failed: %v", err)
+	}
+	for i := 1; i <= rows; i++ {
+		if _, err := repo.Exec(ctx, "INSERT INTO orders (id, customer_id, status, amount) VALUES (?, ?, ?, ?)", i, i%3, "paid", i*10); err != nil {
+			repo.Close()
+			t.Fatalf("insert row %d failed: %v", i, err)
+		}
+	}
+	return repo
+}
+
+func TestExecuteQueryConstructorStreamSQLite(t *testing.T) {
+	repo := newStreamSQLiteRepo(t, 25)
+	defer repo.Close()
+	ctx := context.Background()
+
+	type order struct {
+		ID     int64  `db:"id"`
+		Status string `db:"status"`
+		Amount int    `db:"amount"`
+	}
+
+	qc := NewSQLQueryConstructor(newDistinctOrdersSchema(), NewSQLiteDialect())
+	qc.OrderBy("id", "ASC")
+	it, err := repo.ExecuteQueryConstructorStream(ctx, qc, QueryStreamOptions{})
+	if err != nil {
+		t.Fatalf("open stream failed: %v", err)
+	}
+	defer it.Close()
+	if it.BatchSize() != defaultQueryStreamBatchSize {
+		t.Fatalf("unexpected default batch size: %d", it.BatchSize())
+	}
+
+	count := 0
+	for it.Next() {
+		var item order
+		if err := it.Scan(&item); err != nil {
+			t.Fatalf("scan failed: %v", err)
+		}
+		count++
+		if item.ID != int64(count) || item.Amount != count*10 || item.Status != "paid" {
+			t.Fatalf("unexpected row %d: %+v", count, item)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+	if count != 25 || it.Next() {
+		t.Fatalf("expected 25 rows and exhausted iterator, got %d", count)
+	}
+
+	qc = NewSQLQueryConstructor(newDistinctOrdersSchema(), NewSQLiteDialect())
+	qc.OrderBy("id", "ASC")
+	it, err = repo.ExecuteQueryConstructorStream(ctx, qc, QueryStreamOptions{BatchSize: 10})
+	if err != nil {
+		t.Fatalf("open batched stream failed: %v", err)
+	}
+	sizes := make([]int, 0)
+	for {
+		batch, err := it.NextBatch()
+		if err != nil {
+			t.Fatalf("next batch failed: %v", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		sizes = append(sizes, len(batch))
+	}
+	if fmt.Sprint(sizes) != "[10 10 5]" {
+		t.Fatalf("unexpected batch sizes: %v", sizes)
+	}
+	t.Logf("✓ streamed %d rows in batches %v", count, sizes)
+}
+
+func TestExecuteQueryConstructorStreamContextAndPostProcessing(t *testing.T) {
+	repo := newStreamSQLiteRepo(t, 6)
+	defer repo.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	qc := NewSQLQueryConstructor(newDistinctOrdersSchema(), NewSQLiteDialect())
+	it, err := repo.ExecuteQueryConstructorStream(ctx, qc, QueryStreamOptions{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("open stream failed: %v", err)
+	}
+	if !it.Next() {
+		t.Fatalf("expected first row, err: %v", it.Err())
+	}
+	var row map[string]interface{}
+	if err := it.Scan(&row); err != nil || row["id"] != int64(1) {
+		t.Fatalf("unexpected map scan: %v (%v)", row, err)
+	}
+	cancel()
+	if it.Next() {
+		t.Fatalf("expected iteration to stop after cancellation")
+	}
+	if it.Err() != context.Canceled {
+		t.Fatalf("expected context.Canceled, got: %v", it.Err())
+	}
+	if err := it.Scan(&row); err == nil {
+		t.Fatalf("scan after close should fail")
+	}
+
+	// DISTINCT ON 改写的辅助列逐行移除。
+	distinct := NewSQLQueryConstructor(newDistinctOrdersSchema(), NewSQLiteDialect())
+	distinct.DistinctOn("customer_id").OrderBy("amount", "DESC")
+	it, err = repo.ExecuteQueryConstructorStream(context.Background(), distinct, QueryStreamOptions{})
+	if err != nil {
+		t.Fatalf("open distinct stream failed: %v", err)
+	}
+	seen := 0
+	for it.Next() {
+		if _, ok := it.Row()[distinctOnRowNumberColumn]; ok {
+			t.Fatalf("helper column must not be streamed: %v", it.Row())
+		}
+		seen++
+	}
+	if it.Err() != nil || seen != 3 {
+		t.Fatalf("expected 3 distinct rows, got %d (%v)", seen, it.Err())
+	}
+
+	windowed := NewSQLQueryConstructor(newDistinctOrdersSchema(), NewSQLiteDialectWithVersion("3.24.0"))
+	windowed.Select("id").Window(RowNumber().OrderBy("id", "ASC").As("rn"))
+	if _, err := repo.ExecuteQueryConstructorStream(context.Background(), windowed, QueryStreamOptions{}); err == nil || !strings.Contains(err.Error(), "window function fallback") {
+		t.Fatalf("expected window fallback stream error, got: %v", err)
+	}
+}
+
+func TestQueryRowIteratorMongoCursor(t *testing.T) {
+	docs := []interface{}{
+		bson.D{{Key: "_id", Value: 1}, {Key: "name", Value: "a"}},
+		bson.D{{Key: "_id", Value: 2}, {Key: "name", Value: "b"}},
+	}
+	cursor, err := mongo.NewCursorFromDocuments(docs, nil, nil)
+	if err != nil {
+		t.Fatalf("create cursor failed: %v", err)
+	}
+	it := &QueryRowIterator{ctx: context.Background(), source: &mongoRowSource{cursor: cursor}, batchSize: 1}
+
+	type doc struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+	names := make([]string, 0)
+	for it.Next() {
+		var item doc
+		if err := it.Scan(&item); err != nil {
+			t.Fatalf("scan failed: %v", err)
+		}
+		names = append(names, fmt.Sprintf("%d:%s", item.ID, item.Name))
+	}
+	if it.Err() != nil || strings.Join(names, ",") != "1:a,2:b" {
+		t.Fatalf("unexpected mongo stream: %v (%v)", names, it.Err())
+	}
+}
@@ -0,0 +1,533 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WindowFunc 窗口函数类型。
+type WindowFunc string
+
+const (
+	WindowRowNumber WindowFunc = "row_number"
+	WindowRank      WindowFunc = "rank"
+	WindowDenseRank WindowFunc = "dense_rank"
+	WindowLag       WindowFunc = "lag"
+	WindowLead      WindowFunc = "lead"
+	WindowSum       WindowFunc = "sum"
+	WindowAvg       WindowFunc = "avg"
+	WindowCount     WindowFunc = "count"
+	WindowMin       WindowFunc = "min"
+	WindowMax       WindowFunc = "max"
+)
+
+func (fn WindowFunc) isRanking() bool {
+	return fn == WindowRowNumber || fn == WindowRank || fn == WindowDenseRank
+}
+
+func (fn WindowFunc) isOffset() bool {
+	return fn == WindowLag || fn == WindowLead
+}
+
+// WindowBuilder 窗口函数投影构造器。
+// 用于表达 ROW_NUMBER/RANK/LAG/LEAD 及带 OVER 子句的聚合（如累计求和）。
+type WindowBuilder struct {
+	fn           WindowFunc
+	field        string
+	offset       int
+	defaultValue interface{}
+	hasDefault   bool
+	partitionBy  []string
+	orderBy      []OrderBy
+	alias        string
+}
+
+// NewWindowBuilder 创建 WindowBuilder。
+func NewWindowBuilder(fn WindowFunc, field string) *WindowBuilder {
+	return &WindowBuilder{
+		fn:    WindowFunc(strings.ToLower(strings.TrimSpace(string(fn)))),
+		field: strings.TrimSpace(field),
+	}
+}
+
+// RowNumber 创建 ROW_NUMBER() 窗口投影。
+func RowNumber() *WindowBuilder { return NewWindowBuilder(WindowRowNumber, "") }
+
+// Rank 创建 RANK() 窗口投影。
+func Rank() *WindowBuilder { return NewWindowBuilder(WindowRank, "") }
+
+// DenseRank 创建 DENSE_RANK() 窗口投影。
+func DenseRank() *WindowBuilder { return NewWindowBuilder(WindowDenseRank, "") }
+
+// Lag 创建 LAG(field, offset) 窗口投影；offset <= 0 时按 1 处理。
+func Lag(field string, offset int) *WindowBuilder {
+	b := NewWindowBuilder(WindowLag, field)
+	b.offset = normalizeWindowOffset(offset)
+	return b
+}
+
+// Lead 创建 LEAD(field, offset) 窗口投影；offset <= 0 时按 1 处理。
+func Lead(field string, offset int) *WindowBuilder {
+	b := NewWindowBuilder(WindowLead, field)
+	b.offset = normalizeWindowOffset(offset)
+	return b
+}
+
+// RunningSum 创建累计求和 SUM(field) OVER (...) 窗口投影，需配合 OrderBy 使用。
+func RunningSum(field string) *WindowBuilder { return NewWindowBuilder(WindowSum, field) }
+
+// WindowAggregate 以聚合函数创建窗口投影，例如 WindowAggregate(AggregateAvg, "amount")。
+func WindowAggregate(fn AggregateFunc, field string) *WindowBuilder {
+	return NewWindowBuilder(WindowFunc(fn), field)
+}
+
+func normalizeWindowOffset(offset int) int {
+	if offset <= 0 {
+		return 1
+	}
+	return offset
+}
+
+// PartitionBy 设置 PARTITION BY 字段。
+func (b *WindowBuilder) PartitionBy(fields ...string) *WindowBuilder {
+	if b == nil {
+		return b
+	}
+	for _, field := range fields {
+		if trimmed := strings.TrimSpace(field); trimmed != "" {
+			b.partitionBy = append(b.partitionBy, trimmed)
+		}
+	}
+	return b
+}
+
+// OrderBy 追加窗口内排序字段。
+func (b *WindowBuilder) OrderBy(field string, direction string) *WindowBuilder {
+	if b != nil {
+		b.orderBy = append(b.orderBy, OrderBy{Field: strings.TrimSpace(field), Direction: normalizeOrderDirection(direction)})
+	}
+	return b
+}
+
+// Default 设置 LAG/LEAD 越界时的默认值。
+func (b *WindowBuilder) Default(value interface{}) *WindowBuilder {
+	if b != nil {
+		b.defaultValue = value
+		b.hasDefault = true
+	}
+	return b
+}
+
+// As 为窗口投影设置别名。
+func (b *WindowBuilder) As(alias string) *WindowBuilder {
+	if b != nil {
+		b.alias = strings.TrimSpace(alias)
+	}
+	return b
+}
+
+// toIR 转换为 IR 窗口描述；未设置别名时按 "函数_字段" 生成默认别名。
+func (b *WindowBuilder) toIR() (QueryWindowIR, error) {
+	if b == nil {
+		return QueryWindowIR{}, fmt.Errorf("window builder is nil")
+	}
+	field := b.field
+	switch b.fn {
+	case WindowRowNumber, WindowRank, WindowDenseRank:
+		field = ""
+	case WindowLag, WindowLead, WindowSum, WindowAvg, WindowMin, WindowMax:
+		if field == "" || field == "*" {
+			return QueryWindowIR{}, fmt.Errorf("window function %s requires a field", strings.ToUpper(string(b.fn)))
+		}
+	case WindowCount:
+		if field == "" {
+			field = "*"
+		}
+	default:
+		return QueryWindowIR{}, fmt.Errorf("unsupported window function: %s", b.fn)
+	}
+	if (b.fn.isOffset() || b.fn == WindowRank || b.fn == WindowDenseRank) && len(b.orderBy) == 0 {
+		return QueryWindowIR{}, fmt.Errorf("window function %s requires OrderBy", strings.ToUpper(string(b.fn)))
+	}
+	if b.hasDefault && !b.fn.isOffset() {
+		return QueryWindowIR{}, fmt.Errorf("window function %s does not accept a default value", strings.ToUpper(string(b.fn)))
+	}
+
+	alias := b.alias
+	if alias == "" {
+		switch {
+		case field == "":
+			alias = string(b.fn)
+		case field == "*":
+			alias = string(b.fn) + "_all"
+		default:
+			alias = string(b.fn) + "_" + normalizeOrderFieldName(field)
+		}
+	}
+
+	window := QueryWindowIR{
+		Func:        b.fn,
+		Field:       field,
+		Offset:      b.offset,
+		Default:     b.defaultValue,
+		HasDefault:  b.hasDefault,
+		PartitionBy: append([]string(nil), b.partitionBy...),
+		Alias:       alias,
+	}
+	for _, order := range b.orderBy {
+		window.OrderBy = append(window.OrderBy, QueryOrderIR{Field: order.Field, Direction: order.Direction})
+	}
+	return window, nil
+}
+
+// renderWindowSQLIR 渲染窗口表达式（不含别名），例如 LAG("orders"."amount", 1) OVER (PARTITION BY ... ORDER BY ...)。
+// LAG / LEAD 默认值以占位符绑定，值追加到 args。
+func renderWindowSQLIR(dialect SQLDialect, ir *QueryIR, window QueryWindowIR, args *[]interface{}, argIndex *int) string {
+	var sql strings.Builder
+	sql.WriteString(strings.ToUpper(string(window.Func)))
+	sql.WriteString("(")
+	switch {
+	case window.Func.isOffset():
+		sql.WriteString(dialect.QuoteIdentifier(qualifyIdentifierIR(ir, window.Field)))
+		sql.WriteString(", ")
+		sql.WriteString(strconv.Itoa(normalizeWindowOffset(window.Offset)))
+		if window.HasDefault {
+			sql.WriteString(", ")
+			sql.WriteString(dialect.GetPlaceholder(*argIndex))
+			*args = append(*args, window.Default)
+			*argIndex++
+		}
+	case window.Field == "*":
+		sql.WriteString("*")
+	case window.Field != "":
+		sql.WriteString(dialect.QuoteIdentifier(qualifyIdentifierIR(ir, window.Field)))
+	}
+	sql.WriteString(") OVER (")
+
+	written := false
+	if len(window.PartitionBy) > 0 {
+		sql.WriteString("PARTITION BY ")
+		for i, field := range window.PartitionBy {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			sql.WriteString(dialect.QuoteIdentifier(qualifyIdentifierIR(ir, field)))
+		}
+		written = true
+	}
+	if len(window.OrderBy) > 0 {
+		if written {
+			sql.WriteString(" ")
+		}
+		sql.WriteString("ORDER BY ")
+		for i, order := range window.OrderBy {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			sql.WriteString(dialect.QuoteIdentifier(qualifyIdentifierIR(ir, order.Field)))
+			sql.WriteString(" ")
+			sql.WriteString(normalizeOrderDirection(order.Direction))
+		}
+	} else if window.Func.isRanking() && strings.EqualFold(strings.TrimSpace(dialect.Name()), "sqlserver") {
+		// SQL Server 要求 ROW_NUMBER 必须带 ORDER BY。
+		if written {
+			sql.WriteString(" ")
+		}
+		sql.WriteString("ORDER BY (SELECT NULL)")
+	}
+	sql.WriteString(")")
+	return sql.String()
+}
+
+// findWindowIR 按别名查找窗口投影。
+func findWindowIR(windows []QueryWindowIR, name string) (QueryWindowIR, bool) {
+	trimmed := strings.TrimSpace(name)
+	if trimmed == "" {
+		return QueryWindowIR{}, false
+	}
+	for _, window := range windows {
+		if window.Alias == trimmed {
+			return window, true
+		}
+	}
+	return QueryWindowIR{}, false
+}
+
+// ==================== 应用层窗口计算 ====================
+
+// computeWindowValuesInApp 在结果行上计算窗口函数值，用于数据库版本不支持 OVER 子句时的应用层降级。
+// 行内字段按结果列名（别名或最后一段字段名）读取，计算结果写入窗口别名列；不改变 rows 原有顺序。
+func computeWindowValuesInApp(rows []map[string]interface{}, windows []QueryWindowIR) error {
+	for _, window := range windows {
+		partitions := make(map[string][]int)
+		keys := make([]string, 0)
+		for i, row := range rows {
+			key := windowPartitionKey(row, window.PartitionBy)
+			if _, ok := partitions[key]; !ok {
+				keys = append(keys, key)
+			}
+			partitions[key] = append(partitions[key], i)
+		}
+		for _, key := range keys {
+			indexes := partitions[key]
+			sortRowIndexes(rows, indexes, window.OrderBy)
+			if err := applyWindowToPartition(rows, indexes, window); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func windowPartitionKey(row map[string]interface{}, fields []string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = fmt.Sprintf("%T:%v", row[resultColumnNameIR(field)], row[resultColumnNameIR(field)])
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// sortRowIndexes 按排序字段对行下标做稳定排序。
+func sortRowIndexes(rows []map[string]interface{}, indexes []int, orders []QueryOrderIR) {
+	if len(orders) == 0 {
+		return
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return compareRowsByOrder(rows[indexes[a]], rows[indexes[b]], orders) < 0
+	})
+}
+
+// sortRowsInApp 按排序字段对结果行做稳定排序。
+func sortRowsInApp(rows []map[string]interface{}, orders []QueryOrderIR) {
+	if len(orders) == 0 {
+		return
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		return compareRowsByOrder(rows[a], rows[b], orders) < 0
+	})
+}
+
+func compareRowsByOrder(left, right map[string]interface{}, orders []QueryOrderIR) int {
+	for _, order := range orders {
+		column := resultColumnNameIR(order.Field)
+		cmp := compareWindowValues(left[column], right[column])
+		if cmp == 0 {
+			continue
+		}
+		if normalizeOrderDirection(order.Direction) == "DESC" {
+			return -cmp
+		}
+		return cmp
+	}
+	return 0
+}
+
+// compareWindowValues 比较两个结果值；nil 视为最小值。
+func compareWindowValues(left, right interface{}) int {
+	if left == nil || right == nil {
+		switch {
+		case left == nil && right == nil:
+			return 0
+		case left == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+	if l, ok := asFloat64(left); ok {
+		if r, ok := asFloat64(right); ok {
+			switch {
+			case l < r:
+				return -1
+			case l > r:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+	switch l := left.(type) {
+	case time.Time:
+		if r, ok := right.(time.Time); ok {
+			return l.Compare(r)
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			return strings.Compare(l, r)
+		}
+	case []byte:
+		if r, ok := right.([]byte); ok {
+			return bytes.Compare(l, r)
+		}
+	case bool:
+		if r, ok := right.(bool); ok {
+			switch {
+			case l == r:
+				return 0
+			case !l:
+				return -1
+			default:
+				return 1
+			}
+		}
+	}
+	return strings.Compare(fmt.Sprint(left), fmt.Sprint(right))
+}
+
+func applyWindowToPartition(rows []map[string]interface{}, indexes []int, window QueryWindowIR) error {
+	column := resultColumnNameIR(window.Field)
+	switch window.Func {
+	case WindowRowNumber:
+		for pos, idx := range indexes {
+			rows[idx][window.Alias] = int64(pos + 1)
+		}
+	case WindowRank, WindowDenseRank:
+		var rank, dense int64
+		for pos, idx := range indexes {
+			if pos == 0 || compareRowsByOrder(rows[indexes[pos-1]], rows[idx], window.OrderBy) != 0 {
+				rank = int64(pos + 1)
+				dense++
+			}
+			if window.Func == WindowRank {
+				rows[idx][window.Alias] = rank
+			} else {
+				rows[idx][window.Alias] = dense
+			}
+		}
+	case WindowLag, WindowLead:
+		offset := normalizeWindowOffset(window.Offset)
+		if window.Func == WindowLag {
+			offset = -offset
+		}
+		values := make([]interface{}, len(indexes))
+		for pos, idx := range indexes {
+			values[pos] = rows[idx][column]
+		}
+		for pos, idx := range indexes {
+			target := pos + offset
+			switch {
+			case target >= 0 && target < len(values):
+				rows[idx][window.Alias] = values[target]
+			case window.HasDefault:
+				rows[idx][window.Alias] = window.Default
+			default:
+				rows[idx][window.Alias] = nil
+			}
+		}
+	case WindowSum, WindowAvg, WindowCount, WindowMin, WindowMax:
+		// 有排序时按默认帧（RANGE UNBOUNDED PRECEDING，含同序值行）累计；无排序时覆盖整个分区。
+		end := 0
+		for end < len(indexes) {
+			next := len(indexes)
+			if len(window.OrderBy) > 0 {
+				next = end + 1
+				for next < len(indexes) && compareRowsByOrder(rows[indexes[end]], rows[indexes[next]], window.OrderBy) == 0 {
+					next++
+				}
+			}
+			value, err := aggregateWindowValues(rows, indexes[:next], window.Func, column)
+			if err != nil {
+				return err
+			}
+			for _, idx := range indexes[end:next] {
+				rows[idx][window.Alias] = value
+			}
+			end = next
+		}
+	default:
+		return fmt.Errorf("unsupported window function: %s", window.Func)
+	}
+	return nil
+}
+
+func aggregateWindowValues(rows []map[string]interface{}, indexes []int, fn WindowFunc, column string) (interface{}, error) {
+	var (
+		count    int64
+		intSum   int64
+		floatSum float64
+		allInts  = true
+		best     interface{}
+	)
+	for _, idx := range indexes {
+		if column == "*" {
+			count++
+			continue
+		}
+		value := rows[idx][column]
+		if value == nil {
+			continue
+		}
+		count++
+		switch fn {
+		case WindowSum, WindowAvg:
+			number, ok := asFloat64(value)
+			if !ok {
+				return nil, fmt.Errorf("window function %s requires numeric values, got %T for %q", strings.ToUpper(string(fn)), value, column)
+			}
+			floatSum += number
+			if n, ok := windowInt64Value(value); ok && allInts {
+				intSum += n
+			} else {
+				allInts = false
+			}
+		case WindowMin:
+			if best == nil || compareWindowValues(value, best) < 0 {
+				best = value
+			}
+		case WindowMax:
+			if best == nil || compareWindowValues(value, best) > 0 {
+				best = value
+			}
+		}
+	}
+
+	switch fn {
+	case WindowCount:
+		return count, nil
+	case WindowSum:
+		if count == 0 {
+			return nil, nil
+		}
+		if allInts {
+			return intSum, nil
+		}
+		return floatSum, nil
+	case WindowAvg:
+		if count == 0 {
+			return nil, nil
+		}
+		return floatSum / float64(count), nil
+	default:
+		return best, nil
+	}
+}
+
+func windowInt64Value(value interface{}) (int64, bool) {
+	switch n := value.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
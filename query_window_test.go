@@ -0,0 +1,278 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSQLQueryConstructorWindowPostgres(t *testing.T) {
+	qc := NewSQLQueryConstructor(newGroupByOrdersSchema(), NewPostgreSQLDialect())
+	qc.Select("id", "amount").
+		Window(
+			RowNumber().PartitionBy("customer_id").OrderBy("amount", "DESC").As("rn"),
+			Lag("amount", 1).PartitionBy("customer_id").OrderBy("id", "ASC").Default(0),
+			RunningSum("amount").OrderBy("id", "ASC").As("running_total"),
+		).
+		Where(Eq("status", "paid")).
+		OrderBy("rn", "ASC")
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expected := `SELECT "orders"."id", "orders"."amount", ROW_NUMBER() OVER (PARTITION BY "orders"."customer_id" ORDER BY "orders"."amount" DESC) AS "rn", LAG("orders"."amount", 1, $1) OVER (PARTITION BY "orders"."customer_id" ORDER BY "orders"."id" ASC) AS "lag_amount", SUM("orders"."amount") OVER (ORDER BY "orders"."id" ASC) AS "running_total" FROM "orders" WHERE "orders"."status" = $2 ORDER BY "rn" ASC`
+	if sql != expected {
+		t.Fatalf("unexpected sql:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 2 || args[0] != 0 || args[1] != "paid" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+	t.Logf("✓ PostgreSQL window functions: %s", sql)
+}
+
+func TestSQLQueryConstructorWindowDefaultIsBound(t *testing.T) {
+	// 默认值以参数绑定，反斜杠转义无法闭合字符串字面量
+	payload := `x\' OR 1=1 -- `
+	for _, tc := range []struct {
+		dialect     SQLDialect
+		placeholder string
+		where       string
+	}{
+		{NewMySQLDialectWithVersion("8.0.34"), "LAG(`orders`.`amount`, 1, ?)", "`orders`.`status` = ?"},
+		{NewPostgreSQLDialect(), `LAG("orders"."amount", 1, $1)`, `"orders"."status" = $2`},
+		{NewSQLServerDialect(), "LAG([orders].[amount], 1, @p1)", "[orders].[status] = @p2"},
+	} {
+		qc := NewSQLQueryConstructor(newGroupByOrdersSchema(), tc.dialect)
+		qc.Select("id").
+			Window(Lag("amount", 1).OrderBy("id", "ASC").Default(payload)).
+			Where(Eq("status", "paid"))
+		sql, args, err := qc.Build(context.Background())
+		if err != nil {
+			t.Fatalf("%s build failed: %v", tc.dialect.Name(), err)
+		}
+		if strings.Contains(sql, "OR 1=1") || !strings.Contains(sql, tc.placeholder) || !strings.Contains(sql, tc.where) {
+			t.Fatalf("%s: expected bound default, got %s", tc.dialect.Name(), sql)
+		}
+		if len(args) != 2 || args[0] != payload || args[1] != "paid" {
+			t.Fatalf("%s: unexpected args: %v", tc.dialect.Name(), args)
+		}
+	}
+}
+
+func TestSQLQueryConstructorWindowVersionFallback(t *testing.T) {
+	build := func(dialect SQLDialect) string {
+		qc := NewSQLQueryConstructor(newGroupByOrdersSchema(), dialect)
+		qc.Select("id").
+			Window(RowNumber().PartitionBy("customer_id").OrderBy("amount", "DESC").As("rn")).
+			OrderBy("id", "ASC").
+			Limit(5)
+		sql, _, err := qc.Build(context.Background())
+		if err != nil {
+			t.Fatalf("build failed for %s: %v", dialect.Name(), err)
+		}
+		return sql
+	}
+
+	native := build(NewMySQLDialectWithVersion("8.0.34"))
+	if !strings.Contains(native, "ROW_NUMBER() OVER (PARTITION BY `orders`.`customer_id` ORDER BY `orders`.`amount` DESC) AS `rn`") || !strings.HasSuffix(native, "LIMIT 5") {
+		t.Fatalf("unexpected mysql 8 sql: %s", native)
+	}
+
+	emulated := build(NewMySQLDialectWithVersion("5.7.44"))
+	expected := "SELECT `orders`.`id`, `orders`.`customer_id`, `orders`.`amount` FROM `orders` ORDER BY `orders`.`id` ASC"
+	if emulated != expected {
+		t.Fatalf("unexpected mysql 5.7 sql:\n got: %s\nwant: %s", emulated, expected)
+	}
+
+	sqlserver := NewSQLQueryConstructor(newGroupByOrdersSchema(), NewSQLServerDialect())
+	sqlserver.Window(RowNumber().PartitionBy("status"))
+	sql, _, err := sqlserver.Build(context.Background())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if !strings.Contains(sql, "SELECT [orders].*, ROW_NUMBER() OVER (PARTITION BY [orders].[status] ORDER BY (SELECT NULL)) AS [row_number]") {
+		t.Fatalf("unexpected sql server sql: %s", sql)
+	}
+}
+
+func TestSQLQueryConstructorWindowValidation(t *testing.T) {
+	cases := []struct {
+		name    string
+		builder *WindowBuilder
+		want    string
+	}{
+		{"lag without order", Lag("amount", 1), "requires OrderBy"},
+		{"sum without field", RunningSum(""), "requires a field"},
+		{"unknown field", RowNumber().OrderBy("missing_field", "ASC"), "missing_field"},
+		{"default on rank", Rank().OrderBy("amount", "DESC").Default(0), "default value"},
+	}
+	for _, tc := range cases {
+		qc := NewSQLQueryConstructor(newGroupByOrdersSchema(), NewPostgreSQLDialect())
+		qc.Window(tc.builder)
+		if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: expected error containing %q, got: %v", tc.name, tc.want, err)
+		}
+	}
+
+	qc := NewSQLQueryConstructor(newGroupByOrdersSchema(), NewSQLiteDialectWithVersion("3.24.0"))
+	qc.GroupBy("status").Aggregate(Sum("amount")).Window(RowNumber())
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "fallback") {
+		t.Fatalf("expected grouped window fallback error, got: %v", err)
+	}
+}
+
+func TestSQLQueryConstructorWindowSQLiteExecution(t *testing.T) {
+	cfg := &Config{Adapter: "sqlite", SQLite: &SQLiteConnectionConfig{Path: filepath.Join(t.TempDir(), "window.db")}}
+	repo, err := NewRepository(cfg)
+	if err != nil {
+		t.Fatalf("create repository failed: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, status TEXT, customer_id INTEGER, amount INTEGER)",
+		"INSERT INTO orders (id, status, customer_id, amount) VALUES (1, 'paid', 1, 50), (2, 'paid', 1, 30), (3, 'paid', 2, 70), (4, 'open', 1, 20), (5, 'paid', 2, 10)",
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+
+	newQuery := func(dialect SQLDialect) QueryConstructor {
+		qc := NewSQLQueryConstructor(newGroupByOrdersSchema(), dialect)
+		return qc.Select("id", "amount").
+			Where(Eq("status", "paid")).
+			Window(
+				RowNumber().PartitionBy("customer_id").OrderBy("id", "ASC").As("rn"),
+				Lag("amount", 1).PartitionBy("customer_id").OrderBy("id", "ASC").As("prev_amount"),
+				RunningSum("amount").OrderBy("id", "ASC").As("running_total"),
+			).
+			OrderBy("id", "ASC").
+			Limit(3)
+	}
+
+	expected := []struct {
+		id, rn, total int64
+		prev          interface{}
+	}{
+		{1, 1, 50, nil},
+		{2, 2, 80, int64(50)},
+		{3, 1, 150, nil},
+	}
+	for _, dialect := range []SQLDialect{NewSQLiteDialect(), NewSQLiteDialectWithVersion("3.24.0")} {
+		qc := newQuery(dialect)
+		result, err := repo.ExecuteQueryConstructor(ctx, qc)
+		if err != nil {
+			t.Fatalf("execute failed (version %q): %v", resolveSQLDialectVersionIR(dialect), err)
+		}
+		if len(result.Rows) != len(expected) {
+			t.Fatalf("expected %d rows, got %v", len(expected), result.Rows)
+		}
+		for i, want := range expected {
+			row := result.Rows[i]
+			if row["id"] != want.id || row["rn"] != want.rn || row["running_total"] != want.total || row["prev_amount"] != want.prev {
+				t.Fatalf("version %q row %d: unexpected values %v", resolveSQLDialectVersionIR(dialect), i, row)
+			}
+			if _, ok := row["customer_id"]; ok {
+				t.Fatalf("helper column leaked into result: %v", row)
+			}
+		}
+	}
+
+	ranked := NewSQLQueryConstructor(newGroupByOrdersSchema(), NewSQLiteDialectWithVersion("3.24.0"))
+	ranked.Select("id").
+		Window(DenseRank().OrderBy("customer_id", "ASC").As("customer_rank")).
+		OrderBy("customer_rank", "DESC").
+		OrderBy("id", "ASC").
+		Offset(1).
+		Limit(2)
+	result, err := repo.ExecuteQueryConstructor(ctx, ranked)
+	if err != nil {
+		t.Fatalf("execute ranked failed: %v", err)
+	}
+	if len(result.Rows) != 2 || result.Rows[0]["id"] != int64(5) || result.Rows[0]["customer_rank"] != int64(2) || result.Rows[1]["customer_rank"] != int64(1) {
+		t.Fatalf("unexpected ranked rows: %v", result.Rows)
+	}
+
+	count, err := newQuery(NewSQLiteDialectWithVersion("3.24.0")).SelectCount(ctx, repo)
+	if err != nil {
+		t.Fatalf("select count failed: %v", err)
+	}
+	if count != 4 {
+		t.Fatalf("expected count 4, got %d", count)
+	}
+}
+
+func TestNonSQLQueryConstructorsRejectWindow(t *testing.T) {
+	neo := NewNeo4jQueryConstructor(newGroupByOrdersSchema())
+	neo.Window(RowNumber())
+	if _, _, err := neo.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "window") {
+		t.Fatalf("expected neo4j window rejection, got: %v", err)
+	}
+
+	mongo := NewMongoQueryConstructor(newGroupByOrdersSchema())
+	mongo.Window(RowNumber())
+	if _, err := mongo.BuildFindPlan(); err == nil || !strings.Contains(err.Error(), "window") {
+		t.Fatalf("expected mongodb window rejection, got: %v", err)
+	}
+}
+
+func TestRepositoryQueryConstructorUsesDetectedServerVersion(t *testing.T) {
+	cfg := &Config{Adapter: "sqlite", SQLite: &SQLiteConnectionConfig{Path: filepath.Join(t.TempDir(), "window_version.db")}}
+	repo, err := NewRepository(cfg)
+	if err != nil {
+		t.Fatalf("create repository failed: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	var detected string
+	if err := repo.QueryRow(ctx, "SELECT sqlite_version()").Scan(&detected); err != nil {
+		t.Fatalf("read sqlite version failed: %v", err)
+	}
+	qc, err := repo.NewQueryConstructor(newGroupByOrdersSchema())
+	if err != nil {
+		t.Fatalf("new query constructor failed: %v", err)
+	}
+	if got := resolveSQLDialectVersionIR(qc.GetNativeBuilder().(*SQLQueryConstructor).dialect); got != detected {
+		t.Fatalf("expected dialect version %q from Connect, got %q", detected, got)
+	}
+
+	for _, stmt := range []string{
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, status TEXT, customer_id INTEGER, amount INTEGER)",
+		"INSERT INTO orders (id, status, customer_id, amount) VALUES (1, 'paid', 1, 50), (2, 'paid', 1, 30), (3, 'paid', 2, 70)",
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+
+	// 模拟 3.25.0 以下的实例：经 Repository 创建的构造器应走应用层降级
+	repo.GetAdapter().(*SQLiteAdapter).serverVersion = "3.24.0"
+	legacy, err := repo.NewQueryConstructor(newGroupByOrdersSchema())
+	if err != nil {
+		t.Fatalf("new query constructor failed: %v", err)
+	}
+	legacy.Select("id").Window(RowNumber().PartitionBy("customer_id").OrderBy("id", "ASC").As("rn")).OrderBy("id", "ASC")
+	sql, _, err := legacy.Build(ctx)
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if strings.Contains(sql, "OVER") {
+		t.Fatalf("expected application-layer window fallback, got: %s", sql)
+	}
+	result, err := repo.ExecuteQueryConstructor(ctx, legacy)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if len(result.Rows) != 3 || result.Rows[1]["rn"] != int64(2) || result.Rows[2]["rn"] != int64(1) {
+		t.Fatalf("unexpected rows: %v", result.Rows)
+	}
+	if mode := resolveReturningMode(legacy.GetNativeBuilder().(*SQLQueryConstructor).dialect); mode != returningReadBack {
+		t.Fatalf("expected read-back returning mode for legacy sqlite, got %v", mode)
+	}
+	t.Logf("✓ repository query constructors carry detected server version")
+}
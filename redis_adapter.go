@@ -664,6 +664,18 @@ func (tx *redisTx) Rollback(ctx context.Context) error {
 	return nil
 }
 
+func (tx *redisTx) Savepoint(ctx context.Context, name string) error {
+	return fmt.Errorf("redis transaction does not support savepoints")
+}
+
+func (tx *redisTx) RollbackTo(ctx context.Context, name string) error {
+	return fmt.Errorf("redis transaction does not support savepoints")
+}
+
+func (tx *redisTx) ReleaseSavepoint(ctx context.Context, name string) error {
+	return fmt.Errorf("redis transaction does not support savepoints")
+}
+
 func (tx *redisTx) Query(ctx context.Context, sql string, args ...interface{}) (*sql.Rows, error) {
 	return nil, fmt.Errorf("redis transaction Query is not supported; use native read plans outside Tx")
 }
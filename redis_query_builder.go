@@ -130,6 +130,20 @@ func (qb *RedisQueryConstructor) WhereAny(conditions ...Condition) QueryConstruc
 func (qb *RedisQueryConstructor) Select(fields ...string) QueryConstructor                      { return qb }
 func (qb *RedisQueryConstructor) Count(fieldName ...string) QueryConstructor                    { return qb }
 func (qb *RedisQueryConstructor) CountWith(builder *CountBuilder) QueryConstructor              { return qb }
+func (qb *RedisQueryConstructor) Distinct() QueryConstructor                                    { return qb }
+func (qb *RedisQueryConstructor) DistinctOn(fields ...string) QueryConstructor                  { return qb }
+func (qb *RedisQueryConstructor) GroupBy(fields ...string) QueryConstructor                     { return qb }
+func (qb *RedisQueryConstructor) Having(condition Condition) QueryConstructor                   { return qb }
+func (qb *RedisQueryConstructor) Aggregate(builders ...*AggregateBuilder) QueryConstructor      { return qb }
+func (qb *RedisQueryConstructor) Window(builders ...*WindowBuilder) QueryConstructor            { return qb }
+func (qb *RedisQueryConstructor) With(ctes ...*CTEBuilder) QueryConstructor                    { return qb }
+func (qb *RedisQueryConstructor) Lock(lock *RowLock) QueryConstructor                          { return qb }
+func (qb *RedisQueryConstructor) WithDeleted() QueryConstructor                                 { return qb }
+func (qb *RedisQueryConstructor) OnlyDeleted() QueryConstructor                                 { return qb }
+func (qb *RedisQueryConstructor) Union(other QueryConstructor) QueryConstructor                  { return qb }
+func (qb *RedisQueryConstructor) UnionAll(other QueryConstructor) QueryConstructor               { return qb }
+func (qb *RedisQueryConstructor) Intersect(other QueryConstructor) QueryConstructor              { return qb }
+func (qb *RedisQueryConstructor) Except(other QueryConstructor) QueryConstructor                 { return qb }
 func (qb *RedisQueryConstructor) OrderBy(field string, direction string) QueryConstructor       { return qb }
 func (qb *RedisQueryConstructor) Limit(count int) QueryConstructor                              { return qb }
 func (qb *RedisQueryConstructor) Offset(count int) QueryConstructor                             { return qb }
@@ -145,6 +159,7 @@ func (qb *RedisQueryConstructor) RightJoin(table, onClause string, alias ...stri
 }
 func (qb *RedisQueryConstructor) CrossJoin(table string, alias ...string) QueryConstructor { return qb }
 func (qb *RedisQueryConstructor) JoinWith(builder *JoinBuilder) QueryConstructor            { return qb }
+func (qb *RedisQueryConstructor) Preload(paths ...string) QueryConstructor                     { return qb }
 func (qb *RedisQueryConstructor) CrossTableStrategy(strategy CrossTableStrategy) QueryConstructor {
 	return qb
 }
@@ -77,6 +77,10 @@ func InferSchema(v interface{}) (*BaseSchema, error) {
 			fb.field.Autoinc = true
 		}
 
+		if options.version {
+			fb.Version()
+		}
+
 		if options.hasDefault {
 			fb.field.Default = options.defaultValue
 		}
@@ -95,13 +99,14 @@ type tagOptions struct {
 	unique        bool
 	index         bool
 	autoIncrement bool
+	version       bool
 	hasDefault    bool
 	defaultValue  interface{}
 	typeOverride  *FieldType
 }
 
 // parseDBTag 解析 eit_db/db tag
-// 格式: "column_name,primary_key,not_null,unique,index,auto_increment"
+// 格式: "column_name,primary_key,not_null,unique,index,auto_increment,version"
 func parseDBTag(tag, fieldName string) (string, tagOptions) {
 	if tag == "" {
 		return toSnakeCase(fieldName), tagOptions{}
@@ -146,6 +151,8 @@ func parseDBTag(tag, fieldName string) (string, tagOptions) {
 			opts.index = true
 		case "auto_increment", "autoincrement":
 			opts.autoIncrement = true
+		case "version", "lock_version":
+			opts.version = true
 		}
 	}
 
@@ -201,6 +208,7 @@ func mergeTagOptions(base, override tagOptions) tagOptions {
 	merged.unique = merged.unique || override.unique
 	merged.index = merged.index || override.index
 	merged.autoIncrement = merged.autoIncrement || override.autoIncrement
+	merged.version = merged.version || override.version
 	if override.hasDefault {
 		merged.hasDefault = true
 		merged.defaultValue = override.defaultValue
@@ -0,0 +1,490 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExecuteInto 执行 QueryConstructor，并将结果行映射为 T（struct 或 *struct）。
+// 列与字段的对应关系与 InferSchema 一致（eit_db > db > gorm tag，缺省为字段名的 snake_case）。
+func ExecuteInto[T any](ctx context.Context, repo *Repository, constructor QueryConstructor) ([]T, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repository is nil")
+	}
+	result, err := repo.ExecuteQueryConstructor(ctx, constructor)
+	if err != nil {
+		return nil, err
+	}
+	return MapRows[T](result.Rows)
+}
+
+// ExecuteIntoCached 同 ExecuteQueryConstructorCached，命中结果缓存时同样完成类型映射：
+// 缓存经 JSON 序列化后的数字、时间字符串与 ObjectID 按目标字段类型还原。
+func ExecuteIntoCached[T any](ctx context.Context, repo *Repository, cacheKey string, constructor QueryConstructor, opts CacheOptions) ([]T, *CachedQueryResult, error) {
+	if repo == nil {
+		return nil, nil, fmt.Errorf("repository is nil")
+	}
+	cached, err := repo.ExecuteQueryConstructorCached(ctx, cacheKey, constructor, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := MapRows[T](cached.Result.Rows)
+	if err != nil {
+		return nil, cached, err
+	}
+	return items, cached, nil
+}
+
+// FindAll 以 T 推导的 Schema（InferSchema）创建查询构造器，按条件查询并映射为 T。
+func FindAll[T any](ctx context.Context, repo *Repository, conditions ...Condition) ([]T, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repository is nil")
+	}
+	var zero T
+	schema, err := InferSchema(zero)
+	if err != nil {
+		return nil, err
+	}
+	constructor, err := repo.NewQueryConstructor(schema)
+	if err != nil {
+		return nil, err
+	}
+	for _, condition := range conditions {
+		constructor.Where(condition)
+	}
+	return ExecuteInto[T](ctx, repo, constructor)
+}
+
+// MapRows 将 []map[string]interface{} 结果行映射为 T（struct 或 *struct）。
+// 支持 SQL / MongoDB / Neo4j 执行结果及结果缓存反序列化后的行：
+//   - 列名大小写不敏感；Neo4j 的 "n.field" 列按最后一段匹配，单列返回的节点展开为属性
+//   - MongoDB 的 _id 在未声明 _id 列时映射到 id 字段；ObjectID 可映射到 primitive.ObjectID 或 string
+//   - Neo4j 时间类型（Date / LocalDateTime / LocalTime / Time）与 Mongo DateTime 映射到 time.Time
+//   - []byte 与 string 互转；JSON 文本或嵌套文档映射到 struct / map / slice 字段
+func MapRows[T any](rows []map[string]interface{}) ([]T, error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	isPtr := typ.Kind() == reflect.Ptr
+	structType := typ
+	if isPtr {
+		structType = typ.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("MapRows: T must be struct or pointer to struct, got %s", typ)
+	}
+	fields := mappedStructFields(structType)
+
+	items := make([]T, 0, len(rows))
+	for i, row := range rows {
+		elem := reflect.New(structType).Elem()
+		if err := mapRowIntoStruct(elem, fields, row); err != nil {
+			return nil, fmt.Errorf("MapRows: row %d %w", i, err)
+		}
+		if isPtr {
+			items = append(items, elem.Addr().Interface().(T))
+		} else {
+			items = append(items, elem.Interface().(T))
+		}
+	}
+	return items, nil
+}
+
+type mappedStructField struct {
+	index  int
+	column string
+}
+
+// mappedStructFields 按 InferSchema 的 tag 解析规则收集可映射字段（列名统一小写）。
+func mappedStructFields(typ reflect.Type) []mappedStructField {
+	fields := make([]mappedStructField, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		column, _, ignored := resolveFieldSchemaTag(field)
+		if ignored {
+			continue
+		}
+		fields = append(fields, mappedStructField{index: i, column: strings.ToLower(column)})
+	}
+	return fields
+}
+
+// mapRowIntoStruct 将单行结果写入结构体值 elem。
+func mapRowIntoStruct(elem reflect.Value, fields []mappedStructField, row map[string]interface{}) error {
+	values := normalizeMappedRow(row)
+	for _, field := range fields {
+		raw, ok := values[field.column]
+		if !ok && field.column == "id" {
+			raw, ok = values["_id"]
+		}
+		if !ok {
+			continue
+		}
+		if err := assignMappedValue(elem.Field(field.index), raw); err != nil {
+			return fmt.Errorf("field %s (column %q): %w", elem.Type().Field(field.index).Name, field.column, err)
+		}
+	}
+	return nil
+}
+
+// normalizeMappedRow 统一列名：小写、去掉 Neo4j 变量前缀；单列节点 / 关系展开为属性。
+func normalizeMappedRow(row map[string]interface{}) map[string]interface{} {
+	if len(row) == 1 {
+		for _, value := range row {
+			switch entity := value.(type) {
+			case dbtype.Node:
+				return normalizeMappedRow(entity.Props)
+			case dbtype.Relationship:
+				return normalizeMappedRow(entity.Props)
+			}
+		}
+	}
+	values := make(map[string]interface{}, len(row))
+	for key, value := range row {
+		values[strings.ToLower(resultColumnNameIR(key))] = value
+	}
+	return values
+}
+
+var (
+	mappedTimeType     = reflect.TypeOf(time.Time{})
+	mappedBytesType    = reflect.TypeOf([]byte(nil))
+	mappedObjectIDType = reflect.TypeOf(primitive.ObjectID{})
+)
+
+// mappedTimeLayouts 字符串时间的解析格式（驱动文本值与缓存 JSON 中的 RFC3339）。
+var mappedTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+// normalizeMappedValue 将驱动特有类型转换为通用 Go 类型。
+func normalizeMappedValue(raw interface{}) interface{} {
+	switch v := raw.(type) {
+	case dbtype.Date:
+		return v.Time()
+	case dbtype.LocalDateTime:
+		return v.Time()
+	case dbtype.LocalTime:
+		return v.Time()
+	case dbtype.Time:
+		return v.Time()
+	case dbtype.Node:
+		return v.Props
+	case primitive.DateTime:
+		return v.Time()
+	case primitive.Binary:
+		return v.Data
+	case primitive.D:
+		return normalizeMappedValue(v.Map())
+	case primitive.M:
+		return normalizeMappedValue(map[string]interface{}(v))
+	case primitive.A:
+		return normalizeMappedValue([]interface{}(v))
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = normalizeMappedValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalizeMappedValue(item)
+		}
+		return out
+	}
+	return raw
+}
+
+// assignMappedValue 将单个结果值写入字段，按目标类型做驱动相关的转换。
+func assignMappedValue(dst reflect.Value, raw interface{}) error {
+	raw = normalizeMappedValue(raw)
+	if raw == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		elem := reflect.New(dst.Type().Elem())
+		if err := assignMappedValue(elem.Elem(), raw); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
+	if scanner, ok := dst.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(mappedDriverValue(raw))
+	}
+
+	src := reflect.ValueOf(raw)
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+
+	switch dst.Type() {
+	case mappedTimeType:
+		t, err := toMappedTime(raw)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	case mappedObjectIDType:
+		hex, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("cannot convert %T to %s", raw, dst.Type())
+		}
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(id))
+		return nil
+	case mappedBytesType:
+		if s, ok := raw.(string); ok {
+			dst.SetBytes([]byte(s))
+			return nil
+		}
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		switch v := raw.(type) {
+		case []byte:
+			dst.SetString(string(v))
+		case primitive.ObjectID:
+			dst.SetString(v.Hex())
+		case time.Time:
+			dst.SetString(v.Format(time.RFC3339Nano))
+		case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			dst.SetString(fmt.Sprint(v))
+		default:
+			if src.Kind() != reflect.String {
+				return fmt.Errorf("cannot convert %T to %s", raw, dst.Type())
+			}
+			dst.SetString(src.String())
+		}
+		return nil
+	case reflect.Bool:
+		b, err := toMappedBool(raw)
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toMappedInt64(raw)
+		if err != nil {
+			return err
+		}
+		if dst.OverflowInt(n) {
+			return fmt.Errorf("value %d overflows %s", n, dst.Type())
+		}
+		dst.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := toMappedInt64(raw)
+		if err != nil {
+			return err
+		}
+		if n < 0 || dst.OverflowUint(uint64(n)) {
+			return fmt.Errorf("value %d overflows %s", n, dst.Type())
+		}
+		dst.SetUint(uint64(n))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := toMappedFloat64(raw)
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(f)
+		return nil
+	case reflect.Struct, reflect.Map, reflect.Slice:
+		if handled, err := assignMappedRows(dst, raw); handled {
+			return err
+		}
+		// JSON 列（文本）或嵌套文档：经 JSON 解码到目标类型。
+		var payload []byte
+		switch v := raw.(type) {
+		case string:
+			payload = []byte(v)
+		case []byte:
+			payload = v
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			payload = encoded
+		}
+		return json.Unmarshal(payload, dst.Addr().Interface())
+	}
+	if src.Type().ConvertibleTo(dst.Type()) {
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	}
+	return fmt.Errorf("cannot convert %T to %s", raw, dst.Type())
+}
+
+// assignMappedRows 将嵌套行（Preload 结果、嵌套文档）映射到 struct 或 struct 切片字段：
+// 先按 JSON 解码（兼容 json tag），再按列名规则逐字段覆盖；解码失败返回错误。非行数据返回 handled=false。
+func assignMappedRows(dst reflect.Value, raw interface{}) (bool, error) {
+	switch dst.Kind() {
+	case reflect.Struct:
+		row, ok := raw.(map[string]interface{})
+		if !ok {
+			return false, nil
+		}
+		encoded, err := json.Marshal(row)
+		if err != nil {
+			return true, fmt.Errorf("encode nested row for %s: %w", dst.Type(), err)
+		}
+		if err := json.Unmarshal(encoded, dst.Addr().Interface()); err != nil {
+			return true, fmt.Errorf("decode nested row into %s: %w", dst.Type(), err)
+		}
+		return true, mapRowIntoStruct(dst, mappedStructFields(dst.Type()), row)
+	case reflect.Slice:
+		elemType := dst.Type().Elem()
+		if elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() != reflect.Struct || elemType == mappedTimeType {
+			return false, nil
+		}
+		var items []interface{}
+		switch v := raw.(type) {
+		case []map[string]interface{}:
+			for _, item := range v {
+				items = append(items, item)
+			}
+		case []interface{}:
+			for _, item := range v {
+				if _, ok := item.(map[string]interface{}); !ok {
+					return false, nil
+				}
+			}
+			items = v
+		default:
+			return false, nil
+		}
+		out := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := assignMappedValue(out.Index(i), item); err != nil {
+				return true, fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		dst.Set(out)
+		return true, nil
+	}
+	return false, nil
+}
+
+// mappedDriverValue 将值转换为 sql.Scanner 可接受的 driver.Value 形态。
+func mappedDriverValue(raw interface{}) interface{} {
+	switch v := raw.(type) {
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case primitive.ObjectID:
+		return v.Hex()
+	}
+	return raw
+}
+
+func toMappedTime(raw interface{}) (time.Time, error) {
+	switch v := raw.(type) {
+	case time.Time:
+		return v, nil
+	case []byte:
+		return toMappedTime(string(v))
+	case string:
+		for _, layout := range mappedTimeLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("cannot parse %q as time", v)
+	case int64:
+		return time.Unix(v, 0).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("cannot convert %T to time.Time", raw)
+}
+
+func toMappedInt64(raw interface{}) (int64, error) {
+	switch v := raw.(type) {
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case uint64:
+		if v > 1<<63-1 {
+			return 0, fmt.Errorf("value %d overflows int64", v)
+		}
+		return int64(v), nil
+	case float32, float64:
+		f, _ := asFloat64(v)
+		if f != float64(int64(f)) {
+			return 0, fmt.Errorf("cannot convert non-integral %v to integer", v)
+		}
+		return int64(f), nil
+	case string:
+		return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	case []byte:
+		return strconv.ParseInt(strings.TrimSpace(string(v)), 10, 64)
+	}
+	if _, ok := asFloat64(raw); ok {
+		return reflect.ValueOf(raw).Convert(reflect.TypeOf(int64(0))).Int(), nil
+	}
+	return 0, fmt.Errorf("cannot convert %T to integer", raw)
+}
+
+func toMappedFloat64(raw interface{}) (float64, error) {
+	if f, ok := asFloat64(raw); ok {
+		return f, nil
+	}
+	switch v := raw.(type) {
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	case []byte:
+		return strconv.ParseFloat(strings.TrimSpace(string(v)), 64)
+	case primitive.Decimal128:
+		return strconv.ParseFloat(v.String(), 64)
+	}
+	return 0, fmt.Errorf("cannot convert %T to float", raw)
+}
+
+func toMappedBool(raw interface{}) (bool, error) {
+	switch v := raw.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(strings.TrimSpace(v))
+	case []byte:
+		return strconv.ParseBool(strings.TrimSpace(string(v)))
+	}
+	if f, ok := asFloat64(raw); ok {
+		return f != 0, nil
+	}
+	return false, fmt.Errorf("cannot convert %T to bool", raw)
+}
@@ -0,0 +1,198 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mappedArticle struct {
+	ID        int64      `db:"id,primary_key"`
+	Title     string     `gorm:"column:title"`
+	Views     *int       `db:"views"`
+	Payload   []byte     `db:"payload"`
+	Tags      []string   `db:"tags"`
+	CreatedAt time.Time  `db:"created_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
+	Internal  string     `db:"-"`
+}
+
+func newMappingSQLiteRepo(t *testing.T) *Repository {
+	cfg := &Config{Adapter: "sqlite", SQLite: &SQLiteConnectionConfig{Path: filepath.Join(t.TempDir(), "mapping.db")}}
+	repo, err := NewRepository(cfg)
+	if err != nil {
+		t.Fatalf("create repository failed: %v", err)
+	}
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE mapped_article (id INTEGER PRIMARY KEY, title TEXT, views INTEGER, payload BLOB, tags TEXT, created_at DATETIME, deleted_at DATETIME)",
+		`INSERT INTO mapped_article (id, title, views, payload, tags, created_at, deleted_at) VALUES
+			(1, 'hello', 12, X'6869', '["go","db"]', '2026-03-01 10:20:30', NULL),
+			(2, 'world', NULL, NULL, '[]', '2026-03-02 08:00:00', '2026-03-05 00:00:00')`,
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			repo.Close()
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+	return repo
+}
+
+func TestExecuteIntoSQLite(t *testing.T) {
+	repo := newMappingSQLiteRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+
+	schema, err := InferSchema(mappedArticle{})
+	if err != nil {
+		t.Fatalf("infer schema failed: %v", err)
+	}
+	qc, err := repo.NewQueryConstructor(schema)
+	if err != nil {
+		t.Fatalf("new query constructor failed: %v", err)
+	}
+	qc.WithDeleted().OrderBy("id", "ASC")
+
+	items, err := ExecuteInto[mappedArticle](ctx, repo, qc)
+	if err != nil {
+		t.Fatalf("execute into failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	first := items[0]
+	if first.ID != 1 || first.Title != "hello" || first.Views == nil || *first.Views != 12 {
+		t.Fatalf("unexpected first item: %+v", first)
+	}
+	if string(first.Payload) != "hi" || len(first.Tags) != 2 || first.Tags[1] != "db" {
+		t.Fatalf("unexpected payload/tags: %q %v", first.Payload, first.Tags)
+	}
+	if !first.CreatedAt.Equal(time.Date(2026, 3, 1, 10, 20, 30, 0, time.UTC)) || first.DeletedAt != nil {
+		t.Fatalf("unexpected times: %v %v", first.CreatedAt, first.DeletedAt)
+	}
+	if items[1].Views != nil || items[1].DeletedAt == nil || items[1].DeletedAt.Day() != 5 {
+		t.Fatalf("unexpected second item: %+v", items[1])
+	}
+
+	pointers, err := FindAll[*mappedArticle](ctx, repo, Eq("title", "hello"))
+	if err != nil {
+		t.Fatalf("find all failed: %v", err)
+	}
+	if len(pointers) != 1 || pointers[0].ID != 1 {
+		t.Fatalf("unexpected find all result: %+v", pointers)
+	}
+	// 软删除的记录默认被排除。
+	if deleted, err := FindAll[*mappedArticle](ctx, repo, Eq("title", "world")); err != nil || len(deleted) != 0 {
+		t.Fatalf("expected soft-deleted row to be excluded, got %+v (%v)", deleted, err)
+	}
+	t.Logf("✓ ExecuteInto mapped %d rows", len(items))
+}
+
+func TestExecuteIntoCachedRoundTrip(t *testing.T) {
+	l1 := NewRistrettoCacheBackend()
+	defer l1.Close()
+	ctx := context.Background()
+
+	// 缓存中的行经 JSON 序列化：数字为 float64，时间为 RFC3339 字符串。
+	prebuilt := &QueryConstructorExecutionResult{
+		Statement: "SELECT * FROM mapped_article",
+		Rows: []map[string]interface{}{
+			{"id": float64(7), "title": "cached", "views": float64(3), "created_at": "2026-03-01T10:20:30Z", "tags": []interface{}{"a"}},
+		},
+	}
+	data, _ := marshalRows(prebuilt)
+	_ = l1.Set(ctx, "mapping:key", data, 0)
+
+	repo := newTestMemoryRepo()
+	repo.SetResultCacheBackend(l1)
+
+	items, cached, err := ExecuteIntoCached[mappedArticle](ctx, repo, "mapping:key", &stubQueryConstructor{}, CacheOptions{})
+	if err != nil {
+		t.Fatalf("execute into cached failed: %v", err)
+	}
+	if !cached.FromCache {
+		t.Fatalf("expected cache hit")
+	}
+	if len(items) != 1 || items[0].ID != 7 || *items[0].Views != 3 || items[0].CreatedAt.Hour() != 10 || items[0].Tags[0] != "a" {
+		t.Fatalf("unexpected cached items: %+v", items)
+	}
+
+	if _, err := MapRows[mappedArticle]([]map[string]interface{}{{"id": float64(1.5)}}); err == nil || !strings.Contains(err.Error(), `column "id"`) {
+		t.Fatalf("expected non-integral conversion error, got: %v", err)
+	}
+}
+
+func TestMapRowsMongoValues(t *testing.T) {
+	type profile struct {
+		City string `json:"city"`
+	}
+	type mongoUser struct {
+		ID       primitive.ObjectID `db:"id"`
+		RawID    string             `db:"_id"`
+		Name     string             `db:"name"`
+		Age      int                `db:"age"`
+		JoinedAt time.Time          `db:"joined_at"`
+		Profile  profile            `db:"profile"`
+		Roles    []string           `db:"roles"`
+	}
+	oid := primitive.NewObjectID()
+	joined := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []map[string]interface{}{{
+		"_id":       oid,
+		"name":      "alice",
+		"age":       int32(30),
+		"joined_at": primitive.NewDateTimeFromTime(joined),
+		"profile":   primitive.D{{Key: "city", Value: "Paris"}},
+		"roles":     primitive.A{"admin", "dev"},
+	}}
+
+	items, err := MapRows[mongoUser](rows)
+	if err != nil {
+		t.Fatalf("map rows failed: %v", err)
+	}
+	got := items[0]
+	if got.ID != oid || got.RawID != oid.Hex() || got.Name != "alice" || got.Age != 30 {
+		t.Fatalf("unexpected mongo item: %+v", got)
+	}
+	if !got.JoinedAt.Equal(joined) || got.Profile.City != "Paris" || len(got.Roles) != 2 {
+		t.Fatalf("unexpected mongo nested values: %+v", got)
+	}
+
+	// 结果缓存中的 ObjectID 为十六进制字符串。
+	items, err = MapRows[mongoUser]([]map[string]interface{}{{"_id": oid.Hex()}})
+	if err != nil || items[0].ID != oid {
+		t.Fatalf("unexpected cached object id mapping: %+v (%v)", items, err)
+	}
+}
+
+func TestMapRowsNeo4jValues(t *testing.T) {
+	type person struct {
+		Name     string    `db:"name"`
+		Born     time.Time `db:"born"`
+		Verified bool      `db:"verified"`
+	}
+	born := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	rows := []map[string]interface{}{
+		{"n.name": "neo", "n.born": dbtype.Date(born), "n.verified": true},
+		{"n": dbtype.Node{Props: map[string]any{"name": "trinity", "born": dbtype.LocalDateTime(born), "verified": int64(0)}}},
+	}
+	items, err := MapRows[person](rows)
+	if err != nil {
+		t.Fatalf("map rows failed: %v", err)
+	}
+	if items[0].Name != "neo" || !items[0].Born.Equal(born) || !items[0].Verified {
+		t.Fatalf("unexpected first person: %+v", items[0])
+	}
+	if items[1].Name != "trinity" || items[1].Born.Year() != 1990 || items[1].Verified {
+		t.Fatalf("unexpected node person: %+v", items[1])
+	}
+
+	if _, err := MapRows[string](rows); err == nil || !strings.Contains(err.Error(), "must be struct") {
+		t.Fatalf("expected non-struct error, got: %v", err)
+	}
+}
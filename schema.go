@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"reflect"
 	"strings"
 	"time"
@@ -35,6 +36,8 @@ type Field struct {
 	Autoinc      bool
 	Index        bool
 	Unique       bool
+	Version      bool // 乐观锁版本字段
+	Timezone     bool // 带时区的时间字段（PostgreSQL 映射为 TIMESTAMPTZ）
 	Validators   []Validator
 	Transformers []Transformer
 }
@@ -46,6 +49,8 @@ const (
 	ConstraintPrimaryKey ConstraintKind = "primary_key"
 	ConstraintUnique     ConstraintKind = "unique"
 	ConstraintForeignKey ConstraintKind = "foreign_key"
+	// ConstraintCheck 检查约束，仅用于 Changeset.CheckConstraint 的错误映射。
+	ConstraintCheck ConstraintKind = "check"
 )
 
 // TableConstraint 表级约束定义（用于复合主键、复合唯一约束、复合外键等）
@@ -66,6 +71,13 @@ type TableConstraint struct {
 	ViewHint *ViewHint
 }
 
+// TableIndex 索引定义（AlterTable 索引操作与数据库结构反查使用）。
+type TableIndex struct {
+	Name   string
+	Fields []string
+	Unique bool
+}
+
 // ViewHint 外键约束上的视图提示，声明该关联应创建（或复用）的跨表视图。
 // 适用于高热点跨表查询场景，由 Migration 阶段自动生成视图 DDL，
 // 运行时由 QueryBuilder 自动路由到视图而非直接 JOIN。
@@ -304,6 +316,7 @@ type BaseSchema struct {
 	fields      map[string]*Field
 	fieldList   []*Field
 	constraints []TableConstraint
+	indexes     []TableIndex
 	relations   []SchemaRelation // 关系注册表
 }
 
@@ -314,6 +327,7 @@ func NewBaseSchema(tableName string) *BaseSchema {
 		fields:      make(map[string]*Field),
 		fieldList:   make([]*Field, 0),
 		constraints: make([]TableConstraint, 0),
+		indexes:     make([]TableIndex, 0),
 		relations:   make([]SchemaRelation, 0),
 	}
 }
@@ -335,10 +349,13 @@ func (s *BaseSchema) AddField(field *Field) *BaseSchema {
 // - 字段类型为 TypeTime
 // - 非空（NOT NULL）
 // - 默认值为 CURRENT_TIMESTAMP
+// - 带时区（PostgreSQL 为 TIMESTAMPTZ），与 ChangesetExecutor 默认写入的 UTC 时间对应
 // - 若字段已存在则跳过，避免重复添加
+// - ChangesetExecutor 插入时填充两者，更新 / upsert 时刷新 updated_at（时钟见 Repository.SetTimestampOptions）
 func (s *BaseSchema) AddTimestamps() *BaseSchema {
 	if s.GetField("created_at") == nil {
 		s.AddField(NewField("created_at", TypeTime).
+			WithTimezone().
 			Null(false).
 			Default("CURRENT_TIMESTAMP").
 			Build())
@@ -346,6 +363,7 @@ func (s *BaseSchema) AddTimestamps() *BaseSchema {
 
 	if s.GetField("updated_at") == nil {
 		s.AddField(NewField("updated_at", TypeTime).
+			WithTimezone().
 			Null(false).
 			Default("CURRENT_TIMESTAMP").
 			Build())
@@ -367,6 +385,25 @@ func (s *BaseSchema) AddSoftDelete() *BaseSchema {
 	return s
 }
 
+// AddOptimisticLock 添加乐观锁版本字段（name 为空时使用 version）。
+// 默认行为：
+// - 字段类型为 TypeInteger
+// - 非空（NOT NULL），默认值为 1
+// - 若字段已存在则仅标记为版本字段
+func (s *BaseSchema) AddOptimisticLock(name string) *BaseSchema {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "version"
+	}
+	if field := s.GetField(name); field != nil {
+		field.Version = true
+		return s
+	}
+
+	s.AddField(NewField(name, TypeInteger).Null(false).Default(1).Version().Build())
+	return s
+}
+
 // Fields 返回所有字段
 func (s *BaseSchema) Fields() []*Field {
 	return s.fieldList
@@ -392,6 +429,21 @@ func (s *BaseSchema) Constraints() []TableConstraint {
 	return append([]TableConstraint(nil), s.constraints...)
 }
 
+// AddIndex 添加非约束索引（用于记录结构反查得到的二级索引）。
+func (s *BaseSchema) AddIndex(name string, unique bool, fields ...string) *BaseSchema {
+	normalized := normalizeConstraintFields(fields)
+	if len(normalized) == 0 {
+		return s
+	}
+	s.indexes = append(s.indexes, TableIndex{Name: name, Fields: normalized, Unique: unique})
+	return s
+}
+
+// Indexes 返回索引定义列表（副本）
+func (s *BaseSchema) Indexes() []TableIndex {
+	return append([]TableIndex(nil), s.indexes...)
+}
+
 // ─── 关系注册表方法 ────────────────────────────────────────────────────────────
 
 // HasMany 声明本 Schema 是"一"侧，目标 Schema 持有外键（一对多）。
@@ -569,6 +621,18 @@ func (fb *FieldBuilder) Unique() *FieldBuilder {
 	return fb
 }
 
+// Version 标记为乐观锁版本字段（整数，更新时自动校验并递增）
+func (fb *FieldBuilder) Version() *FieldBuilder {
+	fb.field.Version = true
+	return fb
+}
+
+// WithTimezone 标记为带时区的时间字段
+func (fb *FieldBuilder) WithTimezone() *FieldBuilder {
+	fb.field.Timezone = true
+	return fb
+}
+
 // Validate 添加验证器
 func (fb *FieldBuilder) Validate(validator Validator) *FieldBuilder {
 	fb.field.Validators = append(fb.field.Validators, validator)
@@ -642,14 +706,70 @@ func (v *PatternValidator) Validate(value interface{}) error {
 	return nil
 }
 
-// UniqueValidator 唯一性验证器
+// UniqueValidator 唯一性验证器。
+// 通过 Changeset.ValidateWithContext 执行：上下文需经 WithValidationRepository 携带 Repository（未携带时跳过），
+// 以 SelectCount 查询同值记录，更新时排除当前主键；已软删除的记录同样计入（与数据库唯一索引一致）。
 type UniqueValidator struct {
-	Schema Schema
-	Field  string
+	Schema Schema // 为空时使用 Changeset 的 Schema
+	Field  string // 为空时使用被校验字段
 }
 
 func (v *UniqueValidator) Validate(value interface{}) error {
-	// 需要从数据库查询
+	// 需要从数据库查询，见 ValidateWithContext
+	return nil
+}
+
+func (v *UniqueValidator) ValidateWithContext(ctx context.Context, vc *ValidationContext, value interface{}) error {
+	if vc == nil || vc.Repository == nil || value == nil {
+		return nil
+	}
+	schema, field := v.Schema, v.Field
+	if schema == nil {
+		schema = vc.Schema
+	}
+	if field == "" {
+		field = vc.Field
+	}
+	condition := Eq(field, value)
+	if vc.PrimaryKey != nil && vc.Schema != nil && schema.TableName() == vc.Schema.TableName() {
+		condition = And(condition, Ne(primaryKeyNameOrDefaultIR(schema), vc.PrimaryKey))
+	}
+	count, err := countMatching(ctx, vc.Repository, schema, condition, true)
+	if err != nil {
+		return fmt.Errorf("unique lookup on %s.%s failed: %w", schema.TableName(), field, err)
+	}
+	if count > 0 {
+		return NewValidationError("unique", validationProfileForLocale(vc.Locale).Messages.UniqueTaken)
+	}
+	return nil
+}
+
+// ExistsValidator 引用存在性验证器：要求 Schema 中存在 Field 等于该值的记录（如外键指向的父记录）。
+// 与 UniqueValidator 相同，通过 Changeset.ValidateWithContext 执行；已软删除的记录视为不存在。
+type ExistsValidator struct {
+	Schema Schema
+	Field  string // 为空时使用 Schema 主键
+}
+
+func (v *ExistsValidator) Validate(value interface{}) error {
+	return nil
+}
+
+func (v *ExistsValidator) ValidateWithContext(ctx context.Context, vc *ValidationContext, value interface{}) error {
+	if vc == nil || vc.Repository == nil || value == nil || v.Schema == nil {
+		return nil
+	}
+	field := v.Field
+	if field == "" {
+		field = primaryKeyNameOrDefaultIR(v.Schema)
+	}
+	count, err := countMatching(ctx, vc.Repository, v.Schema, Eq(field, value), false)
+	if err != nil {
+		return fmt.Errorf("exists lookup on %s.%s failed: %w", v.Schema.TableName(), field, err)
+	}
+	if count == 0 {
+		return NewValidationError("exists", validationProfileForLocale(vc.Locale).Messages.ReferenceNotFound)
+	}
 	return nil
 }
 
@@ -842,6 +962,41 @@ type QueryConstructor interface {
 	Count(fieldName ...string) QueryConstructor
 	CountWith(builder *CountBuilder) QueryConstructor
 
+	// 去重：Distinct 对整行去重；DistinctOn 按指定字段去重，每组保留排序后的第一行。
+	// PostgreSQL 使用原生 DISTINCT ON，其他 SQL 方言改写为 ROW_NUMBER() 子查询，MongoDB 使用 $group + $first。
+	Distinct() QueryConstructor
+	DistinctOn(fields ...string) QueryConstructor
+
+	// 分组与聚合（SUM/AVG/MIN/MAX/COUNT DISTINCT）
+	// 后端不支持 GROUP BY / HAVING 时在 Build 阶段返回错误。
+	GroupBy(fields ...string) QueryConstructor
+	Having(condition Condition) QueryConstructor
+	Aggregate(builders ...*AggregateBuilder) QueryConstructor
+
+	// 窗口函数投影（ROW_NUMBER/RANK/LAG/LEAD/累计聚合）
+	// 数据库版本不支持时按 QueryFeatures 的 window_func 降级策略在应用层计算，无降级策略则 Build 阶段返回错误。
+	Window(builders ...*WindowBuilder) QueryConstructor
+
+	// 公用表表达式：普通 CTE 与递归 CTE（树形 / 层级查询）；主查询以 CTE 名称作为表名引用结果。
+	// Neo4j 将递归 CTE 映射为可变长度路径匹配。
+	With(ctes ...*CTEBuilder) QueryConstructor
+
+	// 行级锁（FOR UPDATE [SKIP LOCKED | NOWAIT] / WITH (UPDLOCK, ROWLOCK, READPAST)）。
+	// 仅能在事务绑定的 Repository（WithChangeset 内的 ChangesetExecutor.NewQueryConstructor）创建的构造器上使用；SQLite 等不支持行锁的后端 Build 阶段返回错误。
+	Lock(lock *RowLock) QueryConstructor
+
+	// 软删除作用域：Schema 含 deleted_at 字段时默认排除已软删除记录（SQL 包括 JoinWith 连接目标）。
+	// WithDeleted 包含已软删除记录，OnlyDeleted 仅返回已软删除记录。
+	WithDeleted() QueryConstructor
+	OnlyDeleted() QueryConstructor
+
+	// 集合运算：当前构造器为第一个分支，ORDER BY / LIMIT / OFFSET 作用于整个复合查询。
+	// 后端缺少 INTERSECT / EXCEPT 时按 QueryFeatures 声明的降级策略改写，无降级策略则 Build 阶段返回错误。
+	Union(other QueryConstructor) QueryConstructor
+	UnionAll(other QueryConstructor) QueryConstructor
+	Intersect(other QueryConstructor) QueryConstructor
+	Except(other QueryConstructor) QueryConstructor
+
 	// 排序
 	OrderBy(field string, direction string) QueryConstructor // direction: "ASC" | "DESC"
 
@@ -864,6 +1019,10 @@ type QueryConstructor interface {
 	//             Filter 条件转换为对连接节点的属性过滤。
 	JoinWith(builder *JoinBuilder) QueryConstructor
 
+	// 关联预加载：Preload("items", "items.product") 按 SchemaRelation 逐层加载关联，结果嵌套到父行的关系名字段。
+	// SQL 后端在主查询后每层关系执行一次 IN (...) 批量查询；MongoDB 编译为 $lookup，Neo4j 编译为 OPTIONAL MATCH + collect()。
+	Preload(paths ...string) QueryConstructor
+
 	// 跨表查询策略（方言级默认 + 显式覆盖）
 	CrossTableStrategy(strategy CrossTableStrategy) QueryConstructor
 	CustomMode() QueryConstructor
@@ -992,7 +1151,7 @@ type ConditionTranslator interface {
 // SimpleCondition 简单条件（字段 操作符 值）
 type SimpleCondition struct {
 	Field    string
-	Operator string // "eq", "ne", "gt", "lt", "gte", "lte", "in", "like", "between", "full_text"
+	Operator string // "eq", "ne", "gt", "lt", "gte", "lte", "in", "like", "between", "full_text" 及扩展操作符（见 query_operators.go）
 	Value    interface{}
 }
 
@@ -1035,6 +1194,34 @@ func (c *NotCondition) Translate(translator ConditionTranslator) (string, []inte
 	return "NOT (" + innerSQL + ")", args, nil
 }
 
+// SubqueryCondition 子查询条件（字段 操作符 子查询）。
+// Operator: "in", "not_in", "exists", "not_exists", 以及标量比较 "eq", "ne", "gt", "lt", "gte", "lte"。
+// EXISTS 类条件不使用 Field。
+type SubqueryCondition struct {
+	Field    string
+	Operator string
+	Query    QueryConstructor
+}
+
+func (c *SubqueryCondition) Type() string {
+	return "subquery"
+}
+
+func (c *SubqueryCondition) Translate(translator ConditionTranslator) (string, []interface{}, error) {
+	return translator.TranslateCondition(c)
+}
+
+// FieldRef 字段引用值。
+// 作为条件值使用时表示与另一字段比较（例如关联子查询中引用外层查询字段），而不是绑定参数。
+type FieldRef struct {
+	Name string
+}
+
+// RefField 创建字段引用值，例如 Eq("orders.user_id", RefField("users.id"))。
+func RefField(name string) FieldRef {
+	return FieldRef{Name: strings.TrimSpace(name)}
+}
+
 // ==================== Condition Builder (Fluent API) ====================
 
 // WhereBuilder 条件构造器（独立于 QueryConstructor）。
@@ -1350,6 +1537,103 @@ func (b *CountBuilder) Distinct() *CountBuilder {
 	return b
 }
 
+// AggregateFunc 聚合函数类型。
+type AggregateFunc string
+
+const (
+	AggregateCount AggregateFunc = "count"
+	AggregateSum   AggregateFunc = "sum"
+	AggregateAvg   AggregateFunc = "avg"
+	AggregateMin   AggregateFunc = "min"
+	AggregateMax   AggregateFunc = "max"
+)
+
+// AggregateBuilder 聚合投影构造器。
+// 用于表达 SUM/AVG/MIN/MAX/COUNT(field) 及 DISTINCT、别名，通常配合 GroupBy/Having 使用。
+type AggregateBuilder struct {
+	fn       AggregateFunc
+	field    string
+	distinct bool
+	alias    string
+}
+
+// NewAggregateBuilder 创建 AggregateBuilder。
+// field 为空或 "*" 时仅对 COUNT 有意义（COUNT(*)）。
+func NewAggregateBuilder(fn AggregateFunc, field string) *AggregateBuilder {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		field = "*"
+	}
+	return &AggregateBuilder{fn: AggregateFunc(strings.ToLower(strings.TrimSpace(string(fn)))), field: field}
+}
+
+// Sum 创建 SUM(field) 聚合投影。
+func Sum(field string) *AggregateBuilder { return NewAggregateBuilder(AggregateSum, field) }
+
+// Avg 创建 AVG(field) 聚合投影。
+func Avg(field string) *AggregateBuilder { return NewAggregateBuilder(AggregateAvg, field) }
+
+// Min 创建 MIN(field) 聚合投影。
+func Min(field string) *AggregateBuilder { return NewAggregateBuilder(AggregateMin, field) }
+
+// Max 创建 MAX(field) 聚合投影。
+func Max(field string) *AggregateBuilder { return NewAggregateBuilder(AggregateMax, field) }
+
+// CountOf 创建 COUNT(field) 聚合投影；field 为空时表示 COUNT(*)。
+func CountOf(field string) *AggregateBuilder { return NewAggregateBuilder(AggregateCount, field) }
+
+// CountDistinct 创建 COUNT(DISTINCT field) 聚合投影。
+func CountDistinct(field string) *AggregateBuilder {
+	return NewAggregateBuilder(AggregateCount, field).Distinct()
+}
+
+// As 为聚合投影设置别名。
+func (b *AggregateBuilder) As(alias string) *AggregateBuilder {
+	if b != nil {
+		b.alias = strings.TrimSpace(alias)
+	}
+	return b
+}
+
+// Distinct 切换为 FN(DISTINCT field)。
+func (b *AggregateBuilder) Distinct() *AggregateBuilder {
+	if b != nil {
+		b.distinct = true
+	}
+	return b
+}
+
+// toIR 转换为 IR 聚合描述；未设置别名时按 "函数_字段" 生成默认别名。
+func (b *AggregateBuilder) toIR() (QueryAggregateIR, error) {
+	if b == nil {
+		return QueryAggregateIR{}, fmt.Errorf("aggregate builder is nil")
+	}
+	switch b.fn {
+	case AggregateCount, AggregateSum, AggregateAvg, AggregateMin, AggregateMax:
+	default:
+		return QueryAggregateIR{}, fmt.Errorf("unsupported aggregate function: %s", b.fn)
+	}
+	if b.field == "*" && b.fn != AggregateCount {
+		return QueryAggregateIR{}, fmt.Errorf("aggregate function %s requires a field", strings.ToUpper(string(b.fn)))
+	}
+	if b.field == "*" && b.distinct {
+		return QueryAggregateIR{}, fmt.Errorf("COUNT(DISTINCT *) is not supported; specify a field")
+	}
+
+	alias := b.alias
+	if alias == "" {
+		base := normalizeOrderFieldName(b.field)
+		if b.field == "*" {
+			base = "all"
+		}
+		alias = string(b.fn) + "_" + base
+		if b.distinct {
+			alias = string(b.fn) + "_distinct_" + base
+		}
+	}
+	return QueryAggregateIR{Func: b.fn, Field: b.field, Distinct: b.distinct, Alias: alias}, nil
+}
+
 // Eq 等于条件
 func Eq(field string, value interface{}) Condition {
 	return &SimpleCondition{
@@ -1458,6 +1742,137 @@ func FullText(field string, query string) Condition {
 	}
 }
 
+// IsNull 字段为 NULL
+func IsNull(field string) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "is_null",
+	}
+}
+
+// IsNotNull 字段不为 NULL
+func IsNotNull(field string) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "is_not_null",
+	}
+}
+
+// NotIn NOT IN 条件
+func NotIn(field string, values ...interface{}) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "not_in",
+		Value:    values,
+	}
+}
+
+// ILike 大小写不敏感的 LIKE 条件
+func ILike(field string, pattern string) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "ilike",
+		Value:    pattern,
+	}
+}
+
+// StartsWith 前缀匹配（prefix 中的通配符按字面量处理）
+func StartsWith(field string, prefix string) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "starts_with",
+		Value:    prefix,
+	}
+}
+
+// EndsWith 后缀匹配（suffix 中的通配符按字面量处理）
+func EndsWith(field string, suffix string) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "ends_with",
+		Value:    suffix,
+	}
+}
+
+// Regex 正则匹配（PostgreSQL ~ / MySQL REGEXP / MongoDB $regex / Cypher =~）
+func Regex(field string, pattern string) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "regex",
+		Value:    pattern,
+	}
+}
+
+// JSONPathComparison JSON 路径比较的条件值。
+type JSONPathComparison struct {
+	Path     []string    // 路径段，纯数字段视为数组下标
+	Operator string      // 内层比较操作符（eq/ne/gt/lt/gte/lte/in/like/between/is_null/is_not_null）
+	Value    interface{} // 比较值
+}
+
+// JSONPath JSON 字段路径比较，path 形如 "address.city"、"$.tags.0"。
+// 例如 JSONPath("profile", "address.city", "eq", "Paris")。
+func JSONPath(field string, path string, operator string, value interface{}) Condition {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(path), "$"), ".")
+	segments := make([]string, 0)
+	if trimmed != "" {
+		segments = strings.Split(trimmed, ".")
+	}
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "json_path",
+		Value: JSONPathComparison{
+			Path:     segments,
+			Operator: strings.ToLower(strings.TrimSpace(operator)),
+			Value:    value,
+		},
+	}
+}
+
+// ArrayContains 数组字段包含全部给定元素
+func ArrayContains(field string, values ...interface{}) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "array_contains",
+		Value:    values,
+	}
+}
+
+// ArrayOverlap 数组字段至少包含一个给定元素
+func ArrayOverlap(field string, values ...interface{}) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "array_overlap",
+		Value:    values,
+	}
+}
+
+// InSubquery 字段值在子查询结果中（IN (SELECT ...)）。
+// 子查询需恰好投影一列。
+func InSubquery(field string, query QueryConstructor) Condition {
+	return &SubqueryCondition{Field: field, Operator: "in", Query: query}
+}
+
+// NotInSubquery 字段值不在子查询结果中（NOT IN (SELECT ...)）。
+func NotInSubquery(field string, query QueryConstructor) Condition {
+	return &SubqueryCondition{Field: field, Operator: "not_in", Query: query}
+}
+
+// Exists 子查询存在匹配记录（EXISTS (SELECT ...)）。
+func Exists(query QueryConstructor) Condition {
+	return &SubqueryCondition{Operator: "exists", Query: query}
+}
+
+// NotExists 子查询不存在匹配记录（NOT EXISTS (SELECT ...)）。
+func NotExists(query QueryConstructor) Condition {
+	return &SubqueryCondition{Operator: "not_exists", Query: query}
+}
+
+// CompareSubquery 字段与标量子查询比较，operator 取 eq/ne/gt/lt/gte/lte。
+func CompareSubquery(field string, operator string, query QueryConstructor) Condition {
+	return &SubqueryCondition{Field: field, Operator: strings.ToLower(strings.TrimSpace(operator)), Query: query}
+}
+
 // And AND 条件
 func And(conditions ...Condition) Condition {
 	return &CompositeCondition{
@@ -0,0 +1,238 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SchemaIntrospector 由支持结构反查的 Adapter 实现：从线上数据库读取表结构并生成 BaseSchema，
+// 便于迁移、Changeset 等既有工具直接接管遗留数据库。
+type SchemaIntrospector interface {
+	// IntrospectTables 返回当前数据库（schema）中的全部用户表 / 集合名。
+	IntrospectTables(ctx context.Context) ([]string, error)
+	// IntrospectSchema 读取单表的列、类型、可空、默认值、主键、唯一约束、外键与索引。
+	IntrospectSchema(ctx context.Context, table string) (*BaseSchema, error)
+}
+
+// IntrospectSchemas 从线上数据库反查表结构；tables 为空时读取全部用户表。
+func (r *Repository) IntrospectSchemas(ctx context.Context, tables ...string) ([]*BaseSchema, error) {
+	adapter := r.GetAdapter()
+	if adapter == nil {
+		return nil, fmt.Errorf("adapter is not initialized")
+	}
+	introspector, ok := adapter.(SchemaIntrospector)
+	if !ok {
+		return nil, fmt.Errorf("adapter %T does not support schema introspection", adapter)
+	}
+
+	if len(tables) == 0 {
+		var err error
+		if tables, err = introspector.IntrospectTables(ctx); err != nil {
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+	}
+	schemas := make([]*BaseSchema, 0, len(tables))
+	for _, table := range tables {
+		schema, err := introspector.IntrospectSchema(ctx, table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to introspect table %s: %w", table, err)
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, nil
+}
+
+// introspectedTable 适配器读取到的原始表结构，由 toSchema 统一组装为 BaseSchema。
+type introspectedTable struct {
+	name        string
+	columns     []*Field
+	primaryKey  TableConstraint
+	constraints []TableConstraint // 唯一约束与外键
+	indexes     []TableIndex      // 不属于约束的二级索引
+}
+
+// toSchema 单列主键写入 Field.Primary，复合主键保留为表级约束；单列普通索引同时标记 Field.Index。
+func (t *introspectedTable) toSchema() (*BaseSchema, error) {
+	if len(t.columns) == 0 {
+		return nil, fmt.Errorf("table %s does not exist", t.name)
+	}
+	schema := NewBaseSchema(t.name)
+	for _, column := range t.columns {
+		if len(t.primaryKey.Fields) == 1 && column.Name == t.primaryKey.Fields[0] {
+			column.Primary = true
+		}
+		if !column.Primary {
+			column.Autoinc = false
+		}
+		schema.AddField(column)
+	}
+	if len(t.primaryKey.Fields) > 1 {
+		t.primaryKey.Kind = ConstraintPrimaryKey
+		schema.constraints = append(schema.constraints, t.primaryKey)
+	}
+	schema.constraints = append(schema.constraints, t.constraints...)
+	for _, index := range t.indexes {
+		if field := schema.GetField(index.Fields[0]); field != nil && len(index.Fields) == 1 && !index.Unique {
+			field.Index = true
+		}
+		schema.AddIndex(index.Name, index.Unique, index.Fields...)
+	}
+	return schema, nil
+}
+
+// addConstraintColumn 按行追加约束列：结果集按约束名与列序排列，同名约束的后续行合并到同一约束。
+func (t *introspectedTable) addConstraintColumn(c TableConstraint, field, refField string) {
+	if n := len(t.constraints); n > 0 && c.Name != "" && t.constraints[n-1].Name == c.Name && t.constraints[n-1].Kind == c.Kind {
+		last := &t.constraints[n-1]
+		last.Fields = append(last.Fields, field)
+		if refField != "" {
+			last.RefFields = append(last.RefFields, refField)
+		}
+		return
+	}
+	c.Fields = []string{field}
+	if refField != "" {
+		c.RefFields = []string{refField}
+	}
+	t.constraints = append(t.constraints, c)
+}
+
+// addIndexColumn 按行追加索引列，规则同 addConstraintColumn。
+func (t *introspectedTable) addIndexColumn(name string, unique bool, field string) {
+	if n := len(t.indexes); n > 0 && t.indexes[n-1].Name == name {
+		t.indexes[n-1].Fields = append(t.indexes[n-1].Fields, field)
+		return
+	}
+	t.indexes = append(t.indexes, TableIndex{Name: name, Fields: []string{field}, Unique: unique})
+}
+
+// sortIntrospectedPrimaryKey 按主键序号排列主键列（SQLite PRAGMA 等按列顺序返回时使用）。
+func sortIntrospectedPrimaryKey(fields []string, positions map[string]int) []string {
+	sort.SliceStable(fields, func(i, j int) bool { return positions[fields[i]] < positions[fields[j]] })
+	return fields
+}
+
+// splitIntrospectionTableName 拆分 "schema.table"；未指定 schema 时返回空串，由查询回退到当前 schema。
+func splitIntrospectionTableName(table string) (string, string) {
+	table = strings.TrimSpace(table)
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		return table[:i], table[i+1:]
+	}
+	return "", table
+}
+
+// normalizeReferentialAction 统一外键动作写法（如 SQL Server 的 SET_NULL、PostgreSQL 的单字符编码）。
+func normalizeReferentialAction(action string) string {
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case "a":
+		return "NO ACTION"
+	case "r":
+		return "RESTRICT"
+	case "c":
+		return "CASCADE"
+	case "n":
+		return "SET NULL"
+	case "d":
+		return "SET DEFAULT"
+	}
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(action), "_", " "))
+}
+
+// fieldTypeFromColumnType 将数据库列类型映射为 FieldType（与 mapXXXType 方向相反，未知类型按字符串处理）。
+func fieldTypeFromColumnType(columnType string) FieldType {
+	t := strings.ToLower(strings.TrimSpace(columnType))
+	containsAny := func(parts ...string) bool {
+		for _, part := range parts {
+			if strings.Contains(t, part) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case t == "":
+		return TypeString
+	case strings.HasPrefix(t, "tinyint(1)"), strings.HasPrefix(t, "bool"), t == "bit", t == "bit(1)":
+		return TypeBoolean
+	case t == "array", strings.HasSuffix(t, "[]"), strings.HasPrefix(t, "_"):
+		return TypeArray
+	case strings.Contains(t, "json"):
+		return TypeJSON
+	case containsAny("geometry", "geography", "point", "polygon", "linestring"):
+		return TypeLocation
+	case strings.HasPrefix(t, "interval"):
+		return TypeString
+	case containsAny("int", "serial"):
+		return TypeInteger
+	case containsAny("decimal", "numeric", "money"):
+		return TypeDecimal
+	case containsAny("float", "double", "real"):
+		return TypeFloat
+	case containsAny("date", "time"):
+		return TypeTime
+	case containsAny("blob", "binary", "bytea", "image"):
+		return TypeBinary
+	default:
+		return TypeString
+	}
+}
+
+// normalizeIntrospectedDefault 将数据库返回的默认值定义还原为 Field.Default：
+// 字面量去除引号与类型转换（'a'::text、N'a'、((0))），数值与布尔按字段类型解析，函数与表达式保持原样。
+func normalizeIntrospectedDefault(raw string, fieldType FieldType) interface{} {
+	value := strings.TrimSpace(raw)
+	for len(value) >= 2 && value[0] == '(' && value[len(value)-1] == ')' && isWrappedInParens(value) {
+		value = strings.TrimSpace(value[1 : len(value)-1])
+	}
+	if value == "" || strings.EqualFold(value, "NULL") {
+		return nil
+	}
+
+	if strings.HasPrefix(value, "'") || strings.HasPrefix(value, "N'") || strings.HasPrefix(value, "n'") {
+		if i := strings.LastIndex(value, "'::"); i > 0 {
+			value = value[:i+1]
+		}
+		if isQuotedStringLiteral(value) {
+			value = strings.ReplaceAll(value[strings.Index(value, "'")+1:len(value)-1], "''", "'")
+		}
+	}
+
+	switch fieldType {
+	case TypeInteger:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case TypeFloat:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case TypeBoolean:
+		switch strings.ToLower(value) {
+		case "true", "1", "b'1'":
+			return true
+		case "false", "0", "b'0'":
+			return false
+		}
+	}
+	return value
+}
+
+func isWrappedInParens(value string) bool {
+	depth := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i < len(value)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestIntrospectSchemasSQLite(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE orgs (id INTEGER PRIMARY KEY AUTOINCREMENT, slug VARCHAR(64) NOT NULL UNIQUE)",
+		`CREATE TABLE memberships (
+			org_id INTEGER NOT NULL,
+			user_code TEXT NOT NULL,
+			role TEXT NOT NULL DEFAULT 'member',
+			score REAL NULL DEFAULT 1.5,
+			active BOOLEAN NOT NULL DEFAULT 1,
+			joined_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			CONSTRAINT pk_memberships PRIMARY KEY (org_id, user_code),
+			CONSTRAINT uk_memberships_role UNIQUE (org_id, role),
+			CONSTRAINT fk_memberships_org FOREIGN KEY (org_id) REFERENCES orgs (id) ON DELETE CASCADE
+		)`,
+		"CREATE INDEX idx_memberships_joined ON memberships (joined_at)",
+		"CREATE INDEX idx_memberships_active ON memberships (active) WHERE active = 1",
+	} {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			t.Fatalf("exec %q failed: %v", stmt, err)
+		}
+	}
+
+	schemas, err := repo.IntrospectSchemas(ctx, "orgs", "memberships")
+	if err != nil {
+		t.Fatalf("introspect failed: %v", err)
+	}
+	orgs, memberships := schemas[0], schemas[1]
+	if pk := orgs.PrimaryKeyField(); pk == nil || pk.Name != "id" || !pk.Autoinc || pk.Type != TypeInteger {
+		t.Fatalf("unexpected orgs primary key: %+v", pk)
+	}
+	if c := orgs.Constraints(); len(c) != 1 || c[0].Kind != ConstraintUnique || c[0].Fields[0] != "slug" {
+		t.Fatalf("expected inline unique on slug, got %+v", c)
+	}
+
+	role := memberships.GetField("role")
+	if role == nil || role.Type != TypeString || role.Null || role.Default != "member" {
+		t.Fatalf("unexpected role field: %+v", role)
+	}
+	if score := memberships.GetField("score"); score.Type != TypeFloat || !score.Null || score.Default != 1.5 {
+		t.Fatalf("unexpected score field: %+v", score)
+	}
+	if active := memberships.GetField("active"); active.Type != TypeBoolean || active.Default != true {
+		t.Fatalf("unexpected active field: %+v", active)
+	}
+	if joined := memberships.GetField("joined_at"); joined.Type != TypeTime || joined.Default != "CURRENT_TIMESTAMP" || !joined.Index {
+		t.Fatalf("unexpected joined_at field: %+v", joined)
+	}
+	want := []TableConstraint{
+		{Name: "pk_memberships", Kind: ConstraintPrimaryKey, Fields: []string{"org_id", "user_code"}},
+		{Name: "uk_memberships_role", Kind: ConstraintUnique, Fields: []string{"org_id", "role"}},
+		{Name: "fk_memberships_org", Kind: ConstraintForeignKey, Fields: []string{"org_id"}, RefTable: "orgs", RefFields: []string{"id"}, OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+	}
+	if got := memberships.Constraints(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected constraints:\n got: %+v\nwant: %+v", got, want)
+	}
+	if got := memberships.Indexes(); len(got) != 1 || got[0].Name != "idx_memberships_joined" {
+		t.Fatalf("expected partial index skipped, got %+v", got)
+	}
+
+	// 反查结果可直接交给迁移工具在新库中重建
+	target, err := NewRepository(&Config{Adapter: "sqlite", Database: filepath.Join(t.TempDir(), "introspect_target.db")})
+	if err != nil {
+		t.Fatalf("failed to create target repository: %v", err)
+	}
+	defer target.Close()
+	if err := NewSchemaMigration("20260101000000", "import").CreateTable(orgs).CreateTable(memberships).Up(ctx, target); err != nil {
+		t.Fatalf("recreate from introspected schema failed: %v", err)
+	}
+	rebuilt, err := target.IntrospectSchemas(ctx, "memberships")
+	if err != nil {
+		t.Fatalf("introspect target failed: %v", err)
+	}
+	// CREATE TABLE 生成的复合主键不带约束名，其余约束完整往返
+	if got := rebuilt[0].Constraints(); !reflect.DeepEqual(got[0].Fields, want[0].Fields) || !reflect.DeepEqual(got[1:], want[1:]) {
+		t.Fatalf("expected constraints to round-trip, got %+v", got)
+	}
+
+	// 未指定表时读取全部用户表（含测试仓库预建的 users 表）
+	all, err := repo.IntrospectSchemas(ctx)
+	if err != nil || len(all) != 3 || all[0].TableName() != "memberships" {
+		t.Fatalf("expected all user tables, got %d (%v)", len(all), err)
+	}
+	if _, err := repo.IntrospectSchemas(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("expected missing table error, got: %v", err)
+	}
+	if _, err := (&Repository{adapter: &mockCompositeFallbackAdapter{}}).IntrospectSchemas(ctx); err == nil || !strings.Contains(err.Error(), "does not support schema introspection") {
+		t.Fatalf("expected unsupported adapter error, got: %v", err)
+	}
+	t.Logf("✓ sqlite schema introspection")
+}
+
+func TestIntrospectionTypeAndDefaultMapping(t *testing.T) {
+	types := map[string]FieldType{
+		"character varying":        TypeString,
+		"tinyint(1)":               TypeBoolean,
+		"bit":                      TypeBoolean,
+		"int unsigned":             TypeInteger,
+		"bigserial":                TypeInteger,
+		"numeric(10,2)":            TypeDecimal,
+		"double precision":         TypeFloat,
+		"timestamp with time zone": TypeTime,
+		"datetime2":                TypeTime,
+		"jsonb":                    TypeJSON,
+		"_int4":                    TypeArray,
+		"geography":                TypeLocation,
+		"multipoint":               TypeLocation,
+		"varbinary":                TypeBinary,
+		"interval":                 TypeString,
+		"uniqueidentifier":         TypeString,
+	}
+	for columnType, want := range types {
+		if got := fieldTypeFromColumnType(columnType); got != want {
+			t.Fatalf("column type %s: expected %s, got %s", columnType, want, got)
+		}
+	}
+
+	defaults := []struct {
+		raw       string
+		fieldType FieldType
+		want      interface{}
+	}{
+		{"'active'::character varying", TypeString, "active"},
+		{"'it''s'", TypeString, "it's"},
+		{"(N'draft')", TypeString, "draft"},
+		{"((0))", TypeInteger, int64(0)},
+		{"42", TypeInteger, int64(42)},
+		{"b'1'", TypeBoolean, true},
+		{"false", TypeBoolean, false},
+		{"(getdate())", TypeTime, "getdate()"},
+		{"now()", TypeTime, "now()"},
+		{"NULL", TypeString, nil},
+		{"anon", TypeString, "anon"},
+	}
+	for _, tc := range defaults {
+		if got := normalizeIntrospectedDefault(tc.raw, tc.fieldType); got != tc.want {
+			t.Fatalf("default %q: expected %#v, got %#v", tc.raw, tc.want, got)
+		}
+	}
+	if got := normalizeReferentialAction("SET_NULL"); got != "SET NULL" {
+		t.Fatalf("expected SET NULL, got %s", got)
+	}
+	if got := normalizeReferentialAction("c"); got != "CASCADE" {
+		t.Fatalf("expected CASCADE, got %s", got)
+	}
+}
+
+func TestMongoSchemaFromSpecification(t *testing.T) {
+	options, err := bson.Marshal(bson.D{{Key: "validator", Value: bson.D{{Key: "$jsonSchema", Value: bson.D{
+		{Key: "bsonType", Value: "object"},
+		{Key: "required", Value: bson.A{"email", "age"}},
+		{Key: "properties", Value: bson.D{
+			{Key: "email", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+			{Key: "age", Value: bson.D{{Key: "bsonType", Value: "int"}}},
+			{Key: "nickname", Value: bson.D{{Key: "bsonType", Value: bson.A{"string", "null"}}}},
+			{Key: "tags", Value: bson.D{{Key: "type", Value: "array"}}},
+		}},
+	}}}}})
+	if err != nil {
+		t.Fatalf("marshal options failed: %v", err)
+	}
+	schema, err := mongoSchemaFromSpecification("users", options, []mongoIndexSpecification{
+		{Name: "_id_", Key: bson.D{{Key: "_id", Value: 1}}},
+		{Name: "uk_users_email", Key: bson.D{{Key: "email", Value: 1}}, Unique: true},
+		{Name: "idx_users_tenant_age", Key: bson.D{{Key: "tenant_id", Value: 1}, {Key: "age", Value: -1}}},
+		{Name: "idx_users_home", Key: bson.D{{Key: "home", Value: "2dsphere"}}},
+	})
+	if err != nil {
+		t.Fatalf("build schema failed: %v", err)
+	}
+
+	names := make([]string, 0)
+	for _, field := range schema.Fields() {
+		names = append(names, field.Name)
+	}
+	if got := strings.Join(names, ","); got != "_id,email,age,nickname,tags,tenant_id,home" {
+		t.Fatalf("unexpected fields: %s", got)
+	}
+	if pk := schema.PrimaryKeyField(); pk == nil || pk.Name != "_id" {
+		t.Fatalf("expected _id primary key, got %+v", pk)
+	}
+	if f := schema.GetField("age"); f.Type != TypeInteger || f.Null {
+		t.Fatalf("unexpected age field: %+v", f)
+	}
+	if f := schema.GetField("nickname"); f.Type != TypeString || !f.Null {
+		t.Fatalf("unexpected nickname field: %+v", f)
+	}
+	if f := schema.GetField("tags"); f.Type != TypeArray || !f.Null {
+		t.Fatalf("unexpected tags field: %+v", f)
+	}
+	if f := schema.GetField("home"); f.Type != TypeLocation || !f.Index {
+		t.Fatalf("unexpected home field: %+v", f)
+	}
+	if c := schema.Constraints(); len(c) != 1 || c[0].Name != "uk_users_email" || c[0].Kind != ConstraintUnique {
+		t.Fatalf("unexpected constraints: %+v", c)
+	}
+	if idx := schema.Indexes(); len(idx) != 2 || !reflect.DeepEqual(idx[0].Fields, []string{"tenant_id", "age"}) {
+		t.Fatalf("unexpected indexes: %+v", idx)
+	}
+}
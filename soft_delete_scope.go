@@ -0,0 +1,153 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// softDeleteField 软删除字段名（与 BaseSchema.AddSoftDelete 一致）。
+const softDeleteField = "deleted_at"
+
+// softDeleteScope 查询构造器的软删除作用域。
+type softDeleteScope int
+
+const (
+	softDeleteScopeDefault softDeleteScope = iota // 排除已软删除记录
+	softDeleteScopeWith                           // 包含已软删除记录
+	softDeleteScopeOnly                           // 仅已软删除记录
+)
+
+// schemaSupportsSoftDelete Schema 定义了 deleted_at 字段时视为软删除 Schema。
+func schemaSupportsSoftDelete(schema Schema) bool {
+	return schema != nil && schema.GetField(softDeleteField) != nil
+}
+
+// softDeleteScopeCondition 返回主表的作用域条件；WithDeleted、非软删除 Schema
+// 或已显式以 deleted_at 为条件（由调用方自行控制）时返回 nil。
+// qualifier 为主表限定前缀，为空时任意限定前缀的 deleted_at 条件均视为显式条件。
+func softDeleteScopeCondition(schema Schema, scope softDeleteScope, conditions []Condition, qualifier string) Condition {
+	if !schemaSupportsSoftDelete(schema) || conditionsReferenceField(conditions, softDeleteField, qualifier) {
+		return nil
+	}
+	switch scope {
+	case softDeleteScopeWith:
+		return nil
+	case softDeleteScopeOnly:
+		return IsNotNull(softDeleteField)
+	default:
+		return IsNull(softDeleteField)
+	}
+}
+
+func conditionsReferenceField(conditions []Condition, field, qualifier string) bool {
+	for _, condition := range conditions {
+		switch c := condition.(type) {
+		case *SimpleCondition:
+			name := strings.TrimSpace(c.Field)
+			prefix := ""
+			if idx := strings.LastIndex(name, "."); idx >= 0 {
+				prefix, name = name[:idx], name[idx+1:]
+			}
+			if name == field && (qualifier == "" || prefix == "" || prefix == qualifier) {
+				return true
+			}
+		case *CompositeCondition:
+			if conditionsReferenceField(c.Conditions, field, qualifier) {
+				return true
+			}
+		case *NotCondition:
+			if conditionsReferenceField([]Condition{c.Condition}, field, qualifier) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WithDeleted 查询结果包含已软删除的记录。
+func (qb *SQLQueryConstructor) WithDeleted() QueryConstructor {
+	qb.softDeleteScope = softDeleteScopeWith
+	return qb
+}
+
+// OnlyDeleted 仅查询已软删除的记录（JoinWith 连接目标仍排除已软删除记录）。
+func (qb *SQLQueryConstructor) OnlyDeleted() QueryConstructor {
+	qb.softDeleteScope = softDeleteScopeOnly
+	return qb
+}
+
+// applySoftDeleteScope 在 IR 上追加软删除作用域：主表条件进入 WHERE；
+// JoinWith 连接目标的 deleted_at IS NULL 对 INNER / LEFT JOIN 追加到 ON（保持外连接语义），其余连接追加到 WHERE。
+func (qb *SQLQueryConstructor) applySoftDeleteScope(ir *QueryIR) {
+	if condition := softDeleteScopeCondition(qb.schema, qb.softDeleteScope, qb.conditions, qb.baseTableName()); condition != nil {
+		ir.Conditions = append(ir.Conditions, qb.qualifyCondition(condition))
+	}
+	if qb.softDeleteScope == softDeleteScopeWith {
+		return
+	}
+	for i := range ir.Joins {
+		join := &ir.Joins[i]
+		if !schemaSupportsSoftDelete(join.Schema) {
+			continue
+		}
+		prefix := strings.TrimSpace(join.Alias)
+		if prefix == "" {
+			prefix = strings.TrimSpace(join.Table)
+		}
+		joinType := strings.ToUpper(strings.TrimSpace(join.JoinType))
+		if strings.TrimSpace(join.OnClause) != "" && (joinType == "" || joinType == "INNER" || joinType == "LEFT") {
+			join.OnClause = fmt.Sprintf("(%s) AND %s IS NULL", strings.TrimSpace(join.OnClause), quoteQualifiedIdentifierIR(qb.dialect, prefix, softDeleteField))
+			continue
+		}
+		ir.Conditions = append(ir.Conditions, qb.qualifyConditionWithPrefix(IsNull(softDeleteField), prefix))
+	}
+}
+
+// WithDeleted 查询结果包含已软删除的文档。
+func (qb *MongoQueryConstructor) WithDeleted() QueryConstructor {
+	qb.softDeleted = softDeleteScopeWith
+	return qb
+}
+
+// OnlyDeleted 仅查询已软删除的文档。
+func (qb *MongoQueryConstructor) OnlyDeleted() QueryConstructor {
+	qb.softDeleted = softDeleteScopeOnly
+	return qb
+}
+
+// WithDeleted 查询结果包含已软删除的节点。
+func (qb *Neo4jQueryConstructor) WithDeleted() QueryConstructor {
+	qb.softDeleted = softDeleteScopeWith
+	return qb
+}
+
+// OnlyDeleted 仅查询已软删除的节点。
+func (qb *Neo4jQueryConstructor) OnlyDeleted() QueryConstructor {
+	qb.softDeleted = softDeleteScopeOnly
+	return qb
+}
+
+// Restore 按条件恢复已软删除的记录（deleted_at 置空），返回恢复的行数。
+func (e *ChangesetExecutor) Restore(condition Condition) (int64, error) {
+	if err := e.ready(); err != nil {
+		return 0, err
+	}
+	if !schemaSupportsSoftDelete(e.qb.schema) {
+		return 0, fmt.Errorf("schema %s does not support soft delete (missing deleted_at field)", e.qb.schema.TableName())
+	}
+	if condition == nil {
+		return 0, fmt.Errorf("condition cannot be nil")
+	}
+	cs := NewChangeset(e.qb.schema).PutChange(softDeleteField, nil)
+	return e.hookedWhere(ChangesetHookEvent{Operation: ChangesetOperationUpdate, Changeset: cs, Condition: condition}, func() (int64, error) {
+		return e.writeWhere(And(condition, IsNotNull(softDeleteField)), map[string]interface{}{softDeleteField: nil})
+	})
+}
+
+// RestoreByID 按 ID 恢复已软删除的记录。
+func (e *ChangesetExecutor) RestoreByID(id interface{}) (int64, error) {
+	if err := e.ready(); err != nil {
+		return 0, err
+	}
+	return e.Restore(Eq(primaryKeyNameOrDefaultIR(e.qb.schema), id))
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newSoftDeleteScopeSchemas() (*BaseSchema, *BaseSchema) {
+	users := NewBaseSchema("users")
+	users.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	users.AddField(NewField("name", TypeString).Build())
+	users.AddSoftDelete()
+	orders := NewBaseSchema("orders")
+	orders.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	orders.AddField(NewField("user_id", TypeInteger).Build())
+	orders.AddSoftDelete()
+	return users, orders
+}
+
+func TestSoftDeleteScopeSQL(t *testing.T) {
+	users, orders := newSoftDeleteScopeSchemas()
+	ctx := context.Background()
+
+	qc := NewSQLQueryConstructor(users, NewPostgreSQLDialect())
+	qc.Where(Eq("name", "a"))
+	sql, _, err := qc.Build(ctx)
+	if err != nil || sql != `SELECT * FROM "users" WHERE "users"."name" = $1 AND "users"."deleted_at" IS NULL` {
+		t.Fatalf("unexpected default scope sql: %s (%v)", sql, err)
+	}
+
+	qc = NewSQLQueryConstructor(users, NewPostgreSQLDialect())
+	qc.WithDeleted().Where(Eq("name", "a"))
+	if sql, _, _ = qc.Build(ctx); strings.Contains(sql, "deleted_at") {
+		t.Fatalf("expected WithDeleted to drop scope, got: %s", sql)
+	}
+
+	qc = NewSQLQueryConstructor(users, NewPostgreSQLDialect())
+	qc.OnlyDeleted()
+	if sql, _, _ = qc.Build(ctx); sql != `SELECT * FROM "users" WHERE "users"."deleted_at" IS NOT NULL` {
+		t.Fatalf("unexpected OnlyDeleted sql: %s", sql)
+	}
+
+	// 显式的 deleted_at 条件由调用方控制，不再追加作用域。
+	qc = NewSQLQueryConstructor(users, NewPostgreSQLDialect())
+	qc.Where(Gt("deleted_at", "2026-01-01"))
+	if sql, _, _ = qc.Build(ctx); strings.Count(sql, "deleted_at") != 1 {
+		t.Fatalf("expected explicit deleted_at condition only, got: %s", sql)
+	}
+
+	qc = NewSQLQueryConstructor(users, NewPostgreSQLDialect())
+	qc.JoinWith(NewLeftJoin(orders).As("o").On("o.user_id = users.id"))
+	sql, _, err = qc.Build(ctx)
+	want := `SELECT * FROM "users" LEFT JOIN "orders" AS "o" ON (o.user_id = users.id) AND "o"."deleted_at" IS NULL WHERE "users"."deleted_at" IS NULL`
+	if err != nil || sql != want {
+		t.Fatalf("unexpected join scope sql:\n got: %s\nwant: %s", sql, want)
+	}
+
+	plain := NewBaseSchema("tags")
+	plain.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	qc = NewSQLQueryConstructor(plain, NewPostgreSQLDialect())
+	if sql, _, _ = qc.Build(ctx); strings.Contains(sql, "deleted_at") {
+		t.Fatalf("expected no scope for schema without deleted_at, got: %s", sql)
+	}
+}
+
+func TestSoftDeleteScopeMongo(t *testing.T) {
+	users, _ := newSoftDeleteScopeSchemas()
+	qc := NewMongoQueryConstructor(users)
+	qc.Where(Eq("name", "a"))
+	plan, err := qc.BuildFindPlan()
+	if err != nil {
+		t.Fatalf("build find plan failed: %v", err)
+	}
+	want := map[string]interface{}{"$and": []map[string]interface{}{
+		{"name": "a"},
+		{"deleted_at": nil},
+	}}
+	if !reflect.DeepEqual(plan.Filter, want) {
+		t.Fatalf("unexpected mongo filter:\n got: %v\nwant: %v", plan.Filter, want)
+	}
+
+	qc = NewMongoQueryConstructor(users)
+	qc.WithDeleted()
+	if plan, err = qc.BuildFindPlan(); err != nil || len(plan.Filter) != 0 {
+		t.Fatalf("expected empty filter with deleted rows, got: %v (%v)", plan.Filter, err)
+	}
+}
+
+func TestSoftDeleteRestoreSQLite(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+
+	if _, err := repo.Exec(ctx, "CREATE TABLE accounts (id INTEGER PRIMARY KEY, name TEXT NOT NULL, deleted_at DATETIME NULL)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	schema := NewBaseSchema("accounts")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("name", TypeString).Build())
+	schema.AddSoftDelete()
+
+	count := func(scope func(QueryConstructor) QueryConstructor) int {
+		qc, err := repo.NewQueryConstructor(schema)
+		if err != nil {
+			t.Fatalf("new query constructor failed: %v", err)
+		}
+		result, err := repo.ExecuteQueryConstructor(ctx, scope(qc))
+		if err != nil {
+			t.Fatalf("execute failed: %v", err)
+		}
+		return len(result.Rows)
+	}
+	visible := func(qc QueryConstructor) QueryConstructor { return qc }
+	trashed := func(qc QueryConstructor) QueryConstructor { return qc.OnlyDeleted() }
+	all := func(qc QueryConstructor) QueryConstructor { return qc.WithDeleted() }
+
+	err := repo.WithChangeset(ctx, schema, func(e *ChangesetExecutor) error {
+		for i, name := range []string{"a", "b", "c"} {
+			if _, err := e.Insert(NewChangeset(schema).Cast(map[string]interface{}{"id": i + 1, "name": name})); err != nil {
+				return err
+			}
+		}
+		_, err := e.SoftDeleteWhere(In("id", 1, 2))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("seed failed: %v", err)
+	}
+	if visible, trashed, all := count(visible), count(trashed), count(all); visible != 1 || trashed != 2 || all != 3 {
+		t.Fatalf("unexpected scoped counts: visible=%d trashed=%d all=%d", visible, trashed, all)
+	}
+
+	executor, err := repo.NewChangesetExecutor(ctx, schema)
+	if err != nil {
+		t.Fatalf("new executor failed: %v", err)
+	}
+	if restored, err := executor.RestoreByID(1); err != nil || restored != 1 {
+		t.Fatalf("restore by id: restored=%d err=%v", restored, err)
+	}
+	// 未被软删除的记录不计入恢复行数。
+	if restored, err := executor.Restore(In("id", 2, 3)); err != nil || restored != 1 {
+		t.Fatalf("restore: restored=%d err=%v", restored, err)
+	}
+	if n := count(visible); n != 3 {
+		t.Fatalf("expected all rows visible after restore, got %d", n)
+	}
+
+	plain := NewBaseSchema("users")
+	plain.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	plainExecutor, _ := repo.NewChangesetExecutor(ctx, plain)
+	if _, err := plainExecutor.RestoreByID(1); err == nil || !strings.Contains(err.Error(), "does not support soft delete") {
+		t.Fatalf("expected unsupported soft delete error, got: %v", err)
+	}
+	t.Logf("✓ soft delete scope and restore")
+}
@@ -14,9 +14,10 @@ import (
 
 // SQLiteAdapter SQLite 数据库适配器
 type SQLiteAdapter struct {
-	config *Config
-	db     *gorm.DB
-	sqlDB  *sql.DB
+	config        *Config
+	db            *gorm.DB
+	sqlDB         *sql.DB
+	serverVersion string // Connect 时通过 sqlite_version() 读取，用于版本化特性判断
 }
 
 // NewSQLiteAdapter 创建 SQLite 适配器
@@ -68,6 +69,10 @@ func (a *SQLiteAdapter) Connect(ctx context.Context, config *Config) error {
 		sqlDB.SetConnMaxIdleTime(5 * time.Minute)
 	}
 
+	if err := sqlDB.QueryRowContext(ctx, "SELECT sqlite_version()").Scan(&a.serverVersion); err != nil {
+		return fmt.Errorf("failed to detect SQLite version: %w", err)
+	}
+
 	return nil
 }
 
@@ -209,6 +214,33 @@ func (t *SQLiteTx) Rollback(ctx context.Context) error {
 	return t.tx.Rollback()
 }
 
+// Savepoint 创建事务保存点
+func (t *SQLiteTx) Savepoint(ctx context.Context, name string) error {
+	if err := validateSavepointName(name); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(ctx, "SAVEPOINT "+name)
+	return err
+}
+
+// RollbackTo 回滚到事务保存点
+func (t *SQLiteTx) RollbackTo(ctx context.Context, name string) error {
+	if err := validateSavepointName(name); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+	return err
+}
+
+// ReleaseSavepoint 释放事务保存点
+func (t *SQLiteTx) ReleaseSavepoint(ctx context.Context, name string) error {
+	if err := validateSavepointName(name); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
 // Exec 在事务中执行
 func (t *SQLiteTx) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	return t.tx.ExecContext(ctx, query, args...)
@@ -225,8 +257,9 @@ func (t *SQLiteTx) QueryRow(ctx context.Context, query string, args ...interface
 }
 
 // GetQueryBuilderProvider 返回查询构造器提供者
+// 方言携带 Connect 时检测到的 SQLite 版本，决定窗口函数、RETURNING 等是否走兼容路径。
 func (a *SQLiteAdapter) GetQueryBuilderProvider() QueryConstructorProvider {
-	return NewDefaultSQLQueryConstructorProvider(NewSQLiteDialect())
+	return NewDefaultSQLQueryConstructorProvider(NewSQLiteDialectWithVersion(a.serverVersion))
 }
 
 // GetDatabaseFeatures 返回 SQLite 数据库特性
@@ -0,0 +1,245 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// IntrospectTables 列出 sqlite_master 中的用户表（排除 sqlite_ 内部表）。
+func (a *SQLiteAdapter) IntrospectTables(ctx context.Context) ([]string, error) {
+	rows, err := a.Query(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanIntrospectionNames(rows)
+}
+
+// IntrospectSchema 基于 sqlite_master 与 PRAGMA table_info / index_list / foreign_key_list 反查表结构。
+// SQLite 不在 PRAGMA 中记录约束名，具名约束从建表语句中解析；部分索引与表达式索引无法表示，将被跳过。
+func (a *SQLiteAdapter) IntrospectSchema(ctx context.Context, table string) (*BaseSchema, error) {
+	result := &introspectedTable{name: table}
+	var createSQL string
+	if err := a.QueryRow(ctx, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&createSQL); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return result.toSchema()
+		}
+		return nil, err
+	}
+	named := sqliteNamedConstraints(createSQL)
+
+	rows, err := a.Query(ctx, `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`, table)
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[string]int)
+	for rows.Next() {
+		var (
+			name, columnType string
+			notNull, pk      int
+			defaultValue     sql.NullString
+		)
+		if err := rows.Scan(&name, &columnType, &notNull, &defaultValue, &pk); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		field := &Field{Name: name, Type: fieldTypeFromColumnType(columnType), Null: notNull == 0 && pk == 0}
+		if defaultValue.Valid {
+			field.Default = normalizeIntrospectedDefault(defaultValue.String, field.Type)
+		}
+		if pk > 0 {
+			positions[name] = pk
+			result.primaryKey.Fields = append(result.primaryKey.Fields, name)
+			// INTEGER PRIMARY KEY 是 rowid 别名，插入时自动生成
+			field.Autoinc = strings.EqualFold(strings.TrimSpace(columnType), "INTEGER")
+		}
+		result.columns = append(result.columns, field)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sortIntrospectedPrimaryKey(result.primaryKey.Fields, positions)
+	if len(result.primaryKey.Fields) > 1 {
+		result.primaryKey.Name = named.lookup(ConstraintPrimaryKey, result.primaryKey.Fields)
+	}
+
+	if err := a.introspectIndexes(ctx, table, named, result); err != nil {
+		return nil, err
+	}
+
+	rows, err = a.Query(ctx, `SELECT id, "table", "from", "to", on_update, on_delete FROM pragma_foreign_key_list(?) ORDER BY id, seq`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	lastID := -1
+	for rows.Next() {
+		var (
+			id                 int
+			refTable, from     string
+			to                 sql.NullString
+			onUpdate, onDelete string
+		)
+		if err := rows.Scan(&id, &refTable, &from, &to, &onUpdate, &onDelete); err != nil {
+			return nil, err
+		}
+		if id == lastID {
+			last := &result.constraints[len(result.constraints)-1]
+			last.Fields = append(last.Fields, from)
+			last.RefFields = append(last.RefFields, to.String)
+			continue
+		}
+		lastID = id
+		result.constraints = append(result.constraints, TableConstraint{
+			Kind:      ConstraintForeignKey,
+			Fields:    []string{from},
+			RefTable:  refTable,
+			RefFields: []string{to.String},
+			OnDelete:  normalizeReferentialAction(onDelete),
+			OnUpdate:  normalizeReferentialAction(onUpdate),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range result.constraints {
+		if c := &result.constraints[i]; c.Kind == ConstraintForeignKey {
+			c.Name = named.lookup(ConstraintForeignKey, c.Fields)
+		}
+	}
+	return result.toSchema()
+}
+
+// introspectIndexes 读取唯一约束（origin = u）与显式创建的索引（origin = c）；主键自动索引已由 table_info 覆盖。
+func (a *SQLiteAdapter) introspectIndexes(ctx context.Context, table string, named sqliteConstraintNames, result *introspectedTable) error {
+	rows, err := a.Query(ctx, `SELECT name, "unique", origin, partial FROM pragma_index_list(?) ORDER BY seq DESC`, table)
+	if err != nil {
+		return err
+	}
+	type indexInfo struct {
+		name, origin string
+		unique       bool
+	}
+	indexes := make([]indexInfo, 0)
+	for rows.Next() {
+		var (
+			info            indexInfo
+			unique, partial int
+		)
+		if err := rows.Scan(&info.name, &unique, &info.origin, &partial); err != nil {
+			rows.Close()
+			return err
+		}
+		if info.origin == "pk" || partial != 0 {
+			continue
+		}
+		info.unique = unique != 0
+		indexes = append(indexes, info)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		columns, err := a.Query(ctx, "SELECT name FROM pragma_index_info(?) ORDER BY seqno", index.name)
+		if err != nil {
+			return err
+		}
+		fields := make([]string, 0)
+		expression := false
+		for columns.Next() {
+			var name sql.NullString
+			if err := columns.Scan(&name); err != nil {
+				columns.Close()
+				return err
+			}
+			expression = expression || !name.Valid
+			fields = append(fields, name.String)
+		}
+		columns.Close()
+		if err := columns.Err(); err != nil {
+			return err
+		}
+		if expression || len(fields) == 0 {
+			continue
+		}
+		if index.origin == "u" {
+			result.constraints = append(result.constraints, TableConstraint{
+				Name:   named.lookup(ConstraintUnique, fields),
+				Kind:   ConstraintUnique,
+				Fields: fields,
+			})
+			continue
+		}
+		result.indexes = append(result.indexes, TableIndex{Name: index.name, Fields: fields, Unique: index.unique})
+	}
+	return nil
+}
+
+// sqliteConstraintNames 建表语句中具名表级约束（CONSTRAINT name ...）的类型与列。
+type sqliteConstraintNames []TableConstraint
+
+func sqliteNamedConstraints(createSQL string) sqliteConstraintNames {
+	def, err := parseSQLiteTableDefinition(createSQL)
+	if err != nil {
+		return nil
+	}
+	named := make(sqliteConstraintNames, 0)
+	for _, item := range def.constraints {
+		if item.name == "" {
+			continue
+		}
+		upper := strings.ToUpper(item.sql)
+		c := TableConstraint{Name: item.name}
+		switch {
+		case strings.Contains(upper, "PRIMARY KEY"):
+			c.Kind = ConstraintPrimaryKey
+		case strings.Contains(upper, "UNIQUE"):
+			c.Kind = ConstraintUnique
+		case strings.Contains(upper, "FOREIGN KEY"):
+			c.Kind = ConstraintForeignKey
+		default:
+			continue
+		}
+		open := strings.Index(item.sql, "(")
+		if open < 0 {
+			continue
+		}
+		parts, end := splitSQLiteTopLevel(item.sql, open+1)
+		if end < 0 {
+			continue
+		}
+		for _, part := range parts {
+			name, _, _ := readSQLiteIdentifier(part)
+			c.Fields = append(c.Fields, name)
+		}
+		named = append(named, c)
+	}
+	return named
+}
+
+// lookup 按约束类型与列匹配约束名，未命名时返回空串。
+func (n sqliteConstraintNames) lookup(kind ConstraintKind, fields []string) string {
+	for _, c := range n {
+		if c.Kind == kind && strings.EqualFold(strings.Join(c.Fields, ","), strings.Join(fields, ",")) {
+			return c.Name
+		}
+	}
+	return ""
+}
+
+func scanIntrospectionNames(rows *sql.Rows) ([]string, error) {
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
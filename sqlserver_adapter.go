@@ -864,6 +864,29 @@ func (t *SQLServerTx) Rollback(ctx context.Context) error {
 	return t.tx.Rollback()
 }
 
+// Savepoint 创建事务保存点
+func (t *SQLServerTx) Savepoint(ctx context.Context, name string) error {
+	if err := validateSavepointName(name); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(ctx, "SAVE TRANSACTION "+name)
+	return err
+}
+
+// RollbackTo 回滚到事务保存点
+func (t *SQLServerTx) RollbackTo(ctx context.Context, name string) error {
+	if err := validateSavepointName(name); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(ctx, "ROLLBACK TRANSACTION "+name)
+	return err
+}
+
+// ReleaseSavepoint SQL Server 无释放保存点语句，保存点随事务结束失效
+func (t *SQLServerTx) ReleaseSavepoint(ctx context.Context, name string) error {
+	return validateSavepointName(name)
+}
+
 // Exec 在事务中执行
 func (t *SQLServerTx) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	return t.tx.ExecContext(ctx, query, args...)
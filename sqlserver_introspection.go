@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+)
+
+// IntrospectTables 列出用户表；dbo 下的表返回表名，其他 schema 返回 "schema.table"。
+func (a *SQLServerAdapter) IntrospectTables(ctx context.Context) ([]string, error) {
+	rows, err := a.Query(ctx, `
+		SELECT CASE WHEN s.name = 'dbo' THEN t.name ELSE s.name + '.' + t.name END
+		FROM sys.tables t
+		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
+		WHERE t.is_ms_shipped = 0
+		ORDER BY s.name, t.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanIntrospectionNames(rows)
+}
+
+// IntrospectSchema 基于 sys.columns / sys.default_constraints / sys.indexes / sys.foreign_keys 反查表结构。
+// 列默认值来自独立的默认值约束；筛选索引（has_filter）无法表示，将被跳过。
+func (a *SQLServerAdapter) IntrospectSchema(ctx context.Context, table string) (*BaseSchema, error) {
+	result := &introspectedTable{name: table}
+
+	rows, err := a.Query(ctx, `
+		SELECT c.name, ty.name, c.is_nullable, c.is_identity, COALESCE(dc.definition, '')
+		FROM sys.columns c
+		INNER JOIN sys.types ty ON ty.user_type_id = c.user_type_id
+		LEFT JOIN sys.default_constraints dc ON dc.parent_object_id = c.object_id AND dc.parent_column_id = c.column_id
+		WHERE c.object_id = OBJECT_ID(@p1)
+		ORDER BY c.column_id
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			name, columnType, defaultValue string
+			nullable, identity             bool
+		)
+		if err := rows.Scan(&name, &columnType, &nullable, &identity, &defaultValue); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		field := &Field{Name: name, Type: fieldTypeFromColumnType(columnType), Null: nullable, Autoinc: identity}
+		if defaultValue != "" {
+			field.Default = normalizeIntrospectedDefault(defaultValue, field.Type)
+		}
+		result.columns = append(result.columns, field)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = a.Query(ctx, `
+		SELECT i.name, i.is_primary_key, i.is_unique_constraint, i.is_unique, col.name
+		FROM sys.indexes i
+		INNER JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+		INNER JOIN sys.columns col ON col.object_id = ic.object_id AND col.column_id = ic.column_id
+		WHERE i.object_id = OBJECT_ID(@p1) AND i.type > 0 AND i.has_filter = 0 AND ic.is_included_column = 0
+		ORDER BY i.is_primary_key DESC, i.name, ic.key_ordinal
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			name, column                      string
+			primary, uniqueConstraint, unique bool
+		)
+		if err := rows.Scan(&name, &primary, &uniqueConstraint, &unique, &column); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		switch {
+		case primary:
+			result.primaryKey.Name = name
+			result.primaryKey.Fields = append(result.primaryKey.Fields, column)
+		case uniqueConstraint:
+			result.addConstraintColumn(TableConstraint{Name: name, Kind: ConstraintUnique}, column, "")
+		default:
+			result.addIndexColumn(name, unique, column)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = a.Query(ctx, `
+		SELECT fk.name, pc.name, OBJECT_NAME(fk.referenced_object_id), rc.name,
+			fk.delete_referential_action_desc, fk.update_referential_action_desc
+		FROM sys.foreign_keys fk
+		INNER JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+		INNER JOIN sys.columns pc ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+		INNER JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+		WHERE fk.parent_object_id = OBJECT_ID(@p1)
+		ORDER BY fk.name, fkc.constraint_column_id
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, column, refTable, refColumn, onDelete, onUpdate string
+		if err := rows.Scan(&name, &column, &refTable, &refColumn, &onDelete, &onUpdate); err != nil {
+			return nil, err
+		}
+		result.addConstraintColumn(TableConstraint{
+			Name:     name,
+			Kind:     ConstraintForeignKey,
+			RefTable: refTable,
+			OnDelete: normalizeReferentialAction(onDelete),
+			OnUpdate: normalizeReferentialAction(onUpdate),
+		}, column, refColumn)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result.toSchema()
+}
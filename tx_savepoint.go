@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var savepointNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateSavepointName 保存点名称直接拼入 SQL，只允许普通标识符。
+func validateSavepointName(name string) error {
+	if !savepointNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid savepoint name: %q", name)
+	}
+	return nil
+}
+
+// withSavepoint 在外层事务内以保存点执行嵌套 WithChangeset。
+// 嵌套块失败时回滚到保存点，并丢弃其登记的 after-commit 钩子；
+// 成功时释放保存点（避免长事务内保存点堆积），钩子随外层事务提交后执行。
+func (r *Repository) withSavepoint(ctx context.Context, outer *txAdapter, schema Schema, fn func(*ChangesetExecutor) error) error {
+	outer.savepoints++
+	name := fmt.Sprintf("eit_sp_%d", outer.savepoints)
+	if err := outer.tx.Savepoint(ctx, name); err != nil {
+		return fmt.Errorf("create savepoint failed: %w", err)
+	}
+
+	executor := newChangesetExecutor(schema, r, ctx)
+	if outer.owner != nil {
+		executor.owner = outer.owner
+	}
+	executor.afterCommit = outer.afterCommit
+	pending := 0
+	if outer.afterCommit != nil {
+		pending = len(*outer.afterCommit)
+	}
+
+	if err := fn(executor); err != nil {
+		if rbErr := outer.tx.RollbackTo(ctx, name); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback to savepoint failed: %w", rbErr))
+		}
+		if outer.afterCommit != nil {
+			*outer.afterCommit = (*outer.afterCommit)[:pending]
+		}
+		return err
+	}
+	if err := outer.tx.ReleaseSavepoint(ctx, name); err != nil {
+		return fmt.Errorf("release savepoint failed: %w", err)
+	}
+	return nil
+}
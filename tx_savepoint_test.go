@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestWithChangesetNestedSavepointSQLite(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+
+	if _, err := repo.Exec(ctx, "CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT NOT NULL)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	schema := NewBaseSchema("notes")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("body", TypeString).Build())
+
+	var committed []interface{}
+	if err := repo.RegisterChangesetHook("notes", ChangesetHookFunc(func(ctx context.Context, event *ChangesetHookEvent) error {
+		if event.Stage == HookAfterCommit {
+			committed = append(committed, event.Changeset.Get("id"))
+		}
+		return nil
+	})); err != nil {
+		t.Fatalf("register hook failed: %v", err)
+	}
+
+	insert := func(e *ChangesetExecutor, id int) error {
+		_, err := e.Insert(NewChangeset(schema).Cast(map[string]interface{}{"id": id, "body": "note"}))
+		return err
+	}
+	errInner := errors.New("inner failed")
+	err := repo.WithChangeset(ctx, schema, func(e *ChangesetExecutor) error {
+		if err := insert(e, 1); err != nil {
+			return err
+		}
+		err := e.Repository().WithChangeset(ctx, schema, func(inner *ChangesetExecutor) error {
+			if err := insert(inner, 2); err != nil {
+				return err
+			}
+			return errInner
+		})
+		if !errors.Is(err, errInner) {
+			t.Fatalf("expected inner error, got: %v", err)
+		}
+		// 主键冲突失败的嵌套块同样只回滚自身。
+		if err := e.Repository().WithChangeset(ctx, schema, func(inner *ChangesetExecutor) error {
+			return insert(inner, 1)
+		}); err == nil {
+			t.Fatalf("expected primary key conflict in nested block")
+		}
+		return e.Repository().WithChangeset(ctx, schema, func(inner *ChangesetExecutor) error {
+			return insert(inner, 3)
+		})
+	})
+	if err != nil {
+		t.Fatalf("outer transaction failed: %v", err)
+	}
+
+	rows, err := repo.Query(ctx, "SELECT id FROM notes ORDER BY id")
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			t.Fatalf("scan failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 3}) {
+		t.Fatalf("expected only failed nested block rolled back, got %v", ids)
+	}
+	if fmt.Sprint(committed) != "[1 3]" {
+		t.Fatalf("expected after-commit hooks of rolled back block discarded, got %v", committed)
+	}
+
+	// 外层失败时，已成功的嵌套块随之回滚。
+	err = repo.WithChangeset(ctx, schema, func(e *ChangesetExecutor) error {
+		if err := e.Repository().WithChangeset(ctx, schema, func(inner *ChangesetExecutor) error {
+			return insert(inner, 4)
+		}); err != nil {
+			return err
+		}
+		return errInner
+	})
+	if !errors.Is(err, errInner) {
+		t.Fatalf("expected outer error, got: %v", err)
+	}
+	var n int
+	if err := repo.QueryRow(ctx, "SELECT COUNT(*) FROM notes WHERE id = 4").Scan(&n); err != nil || n != 0 {
+		t.Fatalf("expected nested write rolled back with outer transaction, got %d (%v)", n, err)
+	}
+	t.Logf("✓ nested WithChangeset uses savepoints")
+}
+
+func TestWithChangesetNestedSavepointReleasedSQLite(t *testing.T) {
+	repo := createChangesetExecutorTestRepo(t)
+	defer repo.Close()
+	ctx := context.Background()
+	schema := NewBaseSchema("users")
+
+	err := repo.WithChangeset(ctx, schema, func(e *ChangesetExecutor) error {
+		outer, ok := e.Repository().GetAdapter().(*txAdapter)
+		if !ok {
+			t.Fatalf("expected tx-bound repository, got %T", e.Repository().GetAdapter())
+		}
+		if err := e.Repository().WithChangeset(ctx, schema, func(inner *ChangesetExecutor) error {
+			return nil
+		}); err != nil {
+			return err
+		}
+		// 成功的嵌套块已释放保存点，无法再回滚到它
+		if err := outer.tx.RollbackTo(ctx, "eit_sp_1"); err == nil {
+			t.Fatalf("expected savepoint eit_sp_1 released after nested block succeeded")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("outer transaction failed: %v", err)
+	}
+	t.Logf("✓ nested WithChangeset releases savepoints")
+}
+
+func TestValidateSavepointName(t *testing.T) {
+	for _, name := range []string{"eit_sp_1", "_sp"} {
+		if err := validateSavepointName(name); err != nil {
+			t.Fatalf("expected %q valid, got: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "1sp", "sp; DROP TABLE x", "sp-1"} {
+		if err := validateSavepointName(name); err == nil {
+			t.Fatalf("expected %q rejected", name)
+		}
+	}
+}
@@ -33,6 +33,8 @@ type ValidationMessages struct {
 	PostalInvalidValue string
 	IDCardInvalidType  string
 	IDCardInvalidValue string
+	UniqueTaken        string
+	ReferenceNotFound  string
 }
 
 // ValidationProfile 区域化校验规则配置。
@@ -68,6 +70,8 @@ var (
 				PostalInvalidValue: "邮政编码格式不正确",
 				IDCardInvalidType:  "身份证号必须是字符串",
 				IDCardInvalidValue: "身份证号格式不正确",
+				UniqueTaken:        "已被占用",
+				ReferenceNotFound:  "关联记录不存在",
 			},
 		},
 		"en-US": {
@@ -88,6 +92,8 @@ var (
 				PostalInvalidValue: "invalid postal code format",
 				IDCardInvalidType:  "id card must be a string",
 				IDCardInvalidValue: "invalid id card format",
+				UniqueTaken:        "has already been taken",
+				ReferenceNotFound:  "does not exist",
 			},
 		},
 	}
@@ -275,6 +281,45 @@ type LocaleAwareValidator interface {
 	ValidateWithLocale(value interface{}, locale string) error
 }
 
+// ContextValidator 需要访问数据库的验证器接口（如唯一性、引用存在性）。
+// 仅在 Changeset.*WithContext 校验中执行：同一次校验内并发运行，结果按 Changeset 缓存；
+// 其他校验入口回退调用 Validate。
+type ContextValidator interface {
+	ValidateWithContext(ctx context.Context, vc *ValidationContext, value interface{}) error
+}
+
+// ValidationContext 传递给 ContextValidator 的校验上下文。
+type ValidationContext struct {
+	// Repository 由 WithValidationRepository 写入上下文；未设置时为 nil。
+	Repository *Repository
+	Schema     Schema
+	Field      string
+	Action     Action
+	// PrimaryKey 当前记录主键值，更新 / upsert 时用于排除自身；插入时为 nil。
+	PrimaryKey interface{}
+	Locale     string
+}
+
+type validationRepositoryContextKey struct{}
+
+// WithValidationRepository 将 Repository 写入上下文，供 ContextValidator 查询数据库。
+// 在 WithChangeset 内传入 ChangesetExecutor.Repository() 可在同一事务内校验。
+func WithValidationRepository(ctx context.Context, repo *Repository) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, validationRepositoryContextKey{}, repo)
+}
+
+// ValidationRepositoryFromContext 从上下文中获取校验使用的 Repository。
+func ValidationRepositoryFromContext(ctx context.Context) *Repository {
+	if ctx == nil {
+		return nil
+	}
+	repo, _ := ctx.Value(validationRepositoryContextKey{}).(*Repository)
+	return repo
+}
+
 // RegexValidator 通用正则验证器
 type RegexValidator struct {
 	Pattern      string